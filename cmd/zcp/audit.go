@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zeropsio/zcp/internal/audit"
+)
+
+const auditUsage = `Usage: zcp audit [flags]
+
+Lists tool calls recorded in .zcp/state/audit/ (oldest first).
+
+  --tool <name>       Only calls to this tool, e.g. zerops_deploy
+  --service <host>    Only calls targeting this service hostname
+  --since <when>      Duration ago (2h, 30m) or RFC3339 / YYYY-MM-DD
+  --until <when>      Same formats as --since
+  --summary           Per-tool and per-service counts instead of entries
+  --json              Emit JSON instead of a table
+  --dir <path>        Audit directory (default: ./.zcp/state/audit)`
+
+type auditOptions struct {
+	dir     string
+	filter  audit.Filter
+	summary bool
+	json    bool
+}
+
+func runAudit(args []string) {
+	opts, err := parseAuditArgs(args, time.Now())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, auditUsage)
+		os.Exit(2)
+	}
+	if err := printAudit(os.Stdout, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "audit: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func parseAuditArgs(args []string, now time.Time) (auditOptions, error) {
+	opts := auditOptions{dir: audit.Dir(filepath.Join(".zcp", "state"))}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--summary":
+			opts.summary = true
+		case "--json":
+			opts.json = true
+		case "--tool", "--service", "--since", "--until", "--dir":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s requires a value", args[i])
+			}
+			val := args[i+1]
+			i++
+			var err error
+			switch args[i-1] {
+			case "--tool":
+				opts.filter.Tool = val
+			case "--service":
+				opts.filter.Service = val
+			case "--dir":
+				opts.dir = val
+			case "--since":
+				opts.filter.Since, err = parseAuditTime(val, now)
+			case "--until":
+				opts.filter.Until, err = parseAuditTime(val, now)
+			}
+			if err != nil {
+				return opts, err
+			}
+		case "-h", "--help":
+			return opts, errors.New("help requested")
+		default:
+			return opts, fmt.Errorf("unknown audit flag: %s", args[i])
+		}
+	}
+	return opts, nil
+}
+
+// parseAuditTime accepts a Go duration (relative to now), an RFC3339
+// timestamp, or a bare UTC date.
+func parseAuditTime(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use a duration (2h), RFC3339 or YYYY-MM-DD", s)
+}
+
+func printAudit(w io.Writer, opts auditOptions) error {
+	entries, err := audit.Read(opts.dir, opts.filter)
+	if err != nil {
+		return err
+	}
+
+	if opts.summary {
+		sum := audit.Summarize(entries)
+		if opts.json {
+			return writeAuditJSON(w, sum)
+		}
+		printAuditSummary(w, sum)
+		return nil
+	}
+
+	if opts.json {
+		if entries == nil {
+			entries = []audit.Entry{}
+		}
+		return writeAuditJSON(w, entries)
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No audit entries match.")
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tTOOL\tSTATUS\tMS\tSERVICES\tPROCESSES")
+	for _, e := range entries {
+		status := e.Status
+		if e.ErrorCode != "" {
+			status += " (" + e.ErrorCode + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n",
+			e.Time.Format(time.RFC3339), e.Tool, status, e.DurationMs,
+			dashIfEmpty(strings.Join(e.Services, ",")), dashIfEmpty(strings.Join(e.ProcessIDs, ",")))
+	}
+	return tw.Flush()
+}
+
+func printAuditSummary(w io.Writer, sum audit.Summary) {
+	if sum.Calls == 0 {
+		fmt.Fprintln(w, "No audit entries match.")
+		return
+	}
+	fmt.Fprintf(w, "%d calls, %d errors, %d processes spawned (%s — %s)\n\n",
+		sum.Calls, sum.Errors, sum.Processes, sum.First.Format(time.RFC3339), sum.Last.Format(time.RFC3339))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TOOL\tCALLS\tERRORS\tAVG MS\tMAX MS\tPROCESSES")
+	for _, t := range sum.Tools {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\n", t.Tool, t.Calls, t.Errors, t.TotalMs/int64(t.Calls), t.MaxMs, t.Processes)
+	}
+	_ = tw.Flush()
+
+	if len(sum.Services) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVICE\tCALLS\tERRORS")
+	for _, s := range sum.Services {
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", s.Service, s.Calls, s.Errors)
+	}
+	_ = tw.Flush()
+}
+
+func writeAuditJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zcp/internal/audit"
+)
+
+func TestParseAuditArgs(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	opts, err := parseAuditArgs([]string{"--tool", "zerops_deploy", "--service", "appdev", "--since", "2h", "--until", "2026-03-02T11:30:00Z", "--summary", "--json"}, now)
+	if err != nil {
+		t.Fatalf("parseAuditArgs: %v", err)
+	}
+	if opts.filter.Tool != "zerops_deploy" || opts.filter.Service != "appdev" || !opts.summary || !opts.json {
+		t.Errorf("opts = %+v", opts)
+	}
+	if !opts.filter.Since.Equal(now.Add(-2 * time.Hour)) {
+		t.Errorf("since = %s, want 2h before now", opts.filter.Since)
+	}
+	if !opts.filter.Until.Equal(time.Date(2026, 3, 2, 11, 30, 0, 0, time.UTC)) {
+		t.Errorf("until = %s", opts.filter.Until)
+	}
+
+	for _, bad := range [][]string{{"--since", "yesterday"}, {"--tool"}, {"--verbose"}} {
+		if _, err := parseAuditArgs(bad, now); err == nil {
+			t.Errorf("parseAuditArgs(%v) = nil error, want error", bad)
+		}
+	}
+}
+
+func TestPrintAudit_TableAndSummary(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	log := audit.NewLog(dir)
+	ts := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	for _, e := range []audit.Entry{
+		{Time: ts, Tool: "zerops_deploy", Services: []string{"appdev"}, Status: audit.StatusOK, DurationMs: 1200, ProcessIDs: []string{"proc-1"}},
+		{Time: ts.Add(time.Minute), Tool: "zerops_manage", Services: []string{"db"}, Status: audit.StatusError, ErrorCode: "SERVICE_NOT_FOUND"},
+	} {
+		if err := log.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := printAudit(&buf, auditOptions{dir: dir, filter: audit.Filter{Service: "appdev"}}); err != nil {
+		t.Fatalf("printAudit: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "zerops_deploy") || !strings.Contains(out, "proc-1") || strings.Contains(out, "zerops_manage") {
+		t.Errorf("filtered table:\n%s", out)
+	}
+
+	buf.Reset()
+	if err := printAudit(&buf, auditOptions{dir: dir, summary: true}); err != nil {
+		t.Fatalf("printAudit summary: %v", err)
+	}
+	out = buf.String()
+	if !strings.Contains(out, "2 calls, 1 errors, 1 processes") || !strings.Contains(out, "SERVICE") {
+		t.Errorf("summary:\n%s", out)
+	}
+}
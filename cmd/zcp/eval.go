@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -48,6 +49,8 @@ func runEval(args []string) {
 		runEvalResults(args[1:])
 	case "triage":
 		runEvalTriage(args[1:])
+	case "trends":
+		runEvalTrends(args[1:])
 	case "behavioral":
 		runEvalBehavioral(args[1:])
 	default:
@@ -62,7 +65,7 @@ func printEvalUsage() {
 
 Commands:
   run            --recipe <name>[,name...]      Run evaluation for specific recipes
-  scenario       --file <path>                  Run a single scenario file
+  scenario       --file <path> [--simulate]     Run a single scenario file (offline with --simulate)
   scenario-suite --ids <a,b,...> | --files <p,p>  Run scenarios sequentially under one suite ID
   suite          [--tag <tag>]                  Run evaluation for all recipes
   create         --framework <name> --tier <t>  Create a recipe via headless workflow
@@ -70,6 +73,8 @@ Commands:
   cleanup        [--prefix <prefix>]            Full project cleanup (or prefix-only with --prefix)
   results        [--suite <id>]                 Show latest results summary
   triage         [--suite <id>] [--out <path>]  Aggregate scenario suite EVAL REPORTs into triage.md
+  trends         [--out <dir>] [--threshold <pct>] [--fail-on-regression]
+                                                Cross-suite time series + regressions (trends.md, trends.json)
   behavioral     <list|run|all> [args...]       Two-shot resume scenario runs (interactive C4 eval)`)
 }
 
@@ -112,11 +117,17 @@ func runEvalRun(args []string) {
 }
 
 func runEvalScenario(args []string) {
-	var path string
+	var (
+		path     string
+		simulate bool
+	)
 	for i := 0; i < len(args); i++ {
-		if args[i] == "--file" && i+1 < len(args) {
+		switch {
+		case args[i] == "--file" && i+1 < len(args):
 			path = args[i+1]
 			i++
+		case args[i] == "--simulate":
+			simulate = true
 		}
 	}
 	if path == "" {
@@ -128,8 +139,16 @@ func runEvalScenario(args []string) {
 		os.Exit(1)
 	}
 
-	runner, _, ctx := initEvalRunner()
 	suiteID := time.Now().Format("2006-01-02-150405")
+	var (
+		runner *eval.Runner
+		ctx    context.Context
+	)
+	if simulate {
+		runner, ctx = initSimulatedEvalRunner(suiteID)
+	} else {
+		runner, _, ctx = initEvalRunner()
+	}
 
 	fmt.Fprintf(os.Stderr, "Running scenario: %s (suite=%s)\n", path, suiteID)
 	result, err := runner.RunScenario(ctx, path, suiteID)
@@ -539,6 +558,82 @@ func runEvalTriage(args []string) {
 		outPath, len(triage.Scenarios), countTriageFailures(triage), len(triage.GroupedByRootCause))
 }
 
+// runEvalTrends loads every scenario suite under the results dir, builds
+// per-scenario time series and writes trends.md + trends.json. Output
+// defaults to a "trends" dir next to the results dir — inside it, the
+// files would be mistaken for the latest suite by `results` / `triage`.
+// --fail-on-regression exits 2 when any regression is flagged, for CI.
+func runEvalTrends(args []string) {
+	resultsDir := evalResultsDir()
+
+	var outDir string
+	var opts eval.TrendOptions
+	failOnRegression := false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--out":
+			if i+1 < len(args) {
+				outDir = args[i+1]
+				i++
+			}
+		case "--threshold":
+			if i+1 < len(args) {
+				pct, err := strconv.ParseFloat(strings.TrimSuffix(args[i+1], "%"), 64)
+				if err != nil || pct <= 0 {
+					fmt.Fprintf(os.Stderr, "error: --threshold must be a positive percentage, got %q\n", args[i+1])
+					os.Exit(1)
+				}
+				opts.ToolCallThresholdPct = pct
+				i++
+			}
+		case "--fail-on-regression":
+			failOnRegression = true
+		}
+	}
+	if outDir == "" {
+		outDir = filepath.Join(filepath.Dir(filepath.Clean(resultsDir)), "trends")
+	}
+
+	suites, err := eval.LoadTrendSuites(resultsDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(suites) == 0 {
+		fmt.Fprintf(os.Stderr, "error: no scenario suites found in %s\n", resultsDir)
+		os.Exit(1)
+	}
+	report := eval.BuildTrends(suites, opts)
+
+	reportJSON, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: marshal trends: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "error: create %s: %v\n", outDir, err)
+		os.Exit(1)
+	}
+	mdPath := filepath.Join(outDir, "trends.md")
+	jsonPath := filepath.Join(outDir, "trends.json")
+	if err := os.WriteFile(mdPath, []byte(eval.RenderTrendsMarkdown(report)), 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "error: write %s: %v\n", mdPath, err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(jsonPath, reportJSON, 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "error: write %s: %v\n", jsonPath, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Trends written: %s, %s (%d suites, %d scenarios, %d regressions)\n",
+		mdPath, jsonPath, len(report.Suites), len(report.Scenarios), len(report.Regressions))
+	for _, reg := range report.Regressions {
+		fmt.Fprintf(os.Stderr, "  REGRESSION %s [%s] %s → %s: %s\n", reg.ScenarioID, reg.Kind, reg.FromSuite, reg.ToSuite, reg.Detail)
+	}
+	if failOnRegression && len(report.Regressions) > 0 {
+		os.Exit(2)
+	}
+}
+
 func countTriageFailures(t eval.Triage) int {
 	n := 0
 	for _, entries := range t.GroupedByRootCause {
@@ -612,7 +707,33 @@ func initPlatformClient() (platform.Client, string, context.Context) {
 
 func initEvalRunner() (*eval.Runner, *knowledge.Store, context.Context) {
 	client, projectID, ctx := initPlatformClient()
+	runner, store := newEvalRunner(client, projectID)
+	return runner, store, ctx
+}
 
+// initSimulatedEvalRunner runs the scenario against a simulated project
+// persisted under the suite's results dir. The state file is exported
+// via ZCP_SIM_STATE so the `zcp serve` Claude spawns works on the same
+// project the runner seeds and grades.
+func initSimulatedEvalRunner(suiteID string) (*eval.Runner, context.Context) {
+	statePath := filepath.Join(evalResultsDir(), suiteID, "sim-state.json")
+	s, err := openSimulation(statePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.Setenv(simStateEnv, statePath); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Simulating project %s (state: %s)\n", s.ProjectID(), statePath)
+
+	runner, _ := newEvalRunner(s, s.ProjectID())
+	ctx, _ := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	return runner.WithHTTPDoer(s), ctx
+}
+
+func newEvalRunner(client platform.Client, projectID string) (*eval.Runner, *knowledge.Store) {
 	store, err := knowledge.GetEmbeddedStore()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "knowledge store error: %v\n", err)
@@ -625,8 +746,7 @@ func initEvalRunner() (*eval.Runner, *knowledge.Store, context.Context) {
 		WorkDir:    evalWorkDir(),
 	}
 
-	runner := eval.NewRunner(config, store, client, projectID)
-	return runner, store, ctx
+	return eval.NewRunner(config, store, client, projectID), store
 }
 
 func printSuiteResult(result *eval.SuiteResult) {
@@ -61,7 +61,7 @@ func main() {
 			printVersion()
 			return
 		case "update":
-			runUpdate()
+			runUpdate(os.Args[2:])
 			return
 		case "eval":
 			runEval(os.Args[2:])
@@ -81,9 +81,78 @@ func main() {
 		case "analyze":
 			analyze.Run(os.Args[2:])
 			return
+		case "audit":
+			runAudit(os.Args[2:])
+			return
+		case "replay":
+			runReplay(os.Args[2:])
+			return
+		case "serve":
+			opts, err := parseServeArgs(os.Args[2:])
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				fmt.Fprintln(os.Stderr, serveUsage)
+				os.Exit(2)
+			}
+			serve(opts)
+			return
 		}
 	}
 
+	serve(serveOptions{simState: os.Getenv(simStateEnv), simulate: os.Getenv(simStateEnv) != ""})
+}
+
+// serveOptions selects the MCP transport. Zero value is STDIO — what a
+// bare `zcp` invocation from an MCP client config runs.
+type serveOptions struct {
+	httpAddr string // non-empty → streamable HTTP on this address
+	token    string // bearer token for HTTP; defaults to ZCP_HTTP_TOKEN
+	simulate bool   // serve against the offline simulator, not the Zerops API
+	simState string // simulator state file; defaults to ZCP_SIM_STATE
+}
+
+const serveUsage = `Usage: zcp serve [--http <addr>] [--token <token>] [--simulate [--sim-state <file>]]
+
+  (no flags)          Serve MCP over STDIO (same as bare zcp)
+  --http <addr>       Serve MCP over streamable HTTP, e.g. --http :8080
+  --token <token>     Bearer token clients must send (default: $ZCP_HTTP_TOKEN)
+  --simulate          Run against an in-memory simulated project; no network access
+  --sim-state <file>  Persist the simulated project in <file> (default: $ZCP_SIM_STATE;
+                      setting it implies --simulate)`
+
+func parseServeArgs(args []string) (serveOptions, error) {
+	opts := serveOptions{token: os.Getenv("ZCP_HTTP_TOKEN"), simState: os.Getenv(simStateEnv)}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--simulate":
+			opts.simulate = true
+		case "--http", "--token", "--sim-state":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s requires a value", args[i])
+			}
+			switch args[i] {
+			case "--http":
+				opts.httpAddr = args[i+1]
+			case "--token":
+				opts.token = args[i+1]
+			default:
+				opts.simState = args[i+1]
+			}
+			i++
+		default:
+			return opts, fmt.Errorf("unknown serve flag: %s", args[i])
+		}
+	}
+	if opts.httpAddr != "" && opts.token == "" {
+		return opts, errors.New("--http requires a bearer token (--token or ZCP_HTTP_TOKEN)")
+	}
+	if opts.simState != "" {
+		opts.simulate = true
+	}
+	return opts, nil
+}
+
+func serve(opts serveOptions) {
 	// Ignore SIGPIPE: when Claude Code closes the stdio pipe, Go's default
 	// behavior kills the process on writes to fd 1/2. Converting SIGPIPE to
 	// EPIPE errors lets the MCP SDK shut down gracefully instead.
@@ -93,7 +162,7 @@ func main() {
 	crashLog := setupCrashLog()
 	startedAt := time.Now()
 
-	srv, err := run()
+	srv, err := run(opts)
 	logShutdown(crashLog, err, startedAt, srv)
 
 	if err != nil && !errors.Is(err, context.Canceled) {
@@ -105,7 +174,42 @@ func printVersion() {
 	fmt.Fprintf(os.Stdout, "zcp %s (%s, %s)\n", server.Version, server.Commit, server.Built)
 }
 
-func runUpdate() {
+const updateUsage = `Usage: zcp update [--rollback]
+
+  (no flags)   Download, verify and install the latest release
+  --rollback   Restore the binary replaced by the last update (zcp.prev)`
+
+func runUpdate(args []string) {
+	rollback := false
+	for _, arg := range args {
+		switch arg {
+		case "--rollback":
+			rollback = true
+		default:
+			fmt.Fprintf(os.Stderr, "unknown update flag: %s\n", arg)
+			fmt.Fprintln(os.Stderr, updateUsage)
+			os.Exit(2)
+		}
+	}
+
+	binary, err := os.Executable()
+	if err != nil {
+		log.Fatalf("resolve executable: %v", err)
+	}
+	// Same as the startup auto-update: act on the real binary, not a symlink.
+	if resolved, err := filepath.EvalSymlinks(binary); err == nil {
+		binary = resolved
+	}
+
+	if rollback {
+		if err := update.Rollback(binary); err != nil {
+			log.Fatalf("rollback: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "Rolled back %s (the replaced version is now %s). Restart ZCP to use it.\n",
+			binary, filepath.Base(update.PreviousPath(binary)))
+		return
+	}
+
 	ctx := context.Background()
 
 	fmt.Fprintln(os.Stderr, "Checking for updates...")
@@ -121,16 +225,15 @@ func runUpdate() {
 	fmt.Fprintf(os.Stderr, "Update available: %s → %s\n", info.CurrentVersion, info.LatestVersion)
 	fmt.Fprintln(os.Stderr, "Downloading...")
 
-	binary, err := os.Executable()
-	if err != nil {
-		log.Fatalf("resolve executable: %v", err)
-	}
-
 	if err := update.Apply(ctx, info, binary, nil); err != nil {
 		log.Fatalf("update: %v", err)
 	}
 
 	fmt.Fprintln(os.Stderr, "Updated successfully. Restart ZCP to use the new version.")
+	if _, err := os.Stat(update.PreviousPath(binary)); err == nil {
+		fmt.Fprintf(os.Stderr, "Previous version kept as %s; undo with: zcp update --rollback\n",
+			filepath.Base(update.PreviousPath(binary)))
+	}
 }
 
 // setupCrashLog opens ~/.zcp/serve.log for append, creating the directory if
@@ -189,7 +292,14 @@ func logShutdown(f io.WriteCloser, err error, startedAt time.Time, srv *server.S
 		ts, reason, pid, uptime, calls)
 }
 
-func run() (*server.Server, error) {
+func run(opts serveOptions) (*server.Server, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if opts.simulate {
+		return runSimulated(ctx, opts)
+	}
+
 	// Bootstrap: resolve credentials (env var or zcli) to create platform client.
 	creds, err := auth.ResolveCredentials()
 	if err != nil {
@@ -201,9 +311,6 @@ func run() (*server.Server, error) {
 		return nil, fmt.Errorf("create platform client: %w", err)
 	}
 
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
-
 	// Full auth: validate token via API and discover project.
 	authInfo, err := auth.Resolve(ctx, client)
 	if err != nil {
@@ -242,7 +349,7 @@ func run() (*server.Server, error) {
 		sshDeployer = platform.NewSystemSSHDeployer()
 	}
 
-	// Create and run MCP server on STDIO.
+	// Create the MCP server; the transport is chosen below.
 	srv := server.New(ctx, client, authInfo, store, logFetcher, sshDeployer, mounter, rtInfo)
 
 	// Silent background update — completely invisible to LLM.
@@ -252,9 +359,41 @@ func run() (*server.Server, error) {
 		go update.Once(ctx, server.Version, os.Stderr)
 	}
 
-	err = srv.Run(ctx)
+	return srv, runServer(ctx, srv, opts)
+}
+
+// runSimulated serves against the offline simulator: it stands in for
+// the API, the log backend and the network, so no credentials or
+// Zerops containers are involved and auto-update stays off.
+func runSimulated(ctx context.Context, opts serveOptions) (*server.Server, error) {
+	s, err := openSimulation(opts.simState)
+	if err != nil {
+		return nil, err
+	}
+	authInfo, err := simAuthInfo(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	store, err := knowledge.GetEmbeddedStore()
+	if err != nil {
+		return nil, fmt.Errorf("knowledge store: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "zcp: serving simulated project %s (%s)\n", authInfo.ProjectName, authInfo.ProjectID)
+
+	srv := server.New(ctx, s, authInfo, store, s, nil, nil, runtime.Info{}, server.WithHTTPDoer(s))
+	return srv, runServer(ctx, srv, opts)
+}
+
+// runServer runs srv on the transport opts selects.
+func runServer(ctx context.Context, srv *server.Server, opts serveOptions) error {
+	var err error
+	if opts.httpAddr != "" {
+		err = srv.RunHTTP(ctx, opts.httpAddr, opts.token)
+	} else {
+		err = srv.Run(ctx)
+	}
 	if err != nil && !errors.Is(err, context.Canceled) {
-		return srv, fmt.Errorf("server: %w", err)
+		return fmt.Errorf("server: %w", err)
 	}
-	return srv, err
+	return err
 }
@@ -69,3 +69,74 @@ func TestLogShutdown_NilWriter(t *testing.T) {
 	// Must not panic with nil writer.
 	logShutdown(nil, nil, time.Now(), nil)
 }
+
+func TestParseServeArgs(t *testing.T) {
+	t.Setenv("ZCP_HTTP_TOKEN", "from-env")
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantAddr  string
+		wantToken string
+		wantErr   string
+	}{
+		{name: "no flags is stdio", args: nil, wantToken: "from-env"},
+		{name: "http with env token", args: []string{"--http", ":8080"}, wantAddr: ":8080", wantToken: "from-env"},
+		{name: "flag token wins", args: []string{"--http", ":8080", "--token", "flag"}, wantAddr: ":8080", wantToken: "flag"},
+		{name: "missing value", args: []string{"--http"}, wantErr: "requires a value"},
+		{name: "unknown flag", args: []string{"--stdio"}, wantErr: "unknown serve flag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseServeArgs(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if opts.httpAddr != tt.wantAddr || opts.token != tt.wantToken {
+				t.Errorf("opts = %+v, want addr=%q token=%q", opts, tt.wantAddr, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestParseServeArgs_HTTPWithoutToken(t *testing.T) {
+	t.Setenv("ZCP_HTTP_TOKEN", "")
+	if _, err := parseServeArgs([]string{"--http", ":8080"}); err == nil {
+		t.Fatal("expected error for --http without token")
+	}
+}
+
+func TestParseServeArgs_Simulate(t *testing.T) {
+	t.Setenv("ZCP_HTTP_TOKEN", "")
+
+	tests := []struct {
+		name         string
+		env          string
+		args         []string
+		wantSimulate bool
+		wantState    string
+	}{
+		{name: "off by default", args: nil},
+		{name: "in-memory", args: []string{"--simulate"}, wantSimulate: true},
+		{name: "state flag implies simulate", args: []string{"--sim-state", "/tmp/s.json"}, wantSimulate: true, wantState: "/tmp/s.json"},
+		{name: "state env implies simulate", env: "/tmp/env.json", wantSimulate: true, wantState: "/tmp/env.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(simStateEnv, tt.env)
+			opts, err := parseServeArgs(tt.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if opts.simulate != tt.wantSimulate || opts.simState != tt.wantState {
+				t.Errorf("opts = %+v, want simulate=%v state=%q", opts, tt.wantSimulate, tt.wantState)
+			}
+		})
+	}
+}
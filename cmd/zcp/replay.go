@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/zeropsio/zcp/internal/auth"
+	"github.com/zeropsio/zcp/internal/eval"
+	"github.com/zeropsio/zcp/internal/knowledge"
+	"github.com/zeropsio/zcp/internal/platform"
+	"github.com/zeropsio/zcp/internal/runtime"
+	"github.com/zeropsio/zcp/internal/server"
+)
+
+const replayUsage = `Usage: zcp replay <transcript.jsonl> [flags]
+
+Re-issues every zerops_* tool call recorded in a stream-json transcript
+(an eval log.jsonl) against an in-process server and diffs each response
+with the recorded one. Exits 1 on any divergence, so it can drive
+` + "`git bisect run`" + `.
+
+  --mock              Back the server with the static platform mock
+                      (default: a fresh simulated project)
+  --sim-state <file>  Start from this simulator state file (copied, never modified)
+  --workdir <dir>     Working directory for the server's .zcp/state
+                      (default: a temporary directory)
+  --out <file>        Write the full report as JSON
+  --verbose           Print the diff of every divergent step, not just the first`
+
+type replayOptions struct {
+	transcript string
+	mock       bool
+	simState   string
+	workDir    string
+	out        string
+	verbose    bool
+}
+
+func runReplay(args []string) {
+	opts, err := parseReplayArgs(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, replayUsage)
+		os.Exit(2)
+	}
+	// replayTranscript changes directory; pin file arguments first.
+	for _, p := range []*string{&opts.transcript, &opts.simState, &opts.out} {
+		if *p == "" {
+			continue
+		}
+		if *p, err = filepath.Abs(*p); err != nil {
+			fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	report, err := replayTranscript(ctx, opts)
+	stop()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+		os.Exit(2)
+	}
+	printReplayReport(os.Stdout, report, opts.verbose)
+	if opts.out != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err == nil {
+			err = os.WriteFile(opts.out, data, 0o600)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "replay: write report: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	if report.Diverged > 0 {
+		os.Exit(1)
+	}
+}
+
+func parseReplayArgs(args []string) (replayOptions, error) {
+	var opts replayOptions
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--mock":
+			opts.mock = true
+		case "--verbose", "-v":
+			opts.verbose = true
+		case "--sim-state", "--workdir", "--out":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s requires a value", args[i])
+			}
+			switch args[i] {
+			case "--sim-state":
+				opts.simState = args[i+1]
+			case "--workdir":
+				opts.workDir = args[i+1]
+			default:
+				opts.out = args[i+1]
+			}
+			i++
+		case "-h", "--help":
+			return opts, errors.New("help requested")
+		default:
+			if strings.HasPrefix(args[i], "-") {
+				return opts, fmt.Errorf("unknown replay flag: %s", args[i])
+			}
+			if opts.transcript != "" {
+				return opts, fmt.Errorf("unexpected argument: %s", args[i])
+			}
+			opts.transcript = args[i]
+		}
+	}
+	if opts.transcript == "" {
+		return opts, errors.New("transcript path is required")
+	}
+	if opts.mock && opts.simState != "" {
+		return opts, errors.New("--mock and --sim-state are mutually exclusive")
+	}
+	return opts, nil
+}
+
+// replayTranscript builds the replay server and runs the recorded calls
+// through it. The server derives its .zcp/state from the working
+// directory, so the process moves into opts.workDir (or a temp dir)
+// first — a replay must never touch the caller's real project state.
+func replayTranscript(ctx context.Context, opts replayOptions) (*eval.ReplayReport, error) {
+	steps, err := eval.LoadReplaySteps(opts.transcript)
+	if err != nil {
+		return nil, err
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("%s: no zerops_* tool calls recorded", opts.transcript)
+	}
+
+	workDir := opts.workDir
+	if workDir == "" {
+		tmp, err := os.MkdirTemp("", "zcp-replay-*")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(tmp)
+		workDir = tmp
+	}
+	if err := os.Chdir(workDir); err != nil {
+		return nil, fmt.Errorf("workdir: %w", err)
+	}
+
+	srv, err := newReplayServer(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return eval.Replay(ctx, srv.MCPServer(), opts.transcript, steps)
+}
+
+func newReplayServer(ctx context.Context, opts replayOptions) (*server.Server, error) {
+	store, err := knowledge.GetEmbeddedStore()
+	if err != nil {
+		return nil, fmt.Errorf("knowledge store: %w", err)
+	}
+	if opts.mock {
+		authInfo := &auth.Info{
+			ProjectID: "proj-1", ProjectName: "replay", Token: "replay",
+			APIHost: "mock", Region: "mock", ClientID: "client-1",
+		}
+		return server.New(ctx, platform.NewMock(), authInfo, store, platform.NewMockLogFetcher(), nil, nil, runtime.Info{}), nil
+	}
+
+	statePath := ""
+	if opts.simState != "" {
+		// The simulator writes through to its state file; replay works on
+		// a copy so the same state can seed every bisect step.
+		data, err := os.ReadFile(opts.simState)
+		if err != nil {
+			return nil, fmt.Errorf("sim state: %w", err)
+		}
+		statePath = "replay-sim-state.json"
+		if err := os.WriteFile(statePath, data, 0o600); err != nil {
+			return nil, fmt.Errorf("sim state: %w", err)
+		}
+	}
+	s, err := openSimulation(statePath)
+	if err != nil {
+		return nil, err
+	}
+	authInfo, err := simAuthInfo(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	return server.New(ctx, s, authInfo, store, s, nil, nil, runtime.Info{}, server.WithHTTPDoer(s)), nil
+}
+
+// printReplayReport prints one line per step and the diff of the first
+// divergence (every divergence with verbose).
+func printReplayReport(w io.Writer, r *eval.ReplayReport, verbose bool) {
+	first := r.FirstDivergence()
+	for _, res := range r.Results {
+		status := "ok"
+		switch {
+		case !res.Compared:
+			status = "--"
+		case !res.Match:
+			status = "DIFF"
+		}
+		fmt.Fprintf(w, "%4d  %-4s  %s %s\n", res.Seq, status, res.Name, compactInput(res.Input))
+		if res.Error != "" {
+			fmt.Fprintf(w, "      error: %s\n", res.Error)
+		}
+		if res.Compared && !res.Match && (verbose || res.Seq == first.Seq) {
+			if res.RecordedIsError != res.ReplayedIsError {
+				fmt.Fprintf(w, "      isError: recorded %t, replayed %t\n", res.RecordedIsError, res.ReplayedIsError)
+			}
+			for line := range strings.SplitSeq(strings.TrimRight(res.Diff, "\n"), "\n") {
+				if line != "" {
+					fmt.Fprintf(w, "      %s\n", line)
+				}
+			}
+		}
+	}
+	fmt.Fprintf(w, "\n%d steps: %d matched, %d diverged, %d not compared\n", r.Steps, r.Matched, r.Diverged, r.Uncompared)
+	if first != nil {
+		fmt.Fprintf(w, "first divergence: step %d (%s)\n", first.Seq, first.Name)
+	}
+}
+
+// compactInput shortens a recorded input for the one-line step listing.
+func compactInput(raw json.RawMessage) string {
+	const maxLen = 80
+	s := string(raw)
+	if s == "{}" {
+		return ""
+	}
+	if len(s) > maxLen {
+		s = s[:maxLen-1] + "…"
+	}
+	return s
+}
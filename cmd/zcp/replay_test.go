@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestParseReplayArgs(t *testing.T) {
+	t.Parallel()
+
+	opts, err := parseReplayArgs([]string{"log.jsonl", "--sim-state", "state.json", "--out", "report.json", "-v"})
+	if err != nil {
+		t.Fatalf("parseReplayArgs: %v", err)
+	}
+	if opts.transcript != "log.jsonl" || opts.simState != "state.json" || opts.out != "report.json" || !opts.verbose || opts.mock {
+		t.Errorf("opts = %+v", opts)
+	}
+
+	for _, bad := range [][]string{{}, {"--mock"}, {"a.jsonl", "b.jsonl"}, {"a.jsonl", "--out"}, {"a.jsonl", "--mock", "--sim-state", "s.json"}, {"a.jsonl", "--fast"}} {
+		if _, err := parseReplayArgs(bad); err == nil {
+			t.Errorf("parseReplayArgs(%v) = nil error, want error", bad)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zeropsio/zcp/internal/auth"
+	"github.com/zeropsio/zcp/internal/platform/sim"
+)
+
+// simStateEnv names the simulator state file. Setting it implies
+// --simulate, which is how `zcp eval scenario --simulate` hands its
+// seeded project to the `zcp serve` that Claude spawns.
+const simStateEnv = "ZCP_SIM_STATE"
+
+// openSimulation opens the simulated platform, file-backed when path is
+// set so several processes can share one project.
+func openSimulation(path string) (*sim.Sim, error) {
+	if path == "" {
+		return sim.New(sim.Options{}), nil
+	}
+	s, err := sim.Open(path, sim.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("simulation: %w", err)
+	}
+	return s, nil
+}
+
+// simAuthInfo is what auth.Resolve would return against the simulator;
+// there are no credentials to read.
+func simAuthInfo(ctx context.Context, s *sim.Sim) (*auth.Info, error) {
+	user, err := s.GetUserInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("simulation: %w", err)
+	}
+	project, err := s.GetProject(ctx, s.ProjectID())
+	if err != nil {
+		return nil, fmt.Errorf("simulation: %w", err)
+	}
+	return &auth.Info{
+		Token:       s.Token(),
+		APIHost:     "sim",
+		Region:      "sim",
+		ClientID:    user.ID,
+		Email:       user.Email,
+		FullName:    user.FullName,
+		ProjectID:   project.ID,
+		ProjectName: project.Name,
+	}, nil
+}
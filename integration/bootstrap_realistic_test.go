@@ -137,7 +137,7 @@ func setupRealisticServer(t *testing.T, mock *platform.Mock) (*mcp.ClientSession
 	tools.RegisterKnowledge(mcpSrv, store, mock, nil, nil, nil)
 	tools.RegisterImport(mcpSrv, mock, projectID, engine, "", nil)
 	tools.RegisterProcess(mcpSrv, mock)
-	tools.RegisterMount(mcpSrv, mock, projectID, &nopMounter{}, runtime.Info{}, "", engine, nil)
+	tools.RegisterMount(mcpSrv, mock, projectID, &nopMounter{}, nil, runtime.Info{}, "", engine, nil)
 	tools.RegisterDeploySSH(mcpSrv, mock, nopHTTPDoer{}, projectID, &nopSSH{}, authInfo, logFetcher, runtime.Info{}, "", engine, nil)
 	tools.RegisterSubdomain(mcpSrv, mock, nopHTTPDoer{}, projectID, "")
 	tools.RegisterLogs(mcpSrv, mock, logFetcher, projectID)
@@ -0,0 +1,171 @@
+// Package audit persists an append-only record of every MCP tool call.
+//
+// Entries are JSONL, one file per UTC day under <stateDir>/audit/, so a
+// long-lived container never grows a single unbounded file and old days
+// can be pruned with rm. Arguments are redacted before they reach disk
+// (see Redact) — the log is meant to be shareable in bug reports without
+// leaking env values, tokens or import-time secrets.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Result status values recorded per entry.
+const (
+	StatusOK    = "ok"
+	StatusError = "error" // handler returned IsError (platform / validation error)
+	StatusFault = "fault" // protocol-level failure: unknown tool, bad arguments, panic
+)
+
+const (
+	dirName    = "audit"
+	filePrefix = "audit-"
+	fileSuffix = ".jsonl"
+	dayLayout  = "2006-01-02"
+)
+
+// Entry is one recorded tool call.
+type Entry struct {
+	Time       time.Time      `json:"ts"`
+	Tool       string         `json:"tool"`
+	Args       map[string]any `json:"args,omitempty"`
+	Services   []string       `json:"services,omitempty"`
+	DurationMs int64          `json:"durationMs"`
+	Status     string         `json:"status"`
+	ErrorCode  string         `json:"errorCode,omitempty"`
+	ProcessIDs []string       `json:"processIds,omitempty"`
+	Session    string         `json:"session,omitempty"`
+	PID        int            `json:"pid"`
+}
+
+// Dir returns the audit directory for a state dir.
+func Dir(stateDir string) string {
+	return filepath.Join(stateDir, dirName)
+}
+
+// Log appends entries to the day files in one directory. Safe for
+// concurrent use — HTTP mode serves many sessions from one process.
+type Log struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewLog returns a Log writing under dir. The directory is created lazily
+// on first append so a read-only session never touches the filesystem.
+func NewLog(dir string) *Log {
+	return &Log{dir: dir}
+}
+
+// Append writes one entry to the file for the entry's UTC day.
+func (l *Log) Append(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Time = e.Time.UTC()
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(l.dir, 0o755); err != nil {
+		return fmt.Errorf("create audit dir: %w", err)
+	}
+	path := filepath.Join(l.dir, filePrefix+e.Time.Format(dayLayout)+fileSuffix)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(line); err != nil {
+		return fmt.Errorf("write audit entry: %w", err)
+	}
+	return nil
+}
+
+// Read loads every entry in dir matching f, oldest first. Day files
+// outside [f.Since, f.Until] are skipped without being opened. A missing
+// directory yields no entries and no error. Malformed lines (a crash
+// mid-write) are skipped rather than failing the whole read.
+func Read(dir string, f Filter) ([]Entry, error) {
+	names, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read audit dir: %w", err)
+	}
+
+	var files []string
+	for _, de := range names {
+		name := de.Name()
+		if de.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		day, err := time.Parse(dayLayout, strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix))
+		if err != nil {
+			continue
+		}
+		if !f.Since.IsZero() && day.Add(24*time.Hour).Before(f.Since) {
+			continue
+		}
+		if !f.Until.IsZero() && day.After(f.Until) {
+			continue
+		}
+		files = append(files, filepath.Join(dir, name))
+	}
+	sort.Strings(files)
+
+	var out []Entry
+	for _, path := range files {
+		entries, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if f.Match(e) {
+				out = append(out, e)
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out, nil
+}
+
+func readFile(path string) ([]Entry, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	defer fh.Close()
+
+	var out []Entry
+	scanner := bufio.NewScanner(fh)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			continue
+		}
+		out = append(out, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan audit log %s: %w", path, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,115 @@
+// Tests for: audit/audit.go, audit/query.go — JSONL append, read, filter, summary.
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLog_AppendAndReadAcrossDays(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "audit")
+	log := NewLog(dir)
+	day1 := time.Date(2026, 3, 1, 23, 59, 0, 0, time.UTC)
+	day2 := day1.Add(2 * time.Minute)
+
+	entries := []Entry{
+		{Time: day2, Tool: "zerops_manage", Services: []string{"db"}, Status: StatusOK, DurationMs: 30},
+		{Time: day1, Tool: "zerops_deploy", Services: []string{"appdev"}, Status: StatusOK, DurationMs: 900, ProcessIDs: []string{"p1"}},
+		{Time: day2.Add(time.Second), Tool: "zerops_deploy", Services: []string{"appdev"}, Status: StatusError, ErrorCode: "INVALID_PARAMETER"},
+	}
+	for _, e := range entries {
+		if err := log.Append(e); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "audit-*.jsonl"))
+	if len(files) != 2 {
+		t.Fatalf("day files = %v, want 2", files)
+	}
+
+	all, err := Read(dir, Filter{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(all) != 3 || all[0].Tool != "zerops_deploy" || !all[0].Time.Equal(day1) {
+		t.Fatalf("Read order = %+v, want oldest first", all)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   int
+	}{
+		{"by tool", Filter{Tool: "zerops_deploy"}, 2},
+		{"by service", Filter{Service: "db"}, 1},
+		{"since skips first day", Filter{Since: day2}, 2},
+		{"until skips second day", Filter{Until: day1}, 1},
+		{"tool and service", Filter{Tool: "zerops_manage", Service: "appdev"}, 0},
+	}
+	for _, tt := range tests {
+		got, err := Read(dir, tt.filter)
+		if err != nil {
+			t.Fatalf("%s: Read: %v", tt.name, err)
+		}
+		if len(got) != tt.want {
+			t.Errorf("%s: got %d entries, want %d", tt.name, len(got), tt.want)
+		}
+	}
+}
+
+func TestRead_MissingDirAndCorruptLines(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	got, err := Read(filepath.Join(dir, "nope"), Filter{})
+	if err != nil || got != nil {
+		t.Fatalf("missing dir: got %v, %v; want nil, nil", got, err)
+	}
+
+	body := `{"ts":"2026-03-01T10:00:00Z","tool":"zerops_env","status":"ok","pid":1}
+{"ts":"2026-03-01T10:00:01Z","tool":"zerops_e
+` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "audit-2026-03-01.jsonl"), []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err = Read(dir, Filter{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(got) != 1 {
+		t.Errorf("entries = %d, want 1 (truncated line skipped)", len(got))
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	sum := Summarize([]Entry{
+		{Time: base, Tool: "zerops_deploy", Services: []string{"appdev"}, Status: StatusOK, DurationMs: 100, ProcessIDs: []string{"a"}},
+		{Time: base.Add(time.Minute), Tool: "zerops_deploy", Services: []string{"appdev"}, Status: StatusError, DurationMs: 300},
+		{Time: base.Add(2 * time.Minute), Tool: "zerops_logs", Services: []string{"db"}, Status: StatusOK, DurationMs: 5},
+	})
+
+	if sum.Calls != 3 || sum.Errors != 1 || sum.Processes != 1 {
+		t.Errorf("totals = %d/%d/%d, want 3/1/1", sum.Calls, sum.Errors, sum.Processes)
+	}
+	if !sum.First.Equal(base) || !sum.Last.Equal(base.Add(2*time.Minute)) {
+		t.Errorf("range = %s..%s", sum.First, sum.Last)
+	}
+	if len(sum.Tools) != 2 || sum.Tools[0].Tool != "zerops_deploy" {
+		t.Fatalf("tools = %+v, want zerops_deploy first", sum.Tools)
+	}
+	deploy := sum.Tools[0]
+	if deploy.Calls != 2 || deploy.Errors != 1 || deploy.TotalMs != 400 || deploy.MaxMs != 300 || deploy.LastStatus != StatusError {
+		t.Errorf("deploy stat = %+v", deploy)
+	}
+	if len(sum.Services) != 2 || sum.Services[0].Service != "appdev" || sum.Services[0].Errors != 1 {
+		t.Errorf("services = %+v", sum.Services)
+	}
+}
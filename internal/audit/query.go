@@ -0,0 +1,132 @@
+package audit
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+// Filter selects entries. Zero fields match everything.
+type Filter struct {
+	Tool    string
+	Service string
+	Since   time.Time
+	Until   time.Time
+}
+
+// Match reports whether e passes every set field of f.
+func (f Filter) Match(e Entry) bool {
+	if f.Tool != "" && e.Tool != f.Tool {
+		return false
+	}
+	if f.Service != "" && !slices.Contains(e.Services, f.Service) {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && e.Time.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Summary aggregates a set of entries.
+type Summary struct {
+	Calls     int          `json:"calls"`
+	Errors    int          `json:"errors"`
+	Processes int          `json:"processes"`
+	First     time.Time    `json:"first,omitzero"`
+	Last      time.Time    `json:"last,omitzero"`
+	Tools     []ToolStat   `json:"tools"`
+	Services  []ServiceUse `json:"services,omitempty"`
+}
+
+// ToolStat is the per-tool breakdown of a Summary.
+type ToolStat struct {
+	Tool       string `json:"tool"`
+	Calls      int    `json:"calls"`
+	Errors     int    `json:"errors"`
+	TotalMs    int64  `json:"totalMs"`
+	MaxMs      int64  `json:"maxMs"`
+	Processes  int    `json:"processes"`
+	LastStatus string `json:"lastStatus"`
+}
+
+// ServiceUse counts calls touching one service hostname.
+type ServiceUse struct {
+	Service string `json:"service"`
+	Calls   int    `json:"calls"`
+	Errors  int    `json:"errors"`
+}
+
+// Summarize folds entries into per-tool and per-service counts. Tools are
+// ordered by call count (desc) then name, services the same way, so the
+// busiest surfaces lead the report.
+func Summarize(entries []Entry) Summary {
+	var s Summary
+	tools := map[string]*ToolStat{}
+	services := map[string]*ServiceUse{}
+
+	for _, e := range entries {
+		s.Calls++
+		failed := e.Status != StatusOK
+		if failed {
+			s.Errors++
+		}
+		s.Processes += len(e.ProcessIDs)
+		if s.First.IsZero() || e.Time.Before(s.First) {
+			s.First = e.Time
+		}
+		if e.Time.After(s.Last) {
+			s.Last = e.Time
+		}
+
+		ts, ok := tools[e.Tool]
+		if !ok {
+			ts = &ToolStat{Tool: e.Tool}
+			tools[e.Tool] = ts
+		}
+		ts.Calls++
+		ts.TotalMs += e.DurationMs
+		ts.MaxMs = max(ts.MaxMs, e.DurationMs)
+		ts.Processes += len(e.ProcessIDs)
+		ts.LastStatus = e.Status
+		if failed {
+			ts.Errors++
+		}
+
+		for _, host := range e.Services {
+			su, ok := services[host]
+			if !ok {
+				su = &ServiceUse{Service: host}
+				services[host] = su
+			}
+			su.Calls++
+			if failed {
+				su.Errors++
+			}
+		}
+	}
+
+	s.Tools = make([]ToolStat, 0, len(tools))
+	for _, ts := range tools {
+		s.Tools = append(s.Tools, *ts)
+	}
+	sort.Slice(s.Tools, func(i, j int) bool {
+		if s.Tools[i].Calls != s.Tools[j].Calls {
+			return s.Tools[i].Calls > s.Tools[j].Calls
+		}
+		return s.Tools[i].Tool < s.Tools[j].Tool
+	})
+	for _, su := range services {
+		s.Services = append(s.Services, *su)
+	}
+	sort.Slice(s.Services, func(i, j int) bool {
+		if s.Services[i].Calls != s.Services[j].Calls {
+			return s.Services[i].Calls > s.Services[j].Calls
+		}
+		return s.Services[i].Service < s.Services[j].Service
+	})
+	return s
+}
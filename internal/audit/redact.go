@@ -0,0 +1,190 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	redacted = "[redacted]"
+
+	// maxStringLen truncates long free-form arguments (YAML bodies, file
+	// contents, commands). The audit log records what was asked, not the
+	// payload — the payload is already on disk or on the platform.
+	maxStringLen = 512
+)
+
+// sensitiveKeyParts flags argument keys whose value is never written,
+// whatever the tool. Matched case-insensitively as substrings.
+var sensitiveKeyParts = []string{"token", "secret", "password", "passwd", "apikey", "api_key", "credential", "privatekey", "private_key"}
+
+// hostKeys are the argument names tools use for service hostnames. Their
+// string (or string-array) values become Entry.Services so the log can be
+// filtered per service without knowing each tool's schema.
+var hostKeys = []string{"serviceHostname", "targetService", "sourceService", "hostname", "storageHostname", "service", "services"}
+
+// Redact decodes raw tool arguments and strips everything that may carry
+// a secret. zerops_env values are always dropped: whether a key is
+// sensitive is platform state the middleware does not have, so every
+// value is treated as sensitive and only the key survives. zerops_import
+// content is reduced to its size because envSecrets live inline in the
+// YAML. Returns the redacted argument map and the service hostnames the
+// call targeted, sorted and de-duplicated.
+func Redact(tool string, raw json.RawMessage) (map[string]any, []string) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var args map[string]any
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return map[string]any{"_unparsed": fmt.Sprintf("[%d bytes]", len(raw))}, nil
+	}
+
+	var services []string
+	collectServices(args, &services)
+	slices.Sort(services)
+	services = slices.Compact(services)
+
+	switch tool {
+	case "zerops_env":
+		if vars, ok := args["variables"].([]any); ok {
+			for i, v := range vars {
+				if s, ok := v.(string); ok {
+					vars[i] = redactEnvPair(s)
+				}
+			}
+		}
+	case "zerops_import":
+		if content, ok := args["content"].(string); ok {
+			args["content"] = fmt.Sprintf("[redacted %d bytes]", len(content))
+		}
+	}
+
+	return redactValue(args).(map[string]any), services
+}
+
+// redactEnvPair keeps the KEY of a KEY=VALUE pair. Bare keys (delete)
+// pass through unchanged.
+func redactEnvPair(s string) string {
+	key, _, found := strings.Cut(s, "=")
+	if !found {
+		return s
+	}
+	return key + "=" + redacted
+}
+
+func redactValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			if isSensitiveKey(k) {
+				t[k] = redacted
+				continue
+			}
+			t[k] = redactValue(val)
+		}
+		return t
+	case []any:
+		for i, val := range t {
+			t[i] = redactValue(val)
+		}
+		return t
+	case string:
+		if len(t) > maxStringLen {
+			// Cut on a rune boundary so the log line stays valid UTF-8.
+			cut := maxStringLen / 2
+			for cut > 0 && !utf8.RuneStart(t[cut]) {
+				cut--
+			}
+			return fmt.Sprintf("%s…[%d bytes]", t[:cut], len(t))
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+func isSensitiveKey(k string) bool {
+	lower := strings.ToLower(k)
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func collectServices(v any, out *[]string) {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			if slices.Contains(hostKeys, k) {
+				switch hv := val.(type) {
+				case string:
+					if hv != "" {
+						*out = append(*out, hv)
+					}
+					continue
+				case []any:
+					for _, item := range hv {
+						if s, ok := item.(string); ok && s != "" {
+							*out = append(*out, s)
+						}
+					}
+				}
+			}
+			collectServices(val, out)
+		}
+	case []any:
+		for _, val := range t {
+			collectServices(val, out)
+		}
+	}
+}
+
+// ProcessIDs extracts platform process IDs from a tool's JSON text
+// result. Any object carrying both "id" and "actionName" is a serialized
+// platform.Process — that shape is shared by deploy, manage, scale, env,
+// import and delete responses, nested or not.
+func ProcessIDs(text string) []string {
+	var v any
+	if err := json.Unmarshal([]byte(text), &v); err != nil {
+		return nil
+	}
+	var ids []string
+	collectProcessIDs(v, &ids)
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+func collectProcessIDs(v any, out *[]string) {
+	switch t := v.(type) {
+	case map[string]any:
+		if id, ok := t["id"].(string); ok && id != "" {
+			if _, isProcess := t["actionName"]; isProcess {
+				*out = append(*out, id)
+			}
+		}
+		for _, val := range t {
+			collectProcessIDs(val, out)
+		}
+	case []any:
+		for _, val := range t {
+			collectProcessIDs(val, out)
+		}
+	}
+}
+
+// ErrorCode returns the typed error code from an IsError tool result.
+// Only the code is kept — messages can echo argument values back.
+func ErrorCode(text string) string {
+	var wire struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal([]byte(text), &wire); err != nil {
+		return ""
+	}
+	return wire.Code
+}
@@ -0,0 +1,135 @@
+// Tests for: audit/redact.go — argument redaction, service and process extraction.
+package audit
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestRedact(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		tool         string
+		raw          string
+		wantServices []string
+		mustContain  []string
+		mustNotHave  []string
+	}{
+		{
+			name:         "env set values dropped, keys kept",
+			tool:         "zerops_env",
+			raw:          `{"action":"set","serviceHostname":"api","variables":["DB_PASS=hunter2","PLAIN=visible-value"]}`,
+			wantServices: []string{"api"},
+			mustContain:  []string{`DB_PASS=[redacted]`, `PLAIN=[redacted]`},
+			mustNotHave:  []string{"hunter2", "visible-value"},
+		},
+		{
+			name:        "env delete keys pass through",
+			tool:        "zerops_env",
+			raw:         `{"action":"delete","project":true,"variables":["OLD_KEY"]}`,
+			mustContain: []string{"OLD_KEY"},
+		},
+		{
+			name:        "import content reduced to size",
+			tool:        "zerops_import",
+			raw:         `{"content":"services:\n  - hostname: db\n    envSecrets:\n      KEY: s3cr3t\n"}`,
+			mustContain: []string{"[redacted 61 bytes]"},
+			mustNotHave: []string{"s3cr3t"},
+		},
+		{
+			name:        "sensitive key names redacted anywhere",
+			tool:        "zerops_workflow",
+			raw:         `{"action":"start","nested":{"apiToken":"tkn","dbPassword":"pw"},"list":[{"clientSecret":"cs"}]}`,
+			mustContain: []string{`"apiToken":"[redacted]"`},
+			mustNotHave: []string{`"tkn"`, `"pw"`, `"cs"`},
+		},
+		{
+			name:         "services from nested targets",
+			tool:         "zerops_deploy_batch",
+			raw:          `{"targets":[{"targetService":"appdev","sourceService":"appdev"},{"targetService":"apidev"}]}`,
+			wantServices: []string{"apidev", "appdev"},
+		},
+		{
+			name:         "service array",
+			tool:         "zerops_logs",
+			raw:          `{"services":["web","db"],"serviceHostname":"web"}`,
+			wantServices: []string{"db", "web"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			args, services := Redact(tt.tool, json.RawMessage(tt.raw))
+			if !slices.Equal(services, tt.wantServices) {
+				t.Errorf("services = %v, want %v", services, tt.wantServices)
+			}
+			out, err := json.Marshal(args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.mustContain {
+				if !strings.Contains(string(out), s) {
+					t.Errorf("redacted args %s missing %q", out, s)
+				}
+			}
+			for _, s := range tt.mustNotHave {
+				if strings.Contains(string(out), s) {
+					t.Errorf("redacted args %s leaked %q", out, s)
+				}
+			}
+		})
+	}
+}
+
+func TestRedact_TruncatesLongStrings(t *testing.T) {
+	t.Parallel()
+
+	raw, _ := json.Marshal(map[string]string{"command": strings.Repeat("x", 4000)})
+	args, _ := Redact("zerops_dev_server", raw)
+	got, _ := args["command"].(string)
+	if len(got) > maxStringLen || !strings.Contains(got, "[4000 bytes]") {
+		t.Errorf("truncated command = %q", got)
+	}
+}
+
+func TestRedact_TruncatesOnRuneBoundary(t *testing.T) {
+	t.Parallel()
+
+	// "x" shifts the 3-byte "€" runes so byte maxStringLen/2 lands mid-rune.
+	raw, _ := json.Marshal(map[string]string{"content": "x" + strings.Repeat("€", 1000)})
+	args, _ := Redact("zerops_mount", raw)
+	got, _ := args["content"].(string)
+	if !utf8.ValidString(got) || !strings.HasSuffix(got, "€…[3001 bytes]") {
+		t.Errorf("truncated content = %q", got)
+	}
+}
+
+func TestProcessIDs(t *testing.T) {
+	t.Parallel()
+
+	text := `{"process":{"id":"p-1","actionName":"stack.deploy"},"restartedProcesses":[{"id":"p-2","actionName":"stack.restart"},{"id":"p-1","actionName":"stack.deploy"}],"service":{"id":"svc-1","name":"app"}}`
+	got := ProcessIDs(text)
+	if !slices.Equal(got, []string{"p-1", "p-2"}) {
+		t.Errorf("ProcessIDs = %v, want [p-1 p-2]", got)
+	}
+	if got := ProcessIDs("not json"); got != nil {
+		t.Errorf("ProcessIDs(non-JSON) = %v, want nil", got)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	t.Parallel()
+
+	if got := ErrorCode(`{"code":"SERVICE_NOT_FOUND","error":"Service 'x' not found"}`); got != "SERVICE_NOT_FOUND" {
+		t.Errorf("ErrorCode = %q", got)
+	}
+	if got := ErrorCode("plain text"); got != "" {
+		t.Errorf("ErrorCode(plain) = %q, want empty", got)
+	}
+}
@@ -12,7 +12,7 @@
 //     WRONG_KNOWLEDGE / MISSING_KNOWLEDGE / UNCLEAR_GUIDANCE / PLATFORM_ISSUE.
 //     Anything else falls into UNCATEGORIZED so non-conforming entries still
 //     surface (vs being silently dropped).
-//   - Per-suite output only. Cross-suite trend analysis is a Phase 2 concern.
+//   - Per-suite output only. Cross-suite trend analysis lives in trends.go.
 package eval
 
 import (
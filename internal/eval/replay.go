@@ -0,0 +1,292 @@
+// Package eval — deterministic transcript replay.
+//
+// Replay re-issues every zerops_* tool call recorded in a stream-json
+// transcript (the log.jsonl RunScenario writes) against an in-process MCP
+// server and diffs each response with the recorded one. No model is
+// involved, so a reported bad session can be bisected across atom and
+// guidance changes for free: same inputs, only the server changed.
+//
+// Responses are compared after normalisation — JSON is re-indented with
+// sorted keys, and timestamps, durations and platform IDs are masked —
+// because a replay backend (simulator, mock) never reproduces those
+// byte-for-byte. What remains is the text ZCP itself composes.
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// replayToolPrefix selects the calls replay can re-issue; Bash, Read and
+// the other harness tools never reached ZCP.
+const replayToolPrefix = "zerops_"
+
+// maxReplayDiffLines caps the diff kept per step.
+const maxReplayDiffLines = 40
+
+// ReplayStep is one recorded tool call and its recorded response.
+type ReplayStep struct {
+	Seq             int             `json:"seq"`
+	ToolUseID       string          `json:"toolUseId"`
+	Name            string          `json:"name"`
+	Input           json.RawMessage `json:"input"`
+	Recorded        string          `json:"recorded"`
+	RecordedIsError bool            `json:"recordedIsError,omitempty"`
+	// HasRecording is false when the transcript ends before the result
+	// (killed run); the step is replayed but not compared.
+	HasRecording bool `json:"hasRecording"`
+}
+
+// ReplayStepResult is the outcome of re-issuing one step.
+type ReplayStepResult struct {
+	Seq             int             `json:"seq"`
+	Name            string          `json:"name"`
+	Input           json.RawMessage `json:"input"`
+	Match           bool            `json:"match"`
+	Compared        bool            `json:"compared"`
+	ReplayedIsError bool            `json:"replayedIsError,omitempty"`
+	RecordedIsError bool            `json:"recordedIsError,omitempty"`
+	Diff            string          `json:"diff,omitempty"`
+	Error           string          `json:"error,omitempty"` // transport failure, not a tool error
+	Replayed        string          `json:"replayed"`
+}
+
+// ReplayReport summarises a replay run.
+type ReplayReport struct {
+	Transcript string             `json:"transcript"`
+	Steps      int                `json:"steps"`
+	Matched    int                `json:"matched"`
+	Diverged   int                `json:"diverged"`
+	Uncompared int                `json:"uncompared"`
+	Results    []ReplayStepResult `json:"results"`
+}
+
+// FirstDivergence returns the first step whose response differs, or nil.
+// Later divergences are often knock-on effects of the first one.
+func (r *ReplayReport) FirstDivergence() *ReplayStepResult {
+	for i := range r.Results {
+		if r.Results[i].Compared && !r.Results[i].Match {
+			return &r.Results[i]
+		}
+	}
+	return nil
+}
+
+// LoadReplaySteps extracts the zerops_* tool calls from a stream-json
+// transcript, in the order the agent issued them, paired with their
+// recorded results.
+func LoadReplaySteps(logFile string) ([]ReplayStep, error) {
+	events, err := parseStreamJSON(logFile)
+	if err != nil {
+		return nil, err
+	}
+	var steps []ReplayStep
+	byID := make(map[string]int) // tool_use id → index in steps
+	for _, ev := range events {
+		switch ev.Type {
+		case eventTypeAssistant:
+			for i, raw := range ev.ToolUseNames {
+				name := normalizeToolName(raw)
+				if !strings.HasPrefix(name, replayToolPrefix) {
+					continue
+				}
+				input := ev.ToolUseInputs[i]
+				if len(input) == 0 {
+					input = json.RawMessage("{}")
+				}
+				byID[ev.ToolUseIDs[i]] = len(steps)
+				steps = append(steps, ReplayStep{
+					Seq: len(steps) + 1, ToolUseID: ev.ToolUseIDs[i], Name: name, Input: input,
+				})
+			}
+		case eventTypeUser:
+			for _, res := range ev.ToolResults {
+				idx, ok := byID[res.ID]
+				if !ok {
+					continue
+				}
+				steps[idx].Recorded = res.Text
+				steps[idx].RecordedIsError = res.IsError
+				steps[idx].HasRecording = true
+				delete(byID, res.ID)
+			}
+		}
+	}
+	return steps, nil
+}
+
+// Replay connects to srv over an in-memory transport and re-issues steps
+// in order. A tool error is a response like any other and is compared;
+// only a transport failure is recorded in Error. Stops early when ctx is
+// cancelled.
+func Replay(ctx context.Context, srv *mcp.Server, transcript string, steps []ReplayStep) (*ReplayReport, error) {
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := srv.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connect replay server: %w", err)
+	}
+	defer ss.Close()
+	client := mcp.NewClient(&mcp.Implementation{Name: "zcp-replay", Version: "1"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connect replay client: %w", err)
+	}
+	defer session.Close()
+
+	report := &ReplayReport{Transcript: transcript, Steps: len(steps)}
+	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		res := ReplayStepResult{Seq: step.Seq, Name: step.Name, Input: step.Input, RecordedIsError: step.RecordedIsError}
+		var args map[string]any
+		if err := json.Unmarshal(step.Input, &args); err != nil {
+			res.Error = "decode recorded input: " + err.Error()
+		} else if out, err := session.CallTool(ctx, &mcp.CallToolParams{Name: step.Name, Arguments: args}); err != nil {
+			res.Error = err.Error()
+		} else {
+			res.Replayed = callToolText(out)
+			res.ReplayedIsError = out.IsError
+		}
+
+		switch {
+		case !step.HasRecording:
+			report.Uncompared++
+		default:
+			res.Compared = true
+			want, got := normalizeReplayText(step.Recorded), normalizeReplayText(res.Replayed)
+			res.Match = res.Error == "" && want == got && step.RecordedIsError == res.ReplayedIsError
+			if res.Match {
+				report.Matched++
+			} else {
+				report.Diverged++
+				res.Diff = lineDiff(want, got, maxReplayDiffLines)
+			}
+		}
+		report.Results = append(report.Results, res)
+	}
+	return report, nil
+}
+
+// callToolText joins the text content of a tool result, the same way the
+// stream-json transcript records it.
+func callToolText(r *mcp.CallToolResult) string {
+	var parts []string
+	for _, c := range r.Content {
+		if tc, ok := c.(*mcp.TextContent); ok {
+			parts = append(parts, tc.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+var (
+	replayTimestampRe = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?`)
+	replayDurationRe  = regexp.MustCompile(`\b(?:\d+(?:\.\d+)?(?:h|ms|µs|ns|m|s))+\b`)
+	replayUUIDRe      = regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`)
+	replayTokenRe     = regexp.MustCompile(`\b[A-Za-z0-9_-]{16,}\b`)
+)
+
+// normalizeReplayText canonicalises a response for comparison: JSON is
+// re-indented with sorted keys, then volatile values are masked.
+// Platform IDs (22-char base62 on Zerops, "svc-1"-style in the mock)
+// are masked as long tokens mixing letters and digits.
+func normalizeReplayText(s string) string {
+	s = strings.TrimSpace(s)
+	var v any
+	if json.Unmarshal([]byte(s), &v) == nil {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if enc.Encode(v) == nil {
+			s = strings.TrimSpace(buf.String())
+		}
+	}
+	s = replayTimestampRe.ReplaceAllString(s, "<time>")
+	s = replayUUIDRe.ReplaceAllString(s, "<id>")
+	s = replayTokenRe.ReplaceAllStringFunc(s, func(tok string) string {
+		if strings.IndexFunc(tok, unicode.IsDigit) >= 0 && strings.IndexFunc(tok, unicode.IsLetter) >= 0 {
+			return "<id>"
+		}
+		return tok
+	})
+	return replayDurationRe.ReplaceAllString(s, "<duration>")
+}
+
+// lineDiff renders a unified-style line diff of a → b (LCS-based), keeping
+// at most maxLines changed lines. Inputs beyond lineDiffMaxInput lines
+// fall back to reporting the first differing line.
+func lineDiff(a, b string, maxLines int) string {
+	const lineDiffMaxInput = 3000
+	al, bl := strings.Split(a, "\n"), strings.Split(b, "\n")
+	if len(al) > lineDiffMaxInput || len(bl) > lineDiffMaxInput {
+		for i := 0; i < len(al) || i < len(bl); i++ {
+			var av, bv string
+			if i < len(al) {
+				av = al[i]
+			}
+			if i < len(bl) {
+				bv = bl[i]
+			}
+			if av != bv {
+				return fmt.Sprintf("@@ line %d @@\n-%s\n+%s\n", i+1, av, bv)
+			}
+		}
+		return ""
+	}
+
+	// lcs[i][j] = LCS length of al[i:], bl[j:].
+	lcs := make([][]int, len(al)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bl)+1)
+	}
+	for i := len(al) - 1; i >= 0; i-- {
+		for j := len(bl) - 1; j >= 0; j-- {
+			if al[i] == bl[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var b2 strings.Builder
+	changed := 0
+	emit := func(prefix, line string) bool {
+		if changed >= maxLines {
+			return false
+		}
+		changed++
+		b2.WriteString(prefix + line + "\n")
+		return true
+	}
+	i, j := 0, 0
+	for i < len(al) || j < len(bl) {
+		switch {
+		case i < len(al) && j < len(bl) && al[i] == bl[j]:
+			i++
+			j++
+		case i < len(al) && (j == len(bl) || lcs[i+1][j] >= lcs[i][j+1]):
+			if !emit("-", al[i]) {
+				b2.WriteString("… (diff truncated)\n")
+				return b2.String()
+			}
+			i++
+		default:
+			if !emit("+", bl[j]) {
+				b2.WriteString("… (diff truncated)\n")
+				return b2.String()
+			}
+			j++
+		}
+	}
+	return b2.String()
+}
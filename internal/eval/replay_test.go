@@ -0,0 +1,147 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// replayTranscriptFixture records three zerops_discover calls (one
+// interleaved with a harness Bash call) and a fourth whose result never
+// arrived.
+const replayTranscriptFixture = `{"type":"system","subtype":"init","session_id":"replay"}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"mcp__zerops__zerops_discover","input":{"service":"app"}},{"type":"tool_use","id":"b1","name":"Bash","input":{"command":"ls"}}]}}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":[{"type":"text","text":"{\"service\":\"app\",\"id\":\"Ab3dEf6hIj9kLm2nOp5qRs\",\"created\":\"2026-05-01T10:00:00Z\"}"}]},{"type":"tool_result","tool_use_id":"b1","content":"x"}]}}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t2","name":"mcp__zerops__zerops_discover","input":{"service":"db"}}]}}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t2","content":"service db: ready","is_error":false}]}}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t3","name":"mcp__zerops__zerops_discover","input":{"service":"gone"}}]}}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t3","content":"not found","is_error":true}]}}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t4","name":"mcp__zerops__zerops_discover","input":{}}]}}
+`
+
+type replayDiscoverInput struct {
+	Service string `json:"service,omitempty"`
+}
+
+// replayTestServer answers zerops_discover the way the fixture recorded,
+// except that "db" now reports "starting" — one deliberate divergence.
+func replayTestServer() *mcp.Server {
+	srv := mcp.NewServer(&mcp.Implementation{Name: "replay-test", Version: "1"}, nil)
+	mcp.AddTool(srv, &mcp.Tool{Name: "zerops_discover"}, func(_ context.Context, _ *mcp.CallToolRequest, in replayDiscoverInput) (*mcp.CallToolResult, any, error) {
+		text := func(s string, isErr bool) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: s}}, IsError: isErr}, nil, nil
+		}
+		switch in.Service {
+		case "app":
+			// Different ID, timestamp and key order — all normalised away.
+			return text(`{"created":"2026-10-17T03:22:35.47Z","id":"Zz9yXx8wVv7uTt6sRr5qQq","service":"app"}`, false)
+		case "db":
+			return text("service db: starting", false)
+		case "gone":
+			return text("not found", true)
+		}
+		return text("{}", false)
+	})
+	return srv
+}
+
+func TestLoadReplaySteps(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	if err := os.WriteFile(path, []byte(replayTranscriptFixture), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	steps, err := LoadReplaySteps(path)
+	if err != nil {
+		t.Fatalf("LoadReplaySteps: %v", err)
+	}
+	if len(steps) != 4 {
+		t.Fatalf("steps = %d, want 4 (Bash filtered out)", len(steps))
+	}
+	if steps[0].Name != "zerops_discover" || string(steps[0].Input) != `{"service":"app"}` || !strings.Contains(steps[0].Recorded, `"service":"app"`) {
+		t.Errorf("step 1 = %+v", steps[0])
+	}
+	if steps[1].Recorded != "service db: ready" {
+		t.Errorf("step 2 recorded = %q (string content)", steps[1].Recorded)
+	}
+	if !steps[2].RecordedIsError {
+		t.Error("step 3 should be recorded as an error")
+	}
+	if steps[3].HasRecording || string(steps[3].Input) != "{}" {
+		t.Errorf("step 4 = %+v, want no recording", steps[3])
+	}
+}
+
+func TestReplay_DiffsAgainstRecording(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	if err := os.WriteFile(path, []byte(replayTranscriptFixture), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	steps, err := LoadReplaySteps(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Replay(context.Background(), replayTestServer(), path, steps)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+
+	if report.Steps != 4 || report.Matched != 2 || report.Diverged != 1 || report.Uncompared != 1 {
+		t.Fatalf("report = %d steps, %d matched, %d diverged, %d uncompared",
+			report.Steps, report.Matched, report.Diverged, report.Uncompared)
+	}
+	first := report.FirstDivergence()
+	if first == nil || first.Seq != 2 {
+		t.Fatalf("first divergence = %+v, want step 2", first)
+	}
+	if want := "-service db: ready\n+service db: starting\n"; first.Diff != want {
+		t.Errorf("diff = %q, want %q", first.Diff, want)
+	}
+}
+
+func TestNormalizeReplayText(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"json canonical", `{"b":1,"a":"x"}`, "{\n  \"a\": \"x\",\n  \"b\": 1\n}"},
+		{"timestamp", "built at 2026-05-01T10:00:00.123+02:00", "built at <time>"},
+		{"duration", "took 1m30.5s, then 250ms", "took <duration>, then <duration>"},
+		{"zerops id", "service Ab3dEf6hIj9kLm2nOp5qRs ready", "service <id> ready"},
+		{"uuid", "proc 0f8e7c1a-1b2c-4d5e-8f90-123456789abc", "proc <id>"},
+		{"long word kept", "zerops_verification_summary", "zerops_verification_summary"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := normalizeReplayText(tt.in); got != tt.want {
+				t.Errorf("normalizeReplayText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineDiff_Truncates(t *testing.T) {
+	t.Parallel()
+
+	var a, b []string
+	for i := range 10 {
+		a = append(a, fmt.Sprintf("a%d", i))
+		b = append(b, fmt.Sprintf("b%d", i))
+	}
+	got := lineDiff(strings.Join(a, "\n"), strings.Join(b, "\n"), 4)
+	if strings.Count(got, "\n") != 5 || !strings.HasSuffix(got, "(diff truncated)\n") {
+		t.Errorf("diff = %q", got)
+	}
+}
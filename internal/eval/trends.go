@@ -0,0 +1,455 @@
+// Cross-suite trend analysis — the Phase 2 follow-up aggregate.go defers.
+// Every scenario suite under the results dir becomes one point per
+// scenario in a time series; consecutive points are compared to flag
+// regressions (PASS → FAIL, tool-call count growing past a threshold).
+//
+// Design choices:
+//   - Loading and analysis are split. LoadTrendSuites does the I/O
+//     (suite.json, falling back to per-scenario result.json, plus
+//     tool-calls.json); BuildTrends is a pure function over the loaded
+//     snapshots, so tests feed it literals.
+//   - "Consecutive" is per scenario: a scenario that skipped a suite is
+//     compared with the last suite it ran in, not reported as missing.
+//   - Consulted atoms/URIs come from tool-call INPUTS only. Search results
+//     list many URIs the agent never opened; the inputs are what it asked
+//     for.
+package eval
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultToolCallRegressionPct is the tool-call growth, in percent, above
+// which BuildTrends flags a regression when TrendOptions leaves it zero.
+const DefaultToolCallRegressionPct = 25.0
+
+// Regression kinds.
+const (
+	RegressionPassToFail = "pass-to-fail"
+	RegressionToolCalls  = "tool-calls"
+)
+
+// TrendSuite is one suite as loaded from disk: its results plus the tool
+// calls each scenario made, keyed by scenario ID.
+type TrendSuite struct {
+	SuiteID   string
+	StartedAt time.Time
+	Results   []ScenarioResult
+	ToolCalls map[string][]ToolCall
+}
+
+// TrendPoint is one scenario's outcome in one suite.
+type TrendPoint struct {
+	SuiteID    string            `json:"suiteId"`
+	StartedAt  time.Time         `json:"startedAt"`
+	Passed     bool              `json:"passed"`
+	Error      string            `json:"error,omitempty"`
+	ToolCalls  int               `json:"toolCalls"`
+	WallTime   Duration          `json:"wallTime"`
+	RootCauses map[RootCause]int `json:"rootCauses,omitempty"`
+	Consulted  []string          `json:"consulted,omitempty"`
+}
+
+// ScenarioTrend is the time series for one scenario, oldest first.
+type ScenarioTrend struct {
+	ScenarioID string       `json:"scenarioId"`
+	Points     []TrendPoint `json:"points"`
+}
+
+// Regression is a worsening between two consecutive points of a scenario.
+type Regression struct {
+	ScenarioID string `json:"scenarioId"`
+	Kind       string `json:"kind"`
+	FromSuite  string `json:"fromSuite"`
+	ToSuite    string `json:"toSuite"`
+	Detail     string `json:"detail"`
+}
+
+// TrendSuiteSummary is the per-suite header row of the report.
+type TrendSuiteSummary struct {
+	SuiteID   string    `json:"suiteId"`
+	StartedAt time.Time `json:"startedAt"`
+	Scenarios int       `json:"scenarios"`
+	Passed    int       `json:"passed"`
+}
+
+// TrendReport is the full cross-suite view. Rendered as markdown for
+// humans and marshalled as-is for CI gating.
+type TrendReport struct {
+	ToolCallThresholdPct float64             `json:"toolCallThresholdPct"`
+	Suites               []TrendSuiteSummary `json:"suites"`
+	Scenarios            []ScenarioTrend     `json:"scenarios"`
+	Regressions          []Regression        `json:"regressions"`
+}
+
+// TrendOptions tunes regression detection.
+type TrendOptions struct {
+	// ToolCallThresholdPct flags a regression when a scenario's tool-call
+	// count grows by more than this percentage between consecutive points.
+	// Zero means DefaultToolCallRegressionPct.
+	ToolCallThresholdPct float64
+}
+
+// LoadTrendSuites reads every scenario suite under resultsDir. A suite
+// dir contributes its suite.json when present; otherwise (single
+// `zcp eval scenario` runs, interrupted suites) its per-scenario
+// result.json files. Recipe suites and dirs without scenario results are
+// skipped. Suites come back oldest first.
+func LoadTrendSuites(resultsDir string) ([]TrendSuite, error) {
+	entries, err := os.ReadDir(resultsDir)
+	if err != nil {
+		return nil, fmt.Errorf("read results dir: %w", err)
+	}
+	var out []TrendSuite
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		suite, err := loadTrendSuite(filepath.Join(resultsDir, e.Name()), e.Name())
+		if err != nil {
+			return nil, err
+		}
+		if suite != nil {
+			out = append(out, *suite)
+		}
+	}
+	sortTrendSuites(out)
+	return out, nil
+}
+
+func loadTrendSuite(dir, suiteID string) (*TrendSuite, error) {
+	suite := &TrendSuite{SuiteID: suiteID, ToolCalls: make(map[string][]ToolCall)}
+
+	data, err := os.ReadFile(filepath.Join(dir, "suite.json"))
+	switch {
+	case err == nil:
+		var sr ScenarioSuiteResult
+		if err := json.Unmarshal(data, &sr); err != nil {
+			return nil, fmt.Errorf("parse %s/suite.json: %w", suiteID, err)
+		}
+		suite.StartedAt = sr.StartedAt
+		suite.Results = sr.Results
+	case errors.Is(err, fs.ErrNotExist):
+		results, err := loadScenarioResults(dir)
+		if err != nil {
+			return nil, err
+		}
+		suite.Results = results
+	default:
+		return nil, fmt.Errorf("read %s/suite.json: %w", suiteID, err)
+	}
+
+	// A recipe suite.json decodes with empty scenario IDs — not ours.
+	suite.Results = slices.DeleteFunc(suite.Results, func(r ScenarioResult) bool { return r.ScenarioID == "" })
+	if len(suite.Results) == 0 {
+		return nil, nil
+	}
+	for _, r := range suite.Results {
+		if suite.StartedAt.IsZero() || (!r.StartedAt.IsZero() && r.StartedAt.Before(suite.StartedAt)) {
+			suite.StartedAt = r.StartedAt
+		}
+		calls, err := readToolCalls(filepath.Join(dir, r.ScenarioID, "tool-calls.json"))
+		if err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", suiteID, r.ScenarioID, err)
+		}
+		suite.ToolCalls[r.ScenarioID] = calls
+	}
+	return suite, nil
+}
+
+// loadScenarioResults collects <dir>/*/result.json.
+func loadScenarioResults(dir string) ([]ScenarioResult, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read suite dir: %w", err)
+	}
+	var out []ScenarioResult
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name(), "result.json"))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read result.json: %w", err)
+		}
+		var r ScenarioResult
+		if err := json.Unmarshal(data, &r); err != nil {
+			return nil, fmt.Errorf("parse %s/result.json: %w", e.Name(), err)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// readToolCalls reads a tool-calls.json. Missing means the run made no
+// calls (RunScenario only writes the file when there were some).
+func readToolCalls(path string) ([]ToolCall, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read tool calls: %w", err)
+	}
+	var calls []ToolCall
+	if err := json.Unmarshal(data, &calls); err != nil {
+		return nil, fmt.Errorf("parse tool calls: %w", err)
+	}
+	return calls, nil
+}
+
+// sortTrendSuites orders suites oldest first. Suite IDs start with a
+// timestamp, so they break ties and order suites with no start time.
+func sortTrendSuites(suites []TrendSuite) {
+	sort.SliceStable(suites, func(i, j int) bool {
+		a, b := suites[i], suites[j]
+		if !a.StartedAt.Equal(b.StartedAt) && !a.StartedAt.IsZero() && !b.StartedAt.IsZero() {
+			return a.StartedAt.Before(b.StartedAt)
+		}
+		return a.SuiteID < b.SuiteID
+	})
+}
+
+// BuildTrends turns loaded suites into per-scenario time series and
+// flags regressions between consecutive points. Pure function — no I/O.
+// suites may arrive in any order.
+func BuildTrends(suites []TrendSuite, opts TrendOptions) TrendReport {
+	threshold := opts.ToolCallThresholdPct
+	if threshold <= 0 {
+		threshold = DefaultToolCallRegressionPct
+	}
+	ordered := slices.Clone(suites)
+	sortTrendSuites(ordered)
+
+	report := TrendReport{ToolCallThresholdPct: threshold, Regressions: []Regression{}}
+	byScenario := make(map[string]*ScenarioTrend)
+	var scenarioOrder []string
+
+	for _, s := range ordered {
+		summary := TrendSuiteSummary{SuiteID: s.SuiteID, StartedAt: s.StartedAt, Scenarios: len(s.Results)}
+		for _, r := range s.Results {
+			p := trendPoint(s, r)
+			if p.Passed {
+				summary.Passed++
+			}
+			st := byScenario[r.ScenarioID]
+			if st == nil {
+				st = &ScenarioTrend{ScenarioID: r.ScenarioID}
+				byScenario[r.ScenarioID] = st
+				scenarioOrder = append(scenarioOrder, r.ScenarioID)
+			}
+			st.Points = append(st.Points, p)
+		}
+		report.Suites = append(report.Suites, summary)
+	}
+
+	sort.Strings(scenarioOrder)
+	for _, id := range scenarioOrder {
+		st := byScenario[id]
+		report.Scenarios = append(report.Scenarios, *st)
+		for i := 1; i < len(st.Points); i++ {
+			report.Regressions = append(report.Regressions, compareTrendPoints(id, st.Points[i-1], st.Points[i], threshold)...)
+		}
+	}
+	return report
+}
+
+func trendPoint(s TrendSuite, r ScenarioResult) TrendPoint {
+	calls := s.ToolCalls[r.ScenarioID]
+	p := TrendPoint{
+		SuiteID:   s.SuiteID,
+		StartedAt: r.StartedAt,
+		Passed:    r.Grade.Passed && r.Error == "",
+		Error:     r.Error,
+		ToolCalls: len(calls),
+		WallTime:  r.Duration,
+		Consulted: consultedReferences(calls),
+	}
+	if p.StartedAt.IsZero() {
+		p.StartedAt = s.StartedAt
+	}
+	for _, fc := range ParseAssessment(r.Assessment).FailureChains {
+		if p.RootCauses == nil {
+			p.RootCauses = make(map[RootCause]int)
+		}
+		p.RootCauses[fc.RootCause]++
+	}
+	return p
+}
+
+func compareTrendPoints(scenarioID string, prev, cur TrendPoint, threshold float64) []Regression {
+	var out []Regression
+	if prev.Passed && !cur.Passed {
+		detail := "grader PASS → FAIL"
+		if cur.Error != "" {
+			detail = "PASS → ERROR: " + cur.Error
+		}
+		out = append(out, Regression{
+			ScenarioID: scenarioID, Kind: RegressionPassToFail,
+			FromSuite: prev.SuiteID, ToSuite: cur.SuiteID, Detail: detail,
+		})
+	}
+	if prev.ToolCalls > 0 {
+		growth := float64(cur.ToolCalls-prev.ToolCalls) / float64(prev.ToolCalls) * 100
+		if growth > threshold {
+			out = append(out, Regression{
+				ScenarioID: scenarioID, Kind: RegressionToolCalls,
+				FromSuite: prev.SuiteID, ToSuite: cur.SuiteID,
+				Detail: fmt.Sprintf("tool calls %d → %d (+%.0f%%, threshold %.0f%%)", prev.ToolCalls, cur.ToolCalls, growth, threshold),
+			})
+		}
+	}
+	return out
+}
+
+// consultedReferences lists the atoms and knowledge URIs a run asked for,
+// sorted and de-duplicated: zerops_knowledge uri= fetches, recipe=
+// lookups (as their zerops://recipes/ URI), scope= loads, and
+// zerops_workflow atomId= dispatch-brief atoms.
+func consultedReferences(calls []ToolCall) []string {
+	seen := make(map[string]bool)
+	var out []string
+	add := func(ref string) {
+		if ref != "" && !seen[ref] {
+			seen[ref] = true
+			out = append(out, ref)
+		}
+	}
+	for _, c := range calls {
+		var in struct {
+			URI    string `json:"uri"`
+			Recipe string `json:"recipe"`
+			Scope  string `json:"scope"`
+			AtomID string `json:"atomId"`
+		}
+		if json.Unmarshal([]byte(c.Input), &in) != nil {
+			continue
+		}
+		switch c.Name {
+		case "zerops_knowledge":
+			add(in.URI)
+			if in.Recipe != "" {
+				add("zerops://recipes/" + in.Recipe)
+			}
+			if in.Scope != "" {
+				add("scope:" + in.Scope)
+			}
+		case "zerops_workflow":
+			if in.AtomID != "" {
+				add("atom:" + in.AtomID)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// RenderTrendsMarkdown emits the human-readable trend report. Fixed layout:
+//  1. Regressions (what CI gates on) first
+//  2. Suite overview, oldest → newest
+//  3. Per-scenario series: outcome, tool calls, wall time, root causes
+//  4. Consulted references that changed between a scenario's last two runs
+func RenderTrendsMarkdown(r TrendReport) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Eval trends — %d suites, %d scenarios\n\n", len(r.Suites), len(r.Scenarios))
+
+	// 1. Regressions.
+	fmt.Fprintf(&b, "## Regressions\n\n")
+	if len(r.Regressions) == 0 {
+		fmt.Fprintf(&b, "_None between consecutive suites (tool-call threshold +%.0f%%)._\n\n", r.ToolCallThresholdPct)
+	} else {
+		fmt.Fprintf(&b, "| Scenario | Kind | From → To | Detail |\n|---|---|---|---|\n")
+		for _, reg := range r.Regressions {
+			fmt.Fprintf(&b, "| `%s` | %s | `%s` → `%s` | %s |\n", reg.ScenarioID, reg.Kind, reg.FromSuite, reg.ToSuite, reg.Detail)
+		}
+		fmt.Fprintln(&b)
+	}
+
+	// 2. Suites.
+	fmt.Fprintf(&b, "## Suites\n\n| Suite | Started | Passed |\n|---|---|---|\n")
+	for _, s := range r.Suites {
+		started := "-"
+		if !s.StartedAt.IsZero() {
+			started = s.StartedAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %d/%d |\n", s.SuiteID, started, s.Passed, s.Scenarios)
+	}
+	fmt.Fprintln(&b)
+
+	// 3. Per-scenario series.
+	fmt.Fprintf(&b, "## Scenarios\n\n")
+	for _, st := range r.Scenarios {
+		fmt.Fprintf(&b, "### `%s`\n\n", st.ScenarioID)
+		fmt.Fprintf(&b, "| Suite | Result | Tool calls | Wall time | Root causes |\n|---|---|---|---|---|\n")
+		for _, p := range st.Points {
+			result := statusFail
+			switch {
+			case p.Error != "":
+				result = statusError
+			case p.Passed:
+				result = statusPass
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %d | %s | %s |\n",
+				p.SuiteID, result, p.ToolCalls, time.Duration(p.WallTime).Truncate(time.Second), formatRootCauses(p.RootCauses))
+		}
+		fmt.Fprintln(&b)
+
+		// 4. Consulted-reference drift between the last two runs.
+		if n := len(st.Points); n >= 2 {
+			added, removed := diffSorted(st.Points[n-2].Consulted, st.Points[n-1].Consulted)
+			if len(added) > 0 || len(removed) > 0 {
+				fmt.Fprintf(&b, "Consulted since `%s`:", st.Points[n-2].SuiteID)
+				for _, ref := range added {
+					fmt.Fprintf(&b, " +`%s`", ref)
+				}
+				for _, ref := range removed {
+					fmt.Fprintf(&b, " −`%s`", ref)
+				}
+				fmt.Fprintf(&b, "\n\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// formatRootCauses renders counts in the taxonomy order the triage doc uses.
+func formatRootCauses(counts map[RootCause]int) string {
+	if len(counts) == 0 {
+		return "-"
+	}
+	var parts []string
+	for _, rc := range []RootCause{RootWrongKnowledge, RootMissingKnowledge, RootUnclearGuidance, RootPlatformIssue, RootUncategorized} {
+		if n := counts[rc]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s×%d", rc, n))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// diffSorted returns what b adds to and removes from a; both sorted.
+func diffSorted(a, b []string) (added, removed []string) {
+	for _, s := range b {
+		if _, ok := slices.BinarySearch(a, s); !ok {
+			added = append(added, s)
+		}
+	}
+	for _, s := range a {
+		if _, ok := slices.BinarySearch(b, s); !ok {
+			removed = append(removed, s)
+		}
+	}
+	return added, removed
+}
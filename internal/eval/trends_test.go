@@ -0,0 +1,136 @@
+package eval
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func trendCalls(n int, extra ...ToolCall) []ToolCall {
+	out := make([]ToolCall, 0, n+len(extra))
+	for range n {
+		out = append(out, ToolCall{Name: "zerops_discover", Input: "{}"})
+	}
+	return append(out, extra...)
+}
+
+func TestBuildTrends_Regressions(t *testing.T) {
+	t.Parallel()
+
+	t0 := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	knowledge := ToolCall{Name: "zerops_knowledge", Input: `{"uri":"zerops://themes/core"}`}
+	recipe := ToolCall{Name: "zerops_knowledge", Input: `{"recipe":"nodejs-hello-world"}`}
+	suites := []TrendSuite{
+		// Out of order on purpose — BuildTrends sorts by start time.
+		{
+			SuiteID: "s2", StartedAt: t0.Add(2 * time.Hour),
+			Results: []ScenarioResult{
+				{ScenarioID: "deploy", Grade: GradeResult{Passed: false}, Duration: Duration(90 * time.Second),
+					Assessment: "## EVAL REPORT\n\n### Failure chains\n- **Step**: deploy\n  - **Root cause**: MISSING_KNOWLEDGE\n"},
+				{ScenarioID: "adopt", Grade: GradeResult{Passed: true}},
+			},
+			ToolCalls: map[string][]ToolCall{"deploy": trendCalls(4, recipe), "adopt": trendCalls(13)},
+		},
+		{
+			SuiteID: "s1", StartedAt: t0,
+			Results: []ScenarioResult{
+				{ScenarioID: "deploy", Grade: GradeResult{Passed: true}, Duration: Duration(60 * time.Second)},
+				{ScenarioID: "adopt", Grade: GradeResult{Passed: true}},
+			},
+			ToolCalls: map[string][]ToolCall{"deploy": trendCalls(4, knowledge), "adopt": trendCalls(10)},
+		},
+	}
+
+	r := BuildTrends(suites, TrendOptions{})
+	if r.ToolCallThresholdPct != DefaultToolCallRegressionPct {
+		t.Errorf("threshold = %v, want default", r.ToolCallThresholdPct)
+	}
+	if len(r.Suites) != 2 || r.Suites[0].SuiteID != "s1" || r.Suites[1].Passed != 1 {
+		t.Fatalf("suites = %+v", r.Suites)
+	}
+	if len(r.Scenarios) != 2 || r.Scenarios[0].ScenarioID != "adopt" {
+		t.Fatalf("scenarios = %+v", r.Scenarios)
+	}
+
+	deploy := r.Scenarios[1]
+	if got := deploy.Points[1].RootCauses[RootMissingKnowledge]; got != 1 {
+		t.Errorf("root causes = %v, want MISSING_KNOWLEDGE×1", deploy.Points[1].RootCauses)
+	}
+	if !slices.Equal(deploy.Points[0].Consulted, []string{"zerops://themes/core"}) ||
+		!slices.Equal(deploy.Points[1].Consulted, []string{"zerops://recipes/nodejs-hello-world"}) {
+		t.Errorf("consulted = %v / %v", deploy.Points[0].Consulted, deploy.Points[1].Consulted)
+	}
+
+	// adopt: 10 → 13 calls is +30% > 25% → regression. deploy: PASS → FAIL.
+	var kinds []string
+	for _, reg := range r.Regressions {
+		kinds = append(kinds, reg.ScenarioID+":"+reg.Kind)
+		if reg.FromSuite != "s1" || reg.ToSuite != "s2" {
+			t.Errorf("regression suites = %s → %s", reg.FromSuite, reg.ToSuite)
+		}
+	}
+	want := []string{"adopt:" + RegressionToolCalls, "deploy:" + RegressionPassToFail}
+	if !slices.Equal(kinds, want) {
+		t.Errorf("regressions = %v, want %v", kinds, want)
+	}
+
+	// A looser threshold drops the tool-call regression.
+	loose := BuildTrends(suites, TrendOptions{ToolCallThresholdPct: 50})
+	if len(loose.Regressions) != 1 || loose.Regressions[0].Kind != RegressionPassToFail {
+		t.Errorf("regressions at 50%% = %+v", loose.Regressions)
+	}
+
+	md := RenderTrendsMarkdown(r)
+	for _, want := range []string{"## Regressions", "`adopt` | tool-calls", "MISSING_KNOWLEDGE×1", "+`zerops://recipes/nodejs-hello-world`"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestLoadTrendSuites(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeJSON := func(rel string, v any) {
+		t.Helper()
+		p := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := json.Marshal(v)
+		if err := os.WriteFile(p, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t0 := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	// Full scenario suite.
+	writeJSON("20260501t100000-aa/suite.json", ScenarioSuiteResult{
+		SuiteID: "20260501t100000-aa", StartedAt: t0,
+		Results: []ScenarioResult{{ScenarioID: "deploy", Grade: GradeResult{Passed: true}}},
+	})
+	writeJSON("20260501t100000-aa/deploy/tool-calls.json", trendCalls(3))
+	// Single-scenario run: result.json only.
+	writeJSON("20260502t100000-bb/deploy/result.json", ScenarioResult{ScenarioID: "deploy", StartedAt: t0.Add(24 * time.Hour)})
+	// Recipe suite: no scenario IDs, skipped.
+	writeJSON("20260503t100000-cc/suite.json", map[string]any{"suiteId": "cc", "results": []map[string]any{{"recipe": "laravel"}}})
+
+	suites, err := LoadTrendSuites(dir)
+	if err != nil {
+		t.Fatalf("LoadTrendSuites: %v", err)
+	}
+	if len(suites) != 2 {
+		t.Fatalf("suites = %d, want 2", len(suites))
+	}
+	if suites[0].SuiteID != "20260501t100000-aa" || len(suites[0].ToolCalls["deploy"]) != 3 {
+		t.Errorf("first suite = %+v", suites[0])
+	}
+	if suites[1].StartedAt != t0.Add(24*time.Hour) || suites[1].ToolCalls["deploy"] != nil {
+		t.Errorf("second suite = %+v", suites[1])
+	}
+}
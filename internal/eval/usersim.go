@@ -196,14 +196,24 @@ type parsedEvent struct {
 	ResultText string
 
 	// assistant fields
-	AssistantText  string   // concatenated text-block content
-	ToolUseNames   []string // names of tool_use blocks in this assistant message (in order)
-	ToolUseIDs     []string // matching ids, parallel to ToolUseNames
-	HasAssistantTU bool     // true if this assistant message contained any tool_use
-
-	// user fields (tool_result carrier)
+	AssistantText  string            // concatenated text-block content
+	ToolUseNames   []string          // names of tool_use blocks in this assistant message (in order)
+	ToolUseIDs     []string          // matching ids, parallel to ToolUseNames
+	ToolUseInputs  []json.RawMessage // matching inputs, parallel to ToolUseNames
+	HasAssistantTU bool              // true if this assistant message contained any tool_use
+
+	// user fields (tool_result carrier). ToolResultID/Text are the last
+	// result in the message; ToolResults holds all of them for replay.
 	ToolResultID   string
 	ToolResultText string
+	ToolResults    []parsedToolResult
+}
+
+// parsedToolResult is one tool_result block of a user event.
+type parsedToolResult struct {
+	ID      string
+	Text    string
+	IsError bool
 }
 
 func decodeEvent(line []byte) (parsedEvent, error) {
@@ -218,16 +228,15 @@ func decodeEvent(line []byte) (parsedEvent, error) {
 		Message struct {
 			Role    string `json:"role"`
 			Content []struct {
-				Type      string `json:"type"`
-				Text      string `json:"text"`
-				Name      string `json:"name"`
-				ID        string `json:"id"`
-				ToolUseID string `json:"tool_use_id"` //nolint:tagliatelle // upstream
-				// tool_result content (nested)
-				Content []struct {
-					Type string `json:"type"`
-					Text string `json:"text"`
-				} `json:"content"`
+				Type      string          `json:"type"`
+				Text      string          `json:"text"`
+				Name      string          `json:"name"`
+				ID        string          `json:"id"`
+				Input     json.RawMessage `json:"input"`
+				ToolUseID string          `json:"tool_use_id"` //nolint:tagliatelle // upstream
+				IsError   bool            `json:"is_error"`    //nolint:tagliatelle // upstream
+				// tool_result content: a block list, or a bare string
+				Content json.RawMessage `json:"content"`
 			} `json:"content"`
 		} `json:"message"`
 	}
@@ -256,6 +265,7 @@ func decodeEvent(line []byte) (parsedEvent, error) {
 				pe.HasAssistantTU = true
 				pe.ToolUseNames = append(pe.ToolUseNames, c.Name)
 				pe.ToolUseIDs = append(pe.ToolUseIDs, c.ID)
+				pe.ToolUseInputs = append(pe.ToolUseInputs, c.Input)
 			}
 		}
 		pe.AssistantText = strings.Join(texts, "\n")
@@ -265,19 +275,39 @@ func decodeEvent(line []byte) (parsedEvent, error) {
 		for _, c := range raw.Message.Content {
 			if c.Type == contentTypeToolRes {
 				pe.ToolResultID = c.ToolUseID
-				var rtexts []string
-				for _, inner := range c.Content {
-					if inner.Type == contentTypeText {
-						rtexts = append(rtexts, inner.Text)
-					}
-				}
-				pe.ToolResultText = strings.Join(rtexts, "\n")
+				pe.ToolResultText = toolResultText(c.Content)
+				pe.ToolResults = append(pe.ToolResults, parsedToolResult{
+					ID: c.ToolUseID, Text: pe.ToolResultText, IsError: c.IsError,
+				})
 			}
 		}
 	}
 	return pe, nil
 }
 
+// toolResultText joins the text blocks of a tool_result's content, which
+// the stream carries either as a block list or as a bare string.
+func toolResultText(raw json.RawMessage) string {
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(raw, &blocks) == nil {
+		var texts []string
+		for _, b := range blocks {
+			if b.Type == contentTypeText {
+				texts = append(texts, b.Text)
+			}
+		}
+		return strings.Join(texts, "\n")
+	}
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return text
+	}
+	return ""
+}
+
 // findLastResult returns the last result event in the transcript, or
 // (zero, false) if no terminal event has been emitted yet.
 func findLastResult(events []parsedEvent) (parsedEvent, bool) {
@@ -29,6 +29,9 @@ type Document struct {
 	// Plus the companion <slug>.import.yml file when present.
 	Languages  []string
 	Frameworks []string
+	// Keywords from the optional `keywords: [a, b]` frontmatter list.
+	// Search-only: indexed with a field boost, never rendered.
+	Keywords   []string
 	Repo       string
 	ImportYAML string
 
@@ -107,6 +110,7 @@ func parseDocument(path, content string) *Document {
 		Description: desc,
 		Languages:   parseInlineList(frontmatter["languages"]),
 		Frameworks:  parseInlineList(frontmatter["frameworks"]),
+		Keywords:    parseInlineList(frontmatter["keywords"]),
 		Repo:        frontmatter["repo"],
 	}
 }
@@ -2,7 +2,7 @@ package knowledge
 
 import (
 	"fmt"
-	"sort"
+	"math"
 	"strings"
 	"sync"
 
@@ -10,10 +10,13 @@ import (
 	"github.com/zeropsio/zcp/internal/topology"
 )
 
-// SearchResult represents a single search result.
+// SearchResult represents a single search result. Section names the
+// best-matching H2 section of the document ("" when the match is in the
+// text before the first H2); the snippet is taken from that section.
 type SearchResult struct {
 	URI     string  `json:"uri"`
 	Title   string  `json:"title"`
+	Section string  `json:"section,omitempty"`
 	Score   float64 `json:"score"`
 	Snippet string  `json:"snippet"`
 }
@@ -30,9 +33,10 @@ type Provider interface {
 	ListRecipes() []string
 }
 
-// Store holds the knowledge base with simple text-matching search.
+// Store holds the knowledge base with a BM25 section index for search.
 type Store struct {
-	docs map[string]*Document
+	docs  map[string]*Document
+	index *searchIndex
 }
 
 // Verify Store implements Provider.
@@ -52,9 +56,10 @@ func GetEmbeddedStore() (*Store, error) {
 	return embeddedStore, errEmbeddedStore
 }
 
-// NewStore creates a new Store from pre-loaded documents.
+// NewStore creates a new Store from pre-loaded documents and builds the
+// search index over them.
 func NewStore(docs map[string]*Document) (*Store, error) {
-	return &Store{docs: docs}, nil
+	return &Store{docs: docs, index: newSearchIndex(docs)}, nil
 }
 
 // queryAliases maps common alternative terms to their Zerops equivalents.
@@ -99,7 +104,10 @@ func expandQuery(query string) string {
 	return strings.Join(expanded, " ")
 }
 
-// Search performs a simple text-matching search with field boosts and query expansion.
+// Search ranks documents by their best-scoring H2 section (BM25 over the
+// inverted index, with title / heading / keyword boosts) after alias
+// query expansion. Each document appears at most once. Ties break on URI,
+// so the ordering is deterministic.
 func (s *Store) Search(query string, limit int) []SearchResult {
 	if limit <= 0 {
 		limit = 5
@@ -112,68 +120,42 @@ func (s *Store) Search(query string, limit int) []SearchResult {
 	synonymHits := wireContractSearchResults(query)
 
 	expanded := expandQuery(query)
-	words := strings.Fields(strings.ToLower(expanded))
-
-	type scored struct {
-		uri   string
-		score float64
-	}
-	var hits []scored
-
-	for uri, doc := range s.docs {
-		score := 0.0
-		titleLower := strings.ToLower(doc.Title)
-		contentLower := strings.ToLower(doc.Content)
-
-		for _, word := range words {
-			if strings.Contains(titleLower, word) {
-				score += 2.0
-			}
-			if strings.Contains(contentLower, word) {
-				score += 1.0
-			}
-		}
-
-		if score > 0 {
-			hits = append(hits, scored{uri, score})
-		}
-	}
-
-	// Sort by score descending, then by URI for determinism.
-	sort.Slice(hits, func(i, j int) bool {
-		if hits[i].score != hits[j].score {
-			return hits[i].score > hits[j].score
-		}
-		return hits[i].uri < hits[j].uri
-	})
 
 	// Dedupe the text-match hits against any synonym hit already added
 	// by URI. Synonym URIs (zerops://recipe-atom/...) don't overlap
 	// with the embedded document corpus, so in practice this is a
 	// no-op; kept defensive against future atom IDs bleeding into the
 	// document URI namespace.
-	synonymURIs := make(map[string]bool, len(synonymHits))
+	seen := make(map[string]bool, len(synonymHits))
 	for _, h := range synonymHits {
-		synonymURIs[h.URI] = true
+		seen[h.URI] = true
 	}
 
 	// Budget: synonym hits consume the head of the limit; text-match
-	// hits fill the remainder.
+	// hits fill the remainder. Hits arrive sorted, so the first section
+	// seen for a document is its best one.
 	remaining := max(limit-len(synonymHits), 0)
 	textResults := make([]SearchResult, 0, remaining)
-	for _, h := range hits {
+	for _, h := range s.index.search(expanded) {
 		if len(textResults) >= remaining {
 			break
 		}
-		if synonymURIs[h.uri] {
+		sec := s.index.sections[h.section]
+		if seen[sec.uri] {
 			continue
 		}
-		doc := s.docs[h.uri]
+		seen[sec.uri] = true
+		doc := s.docs[sec.uri]
+		snippetSource := sec.body
+		if strings.TrimSpace(snippetSource) == "" {
+			snippetSource = doc.Content
+		}
 		textResults = append(textResults, SearchResult{
 			URI:     doc.URI,
 			Title:   doc.Title,
-			Score:   h.score,
-			Snippet: extractSnippet(doc.Content, expanded, 300),
+			Section: sec.heading,
+			Score:   math.Round(h.score*100) / 100,
+			Snippet: extractSnippet(snippetSource, expanded, 300),
 		})
 	}
 
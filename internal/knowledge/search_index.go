@@ -0,0 +1,259 @@
+package knowledge
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// BM25 parameters. k1 controls term-frequency saturation, b the strength
+// of section-length normalization. The usual defaults work well on the
+// mixed short-section / long-section corpus.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Field boosts for the weighted term frequency (BM25F-style): a query
+// term in the document title or the section heading is worth several
+// body occurrences; frontmatter keywords sit between the two.
+const (
+	boostTitle   = 3.0
+	boostHeading = 2.5
+	boostKeyword = 2.0
+	boostBody    = 1.0
+)
+
+// prefixMatchWeight discounts vocabulary terms reached through prefix
+// expansion ("autoscal" → "autoscaling") relative to exact term hits.
+const prefixMatchWeight = 0.5
+
+// minPrefixLen is the shortest query term that expands to prefixes.
+// Shorter terms would fan out to large parts of the vocabulary.
+const minPrefixLen = 4
+
+// searchIndex is the inverted index behind Store.Search, built once in
+// NewStore. The unit of retrieval is the H2 section: every document is
+// split into its preamble (text before the first H2) plus one entry per
+// Document.H2Sections heading, so a long reference document competes
+// section by section instead of winning on sheer length.
+type searchIndex struct {
+	sections []indexedSection
+	postings map[string][]posting
+	vocab    []string // sorted postings keys, for prefix expansion
+	avgLen   float64
+}
+
+type indexedSection struct {
+	uri     string
+	heading string // "" for the preamble
+	body    string
+	length  float64 // weighted token count across all fields
+}
+
+type posting struct {
+	section int
+	tf      float64 // boost-weighted term frequency
+}
+
+// sectionHit is one scored section.
+type sectionHit struct {
+	section int
+	score   float64
+}
+
+func newSearchIndex(docs map[string]*Document) *searchIndex {
+	idx := &searchIndex{postings: map[string][]posting{}}
+
+	uris := make([]string, 0, len(docs))
+	for uri := range docs {
+		uris = append(uris, uri)
+	}
+	slices.Sort(uris)
+
+	var totalLen float64
+	for _, uri := range uris {
+		doc := docs[uri]
+		title := tokenize(doc.Title)
+		keywords := tokenize(strings.Join(docKeywords(doc), " "))
+
+		sections := doc.H2Sections()
+		headings := make([]string, 0, len(sections))
+		for h := range sections {
+			headings = append(headings, h)
+		}
+		slices.Sort(headings)
+
+		add := func(heading, body string) {
+			tf := map[string]float64{}
+			length := 0.0
+			for _, f := range []struct {
+				tokens []string
+				boost  float64
+			}{
+				{title, boostTitle},
+				{tokenize(heading), boostHeading},
+				{keywords, boostKeyword},
+				{tokenize(body), boostBody},
+			} {
+				for _, tok := range f.tokens {
+					tf[tok] += f.boost
+				}
+				length += f.boost * float64(len(f.tokens))
+			}
+			id := len(idx.sections)
+			idx.sections = append(idx.sections, indexedSection{uri: uri, heading: heading, body: body, length: length})
+			totalLen += length
+			for term, w := range tf {
+				idx.postings[term] = append(idx.postings[term], posting{section: id, tf: w})
+			}
+		}
+
+		if pre := preambleOf(doc.Content); strings.TrimSpace(pre) != "" || len(headings) == 0 {
+			add("", pre)
+		}
+		for _, h := range headings {
+			add(h, sections[h])
+		}
+	}
+
+	if n := len(idx.sections); n > 0 {
+		idx.avgLen = totalLen / float64(n)
+	}
+	idx.vocab = make([]string, 0, len(idx.postings))
+	for term := range idx.postings {
+		idx.vocab = append(idx.vocab, term)
+	}
+	slices.Sort(idx.vocab)
+	return idx
+}
+
+// search scores every section containing at least one query term and
+// returns the hits sorted by score descending, then URI, then heading.
+func (idx *searchIndex) search(query string) []sectionHit {
+	weights := idx.queryTerms(query)
+	if len(weights) == 0 {
+		return nil
+	}
+
+	n := float64(len(idx.sections))
+	scores := map[int]float64{}
+	for term, qw := range weights {
+		list := idx.postings[term]
+		// Lucene-style IDF: always positive, even for terms in most sections.
+		idf := math.Log(1 + (n-float64(len(list))+0.5)/(float64(len(list))+0.5))
+		for _, p := range list {
+			norm := bm25K1 * (1 - bm25B + bm25B*idx.sections[p.section].length/idx.avgLen)
+			scores[p.section] += qw * idf * p.tf * (bm25K1 + 1) / (p.tf + norm)
+		}
+	}
+
+	hits := make([]sectionHit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, sectionHit{section: id, score: score})
+	}
+	slices.SortFunc(hits, func(a, b sectionHit) int {
+		if a.score != b.score {
+			if a.score > b.score {
+				return -1
+			}
+			return 1
+		}
+		sa, sb := idx.sections[a.section], idx.sections[b.section]
+		if c := strings.Compare(sa.uri, sb.uri); c != 0 {
+			return c
+		}
+		return strings.Compare(sa.heading, sb.heading)
+	})
+	return hits
+}
+
+// queryTerms tokenizes the (alias-expanded) query into term weights.
+// A term absent from the vocabulary falls back to every vocabulary term
+// it prefixes, at prefixMatchWeight — the substring scan this index
+// replaced matched "autoscal" inside "autoscaling", and agents rely on
+// truncated queries like that.
+func (idx *searchIndex) queryTerms(query string) map[string]float64 {
+	weights := map[string]float64{}
+	for _, tok := range tokenize(query) {
+		if _, ok := idx.postings[tok]; ok {
+			weights[tok] = max(weights[tok], 1)
+			continue
+		}
+		if len(tok) < minPrefixLen {
+			continue
+		}
+		start, _ := slices.BinarySearch(idx.vocab, tok)
+		for _, term := range idx.vocab[start:] {
+			if !strings.HasPrefix(term, tok) {
+				break
+			}
+			weights[term] = max(weights[term], prefixMatchWeight)
+		}
+	}
+	return weights
+}
+
+// tokenize lowercases s and splits it into terms. Runs of letters,
+// digits and the joiners . _ - @ / form a compound token; compounds are
+// indexed both whole ("zerops.yaml", "nodejs@22", "object-storage") and
+// as their alphanumeric parts, so exact technical identifiers rank
+// highest while their parts still match plain-word queries. Trailing
+// plural "s" is stripped from both index and query terms.
+func tokenize(s string) []string {
+	var out []string
+	for _, compound := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("._-@/", r)
+	}) {
+		compound = strings.Trim(compound, "._-@/")
+		if compound == "" {
+			continue
+		}
+		parts := strings.FieldsFunc(compound, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(parts) > 1 {
+			out = append(out, stem(compound))
+		}
+		for _, p := range parts {
+			out = append(out, stem(p))
+		}
+	}
+	return out
+}
+
+// stem strips a plural "s" ("variables" → "variable"). Applied to both
+// sides, so over-stemming ("status" → "statu") is harmless.
+func stem(term string) string {
+	if len(term) > 3 && strings.HasSuffix(term, "s") && !strings.HasSuffix(term, "ss") {
+		return term[:len(term)-1]
+	}
+	return term
+}
+
+// docKeywords is the frontmatter keyword field: explicit keywords plus
+// the recipe language and framework lists.
+func docKeywords(doc *Document) []string {
+	out := make([]string, 0, len(doc.Keywords)+len(doc.Languages)+len(doc.Frameworks))
+	out = append(out, doc.Keywords...)
+	out = append(out, doc.Languages...)
+	return append(out, doc.Frameworks...)
+}
+
+// preambleOf returns the content before the first H2 heading outside a
+// fenced code block — the part parseH2Sections does not capture.
+func preambleOf(content string) string {
+	inCodeBlock := false
+	offset := 0
+	for line := range strings.SplitAfterSeq(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inCodeBlock = !inCodeBlock
+		} else if !inCodeBlock && strings.HasPrefix(trimmed, "## ") {
+			return content[:offset]
+		}
+		offset += len(line)
+	}
+	return content
+}
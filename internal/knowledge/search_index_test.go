@@ -0,0 +1,100 @@
+// Tests for: knowledge/search_index.go — BM25 section index behind Store.Search.
+package knowledge
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func testIndexStore(t *testing.T, docs ...*Document) *Store {
+	t.Helper()
+	m := make(map[string]*Document, len(docs))
+	for _, d := range docs {
+		m[d.URI] = d
+	}
+	store, err := NewStore(m)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	return store
+}
+
+func TestSearch_SectionLevelRanking(t *testing.T) {
+	t.Parallel()
+
+	// "ports" and "firewall" co-occur in one focused section of the guide;
+	// the reference mentions both words, but far apart in a long document.
+	guide := &Document{URI: "zerops://guides/net", Title: "Networking", Content: "# Networking\n\nIntro.\n\n" +
+		"## Firewall\n\nThe firewall blocks inbound ports except the ones you open.\n\n" +
+		"## DNS\n\nRecords and domains.\n"}
+	reference := &Document{URI: "zerops://themes/ref", Title: "Reference", Content: "# Reference\n\n" +
+		"## Services\n\nServices expose ports. " + strings.Repeat("Services scale and restart. ", 40) + "\n\n" +
+		"## Security\n\nA firewall exists. " + strings.Repeat("Credentials rotate on import. ", 40) + "\n"}
+	store := testIndexStore(t, guide, reference)
+
+	results := store.Search("firewall ports", 5)
+	if len(results) != 2 {
+		t.Fatalf("results = %v, want both documents once", urisFromResults(results))
+	}
+	if results[0].URI != guide.URI || results[0].Section != "Firewall" {
+		t.Errorf("top hit = %s §%q, want %s §Firewall", results[0].URI, results[0].Section, guide.URI)
+	}
+	if !strings.Contains(results[0].Snippet, "blocks inbound ports") {
+		t.Errorf("snippet should come from the matched section: %q", results[0].Snippet)
+	}
+	if results[0].Score <= results[1].Score {
+		t.Errorf("scores not descending: %v", results)
+	}
+}
+
+func TestSearch_FieldBoosts(t *testing.T) {
+	t.Parallel()
+
+	body := &Document{URI: "zerops://a/body", Title: "Misc", Content: "# Misc\n\n## Notes\n\nValkey is mentioned here once.\n"}
+	heading := &Document{URI: "zerops://b/heading", Title: "Caches", Content: "# Caches\n\n## Valkey\n\nIn-memory store.\n"}
+	keyword := &Document{URI: "zerops://c/keyword", Title: "Stores", Keywords: []string{"valkey"}, Content: "# Stores\n\n## Overview\n\nIn-memory store.\n"}
+	store := testIndexStore(t, body, heading, keyword)
+
+	got := urisFromResults(store.Search("valkey", 5))
+	if len(got) != 3 || got[2] != body.URI {
+		t.Errorf("ranking = %v, want heading and keyword matches above the body-only match", got)
+	}
+}
+
+func TestSearch_PrefixFallbackAndDeterminism(t *testing.T) {
+	t.Parallel()
+
+	a := &Document{URI: "zerops://x/a", Title: "A", Content: "# A\n\nHorizontal autoscaling.\n"}
+	b := &Document{URI: "zerops://x/b", Title: "B", Content: "# B\n\nHorizontal autoscaling.\n"}
+	store := testIndexStore(t, b, a)
+
+	for range 5 {
+		got := urisFromResults(store.Search("autoscal", 5))
+		if !slices.Equal(got, []string{a.URI, b.URI}) {
+			t.Fatalf("prefix query results = %v, want equal-score docs ordered by URI", got)
+		}
+	}
+	if got := store.Search("aut", 5); len(got) != 0 {
+		t.Errorf("short prefixes must not expand: %v", urisFromResults(got))
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	t.Parallel()
+
+	got := tokenize("Deploy nodejs@22 via zerops.yaml: object-storage Variables")
+	want := []string{"deploy", "nodejs@22", "nodej", "22", "via", "zerops.yaml", "zerop", "yaml", "object-storage", "object", "storage", "variable"}
+	if !slices.Equal(got, want) {
+		t.Errorf("tokenize = %v\nwant       %v", got, want)
+	}
+}
+
+func TestPreambleOf(t *testing.T) {
+	t.Parallel()
+
+	content := "# T\n\nIntro\n```\n## not a heading\n```\n## Real\nbody\n"
+	if got := preambleOf(content); got != "# T\n\nIntro\n```\n## not a heading\n```\n" {
+		t.Errorf("preambleOf = %q", got)
+	}
+}
@@ -126,11 +126,11 @@ var wireContractAtoms = []wireContractAtom{
 }
 
 // synonymBoostScore is the base score assigned to synonym hits so they
-// always rank above embedding-based text-match hits in the Search
-// result list. The highest-ever text-match score in practice is
-// roughly `len(words) * (titleBoost + contentBoost) = N*3`; 100 is
-// comfortably above any realistic text-match total for bounded query
-// lengths.
+// always rank above BM25 text-match hits in the Search result list.
+// Search prepends synonym hits regardless of score; the boost keeps the
+// reported scores monotone. A BM25 section score is bounded by roughly
+// `idf * (k1+1)` per query term (single digits on this corpus), so 100
+// stays above any realistic text-match total for bounded query lengths.
 const synonymBoostScore = 100.0
 
 // matchWireContractSynonyms returns the wire-contract atoms whose
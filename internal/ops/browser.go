@@ -72,6 +72,13 @@ type BrowserBatchInput struct {
 	// ~2s pre-roll; do not enable on every call — it defeats the
 	// persistent-daemon fast path.
 	ForceReset bool `json:"forceReset,omitempty" jsonschema:"Force full reset of agent-browser daemon + Chrome before starting. Use after CDP-timeout or repeat-recovery failures."`
+
+	// Screenshot, Checkpoint, Compare and Action drive visual evidence —
+	// see BrowserVisualBatch. Plain BrowserBatch ignores them.
+	Screenshot bool   `json:"screenshot,omitempty" jsonschema:"Capture a full-page PNG after your commands, stored under .zcp/state/browser/<host>/<checkpoint>/."`
+	Checkpoint string `json:"checkpoint,omitempty" jsonschema:"Name of the visual checkpoint (e.g. home, checkout-form). Default: default. Implies screenshot."`
+	Compare    bool   `json:"compare,omitempty" jsonschema:"Diff the capture against the checkpoint's accepted baseline; returns diffPercent and a diff image path. Implies screenshot."`
+	Action     string `json:"action,omitempty" jsonschema:"accept: promote the checkpoint's latest capture to its baseline. Does not open the browser."`
 }
 
 // BrowserStepResult is one step from agent-browser's --json output.
@@ -84,14 +91,15 @@ type BrowserStepResult struct {
 
 // BrowserBatchResult is the structured return value.
 type BrowserBatchResult struct {
-	URL                   string              `json:"url"`
-	Steps                 []BrowserStepResult `json:"steps,omitempty"`
-	ErrorsOutput          json.RawMessage     `json:"errorsOutput,omitempty"`
-	ConsoleOutput         json.RawMessage     `json:"consoleOutput,omitempty"`
-	DurationMs            int64               `json:"durationMs"`
-	ForkRecoveryAttempted bool                `json:"forkRecoveryAttempted,omitempty"`
-	OutputTruncated       bool                `json:"outputTruncated,omitempty"`
-	Message               string              `json:"message,omitempty"`
+	URL                   string               `json:"url"`
+	Steps                 []BrowserStepResult  `json:"steps,omitempty"`
+	ErrorsOutput          json.RawMessage      `json:"errorsOutput,omitempty"`
+	ConsoleOutput         json.RawMessage      `json:"consoleOutput,omitempty"`
+	DurationMs            int64                `json:"durationMs"`
+	ForkRecoveryAttempted bool                 `json:"forkRecoveryAttempted,omitempty"`
+	OutputTruncated       bool                 `json:"outputTruncated,omitempty"`
+	Message               string               `json:"message,omitempty"`
+	Visual                *BrowserVisualResult `json:"visual,omitempty"`
 }
 
 // browserRunner abstracts the agent-browser invocation for testability.
@@ -336,6 +344,8 @@ const (
 	browserCmdClose = "close"
 	// browserCmdOpen is the agent-browser open command.
 	browserCmdOpen = "open"
+	// browserCmdScreenshot is the agent-browser screenshot command.
+	browserCmdScreenshot = "screenshot"
 )
 
 // postRecoveryGrace is the pause after a pkill recovery, to give the
@@ -394,6 +404,12 @@ var cdpWedgeSignals = []string{
 // BrowserBatch runs one bounded agent-browser session against the given URL.
 // See package doc for the lifecycle contract.
 func BrowserBatch(ctx context.Context, input BrowserBatchInput) (*BrowserBatchResult, error) {
+	return browserBatch(ctx, input, "")
+}
+
+// browserBatch is BrowserBatch with an optional full-page screenshot
+// written to screenshotPath after the caller's commands.
+func browserBatch(ctx context.Context, input BrowserBatchInput, screenshotPath string) (*BrowserBatchResult, error) {
 	if strings.TrimSpace(input.URL) == "" {
 		return nil, platform.NewPlatformError(
 			platform.ErrInvalidParameter,
@@ -410,7 +426,7 @@ func BrowserBatch(ctx context.Context, input BrowserBatchInput) (*BrowserBatchRe
 		timeout = browserMaxTimeout
 	}
 
-	batch := buildCanonicalBatch(input.URL, input.Commands)
+	batch := buildCanonicalBatch(input.URL, input.Commands, screenshotPath)
 	stdinBytes, err := json.Marshal(batch)
 	if err != nil {
 		return nil, fmt.Errorf("marshal batch: %w", err)
@@ -584,9 +600,12 @@ func scanStepsForCDPWedge(steps []BrowserStepResult) (string, bool) {
 }
 
 // buildCanonicalBatch assembles [open url] + stripped caller commands +
-// [errors] [console] [close]. Any open/close in the caller's commands is
-// silently dropped — the canonical wrappers are the only lifecycle markers.
-func buildCanonicalBatch(url string, commands [][]string) [][]string {
+// [screenshot path --full] (when screenshotPath is set) + [errors]
+// [console] [close]. Any open/close in the caller's commands is silently
+// dropped — the canonical wrappers are the only lifecycle markers. The
+// screenshot goes before [errors] so the penultimate-step extraction in
+// BrowserBatch is unaffected.
+func buildCanonicalBatch(url string, commands [][]string, screenshotPath string) [][]string {
 	inner := make([][]string, 0, len(commands))
 	for _, cmd := range commands {
 		if len(cmd) == 0 {
@@ -599,9 +618,12 @@ func buildCanonicalBatch(url string, commands [][]string) [][]string {
 		}
 		inner = append(inner, cmd)
 	}
-	batch := make([][]string, 0, len(inner)+4)
+	batch := make([][]string, 0, len(inner)+5)
 	batch = append(batch, []string{browserCmdOpen, url})
 	batch = append(batch, inner...)
+	if screenshotPath != "" {
+		batch = append(batch, []string{browserCmdScreenshot, screenshotPath, "--full"})
+	}
 	batch = append(batch, []string{"errors"}, []string{"console"}, []string{browserCmdClose})
 	return batch
 }
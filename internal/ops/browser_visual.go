@@ -0,0 +1,381 @@
+// Package ops — visual checkpoints for zerops_browser.
+//
+// Frontend services regress visually without any HTTP status change: a
+// broken CSS bundle still returns 200. BrowserVisualBatch keeps PNG
+// evidence per (host, checkpoint) and diffs it against an accepted
+// baseline:
+//
+//	.zcp/state/browser/<host>/<checkpoint>/
+//	    latest.png    most recent capture
+//	    baseline.png  accepted reference; the first capture seeds it
+//	    diff.png      last comparison (changed pixels red over a faded copy)
+//
+// Baselines only move on an explicit action=accept, so a regression
+// stays visible across repeated compares until someone signs it off.
+package ops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// BrowserActionAccept promotes a checkpoint's latest capture to baseline.
+const BrowserActionAccept = "accept"
+
+const (
+	browserCheckpointDefault = "default"
+	browserLatestFile        = "latest.png"
+	browserBaselineFile      = "baseline.png"
+	browserDiffFile          = "diff.png"
+	// browserCaptureFile is where agent-browser writes; renamed to
+	// latest.png only after a clean walk so a failed run never replaces
+	// the last good capture.
+	browserCaptureFile = ".capture.png"
+	// browserPixelThreshold is the per-pixel YIQ colour distance (0..1)
+	// below which two pixels count as equal — pixelmatch's default, which
+	// absorbs font anti-aliasing and JPEG-ish noise in hero images.
+	browserPixelThreshold = 0.1
+)
+
+// browserCheckpointRe bounds checkpoint names to one safe path segment.
+var browserCheckpointRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// BrowserVisualResult reports what happened to a checkpoint.
+type BrowserVisualResult struct {
+	Host            string             `json:"host"`
+	Checkpoint      string             `json:"checkpoint"`
+	Screenshot      string             `json:"screenshot,omitempty"`
+	Baseline        string             `json:"baseline,omitempty"`
+	BaselineCreated bool               `json:"baselineCreated,omitempty"`
+	Accepted        bool               `json:"accepted,omitempty"`
+	Diff            *BrowserVisualDiff `json:"diff,omitempty"`
+	Message         string             `json:"message,omitempty"`
+}
+
+// BrowserVisualDiff is the outcome of comparing a capture to its baseline.
+type BrowserVisualDiff struct {
+	// DiffPercent is the share of differing pixels over the larger of
+	// the two canvases, rounded to 3 decimals.
+	DiffPercent  float64 `json:"diffPercent"`
+	DiffPixels   int     `json:"diffPixels"`
+	TotalPixels  int     `json:"totalPixels"`
+	DiffImage    string  `json:"diffImage"`
+	SizeChanged  bool    `json:"sizeChanged,omitempty"`
+	BaselineSize string  `json:"baselineSize"`
+	CaptureSize  string  `json:"captureSize"`
+}
+
+// BrowserVisualBatch is BrowserBatch plus visual checkpoints under
+// stateDir (.zcp/state). Without screenshot/checkpoint/compare/action it
+// is exactly BrowserBatch. action=accept never opens the browser.
+func BrowserVisualBatch(ctx context.Context, stateDir string, input BrowserBatchInput) (*BrowserBatchResult, error) {
+	if input.Action != "" && input.Action != BrowserActionAccept {
+		return nil, platform.NewPlatformError(
+			platform.ErrInvalidParameter,
+			fmt.Sprintf("unknown action %q", input.Action),
+			"The only action is \"accept\"; omit action to walk the page.",
+		)
+	}
+	if !input.Screenshot && !input.Compare && input.Checkpoint == "" && input.Action == "" {
+		return BrowserBatch(ctx, input)
+	}
+	if stateDir == "" {
+		return nil, platform.NewPlatformError(
+			platform.ErrPrerequisiteMissing,
+			"no state directory for browser checkpoints",
+			"Run ZCP from a project directory so .zcp/state can be created.",
+		)
+	}
+	host, err := browserURLHost(input.URL)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := input.Checkpoint
+	if checkpoint == "" {
+		checkpoint = browserCheckpointDefault
+	}
+	if !browserCheckpointRe.MatchString(checkpoint) {
+		return nil, platform.NewPlatformError(
+			platform.ErrInvalidParameter,
+			fmt.Sprintf("invalid checkpoint %q", checkpoint),
+			"Use letters, digits, '.', '_' or '-' (max 64 chars), e.g. \"home\" or \"checkout-form\".",
+		)
+	}
+	dir := filepath.Join(stateDir, "browser", host, checkpoint)
+	vis := &BrowserVisualResult{Host: host, Checkpoint: checkpoint}
+
+	if input.Action == BrowserActionAccept {
+		if err := acceptBrowserBaseline(dir, vis); err != nil {
+			return nil, err
+		}
+		return &BrowserBatchResult{URL: input.URL, Visual: vis}, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("browser checkpoint dir: %w", err)
+	}
+	capturePath := filepath.Join(dir, browserCaptureFile)
+	_ = os.Remove(capturePath)
+
+	result, err := browserBatch(ctx, input, capturePath)
+	if err != nil {
+		return nil, err
+	}
+	result.Visual = vis
+	if result.ForkRecoveryAttempted || result.Message != "" {
+		_ = os.Remove(capturePath)
+		vis.Message = "Walk did not complete; no screenshot kept. The previous capture and baseline are unchanged."
+		return result, nil
+	}
+	if _, err := os.Stat(capturePath); err != nil {
+		vis.Message = "agent-browser did not write the screenshot" + screenshotStepError(result.Steps) + "."
+		return result, nil
+	}
+	latest := filepath.Join(dir, browserLatestFile)
+	if err := os.Rename(capturePath, latest); err != nil {
+		return nil, fmt.Errorf("store screenshot: %w", err)
+	}
+	vis.Screenshot = latest
+
+	baseline := filepath.Join(dir, browserBaselineFile)
+	if _, err := os.Stat(baseline); errors.Is(err, os.ErrNotExist) {
+		if err := copyFile(latest, baseline); err != nil {
+			return nil, fmt.Errorf("seed baseline: %w", err)
+		}
+		vis.Baseline = baseline
+		vis.BaselineCreated = true
+		vis.Message = "First capture for this checkpoint — stored as the baseline. Later compares diff against it."
+		return result, nil
+	}
+	vis.Baseline = baseline
+
+	if input.Compare {
+		diff, err := compareBrowserScreenshots(baseline, latest, filepath.Join(dir, browserDiffFile))
+		if err != nil {
+			vis.Message = "Compare failed: " + err.Error()
+			return result, nil
+		}
+		vis.Diff = diff
+		if diff.DiffPixels > 0 {
+			vis.Message = fmt.Sprintf("%.3f%% of pixels differ from the baseline; inspect %s. "+
+				"If the change is intended, call again with action=accept and the same url + checkpoint.",
+				diff.DiffPercent, diff.DiffImage)
+		}
+	}
+	return result, nil
+}
+
+// acceptBrowserBaseline copies latest.png over baseline.png and drops the
+// now-stale diff image.
+func acceptBrowserBaseline(dir string, vis *BrowserVisualResult) error {
+	latest := filepath.Join(dir, browserLatestFile)
+	if _, err := os.Stat(latest); err != nil {
+		return platform.NewPlatformError(
+			platform.ErrFileNotFound,
+			fmt.Sprintf("no capture to accept for checkpoint %q on %s", vis.Checkpoint, vis.Host),
+			"Capture first with screenshot=true (or compare=true) for the same url and checkpoint.",
+		)
+	}
+	baseline := filepath.Join(dir, browserBaselineFile)
+	if err := copyFile(latest, baseline); err != nil {
+		return fmt.Errorf("accept baseline: %w", err)
+	}
+	_ = os.Remove(filepath.Join(dir, browserDiffFile))
+	vis.Screenshot = latest
+	vis.Baseline = baseline
+	vis.Accepted = true
+	return nil
+}
+
+// browserURLHost extracts the lower-cased hostname used as the checkpoint
+// directory. Scheme-less URLs ("appstage-1a2b.prg1.zerops.app") are
+// accepted the way agent-browser accepts them.
+func browserURLHost(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err == nil && u.Host == "" {
+		u, err = url.Parse("https://" + raw)
+	}
+	if err != nil || u.Hostname() == "" {
+		return "", platform.NewPlatformError(
+			platform.ErrInvalidParameter,
+			fmt.Sprintf("cannot derive a host from url %q", raw),
+			"Pass the full subdomain URL, e.g. https://appstage-1a2b-3000.prg1.zerops.app.",
+		)
+	}
+	host := strings.ToLower(u.Hostname())
+	if strings.ContainsAny(host, `/\`) || host == "." || host == ".." {
+		return "", platform.NewPlatformError(platform.ErrInvalidParameter, fmt.Sprintf("invalid host %q", host), "")
+	}
+	return host, nil
+}
+
+// screenshotStepError renders the screenshot step's own error, if any.
+func screenshotStepError(steps []BrowserStepResult) string {
+	for _, s := range steps {
+		if isCommand(s.Command, browserCmdScreenshot) && s.Error != nil {
+			return ": " + *s.Error
+		}
+	}
+	return ""
+}
+
+// compareBrowserScreenshots diffs capture against baseline pixel by pixel
+// using the YIQ perceptual distance and writes the diff image to
+// diffPath. Canvases of different size are compared over their union;
+// pixels present in only one of them count as changed (a page that grew
+// taller is a visual change).
+func compareBrowserScreenshots(baselinePath, capturePath, diffPath string) (*BrowserVisualDiff, error) {
+	base, err := decodePNGFile(baselinePath)
+	if err != nil {
+		return nil, fmt.Errorf("baseline: %w", err)
+	}
+	capt, err := decodePNGFile(capturePath)
+	if err != nil {
+		return nil, fmt.Errorf("capture: %w", err)
+	}
+	diffImg, changed := perceptualDiff(base, capt, browserPixelThreshold)
+
+	f, err := os.Create(diffPath)
+	if err != nil {
+		return nil, fmt.Errorf("diff image: %w", err)
+	}
+	if err := png.Encode(f, diffImg); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("diff image: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("diff image: %w", err)
+	}
+
+	total := diffImg.Bounds().Dx() * diffImg.Bounds().Dy()
+	pct := 0.0
+	if total > 0 {
+		pct = math.Round(float64(changed)/float64(total)*100*1000) / 1000
+	}
+	return &BrowserVisualDiff{
+		DiffPercent:  pct,
+		DiffPixels:   changed,
+		TotalPixels:  total,
+		DiffImage:    diffPath,
+		SizeChanged:  base.Bounds().Size() != capt.Bounds().Size(),
+		BaselineSize: fmt.Sprintf("%dx%d", base.Bounds().Dx(), base.Bounds().Dy()),
+		CaptureSize:  fmt.Sprintf("%dx%d", capt.Bounds().Dx(), capt.Bounds().Dy()),
+	}, nil
+}
+
+// perceptualDiff returns a diff image over the union of a and b plus the
+// count of changed pixels. threshold is the 0..1 share of the maximum
+// YIQ distance two pixels may differ by and still count as equal.
+// Unchanged pixels are drawn as a faded grey copy of b; changed ones red.
+func perceptualDiff(a, b *image.RGBA, threshold float64) (*image.RGBA, int) {
+	// Max YIQ delta between black and white, per pixelmatch.
+	const maxDelta = 35215.0
+	limit := maxDelta * threshold * threshold
+
+	w := max(a.Bounds().Dx(), b.Bounds().Dx())
+	h := max(a.Bounds().Dy(), b.Bounds().Dy())
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	red := color.RGBA{R: 255, A: 255}
+	changed := 0
+	for y := range h {
+		for x := range w {
+			pa, inA := rgbaAt(a, x, y)
+			pb, inB := rgbaAt(b, x, y)
+			if !inA || !inB || yiqDelta(pa, pb) > limit {
+				out.SetRGBA(x, y, red)
+				changed++
+				continue
+			}
+			// Faded luma so the red stands out against the page layout.
+			l := uint8(255 - (255-yiqLuma(pb))*0.1)
+			out.SetRGBA(x, y, color.RGBA{R: l, G: l, B: l, A: 255})
+		}
+	}
+	return out, changed
+}
+
+// rgbaAt returns the pixel at (x, y) relative to img's origin, blended
+// onto white, and whether it lies within img.
+func rgbaAt(img *image.RGBA, x, y int) ([3]float64, bool) {
+	r := img.Bounds()
+	if x >= r.Dx() || y >= r.Dy() {
+		return [3]float64{}, false
+	}
+	i := img.PixOffset(r.Min.X+x, r.Min.Y+y)
+	p := img.Pix[i : i+4 : i+4]
+	// Premultiplied alpha: blending onto white adds (255 - a).
+	bg := 255 - float64(p[3])
+	return [3]float64{float64(p[0]) + bg, float64(p[1]) + bg, float64(p[2]) + bg}, true
+}
+
+func yiqLuma(p [3]float64) float64 {
+	return p[0]*0.29889531 + p[1]*0.58662247 + p[2]*0.11448223
+}
+
+// yiqDelta is the squared perceptual distance between two colours in YIQ
+// space (Kotsarenko & Ramos), weighted the way pixelmatch weights it.
+func yiqDelta(a, b [3]float64) float64 {
+	dy := yiqLuma(a) - yiqLuma(b)
+	di := (a[0]*0.59597799 - a[1]*0.27417610 - a[2]*0.32180189) - (b[0]*0.59597799 - b[1]*0.27417610 - b[2]*0.32180189)
+	dq := (a[0]*0.21147017 - a[1]*0.52261711 + a[2]*0.31114694) - (b[0]*0.21147017 - b[1]*0.52261711 + b[2]*0.31114694)
+	return 0.5053*dy*dy + 0.299*di*di + 0.1957*dq*dq
+}
+
+// decodePNGFile reads a PNG into an RGBA canvas for direct Pix access.
+func decodePNGFile(path string) (*image.RGBA, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba, nil
+	}
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	return rgba, nil
+}
+
+// copyFile copies src over dst via a temp file + rename so readers never
+// see a half-written baseline.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp := dst + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
@@ -0,0 +1,212 @@
+// Tests for: BrowserVisualBatch — screenshot checkpoints, baselines and
+// perceptual diff. Sequential for the same reason as browser_test.go:
+// the runner is a package-level override.
+package ops
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// screenshotRunner answers the batch like agent-browser would, writing
+// next to the path of the [screenshot path --full] step.
+type screenshotRunner struct {
+	fakeBrowserRunner
+	t    *testing.T
+	page *image.RGBA // nil → no file written (screenshot step "failed")
+	runs int
+}
+
+func (s *screenshotRunner) Run(ctx context.Context, stdin string, timeout time.Duration) (string, string, bool, error) {
+	s.runs++
+	batch := parseStdinBatch(s.t, stdin)
+	for _, cmd := range batch {
+		if cmd[0] == "screenshot" && s.page != nil {
+			writePNG(s.t, cmd[1], s.page)
+		}
+	}
+	s.runStdout = makeStdout(s.t, batch)
+	return s.fakeBrowserRunner.Run(ctx, stdin, timeout)
+}
+
+func solidPage(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func writePNG(t *testing.T, path string, img image.Image) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBrowserVisualBatch_BaselineCompareAccept(t *testing.T) {
+	stateDir := t.TempDir()
+	white := color.RGBA{255, 255, 255, 255}
+	runner := &screenshotRunner{t: t, page: solidPage(10, 10, white)}
+	defer OverrideBrowserRunnerForTest(runner)()
+
+	in := BrowserBatchInput{URL: "https://AppStage-1a2b-3000.prg1.zerops.app/cart", Checkpoint: "cart", Compare: true}
+	dir := filepath.Join(stateDir, "browser", "appstage-1a2b-3000.prg1.zerops.app", "cart")
+
+	// 1. First capture seeds the baseline; screenshot step sits before [errors].
+	res, err := BrowserVisualBatch(context.Background(), stateDir, in)
+	if err != nil {
+		t.Fatalf("first capture: %v", err)
+	}
+	batch := parseStdinBatch(t, runner.lastStdin)
+	if shot := batch[len(batch)-4]; shot[0] != "screenshot" || shot[1] != filepath.Join(dir, ".capture.png") || shot[2] != "--full" {
+		t.Errorf("screenshot step = %v", shot)
+	}
+	if len(res.ErrorsOutput) == 0 {
+		t.Error("errorsOutput should still come from the [errors] step")
+	}
+	if v := res.Visual; v == nil || !v.BaselineCreated || v.Diff != nil || v.Baseline != filepath.Join(dir, "baseline.png") {
+		t.Fatalf("visual = %+v, want baseline created", res.Visual)
+	}
+
+	// 2. Identical page → zero diff.
+	res, err = BrowserVisualBatch(context.Background(), stateDir, in)
+	if err != nil {
+		t.Fatalf("identical compare: %v", err)
+	}
+	if d := res.Visual.Diff; d == nil || d.DiffPixels != 0 || d.DiffPercent != 0 {
+		t.Fatalf("diff = %+v, want zero", res.Visual.Diff)
+	}
+
+	// 3. A quarter of the page turns red → 25%.
+	changed := solidPage(10, 10, white)
+	for y := range 5 {
+		for x := range 5 {
+			changed.SetRGBA(x, y, color.RGBA{200, 0, 0, 255})
+		}
+	}
+	runner.page = changed
+	res, err = BrowserVisualBatch(context.Background(), stateDir, in)
+	if err != nil {
+		t.Fatalf("changed compare: %v", err)
+	}
+	d := res.Visual.Diff
+	if d == nil || d.DiffPixels != 25 || d.DiffPercent != 25 || d.DiffImage != filepath.Join(dir, "diff.png") {
+		t.Fatalf("diff = %+v, want 25 px / 25%%", d)
+	}
+	if _, err := os.Stat(d.DiffImage); err != nil {
+		t.Errorf("diff image not written: %v", err)
+	}
+	if res.Visual.Message == "" {
+		t.Error("a non-zero diff should point at action=accept")
+	}
+
+	// 4. Accept promotes latest without opening the browser.
+	runs := runner.runs
+	res, err = BrowserVisualBatch(context.Background(), stateDir, BrowserBatchInput{URL: in.URL, Checkpoint: "cart", Action: BrowserActionAccept})
+	if err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	if runner.runs != runs || !res.Visual.Accepted {
+		t.Errorf("accept ran browser (%d → %d) or not accepted: %+v", runs, runner.runs, res.Visual)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "diff.png")); !os.IsNotExist(err) {
+		t.Error("stale diff.png should be removed on accept")
+	}
+
+	// 5. The accepted page is the new reference.
+	res, err = BrowserVisualBatch(context.Background(), stateDir, in)
+	if err != nil {
+		t.Fatalf("post-accept compare: %v", err)
+	}
+	if res.Visual.Diff == nil || res.Visual.Diff.DiffPixels != 0 {
+		t.Errorf("diff after accept = %+v, want zero", res.Visual.Diff)
+	}
+}
+
+func TestBrowserVisualBatch_MissingScreenshotKeepsLatest(t *testing.T) {
+	stateDir := t.TempDir()
+	runner := &screenshotRunner{t: t, page: solidPage(4, 4, color.RGBA{0, 0, 255, 255})}
+	defer OverrideBrowserRunnerForTest(runner)()
+
+	in := BrowserBatchInput{URL: "https://app.example.com", Screenshot: true}
+	if _, err := BrowserVisualBatch(context.Background(), stateDir, in); err != nil {
+		t.Fatal(err)
+	}
+	runner.page = nil
+	res, err := BrowserVisualBatch(context.Background(), stateDir, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Visual.Screenshot != "" || res.Visual.Message == "" {
+		t.Errorf("visual = %+v, want message and no screenshot", res.Visual)
+	}
+	latest := filepath.Join(stateDir, "browser", "app.example.com", "default", "latest.png")
+	if _, err := os.Stat(latest); err != nil {
+		t.Errorf("previous latest.png should survive a failed capture: %v", err)
+	}
+}
+
+func TestBrowserVisualBatch_InputErrors(t *testing.T) {
+	runner := &screenshotRunner{t: t}
+	defer OverrideBrowserRunnerForTest(runner)()
+
+	tests := []struct {
+		name     string
+		stateDir string
+		input    BrowserBatchInput
+		code     string
+	}{
+		{"unknown action", "x", BrowserBatchInput{URL: "https://a.example", Action: "reject"}, platform.ErrInvalidParameter},
+		{"traversal checkpoint", "x", BrowserBatchInput{URL: "https://a.example", Checkpoint: "../etc"}, platform.ErrInvalidParameter},
+		{"no state dir", "", BrowserBatchInput{URL: "https://a.example", Screenshot: true}, platform.ErrPrerequisiteMissing},
+		{"accept without capture", t.TempDir(), BrowserBatchInput{URL: "https://a.example", Action: BrowserActionAccept}, platform.ErrFileNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BrowserVisualBatch(context.Background(), tt.stateDir, tt.input)
+			var pe *platform.PlatformError
+			if !errorAs(err, &pe) || pe.Code != tt.code {
+				t.Errorf("err = %v, want %s", err, tt.code)
+			}
+		})
+	}
+	if runner.runs != 0 {
+		t.Errorf("browser ran %d times on invalid input", runner.runs)
+	}
+}
+
+func TestPerceptualDiff(t *testing.T) {
+	t.Parallel()
+
+	white := color.RGBA{255, 255, 255, 255}
+	base := solidPage(4, 4, white)
+
+	// Near-white stays under the perceptual threshold.
+	if _, n := perceptualDiff(base, solidPage(4, 4, color.RGBA{250, 250, 250, 255}), browserPixelThreshold); n != 0 {
+		t.Errorf("near-white changed pixels = %d, want 0", n)
+	}
+	// A taller capture: the extra rows count as changed.
+	img, n := perceptualDiff(base, solidPage(4, 6, white), browserPixelThreshold)
+	if n != 8 || img.Bounds().Dy() != 6 {
+		t.Errorf("taller capture: changed = %d, height = %d; want 8, 6", n, img.Bounds().Dy())
+	}
+	if c := img.RGBAAt(0, 5); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("extra row pixel = %v, want red", c)
+	}
+}
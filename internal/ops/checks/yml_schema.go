@@ -17,7 +17,9 @@ import (
 //
 // Returns nil when validFields is nil (shim mode without a schema cache)
 // or when the yaml file is absent from ymlDir — the file-existence check
-// is the upstream surface for that case.
+// is the upstream surface for that case. When the fields come from a
+// stale schema (disk cache or embedded snapshot) unknown fields are
+// reported but pass: the field may simply be newer than the schema.
 func CheckZeropsYmlFields(_ context.Context, ymlDir string, validFields *schema.ValidFields) []workflow.StepCheck {
 	if validFields == nil {
 		return nil
@@ -41,6 +43,16 @@ func CheckZeropsYmlFields(_ context.Context, ymlDir string, validFields *schema.
 	for i, e := range fieldErrs {
 		details[i] = e.Error()
 	}
+	if validFields.Provenance.Stale() {
+		return []workflow.StepCheck{{
+			Name:   "zerops_yml_schema_fields",
+			Status: StatusPass,
+			Detail: fmt.Sprintf(
+				"not enforced — the platform schema is unavailable and the %s may predate these fields; verify they belong in zerops.yaml: %s",
+				validFields.Provenance, strings.Join(details, "; "),
+			),
+		}}
+	}
 	return []workflow.StepCheck{{
 		Name:   "zerops_yml_schema_fields",
 		Status: StatusFail,
@@ -21,6 +21,12 @@ func testValidFields() *schema.ValidFields {
 	}
 }
 
+func staleValidFields() *schema.ValidFields {
+	vf := testValidFields()
+	vf.Provenance = schema.Provenance{Source: schema.SourceDisk, Age: "72h0m0s"}
+	return vf
+}
+
 func writeYML(t *testing.T, content string) string {
 	t.Helper()
 	dir := t.TempDir()
@@ -76,6 +82,14 @@ func TestCheckZeropsYmlFields_Table(t *testing.T) {
 			wantStatus: "fail",
 			wantDetail: []string{"envVariableS"},
 		},
+		{
+			name:       "unknown field against stale schema passes with note",
+			vf:         staleValidFields(),
+			setupDir:   true,
+			yaml:       "zerops:\n  - setup: dev\n    run:\n      base: nodejs@22\n      newRunField: true\n",
+			wantStatus: "pass",
+			wantDetail: []string{"not enforced", "disk cache fetched 72h0m0s ago", "newRunField"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,134 @@
+package ops
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// pushArchive is the tar.gz a native local push uploads, spooled to a
+// temp file so its size is known before the PUT starts (the upload URL
+// needs Content-Length, and progress needs a total).
+type pushArchive struct {
+	file  *os.File
+	size  int64
+	files int
+}
+
+// Close removes the spooled archive.
+func (a *pushArchive) Close() error {
+	name := a.file.Name()
+	err := a.file.Close()
+	_ = os.Remove(name)
+	return err
+}
+
+// buildPushArchive packs workingDir the way `zcli push --no-git` does:
+// the whole tree, except that .git is never shipped and .deployignore
+// patterns are honoured. deployFiles selection is left to the builder,
+// which also needs build.addToRunPrepare files and whatever
+// prepareCommands read. Symlinks are stored as links, not followed.
+func buildPushArchive(workingDir string) (*pushArchive, error) {
+	ignore, err := loadDeployignore(workingDir)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp("", "zcp-push-*.tar.gz")
+	if err != nil {
+		return nil, fmt.Errorf("create push archive: %w", err)
+	}
+	archive := &pushArchive{file: tmp}
+	fail := func(err error) (*pushArchive, error) {
+		_ = archive.Close()
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(tmp)
+	tw := tar.NewWriter(gz)
+	walkErr := filepath.WalkDir(workingDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(workingDir, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == ".git" || ignore.excluded(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		added, err := addArchiveEntry(tw, p, rel, d)
+		if added {
+			archive.files++
+		}
+		return err
+	})
+	if walkErr != nil {
+		return fail(fmt.Errorf("pack %s: %w", workingDir, walkErr))
+	}
+	if err := tw.Close(); err != nil {
+		return fail(fmt.Errorf("finish push archive: %w", err))
+	}
+	if err := gz.Close(); err != nil {
+		return fail(fmt.Errorf("finish push archive: %w", err))
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fail(fmt.Errorf("size push archive: %w", err))
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return fail(fmt.Errorf("rewind push archive: %w", err))
+	}
+	archive.size = size
+	return archive, nil
+}
+
+// addArchiveEntry writes one walked path. Reports whether a regular file
+// was added; directories and symlinks are written but not counted.
+func addArchiveEntry(tw *tar.Writer, p, rel string, d fs.DirEntry) (bool, error) {
+	info, err := d.Info()
+	if err != nil {
+		return false, err
+	}
+	var link string
+	if info.Mode()&fs.ModeSymlink != 0 {
+		if link, err = os.Readlink(p); err != nil {
+			return false, err
+		}
+	} else if !info.IsDir() && !info.Mode().IsRegular() {
+		return false, nil // sockets, fifos, devices
+	}
+	hdr, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return false, err
+	}
+	hdr.Name = rel
+	if info.IsDir() {
+		hdr.Name += "/"
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return false, err
+	}
+	if !info.Mode().IsRegular() {
+		return false, nil
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	if _, err := io.Copy(tw, f); err != nil {
+		return false, err
+	}
+	return true, nil
+}
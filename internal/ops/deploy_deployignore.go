@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 )
@@ -98,3 +99,97 @@ func matchesLine(canonical, pattern string) bool {
 	}
 	return false
 }
+
+// deployignore is a parsed .deployignore: gitignore-style patterns that
+// keep paths out of the push archive. Later patterns win, so a `!`
+// negation re-includes what an earlier line excluded.
+type deployignore struct {
+	rules []deployignoreRule
+}
+
+type deployignoreRule struct {
+	pattern  string // slash-separated, no leading "/" or trailing "/"
+	negate   bool
+	dirOnly  bool // trailing "/": matches directories only
+	anchored bool // contains "/": matched against the full relative path
+}
+
+// loadDeployignore parses <workingDir>/.deployignore. A missing file
+// yields an empty matcher that excludes nothing.
+func loadDeployignore(workingDir string) (*deployignore, error) {
+	f, err := os.Open(filepath.Join(workingDir, ".deployignore"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &deployignore{}, nil
+		}
+		return nil, fmt.Errorf("read .deployignore: %w", err)
+	}
+	defer f.Close()
+
+	var out deployignore
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var r deployignoreRule
+		if rest, ok := strings.CutPrefix(line, "!"); ok {
+			r.negate, line = true, rest
+		}
+		line = strings.TrimPrefix(line, "./")
+		if rest, ok := strings.CutSuffix(line, "/"); ok {
+			r.dirOnly, line = true, rest
+		}
+		if rest, ok := strings.CutPrefix(line, "/"); ok {
+			r.anchored, line = true, rest
+		}
+		if strings.Contains(line, "/") {
+			r.anchored = true
+		}
+		if line == "" {
+			continue
+		}
+		r.pattern = line
+		out.rules = append(out.rules, r)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("scan .deployignore: %w", err)
+	}
+	return &out, nil
+}
+
+// excluded reports whether rel (slash-separated, relative to the working
+// dir) is excluded. Callers walk top-down and skip excluded directories,
+// so a file under an ignored directory never reaches this check.
+func (d *deployignore) excluded(rel string, isDir bool) bool {
+	excluded := false
+	for _, r := range d.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if r.matches(rel) {
+			excluded = !r.negate
+		}
+	}
+	return excluded
+}
+
+func (r deployignoreRule) matches(rel string) bool {
+	if !r.anchored {
+		ok, _ := path.Match(r.pattern, path.Base(rel))
+		return ok
+	}
+	if rest, ok := strings.CutPrefix(r.pattern, "**/"); ok {
+		// "**/x" matches x at any depth, including the root.
+		segs := strings.Split(rel, "/")
+		for i := range segs {
+			if m, _ := path.Match(rest, strings.Join(segs[i:], "/")); m {
+				return true
+			}
+		}
+		return false
+	}
+	ok, _ := path.Match(r.pattern, rel)
+	return ok
+}
@@ -106,3 +106,52 @@ func TestLintDeployignore_LegitimatePathPasses(t *testing.T) {
 		t.Errorf("legitimate paths should pass, got %+v", res)
 	}
 }
+
+func TestDeployignore_Excluded(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	body := "# comment\n*.log\nbuild/\n/secrets\ndocs/**/draft.md\n**/tmp\n!keep.log\n"
+	if err := os.WriteFile(filepath.Join(dir, ".deployignore"), []byte(body), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	ig, err := loadDeployignore(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"app.log", false, true},
+		{"sub/app.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"build", false, false}, // dir-only rule
+		{"secrets", false, true},
+		{"sub/secrets", false, false}, // anchored to root
+		{"docs/a/draft.md", false, true},
+		{"tmp", true, true},
+		{"a/b/tmp", true, true},
+		{"main.go", false, false},
+	}
+	for _, tt := range tests {
+		if got := ig.excluded(tt.rel, tt.isDir); got != tt.want {
+			t.Errorf("excluded(%q, dir=%v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestDeployignore_Missing(t *testing.T) {
+	t.Parallel()
+
+	ig, err := loadDeployignore(t.TempDir())
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if ig.excluded("anything", false) {
+		t.Error("missing .deployignore must exclude nothing")
+	}
+}
@@ -1,75 +1,39 @@
 package ops
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"os"
-	"os/exec"
-	"strings"
+	"io"
 
-	"github.com/zeropsio/zcp/internal/auth"
 	"github.com/zeropsio/zcp/internal/platform"
 )
 
-// commandRunner abstracts command execution for testability.
-type commandRunner interface {
-	LookPath(file string) (string, error)
-	Run(ctx context.Context, name string, args ...string) (stdout, stderr string, err error)
-}
-
-// execRunner is the production implementation using os/exec.
-type execRunner struct{}
-
-func (execRunner) LookPath(file string) (string, error) { return exec.LookPath(file) }
-
-func (execRunner) Run(ctx context.Context, name string, args ...string) (string, string, error) {
-	cmd := exec.CommandContext(ctx, name, args...)
-	var out, errBuf bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &errBuf
-	err := cmd.Run()
-	return out.String(), errBuf.String(), err
-}
-
-// runner is the active command runner. Tests override via OverrideRunnerForTest.
-var runner commandRunner = execRunner{}
-
-// OverrideRunnerForTest replaces the command runner for testing. Returns a restore function.
-func OverrideRunnerForTest(r commandRunner) func() {
-	old := runner
-	runner = r
-	return func() { runner = old }
-}
-
-// DeployLocal deploys code from the user's local machine to a Zerops service via zcli push.
+// DeployLocal deploys code from the user's local machine to a Zerops service
+// with a native push — the same three API steps `zcli push --no-git` takes,
+// without needing zcli installed:
 //
-// Uses --service-id and --project-id flags for non-interactive mode (no TTY needed).
-// zcli push blocks until the build pipeline completes. pollDeployBuild (in tool handler)
-// then confirms the final status via API.
+//  1. create an app version on the target (returns a pre-signed upload URL),
+//  2. pack the working dir into a tar.gz (.git and .deployignore entries
+//     excluded) and PUT it, reporting upload progress via onProgress,
+//  3. trigger build + deploy with the local zerops.yaml.
 //
-// Always runs with --no-git; recipes that need committed history go
-// through strategy=git-push (handleLocalGitPush in the tool layer),
-// which drives the user's own git CLI on a separate code path.
+// Returns once the build is triggered; pollDeployBuild (in tool handler)
+// then follows it via ops.PollBuild, exactly as on the SSH path.
+// onProgress may be nil.
+//
+// Recipes that need committed history go through strategy=git-push
+// (handleLocalGitPush in the tool layer), which drives the user's own
+// git CLI on a separate code path.
 func DeployLocal(
 	ctx context.Context,
 	client platform.Client,
 	projectID string,
-	authInfo auth.Info,
 	targetService string,
 	setup string,
 	workingDir string,
+	onProgress ProgressCallback,
 ) (*DeployResult, error) {
-	// 1. Validate zcli.
-	if _, err := runner.LookPath("zcli"); err != nil {
-		return nil, platform.NewPlatformError(
-			platform.ErrPrerequisiteMissing,
-			"zcli not found in PATH",
-			"Install zcli: https://docs.zerops.io/references/cli",
-		)
-	}
-
-	// 2. Validate targetService.
+	// 1. Validate targetService.
 	if targetService == "" {
 		return nil, platform.NewPlatformError(
 			platform.ErrInvalidParameter,
@@ -87,14 +51,14 @@ func DeployLocal(
 		return nil, err
 	}
 
-	// 3. Default workingDir.
+	// 2. Default workingDir.
 	if workingDir == "" {
 		workingDir = "."
 	}
 
-	// 4. Validate zerops.yaml.
-	zeropsYmlPath := workingDir + "/zerops.yml"
-	if _, statErr := os.Stat(zeropsYmlPath); statErr != nil {
+	// 3. Validate zerops.yaml.
+	zeropsYml, readErr := ReadZeropsYmlRaw(workingDir)
+	if readErr != nil {
 		return nil, platform.NewPlatformError(
 			platform.ErrInvalidParameter,
 			fmt.Sprintf("zerops.yaml not found at %s", workingDir),
@@ -134,35 +98,38 @@ func DeployLocal(
 		return nil, err
 	}
 
-	// 5. Login.
-	_, stderr, err := runner.Run(ctx, "zcli", "login", "--", authInfo.Token)
+	// 4. Pack.
+	archive, err := buildPushArchive(workingDir)
 	if err != nil {
 		return nil, platform.NewPlatformError(
 			platform.ErrDeployFailed,
-			"zcli login failed: "+strings.TrimSpace(lastLines(stderr, 3)),
-			"Check your API token. Regenerate in Zerops GUI if expired.",
+			fmt.Sprintf("pack %s: %v", workingDir, err),
+			"Check file permissions in the working directory, or exclude unreadable paths via .deployignore.",
+		)
+	}
+	defer archive.Close()
+	if archive.files == 0 {
+		return nil, platform.NewPlatformError(
+			platform.ErrInvalidParameter,
+			"nothing to push: every file in "+workingDir+" is excluded",
+			"Check .deployignore — it excludes the whole working directory.",
 		)
 	}
 
-	// 6. Push with explicit --service-id + --project-id → non-interactive (no TTY needed).
-	// zcli blocks until build+deploy completes, then pollDeployBuild confirms via API.
-	args := []string{
-		"push",
-		"--service-id", target.ID,
-		"--project-id", projectID,
-		"--working-dir", workingDir,
+	// 5. Create app version + upload.
+	upload, err := client.CreateAppVersion(ctx, target.ID)
+	if err != nil {
+		return nil, fmt.Errorf("create app version: %w", err)
 	}
-	if setup != "" {
-		args = append(args, "--setup", setup)
+	body := newUploadProgressReader(archive.file, archive.size, onProgress)
+	if err := client.UploadAppVersion(ctx, upload, body, archive.size); err != nil {
+		return nil, err
 	}
-	args = append(args, "--no-git")
-	_, stderr, err = runner.Run(ctx, "zcli", args...)
-	if err != nil {
-		return nil, platform.NewPlatformError(
-			platform.ErrDeployFailed,
-			"zcli push failed: "+strings.TrimSpace(lastLines(stderr, 5)),
-			"Check zerops.yaml syntax and build configuration.",
-		)
+
+	// 6. Trigger build + deploy. The process only tracks the trigger;
+	// pollDeployBuild follows the app version itself.
+	if _, err := client.BuildAndDeployAppVersion(ctx, upload.ID, string(zeropsYml), setupName); err != nil {
+		return nil, fmt.Errorf("trigger build: %w", err)
 	}
 
 	return &DeployResult{
@@ -171,10 +138,50 @@ func DeployLocal(
 		TargetService:     targetService,
 		TargetServiceID:   target.ID,
 		TargetServiceType: target.ServiceStackTypeInfo.ServiceStackTypeVersionName,
-		Message:           fmt.Sprintf("Build triggered for %s via zcli push", targetService),
+		Message:           fmt.Sprintf("Build triggered for %s (app version %s, %d files uploaded)", targetService, upload.ID, archive.files),
 		MonitorHint:       "Build runs asynchronously. Poll zerops_events for build/deploy FINISHED status.",
 		Warnings:          warnings,
 	}, nil
 }
 
-// lastLines is defined in deploy_classify.go
+// uploadProgressReader reports archive upload progress in 10% steps so a
+// multi-megabyte push does not flood the MCP progress channel.
+type uploadProgressReader struct {
+	r          io.Reader
+	total      int64
+	onProgress ProgressCallback
+	sent       int64
+	reported   int64 // last reported decile
+}
+
+func newUploadProgressReader(r io.Reader, total int64, onProgress ProgressCallback) io.Reader {
+	if onProgress == nil || total <= 0 {
+		return r
+	}
+	return &uploadProgressReader{r: r, total: total, onProgress: onProgress, reported: -1}
+}
+
+func (u *uploadProgressReader) Read(p []byte) (int, error) {
+	n, err := u.r.Read(p)
+	u.sent += int64(n)
+	if decile := u.sent * 10 / u.total; decile != u.reported {
+		u.reported = decile
+		u.onProgress(fmt.Sprintf("Uploading archive: %s / %s", formatBytes(u.sent), formatBytes(u.total)),
+			float64(u.sent), float64(u.total))
+	}
+	return n, err
+}
+
+// formatBytes renders n with a binary unit suffix.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,65 @@
+// Tests for: ops/deploy_local.go + ops/progress.go — local deploy against the simulated platform.
+package ops
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zcp/internal/platform"
+	"github.com/zeropsio/zcp/internal/platform/sim"
+)
+
+func TestDeployLocal_Simulated(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		buildCmd   string
+		wantStatus string
+		wantLog    string
+	}{
+		{"build succeeds", "npm ci", "ACTIVE", "creating deploy artifact"},
+		{"build fails", "exit 1", platform.BuildStatusBuildFailed, `"exit 1" exited with status 1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			s := sim.New(sim.Options{Start: time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)})
+
+			if _, err := s.ImportServices(ctx, s.ProjectID(), "services:\n  - hostname: app\n    type: nodejs@22\n"); err != nil {
+				t.Fatalf("import: %v", err)
+			}
+			s.Advance(time.Minute)
+
+			dir := t.TempDir()
+			yml := "zerops:\n  - setup: app\n    build:\n      base: nodejs@22\n      buildCommands:\n        - " + tt.buildCmd +
+				"\n      deployFiles: ./\n    run:\n      base: nodejs@22\n      ports:\n        - port: 3000\n          httpSupport: true\n      start: node server.js\n"
+			if err := os.WriteFile(filepath.Join(dir, "zerops.yml"), []byte(yml), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := DeployLocal(ctx, s, s.ProjectID(), "app", "app", dir, nil)
+			if err != nil {
+				t.Fatalf("DeployLocal: %v", err)
+			}
+
+			cfg := testConfig()
+			cfg.timeout = 5 * time.Second
+			event, err := pollBuild(ctx, s, s.ProjectID(), result.TargetServiceID, nil, cfg)
+			if err != nil {
+				t.Fatalf("pollBuild: %v", err)
+			}
+			if event.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", event.Status, tt.wantStatus)
+			}
+			logs := strings.Join(FetchBuildLogs(ctx, s, s, s.ProjectID(), event, 50), "\n")
+			if !strings.Contains(logs, tt.wantLog) {
+				t.Errorf("build logs missing %q:\n%s", tt.wantLog, logs)
+			}
+		})
+	}
+}
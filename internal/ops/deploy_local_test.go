@@ -1,77 +1,62 @@
-// Tests for: ops/deploy_local.go — DeployLocal via zcli push.
+// Tests for: ops/deploy_local.go — DeployLocal via native push.
 package ops
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"context"
-	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
-	"github.com/zeropsio/zcp/internal/auth"
 	"github.com/zeropsio/zcp/internal/platform"
 )
 
-// mockRunner is a test mock for commandRunner.
-type mockRunner struct {
-	lookPathErr error
-	runResults  []runResult // consumed in order
-	runCalls    []runCall
-	callIdx     int
-}
-
-type runResult struct {
-	stdout string
-	stderr string
-	err    error
-}
-
-type runCall struct {
-	name string
-	args []string
-}
-
-func (m *mockRunner) LookPath(_ string) (string, error) {
-	if m.lookPathErr != nil {
-		return "", m.lookPathErr
-	}
-	return "/usr/local/bin/zcli", nil
-}
-
-func (m *mockRunner) Run(_ context.Context, name string, args ...string) (string, string, error) {
-	m.runCalls = append(m.runCalls, runCall{name: name, args: args})
-	if m.callIdx >= len(m.runResults) {
-		return "", "", nil
-	}
-	r := m.runResults[m.callIdx]
-	m.callIdx++
-	return r.stdout, r.stderr, r.err
-}
-
-func localTestAuth() auth.Info {
-	return auth.Info{
-		Token:   "test-token",
-		APIHost: "api.app-prg1.zerops.io",
-		Region:  "prg1",
+// writeTree creates files (relative path → content) under dir.
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
 	}
 }
 
-// tmpWithZeropsYml creates a temp dir with a minimal zerops.yaml (uses .yml for fallback testing).
-func tmpWithZeropsYml(t *testing.T) string {
+// archiveFiles lists the regular files in a captured tar.gz upload.
+func archiveFiles(t *testing.T, data []byte) []string {
 	t.Helper()
-	dir := t.TempDir()
-	if err := os.WriteFile(filepath.Join(dir, "zerops.yml"), []byte("zerops:\n  - setup: app\n"), 0o644); err != nil {
-		t.Fatal(err)
-	}
-	return dir
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	var out []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("tar: %v", err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			out = append(out, hdr.Name)
+		}
+	}
+	slices.Sort(out)
+	return out
 }
 
-// Note: tests that use OverrideRunnerForTest must NOT use t.Parallel()
-// because they mutate the global runner variable.
-
-func TestDeployLocal_Success(t *testing.T) {
-	mock := platform.NewMock().
+func appstageMock() *platform.Mock {
+	return platform.NewMock().
 		WithServices([]platform.ServiceStack{
 			{
 				ID:   "svc-1",
@@ -81,16 +66,26 @@ func TestDeployLocal_Success(t *testing.T) {
 				},
 			},
 		})
+}
 
-	mr := &mockRunner{runResults: []runResult{{}, {}}}
-	restore := OverrideRunnerForTest(mr)
-	defer restore()
+func TestDeployLocal_Success(t *testing.T) {
+	t.Parallel()
+	mock := appstageMock()
 
 	dir := t.TempDir()
-	_ = os.WriteFile(filepath.Join(dir, "zerops.yml"), []byte("zerops:\n  - setup: appstage\n    build:\n      base: nodejs@22\n"), 0o644)
-
-	result, err := DeployLocal(context.Background(), mock, "proj-1", localTestAuth(),
-		"appstage", "", dir)
+	yml := "zerops:\n  - setup: appstage\n    build:\n      base: nodejs@22\n      buildCommands:\n        - npm ci\n      deployFiles: ./dist\n"
+	writeTree(t, dir, map[string]string{
+		"zerops.yml":    yml,
+		"src/index.js":  "console.log(1)",
+		"package.json":  "{}",
+		"debug.log":     "noise",
+		".git/HEAD":     "ref: refs/heads/main",
+		".deployignore": "*.log\n",
+	})
+
+	var progress []string
+	result, err := DeployLocal(context.Background(), mock, "proj-1",
+		"appstage", "", dir, func(msg string, _, _ float64) { progress = append(progress, msg) })
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -100,9 +95,6 @@ func TestDeployLocal_Success(t *testing.T) {
 	if result.Mode != "local" {
 		t.Errorf("mode = %s, want local", result.Mode)
 	}
-	if result.TargetService != "appstage" {
-		t.Errorf("targetService = %s, want appstage", result.TargetService)
-	}
 	if result.TargetServiceID != "svc-1" {
 		t.Errorf("targetServiceID = %s, want svc-1", result.TargetServiceID)
 	}
@@ -110,138 +102,120 @@ func TestDeployLocal_Success(t *testing.T) {
 		t.Errorf("targetServiceType = %s, want nodejs@22", result.TargetServiceType)
 	}
 
-	// Verify zcli commands.
-	if len(mr.runCalls) != 2 {
-		t.Fatalf("expected 2 run calls, got %d", len(mr.runCalls))
-	}
-	if mr.runCalls[0].name != "zcli" || mr.runCalls[0].args[0] != "login" {
-		t.Errorf("call[0] = %s %v, want zcli login ...", mr.runCalls[0].name, mr.runCalls[0].args)
+	// The whole tree goes up, minus .git and .deployignore matches.
+	got := archiveFiles(t, mock.CapturedUploads["av-svc-1"])
+	want := []string{".deployignore", "package.json", "src/index.js", "zerops.yml"}
+	if !slices.Equal(got, want) {
+		t.Errorf("archive files = %v, want %v", got, want)
 	}
-	pushArgs := strings.Join(mr.runCalls[1].args, " ")
-	if !strings.Contains(pushArgs, "push") {
-		t.Errorf("push args should contain push, got: %s", pushArgs)
+
+	if len(mock.CapturedBuildAndDeploy) != 1 {
+		t.Fatalf("BuildAndDeployAppVersion calls = %d, want 1", len(mock.CapturedBuildAndDeploy))
 	}
-	if !strings.Contains(pushArgs, "--service-id") || !strings.Contains(pushArgs, "--project-id") {
-		t.Errorf("push args should contain --service-id and --project-id for non-interactive mode, got: %s", pushArgs)
+	call := mock.CapturedBuildAndDeploy[0]
+	if call.AppVersionID != "av-svc-1" || call.Setup != "appstage" || call.ZeropsYaml != yml {
+		t.Errorf("build call = %+v", call)
 	}
-	if !strings.Contains(pushArgs, "--no-git") {
-		t.Errorf("push args should contain --no-git by default, got: %s", pushArgs)
+
+	if len(progress) == 0 || !strings.HasPrefix(progress[len(progress)-1], "Uploading archive") {
+		t.Errorf("progress = %v, want upload progress", progress)
 	}
 }
 
-func TestDeployLocal_ZcliNotFound(t *testing.T) {
-	mock := platform.NewMock()
-	mr := &mockRunner{lookPathErr: fmt.Errorf("not found")}
-	restore := OverrideRunnerForTest(mr)
-	defer restore()
-
-	_, err := DeployLocal(context.Background(), mock, "proj-1", localTestAuth(),
-		"app", "", ".")
-	if err == nil {
-		t.Fatal("expected error for missing zcli")
-	}
+// TestDeployLocal_NoBuildCommandsShipsWholeTree: like zcli push, a setup
+// without buildCommands still ships everything — addToRunPrepare files
+// and what prepareCommands read live outside deployFiles.
+func TestDeployLocal_NoBuildCommandsShipsWholeTree(t *testing.T) {
+	t.Parallel()
+	mock := appstageMock()
 
-	var pe *platform.PlatformError
-	if !errorAs(err, &pe) {
-		t.Fatalf("expected PlatformError, got %T: %v", err, err)
+	dir := t.TempDir()
+	yml := "zerops:\n  - setup: appstage\n    build:\n      base: nodejs@22\n" +
+		"      deployFiles:\n        - ./public/~\n        - server.js\n" +
+		"      addToRunPrepare:\n        - requirements.txt\n" +
+		"    run:\n      prepareCommands:\n        - sh scripts/setup.sh\n"
+	writeTree(t, dir, map[string]string{
+		"zerops.yml":       yml,
+		"public/index.js":  "x",
+		"server.js":        "x",
+		"requirements.txt": "x",
+		"scripts/setup.sh": "x",
+	})
+
+	if _, err := DeployLocal(context.Background(), mock, "proj-1", "appstage", "", dir, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if pe.Code != platform.ErrPrerequisiteMissing {
-		t.Errorf("code = %s, want %s", pe.Code, platform.ErrPrerequisiteMissing)
+	got := archiveFiles(t, mock.CapturedUploads["av-svc-1"])
+	want := []string{"public/index.js", "requirements.txt", "scripts/setup.sh", "server.js", "zerops.yml"}
+	if !slices.Equal(got, want) {
+		t.Errorf("archive files = %v, want %v", got, want)
 	}
 }
 
-func TestDeployLocal_MissingZeropsYml(t *testing.T) {
-	mock := platform.NewMock().
-		WithServices([]platform.ServiceStack{{ID: "svc-1", Name: "app"}})
-
-	mr := &mockRunner{}
-	restore := OverrideRunnerForTest(mr)
-	defer restore()
+func TestDeployLocal_NothingToPush(t *testing.T) {
+	t.Parallel()
+	mock := appstageMock()
 
-	dir := t.TempDir() // empty dir
-
-	_, err := DeployLocal(context.Background(), mock, "proj-1", localTestAuth(),
-		"app", "", dir)
-	if err == nil {
-		t.Fatal("expected error for missing zerops.yaml")
-	}
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"zerops.yml":    "zerops:\n  - setup: appstage\n",
+		".deployignore": "*\n",
+	})
 
+	_, err := DeployLocal(context.Background(), mock, "proj-1", "appstage", "", dir, nil)
 	var pe *platform.PlatformError
-	if !errorAs(err, &pe) {
-		t.Fatalf("expected PlatformError, got %T: %v", err, err)
+	if !errorAs(err, &pe) || pe.Code != platform.ErrInvalidParameter {
+		t.Fatalf("err = %v, want %s", err, platform.ErrInvalidParameter)
 	}
-	if pe.Code != platform.ErrInvalidParameter {
-		t.Errorf("code = %s, want %s", pe.Code, platform.ErrInvalidParameter)
+	if mock.CallCounts["CreateAppVersion"] != 0 {
+		t.Error("app version created for an empty archive")
 	}
 }
 
-func TestDeployLocal_LoginFailed(t *testing.T) {
-	mock := platform.NewMock().
-		WithServices([]platform.ServiceStack{{ID: "svc-1", Name: "app"}})
+func TestDeployLocal_UploadFailed(t *testing.T) {
+	t.Parallel()
+	mock := appstageMock().WithError("UploadAppVersion",
+		platform.NewPlatformError(platform.ErrNetworkError, "archive upload failed: connection reset", ""))
 
-	mr := &mockRunner{
-		runResults: []runResult{
-			{stderr: "Error: login failed\n", err: fmt.Errorf("exit status 1")},
-		},
-	}
-	restore := OverrideRunnerForTest(mr)
-	defer restore()
-
-	dir := tmpWithZeropsYml(t)
-
-	_, err := DeployLocal(context.Background(), mock, "proj-1", localTestAuth(),
-		"app", "", dir)
-	if err == nil {
-		t.Fatal("expected error for login failure")
-	}
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"zerops.yml": "zerops:\n  - setup: appstage\n"})
 
+	_, err := DeployLocal(context.Background(), mock, "proj-1", "appstage", "", dir, nil)
 	var pe *platform.PlatformError
-	if !errorAs(err, &pe) {
-		t.Fatalf("expected PlatformError, got %T: %v", err, err)
+	if !errorAs(err, &pe) || pe.Code != platform.ErrNetworkError {
+		t.Fatalf("err = %v, want %s", err, platform.ErrNetworkError)
 	}
-	if pe.Code != platform.ErrDeployFailed {
-		t.Errorf("code = %s, want %s", pe.Code, platform.ErrDeployFailed)
+	if len(mock.CapturedBuildAndDeploy) != 0 {
+		t.Error("build triggered after a failed upload")
 	}
 }
 
-func TestDeployLocal_PushFailed(t *testing.T) {
+func TestDeployLocal_MissingZeropsYml(t *testing.T) {
+	t.Parallel()
 	mock := platform.NewMock().
 		WithServices([]platform.ServiceStack{{ID: "svc-1", Name: "app"}})
 
-	mr := &mockRunner{
-		runResults: []runResult{
-			{}, // login ok
-			{stderr: "Error: push failed\nbuild error\n", err: fmt.Errorf("exit status 1")},
-		},
-	}
-	restore := OverrideRunnerForTest(mr)
-	defer restore()
-
-	dir := tmpWithZeropsYml(t)
+	dir := t.TempDir() // empty dir
 
-	_, err := DeployLocal(context.Background(), mock, "proj-1", localTestAuth(),
-		"app", "", dir)
+	_, err := DeployLocal(context.Background(), mock, "proj-1", "app", "", dir, nil)
 	if err == nil {
-		t.Fatal("expected error for push failure")
+		t.Fatal("expected error for missing zerops.yaml")
 	}
 
 	var pe *platform.PlatformError
 	if !errorAs(err, &pe) {
 		t.Fatalf("expected PlatformError, got %T: %v", err, err)
 	}
-	if pe.Code != platform.ErrDeployFailed {
-		t.Errorf("code = %s, want %s", pe.Code, platform.ErrDeployFailed)
+	if pe.Code != platform.ErrInvalidParameter {
+		t.Errorf("code = %s, want %s", pe.Code, platform.ErrInvalidParameter)
 	}
 }
 
 func TestDeployLocal_NoTargetService(t *testing.T) {
+	t.Parallel()
 	mock := platform.NewMock()
-	mr := &mockRunner{}
-	restore := OverrideRunnerForTest(mr)
-	defer restore()
 
-	_, err := DeployLocal(context.Background(), mock, "proj-1", localTestAuth(),
-		"", "", ".")
+	_, err := DeployLocal(context.Background(), mock, "proj-1", "", "", ".", nil)
 	if err == nil {
 		t.Fatal("expected error for empty targetService")
 	}
@@ -256,15 +230,11 @@ func TestDeployLocal_NoTargetService(t *testing.T) {
 }
 
 func TestDeployLocal_ServiceNotFound(t *testing.T) {
+	t.Parallel()
 	mock := platform.NewMock().
 		WithServices([]platform.ServiceStack{{ID: "svc-1", Name: "other"}})
 
-	mr := &mockRunner{}
-	restore := OverrideRunnerForTest(mr)
-	defer restore()
-
-	_, err := DeployLocal(context.Background(), mock, "proj-1", localTestAuth(),
-		"nonexistent", "", ".")
+	_, err := DeployLocal(context.Background(), mock, "proj-1", "nonexistent", "", ".", nil)
 	if err == nil {
 		t.Fatal("expected error for nonexistent service")
 	}
@@ -0,0 +1,169 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// rollbackSearchLimit bounds the app-version history scanned for rollback
+// candidates. SearchAppVersions is project-wide (all services share the
+// window), so the limit is wider than pollBuild's 10 to keep a few
+// retained versions per service in view on busy projects.
+const rollbackSearchLimit = 50
+
+// RollbackPlan names the app version a rollback will reactivate and the
+// version it replaces. Produced by PlanRollback (read-only) and consumed
+// by Rollback, so resolution failures never reach the platform.
+type RollbackPlan struct {
+	Hostname  string
+	ServiceID string
+	Target    platform.AppVersionEvent
+	Current   *platform.AppVersionEvent // nil when no version is ACTIVE
+}
+
+// RollbackResult contains the outcome of a rollback.
+type RollbackResult struct {
+	Status           string            `json:"status"`
+	TargetService    string            `json:"targetService"`
+	TargetServiceID  string            `json:"targetServiceId"`
+	AppVersionID     string            `json:"appVersionId"`
+	Sequence         int               `json:"sequence"`
+	FromAppVersionID string            `json:"fromAppVersionId,omitempty"`
+	FromSequence     int               `json:"fromSequence,omitempty"`
+	Process          *platform.Process `json:"process,omitempty"`
+	TimedOut         bool              `json:"timedOut,omitempty"`
+	Message          string            `json:"message"`
+	NextActions      string            `json:"nextActions,omitempty"`
+}
+
+// PlanRollback resolves which app version a rollback of hostname would
+// reactivate. With appVersionID empty it picks the newest retained version
+// older than the currently ACTIVE one; otherwise it validates the requested
+// ID belongs to the service and still has a deployable artifact.
+func PlanRollback(ctx context.Context, client platform.Client, projectID, hostname, appVersionID string) (*RollbackPlan, error) {
+	svc, err := resolveService(ctx, client, projectID, hostname)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := client.SearchAppVersions(ctx, projectID, rollbackSearchLimit)
+	if err != nil {
+		return nil, fmt.Errorf("list app versions for %s: %w", hostname, err)
+	}
+
+	var (
+		versions []platform.AppVersionEvent
+		current  *platform.AppVersionEvent
+	)
+	for i := range events {
+		ev := events[i]
+		if ev.ServiceStackID != svc.ID || isStartWithoutCodeEvent(&ev) {
+			continue
+		}
+		versions = append(versions, ev)
+		if ev.Status == statusActive && (current == nil || ev.Sequence > current.Sequence) {
+			evCopy := ev
+			current = &evCopy
+		}
+	}
+
+	plan := &RollbackPlan{Hostname: hostname, ServiceID: svc.ID, Current: current}
+
+	if appVersionID != "" {
+		for _, v := range versions {
+			if v.ID != appVersionID {
+				continue
+			}
+			if current != nil && v.ID == current.ID {
+				return nil, platform.NewPlatformError(platform.ErrInvalidParameter,
+					fmt.Sprintf("App version %s is already active on %s", appVersionID, hostname),
+					"Omit appVersionId to roll back to the previous version, or pick one from: "+listRollbackCandidates(versions, current))
+			}
+			if !isRollbackCandidate(v.Status) {
+				return nil, platform.NewPlatformError(platform.ErrInvalidParameter,
+					fmt.Sprintf("App version %s has status %s — only retained builds (%s) can be reactivated", appVersionID, v.Status, platform.AppVersionStatusBackup),
+					"Pick one from: "+listRollbackCandidates(versions, current))
+			}
+			plan.Target = v
+			return plan, nil
+		}
+		return nil, platform.NewPlatformError(platform.ErrInvalidParameter,
+			fmt.Sprintf("App version %s not found for service %s", appVersionID, hostname),
+			"Pick one from: "+listRollbackCandidates(versions, current))
+	}
+
+	var target *platform.AppVersionEvent
+	for i := range versions {
+		v := &versions[i]
+		if !isRollbackCandidate(v.Status) || (current != nil && v.ID == current.ID) {
+			continue
+		}
+		if current != nil && v.Sequence > current.Sequence {
+			continue
+		}
+		if target == nil || v.Sequence > target.Sequence {
+			target = v
+		}
+	}
+	if target == nil {
+		return nil, platform.NewPlatformError(platform.ErrInvalidParameter,
+			fmt.Sprintf("No previous app version to roll back to on %s", hostname),
+			"Rollback needs an earlier successful build the platform still retains. Redeploy known-good source with zerops_deploy instead.")
+	}
+	plan.Target = *target
+	return plan, nil
+}
+
+// Rollback triggers reactivation of plan.Target. The returned process is
+// not polled — callers drive it with PollProcess like any other async
+// lifecycle operation.
+func Rollback(ctx context.Context, client platform.Client, plan *RollbackPlan) (*RollbackResult, error) {
+	proc, err := client.DeployAppVersion(ctx, plan.Target.ID)
+	if err != nil {
+		return nil, err
+	}
+	result := &RollbackResult{
+		Status:          "ROLLBACK_TRIGGERED",
+		TargetService:   plan.Hostname,
+		TargetServiceID: plan.ServiceID,
+		AppVersionID:    plan.Target.ID,
+		Sequence:        plan.Target.Sequence,
+		Process:         proc,
+		Message:         fmt.Sprintf("Reactivating app version #%d on %s.", plan.Target.Sequence, plan.Hostname),
+	}
+	if plan.Current != nil {
+		result.FromAppVersionID = plan.Current.ID
+		result.FromSequence = plan.Current.Sequence
+	}
+	return result, nil
+}
+
+// isRollbackCandidate reports whether an app version still carries a built
+// artifact the platform can redeploy. ACTIVE is included so an explicit
+// appVersionId naming a stale ACTIVE record (superseded by a higher
+// sequence) is not rejected on status alone.
+func isRollbackCandidate(status string) bool {
+	return status == platform.AppVersionStatusBackup || status == statusActive
+}
+
+// listRollbackCandidates renders "id (#seq)" for every version that can be
+// reactivated, newest first, for error suggestions.
+func listRollbackCandidates(versions []platform.AppVersionEvent, current *platform.AppVersionEvent) string {
+	sorted := slices.Clone(versions)
+	slices.SortFunc(sorted, func(a, b platform.AppVersionEvent) int { return b.Sequence - a.Sequence })
+	var parts []string
+	for _, v := range sorted {
+		if !isRollbackCandidate(v.Status) || (current != nil && v.ID == current.ID) {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s (#%d)", v.ID, v.Sequence))
+	}
+	if len(parts) == 0 {
+		return "(none)"
+	}
+	return strings.Join(parts, ", ")
+}
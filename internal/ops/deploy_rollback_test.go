@@ -0,0 +1,158 @@
+// Tests for: ops/deploy_rollback.go — rollback target resolution and trigger.
+package ops
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+func rollbackMock(events []platform.AppVersionEvent) *platform.Mock {
+	return platform.NewMock().
+		WithServices([]platform.ServiceStack{
+			{ID: "svc-app", Name: "app", ProjectID: "proj-1"},
+			{ID: "svc-other", Name: "other", ProjectID: "proj-1"},
+		}).
+		WithAppVersionEvents(events)
+}
+
+func builtVersion(id, svcID, status string, seq int) platform.AppVersionEvent {
+	return platform.AppVersionEvent{
+		ID:             id,
+		ServiceStackID: svcID,
+		Source:         "CLI",
+		Status:         status,
+		Sequence:       seq,
+		Build:          &platform.BuildInfo{},
+	}
+}
+
+func TestPlanRollback(t *testing.T) {
+	t.Parallel()
+
+	history := []platform.AppVersionEvent{
+		builtVersion("av-5", "svc-app", "BUILD_FAILED", 5),
+		builtVersion("av-4", "svc-app", statusActive, 4),
+		builtVersion("av-9", "svc-other", platform.AppVersionStatusBackup, 9),
+		builtVersion("av-3", "svc-app", platform.AppVersionStatusBackup, 3),
+		builtVersion("av-2", "svc-app", platform.AppVersionStatusBackup, 2),
+		{ID: "av-0", ServiceStackID: "svc-app", Source: "NONE", Status: platform.AppVersionStatusBackup, Sequence: 1},
+	}
+
+	tests := []struct {
+		name        string
+		events      []platform.AppVersionEvent
+		requested   string
+		wantTarget  string
+		wantCurrent string
+		wantErr     string
+	}{
+		{name: "default picks newest backup below active", events: history, wantTarget: "av-3", wantCurrent: "av-4"},
+		{name: "explicit older version", events: history, requested: "av-2", wantTarget: "av-2", wantCurrent: "av-4"},
+		{name: "explicit active rejected", events: history, requested: "av-4", wantErr: "already active"},
+		{name: "explicit failed build rejected", events: history, requested: "av-5", wantErr: "BUILD_FAILED"},
+		{name: "other service version not found", events: history, requested: "av-9", wantErr: "not found"},
+		{name: "no history", events: nil, wantErr: "No previous app version"},
+		{
+			name:       "startWithoutCode skipped",
+			events:     []platform.AppVersionEvent{history[1], history[5]},
+			wantErr:    "No previous app version",
+			wantTarget: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			plan, err := PlanRollback(context.Background(), rollbackMock(tt.events), "proj-1", "app", tt.requested)
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("expected error containing %q, got plan %+v", tt.wantErr, plan)
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %q, want substring %q", err.Error(), tt.wantErr)
+				}
+				var pe *platform.PlatformError
+				if !errors.As(err, &pe) || pe.Code != platform.ErrInvalidParameter {
+					t.Errorf("expected INVALID_PARAMETER PlatformError, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if plan.Target.ID != tt.wantTarget {
+				t.Errorf("target = %q, want %q", plan.Target.ID, tt.wantTarget)
+			}
+			if plan.Current == nil || plan.Current.ID != tt.wantCurrent {
+				t.Errorf("current = %+v, want %q", plan.Current, tt.wantCurrent)
+			}
+		})
+	}
+}
+
+func TestPlanRollback_UnknownService(t *testing.T) {
+	t.Parallel()
+
+	_, err := PlanRollback(context.Background(), rollbackMock(nil), "proj-1", "ghost", "")
+	var pe *platform.PlatformError
+	if !errors.As(err, &pe) || pe.Code != platform.ErrServiceNotFound {
+		t.Fatalf("expected SERVICE_NOT_FOUND, got %v", err)
+	}
+}
+
+func TestRollback_TriggersAndPollsToFinished(t *testing.T) {
+	t.Parallel()
+
+	mock := rollbackMock([]platform.AppVersionEvent{
+		builtVersion("av-4", "svc-app", statusActive, 4),
+		builtVersion("av-3", "svc-app", platform.AppVersionStatusBackup, 3),
+	}).
+		WithProcess(&platform.Process{ID: "proc-deploy-version-av-3", ActionName: "stack.deploy"}).
+		WithProcessScenario("proc-deploy-version-av-3", platform.ProcessScenario{
+			InitialStatus: "PENDING",
+			Transitions: []platform.ProcessTransition{
+				{AtCall: 2, Status: "RUNNING"},
+				{AtCall: 3, Status: statusFinished},
+			},
+		})
+
+	ctx := context.Background()
+	plan, err := PlanRollback(ctx, mock, "proj-1", "app", "")
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	result, err := Rollback(ctx, mock, plan)
+	if err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if result.AppVersionID != "av-3" || result.FromAppVersionID != "av-4" {
+		t.Errorf("result versions = %s from %s, want av-3 from av-4", result.AppVersionID, result.FromAppVersionID)
+	}
+	if got := mock.CapturedDeployAppVersionIDs; len(got) != 1 || got[0] != "av-3" {
+		t.Errorf("DeployAppVersion calls = %v, want [av-3]", got)
+	}
+
+	final, err := pollProcess(ctx, mock, result.Process.ID, nil, testConfig())
+	if err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if final.Status != statusFinished {
+		t.Errorf("final status = %s, want FINISHED", final.Status)
+	}
+}
+
+func TestRollback_APIError(t *testing.T) {
+	t.Parallel()
+
+	mock := rollbackMock(nil).WithError("DeployAppVersion",
+		platform.NewPlatformError(platform.ErrAPIError, "boom", ""))
+	plan := &RollbackPlan{Hostname: "app", ServiceID: "svc-app", Target: builtVersion("av-3", "svc-app", platform.AppVersionStatusBackup, 3)}
+
+	if _, err := Rollback(context.Background(), mock, plan); err == nil {
+		t.Fatal("expected error from DeployAppVersion")
+	}
+}
@@ -370,19 +370,7 @@ type envRef struct {
 // parseEnvRefs extracts all ${hostname_varName} references from a string.
 func parseEnvRefs(s string) []envRef {
 	var refs []envRef
-	for {
-		idx := strings.Index(s, "${")
-		if idx == -1 {
-			break
-		}
-		s = s[idx:]
-		end := strings.Index(s, "}")
-		if end == -1 {
-			break
-		}
-		inner := s[2:end] // hostname_varName
-		s = s[end+1:]
-
+	for _, inner := range scanEnvRefs(s) {
 		// Must contain exactly one underscore separating hostname and varName.
 		underIdx := strings.Index(inner, "_")
 		if underIdx <= 0 || underIdx == len(inner)-1 {
@@ -397,6 +385,27 @@ func parseEnvRefs(s string) []envRef {
 	return refs
 }
 
+// scanEnvRefs returns the inner text of every non-empty ${...} in s —
+// cross-service hostname_varName and same-service varName alike.
+func scanEnvRefs(s string) []string {
+	var out []string
+	for {
+		idx := strings.Index(s, "${")
+		if idx == -1 {
+			return out
+		}
+		s = s[idx+2:]
+		end := strings.Index(s, "}")
+		if end == -1 {
+			return out
+		}
+		if inner := s[:end]; inner != "" {
+			out = append(out, inner)
+		}
+		s = s[end+1:]
+	}
+}
+
 // IsImplicitWebServerType returns true if the given service type (e.g. "php-nginx@8.4")
 // has a built-in web server that starts automatically.
 func IsImplicitWebServerType(serviceType string) bool {
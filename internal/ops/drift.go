@@ -0,0 +1,415 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/zeropsio/zcp/internal/platform"
+	"github.com/zeropsio/zcp/internal/topology"
+)
+
+// DefaultDriftFile is the declared import YAML drift detection reads when
+// no content or path is given — the file the export workflow generates
+// at repo root.
+const DefaultDriftFile = "zerops-project-import.yaml"
+
+// Drift kinds, always phrased from the live project's point of view.
+const (
+	DriftAdded   = "added"   // live has it, the YAML does not declare it
+	DriftRemoved = "removed" // the YAML declares it, live does not have it
+	DriftChanged = "changed" // both have it with different values
+)
+
+// Drift categories.
+const (
+	DriftCategoryService     = "service"
+	DriftCategoryType        = "type"
+	DriftCategoryMode        = "mode"
+	DriftCategoryAutoscaling = "autoscaling"
+	DriftCategoryPorts       = "ports"
+	DriftCategorySubdomain   = "subdomain"
+	DriftCategoryEnv         = "env"
+	DriftCategoryConfig      = "config"
+)
+
+// DriftReport is the result of comparing a declared import YAML against
+// the running project.
+type DriftReport struct {
+	Source   string       `json:"source"`
+	InSync   bool         `json:"inSync"`
+	Entries  []DriftEntry `json:"entries,omitempty"`
+	Summary  string       `json:"summary"`
+	Warnings []string     `json:"warnings,omitempty"`
+}
+
+// DriftEntry is one typed difference. Service is empty for project-level
+// entries. Fix names the tool call (or YAML edit) that closes the gap.
+type DriftEntry struct {
+	Kind     string `json:"kind"`
+	Category string `json:"category"`
+	Service  string `json:"service,omitempty"`
+	Field    string `json:"field,omitempty"`
+	Declared any    `json:"declared,omitempty"`
+	Live     any    `json:"live,omitempty"`
+	Fix      string `json:"fix"`
+}
+
+// exportConfigFields are service keys compared against the platform
+// export rather than ServiceStack, which does not carry them.
+var exportConfigFields = []string{
+	"buildFromGit", "zeropsSetup", "priority", "enableCdn",
+	"objectStorageSize", "objectStoragePolicy",
+}
+
+// DetectDrift compares a declared import YAML (content, or filePath, or
+// DefaultDriftFile) against the live project: ListServices for the
+// inventory, GetService for ports and autoscaling, the env APIs for keys,
+// and GetProjectExport for build/storage settings ServiceStack lacks.
+//
+// Env comparison is key-only — values are never read into the report.
+// Keys the platform generates on managed services (credentials,
+// connection strings) are not reported as added.
+func DetectDrift(
+	ctx context.Context,
+	client platform.Client,
+	projectID string,
+	content string,
+	filePath string,
+) (*DriftReport, error) {
+	source := "content"
+	if content == "" {
+		if filePath == "" {
+			filePath = DefaultDriftFile
+		}
+		source = filePath
+	}
+	yamlContent, err := resolveInput(content, filePath)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal([]byte(yamlContent), &doc); err != nil {
+		return nil, platform.NewPlatformError(platform.ErrInvalidImportYml,
+			fmt.Sprintf("invalid YAML in %s: %v", source, err), "Check YAML syntax")
+	}
+
+	services, err := client.ListServices(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	live := make(map[string]*platform.ServiceStack, len(services))
+	for i := range services {
+		if !services[i].IsSystem() {
+			live[services[i].Name] = &services[i]
+		}
+	}
+
+	report := &DriftReport{Source: source}
+	exported := exportedServices(ctx, client, projectID, report)
+
+	declared := importServices(doc)
+	secrets, err := declaredSecrets(ctx, declared, false)
+	if err != nil {
+		return nil, err
+	}
+	declaredHosts := make(map[string]bool, len(declared))
+	for i, svcMap := range declared {
+		hostname, _ := svcMap["hostname"].(string)
+		declaredHosts[hostname] = true
+		summary, ok := live[hostname]
+		if !ok {
+			report.Entries = append(report.Entries, DriftEntry{
+				Kind: DriftRemoved, Category: DriftCategoryService, Service: hostname,
+				Declared: svcMap["type"],
+				Fix:      "zerops_import the declared entry for " + hostname + ", or remove it from the YAML",
+			})
+			continue
+		}
+		// ListServices omits ports and active autoscaling; fetch detail.
+		svc, err := client.GetService(ctx, summary.ID)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := serviceDrift(ctx, client, svc, svcMap, secrets[i], exported[hostname])
+		if err != nil {
+			return nil, err
+		}
+		report.Entries = append(report.Entries, entries...)
+	}
+	for _, svc := range services {
+		if svc.IsSystem() || declaredHosts[svc.Name] {
+			continue
+		}
+		report.Entries = append(report.Entries, DriftEntry{
+			Kind: DriftAdded, Category: DriftCategoryService, Service: svc.Name,
+			Live: svc.ServiceStackTypeInfo.ServiceStackTypeVersionName,
+			Fix:  "Add " + svc.Name + " to the YAML (zerops_export shows its current definition), or zerops_delete it",
+		})
+	}
+
+	if project, ok := doc["project"].(map[string]any); ok {
+		entries, err := projectEnvDrift(ctx, client, projectID, project)
+		if err != nil {
+			return nil, err
+		}
+		report.Entries = append(report.Entries, entries...)
+	}
+
+	report.InSync = len(report.Entries) == 0
+	report.Summary = summarizeDrift(report)
+	return report, nil
+}
+
+// exportedServices indexes the platform export by hostname. The export
+// only adds detail, so a failure degrades to a warning.
+func exportedServices(ctx context.Context, client platform.Client, projectID string, report *DriftReport) map[string]map[string]any {
+	out := map[string]map[string]any{}
+	raw, err := client.GetProjectExport(ctx, projectID)
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("project export unavailable, %s not compared: %v", strings.Join(exportConfigFields, "/"), err))
+		return out
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal([]byte(raw), &doc); err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("project export is not valid YAML, %s not compared: %v", strings.Join(exportConfigFields, "/"), err))
+		return out
+	}
+	for _, svcMap := range importServices(doc) {
+		if hostname, _ := svcMap["hostname"].(string); hostname != "" {
+			out[hostname] = svcMap
+		}
+	}
+	return out
+}
+
+func serviceDrift(
+	ctx context.Context,
+	client platform.Client,
+	svc *platform.ServiceStack,
+	svcMap map[string]any,
+	declaredEnv map[string]string,
+	exported map[string]any,
+) ([]DriftEntry, error) {
+	hostname := svc.Name
+	liveType := svc.ServiceStackTypeInfo.ServiceStackTypeVersionName
+	ps := PlannedService{Hostname: hostname, Scaling: declaredScaling(svcMap)}
+	ps.Type, _ = svcMap["type"].(string)
+	if topology.ServiceSupportsMode(liveType) {
+		ps.Mode, _ = svcMap["mode"].(string)
+	}
+
+	var entries []DriftEntry
+	for _, d := range diffServiceDeclaration(svc, ps) {
+		e := DriftEntry{Kind: DriftChanged, Service: hostname, Field: d.Field, Declared: d.Declared, Live: d.Live}
+		switch d.Field {
+		case "type":
+			e.Category = DriftCategoryType
+			e.Fix = "Type cannot change in place: update the YAML, or re-import " + hostname + " with override=true (destructive)"
+		case "mode":
+			e.Category = DriftCategoryMode
+			e.Fix = "Mode cannot change in place: update the YAML, or re-import " + hostname + " with override=true (destructive)"
+		default:
+			e.Category = DriftCategoryAutoscaling
+			e.Fix = fmt.Sprintf("zerops_scale serviceHostname=%s %s=%v, or update the YAML", hostname, scaleParam(d.Field), d.Declared)
+		}
+		entries = append(entries, e)
+	}
+
+	// An absent key declares nothing; the subdomain may have been enabled
+	// later through zerops_subdomain.
+	if declared, ok := svcMap["enableSubdomainAccess"].(bool); ok && declared != svc.SubdomainAccess && !topology.IsManagedService(liveType) {
+		action := "enable"
+		if !declared {
+			action = "disable"
+		}
+		entries = append(entries, DriftEntry{
+			Kind: DriftChanged, Category: DriftCategorySubdomain, Service: hostname, Field: "enableSubdomainAccess",
+			Declared: declared, Live: svc.SubdomainAccess,
+			Fix: fmt.Sprintf("zerops_subdomain serviceHostname=%s action=%s, or update the YAML", hostname, action),
+		})
+	}
+
+	entries = append(entries, portDrift(svc, svcMap)...)
+
+	for _, field := range exportConfigFields {
+		want, declaredOK := svcMap[field]
+		have, liveOK := exported[field]
+		if !declaredOK || !liveOK || fmt.Sprint(want) == fmt.Sprint(have) {
+			continue
+		}
+		entries = append(entries, DriftEntry{
+			Kind: DriftChanged, Category: DriftCategoryConfig, Service: hostname, Field: field,
+			Declared: want, Live: have,
+			Fix: "Update the YAML to match, or re-import " + hostname + " with override=true (destructive)",
+		})
+	}
+
+	liveEnv, err := client.GetServiceEnv(ctx, svc.ID)
+	if err != nil {
+		return nil, err
+	}
+	entries = append(entries, envKeyDrift(hostname, declaredEnv, liveEnv, !topology.IsManagedService(liveType))...)
+	return entries, nil
+}
+
+// scaleParam maps an import YAML scaling key to its zerops_scale parameter.
+func scaleParam(field string) string {
+	if field == "startCpuCoreCount" {
+		return "startCpu"
+	}
+	return field
+}
+
+// portDrift compares run.ports of the declared zeropsYaml setup against
+// the live ports. Ports are only compared when the YAML inlines a
+// zeropsYaml — otherwise they are not declared here at all.
+func portDrift(svc *platform.ServiceStack, svcMap map[string]any) []DriftEntry {
+	declared, ok := declaredPorts(svcMap)
+	if !ok {
+		return nil
+	}
+	live := make([]string, 0, len(svc.Ports))
+	for _, p := range svc.Ports {
+		live = append(live, portKey(p.Port, p.Protocol))
+	}
+	slices.Sort(live)
+	var entries []DriftEntry
+	for _, p := range declared {
+		if !slices.Contains(live, p) {
+			entries = append(entries, DriftEntry{
+				Kind: DriftRemoved, Category: DriftCategoryPorts, Service: svc.Name, Field: "run.ports", Declared: p,
+				Fix: "Redeploy " + svc.Name + " with zerops_deploy so zerops.yaml run.ports applies",
+			})
+		}
+	}
+	for _, p := range live {
+		if !slices.Contains(declared, p) {
+			entries = append(entries, DriftEntry{
+				Kind: DriftAdded, Category: DriftCategoryPorts, Service: svc.Name, Field: "run.ports", Live: p,
+				Fix: "Declare the port in zeropsYaml run.ports, or remove it from zerops.yaml and redeploy",
+			})
+		}
+	}
+	return entries
+}
+
+// declaredPorts returns "port/protocol" keys from the zeropsYaml setup the
+// service uses: zeropsSetup when set, else the setup named after the
+// hostname, else the only setup.
+func declaredPorts(svcMap map[string]any) ([]string, bool) {
+	zy, ok := svcMap["zeropsYaml"].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	setups, _ := zy["zerops"].([]any)
+	want, _ := svcMap["zeropsSetup"].(string)
+	if want == "" {
+		want, _ = svcMap["hostname"].(string)
+	}
+	var chosen map[string]any
+	for _, s := range setups {
+		setup, ok := s.(map[string]any)
+		if !ok {
+			continue
+		}
+		if name, _ := setup["setup"].(string); name == want || len(setups) == 1 {
+			chosen = setup
+			break
+		}
+	}
+	if chosen == nil {
+		return nil, false
+	}
+	run, _ := chosen["run"].(map[string]any)
+	rawPorts, ok := run["ports"].([]any)
+	if !ok {
+		return nil, false
+	}
+	out := make([]string, 0, len(rawPorts))
+	for _, rp := range rawPorts {
+		p, ok := rp.(map[string]any)
+		if !ok {
+			continue
+		}
+		port, _ := p["port"].(int)
+		proto, _ := p["protocol"].(string)
+		out = append(out, portKey(port, proto))
+	}
+	slices.Sort(out)
+	return out, true
+}
+
+func portKey(port int, protocol string) string {
+	if protocol == "" {
+		protocol = "tcp"
+	}
+	return fmt.Sprintf("%d/%s", port, strings.ToLower(protocol))
+}
+
+// envKeyDrift reports declared keys missing live and, when reportAdded,
+// live keys the YAML does not declare.
+func envKeyDrift(service string, declared map[string]string, live []platform.EnvVar, reportAdded bool) []DriftEntry {
+	liveKeys := envKeySet(live)
+	scope := "serviceHostname=" + service
+	if service == "" {
+		scope = "project=true"
+	}
+	var entries []DriftEntry
+	for _, key := range sortedKeys(declared) {
+		if !liveKeys[key] {
+			entries = append(entries, DriftEntry{
+				Kind: DriftRemoved, Category: DriftCategoryEnv, Service: service, Field: key,
+				Fix: fmt.Sprintf("zerops_env action=set %s variables=[%q]", scope, key+"=<value>"),
+			})
+		}
+	}
+	if !reportAdded {
+		return entries
+	}
+	var added []string
+	for _, v := range live {
+		if _, ok := declared[v.Key]; !ok {
+			added = append(added, v.Key)
+		}
+	}
+	slices.Sort(added)
+	for _, key := range slices.Compact(added) {
+		entries = append(entries, DriftEntry{
+			Kind: DriftAdded, Category: DriftCategoryEnv, Service: service, Field: key,
+			Fix: fmt.Sprintf("Declare %s in the YAML, or zerops_env action=delete %s variables=[%q]", key, scope, key),
+		})
+	}
+	return entries
+}
+
+func projectEnvDrift(ctx context.Context, client platform.Client, projectID string, project map[string]any) ([]DriftEntry, error) {
+	rawEnv, ok := project["envVariables"].(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+	declared := make(map[string]string, len(rawEnv))
+	for k, v := range rawEnv {
+		declared[k] = fmt.Sprint(v)
+	}
+	live, err := client.GetProjectEnv(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return envKeyDrift("", declared, live, true), nil
+}
+
+func summarizeDrift(report *DriftReport) string {
+	if report.InSync {
+		return fmt.Sprintf("Live project matches %s.", report.Source)
+	}
+	counts := map[string]int{}
+	for _, e := range report.Entries {
+		counts[e.Kind]++
+	}
+	return fmt.Sprintf("%d difference(s) between %s and the live project: %d added live, %d missing live, %d changed.",
+		len(report.Entries), report.Source, counts[DriftAdded], counts[DriftRemoved], counts[DriftChanged])
+}
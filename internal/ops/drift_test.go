@@ -0,0 +1,174 @@
+// Tests for: ops/drift.go — declared import YAML vs live project drift.
+package ops
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+const driftDeclared = `project:
+  name: shop
+  envVariables:
+    APP_ENV: prod
+    FEATURE_X: "on"
+services:
+  - hostname: app
+    type: nodejs@22
+    buildFromGit: https://github.com/acme/shop
+    enableSubdomainAccess: true
+    minContainers: 1
+    maxContainers: 4
+    verticalAutoscaling:
+      maxRam: 4
+    envSecrets:
+      JWT_SECRET: x
+      SESSION_KEY: y
+    zeropsYaml:
+      zerops:
+        - setup: app
+          run:
+            ports:
+              - port: 3000
+                httpSupport: true
+  - hostname: db
+    type: postgresql@16
+    mode: HA
+  - hostname: queue
+    type: nats@2
+`
+
+func driftMock() *platform.Mock {
+	return platform.NewMock().
+		WithServices([]platform.ServiceStack{
+			{
+				ID: "svc-app", Name: "app", Status: "ACTIVE", Mode: "NON_HA",
+				ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "nodejs@22"},
+				Ports:                []platform.Port{{Port: 3000, Protocol: "tcp"}, {Port: 9229, Protocol: "tcp"}},
+				CustomAutoscaling:    &platform.CustomAutoscaling{MaxRAM: 2, HorizontalMinCount: 1, HorizontalMaxCount: 4},
+			},
+			{
+				ID: "svc-db", Name: "db", Status: "ACTIVE", Mode: "NON_HA",
+				ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "postgresql@16"},
+			},
+			{
+				ID: "svc-worker", Name: "worker", Status: "ACTIVE",
+				ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "go@1"},
+			},
+			{
+				ID: "svc-core", Name: "core", Status: "ACTIVE",
+				ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeCategoryName: "CORE"},
+			},
+		}).
+		WithServiceEnv("svc-app", []platform.EnvVar{{Key: "JWT_SECRET"}, {Key: "DEBUG"}}).
+		WithServiceEnv("svc-db", []platform.EnvVar{{Key: "password"}, {Key: "connectionString"}}).
+		WithProjectEnv([]platform.EnvVar{{Key: "APP_ENV"}}).
+		WithExportYAML("services:\n  - hostname: app\n    buildFromGit: https://github.com/acme/shop-old\n")
+}
+
+type driftKey struct{ kind, category, service, field string }
+
+func TestDetectDrift(t *testing.T) {
+	t.Parallel()
+
+	report, err := DetectDrift(context.Background(), driftMock(), "proj-1", driftDeclared, "")
+	if err != nil {
+		t.Fatalf("DetectDrift: %v", err)
+	}
+	if report.InSync {
+		t.Fatal("InSync = true for a drifted project")
+	}
+
+	got := map[driftKey]DriftEntry{}
+	for _, e := range report.Entries {
+		if e.Fix == "" {
+			t.Errorf("entry without fix: %+v", e)
+		}
+		got[driftKey{e.Kind, e.Category, e.Service, e.Field}] = e
+	}
+	want := []driftKey{
+		{DriftRemoved, DriftCategoryService, "queue", ""},
+		{DriftAdded, DriftCategoryService, "worker", ""},
+		{DriftChanged, DriftCategoryAutoscaling, "app", "maxRam"},
+		{DriftChanged, DriftCategorySubdomain, "app", "enableSubdomainAccess"},
+		{DriftAdded, DriftCategoryPorts, "app", "run.ports"},
+		{DriftChanged, DriftCategoryConfig, "app", "buildFromGit"},
+		{DriftRemoved, DriftCategoryEnv, "app", "SESSION_KEY"},
+		{DriftAdded, DriftCategoryEnv, "app", "DEBUG"},
+		{DriftChanged, DriftCategoryMode, "db", "mode"},
+		{DriftRemoved, DriftCategoryEnv, "", "FEATURE_X"},
+	}
+	for _, k := range want {
+		if _, ok := got[k]; !ok {
+			t.Errorf("missing drift entry %+v", k)
+		}
+	}
+	if len(report.Entries) != len(want) {
+		t.Errorf("got %d entries, want %d: %+v", len(report.Entries), len(want), report.Entries)
+	}
+	if e := got[driftKey{DriftChanged, DriftCategoryAutoscaling, "app", "maxRam"}]; e.Fix != "zerops_scale serviceHostname=app maxRam=4, or update the YAML" {
+		t.Errorf("maxRam fix = %q", e.Fix)
+	}
+	if e := got[driftKey{DriftAdded, DriftCategoryPorts, "app", "run.ports"}]; e.Live != "9229/tcp" {
+		t.Errorf("added port = %v, want 9229/tcp", e.Live)
+	}
+}
+
+func TestDetectDrift_InSync(t *testing.T) {
+	t.Parallel()
+
+	// api has no enableSubdomainAccess key, so a subdomain enabled later
+	// through zerops_subdomain is not drift.
+	mock := platform.NewMock().
+		WithServices([]platform.ServiceStack{{
+			ID: "svc-db", Name: "db", Status: "ACTIVE", Mode: "HA",
+			ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "postgresql@16"},
+		}, {
+			ID: "svc-api", Name: "api", Status: "ACTIVE", SubdomainAccess: true,
+			ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "nodejs@22"},
+		}}).
+		WithServiceEnv("svc-db", []platform.EnvVar{{Key: "password"}})
+	declared := "services:\n  - hostname: db\n    type: postgresql@16\n    mode: HA\n  - hostname: api\n    type: nodejs@22\n"
+	report, err := DetectDrift(context.Background(), mock, "proj-1", declared, "")
+	if err != nil {
+		t.Fatalf("DetectDrift: %v", err)
+	}
+	if !report.InSync || len(report.Entries) != 0 {
+		t.Errorf("report = %+v, want in sync", report)
+	}
+}
+
+func TestDetectDrift_DefaultFileAndExportFailure(t *testing.T) {
+	// Chdir: DefaultDriftFile is resolved against the working directory.
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, DefaultDriftFile), []byte("services: []\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	mock := platform.NewMock().WithError("GetProjectExport", errors.New("export down"))
+	report, err := DetectDrift(context.Background(), mock, "proj-1", "", "")
+	if err != nil {
+		t.Fatalf("DetectDrift: %v", err)
+	}
+	if report.Source != DefaultDriftFile || !report.InSync {
+		t.Errorf("report = %+v", report)
+	}
+	if len(report.Warnings) != 1 {
+		t.Errorf("warnings = %v, want export degradation warning", report.Warnings)
+	}
+}
+
+func TestDetectDrift_MissingFile(t *testing.T) {
+	t.Parallel()
+
+	_, err := DetectDrift(context.Background(), platform.NewMock(), "proj-1", "", filepath.Join(t.TempDir(), "nope.yaml"))
+	var pe *platform.PlatformError
+	if !errors.As(err, &pe) || pe.Code != platform.ErrFileNotFound {
+		t.Fatalf("err = %v, want FILE_NOT_FOUND", err)
+	}
+}
@@ -287,3 +287,25 @@ func buildEnvFileContent(pairs []envPair) string {
 	}
 	return b.String()
 }
+
+// buildQuotedEnvFileContent renders pairs as an env file for values that
+// came from another env file or service (import, promote). Multiline
+// values (PEM keys, certificates) are double-quoted with \ and " escaped,
+// the syntax ParseDotenv reads, so the newlines stay inside the value.
+// Single-line values are written bare, as buildEnvFileContent does.
+func buildQuotedEnvFileContent(pairs []envPair) string {
+	var b strings.Builder
+	for _, p := range pairs {
+		b.WriteString(p.Key)
+		b.WriteByte('=')
+		if strings.Contains(p.Value, "\n") {
+			b.WriteByte('"')
+			b.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(p.Value))
+			b.WriteByte('"')
+		} else {
+			b.WriteString(p.Value)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
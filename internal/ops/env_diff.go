@@ -0,0 +1,236 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// maskedValue replaces the value of a sensitive env var in diff output.
+const maskedValue = "[masked]"
+
+// sensitiveEnvKeyParts flags env keys whose values a diff never shows.
+// Matched case-insensitively as substrings; "KEY" covers APP_KEY,
+// API_KEY, *_ACCESS_KEY_ID and friends.
+var sensitiveEnvKeyParts = []string{"KEY", "TOKEN", "SECRET", "PASS", "CREDENTIAL", "DSN", "PRIVATE", "SALT", "AUTH"}
+
+func isSensitiveEnvKey(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, part := range sensitiveEnvKeyParts {
+		if strings.Contains(upper, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// EnvDiffEntry is one key that differs between source and target. Values
+// of sensitive keys are masked.
+type EnvDiffEntry struct {
+	Key       string `json:"key"`
+	Source    string `json:"source,omitempty"`
+	Target    string `json:"target,omitempty"`
+	Sensitive bool   `json:"sensitive,omitempty"`
+}
+
+// EnvDiff compares the service env vars of two services, typically the
+// dev and stage halves of a standard pair. Added keys exist only in the
+// source, Removed only in the target.
+type EnvDiff struct {
+	Source    string         `json:"source"`
+	Target    string         `json:"target"`
+	Added     []EnvDiffEntry `json:"added"`
+	Removed   []EnvDiffEntry `json:"removed"`
+	Changed   []EnvDiffEntry `json:"changed"`
+	Unchanged int            `json:"unchanged"`
+	Excluded  []string       `json:"excluded,omitempty"`
+
+	sourceEnv map[string]string
+	targetID  string
+}
+
+// EnvDiffServices diffs the service env vars of source against target.
+// exclude holds keys or path.Match patterns ("*_URL") that must differ per
+// half; matching keys are listed under Excluded and never diffed.
+func EnvDiffServices(ctx context.Context, client platform.Client, projectID, source, target string, exclude []string) (*EnvDiff, error) {
+	if source == "" || target == "" {
+		return nil, platform.NewPlatformError(platform.ErrInvalidParameter,
+			"diff and promote need a source and a target service", "Pass source=<dev hostname> target=<stage hostname>")
+	}
+	if source == target {
+		return nil, platform.NewPlatformError(platform.ErrInvalidParameter,
+			fmt.Sprintf("source and target are both %q", source), "Pass two different services")
+	}
+	for _, pattern := range exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, platform.NewPlatformError(platform.ErrInvalidParameter,
+				fmt.Sprintf("invalid exclude pattern %q: %v", pattern, err), "Use exact keys or shell globs like *_URL")
+		}
+	}
+
+	services, err := ListProjectServices(ctx, client, projectID)
+	if err != nil {
+		return nil, err
+	}
+	src, err := FindService(services, source)
+	if err != nil {
+		return nil, err
+	}
+	tgt, err := FindService(services, target)
+	if err != nil {
+		return nil, err
+	}
+	srcEnv, err := serviceEnvMap(ctx, client, src.ID)
+	if err != nil {
+		return nil, err
+	}
+	tgtEnv, err := serviceEnvMap(ctx, client, tgt.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &EnvDiff{
+		Source: src.Name, Target: tgt.Name,
+		Added: []EnvDiffEntry{}, Removed: []EnvDiffEntry{}, Changed: []EnvDiffEntry{},
+		sourceEnv: srcEnv, targetID: tgt.ID,
+	}
+	keys := slices.Sorted(maps.Keys(srcEnv))
+	for k := range tgtEnv {
+		if _, ok := srcEnv[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		if envKeyExcluded(k, exclude) {
+			diff.Excluded = append(diff.Excluded, k)
+			continue
+		}
+		sv, inSrc := srcEnv[k]
+		tv, inTgt := tgtEnv[k]
+		entry := EnvDiffEntry{Key: k, Source: sv, Target: tv, Sensitive: isSensitiveEnvKey(k)}
+		if entry.Sensitive {
+			entry.Source, entry.Target = maskIfSet(sv), maskIfSet(tv)
+		}
+		switch {
+		case !inTgt:
+			diff.Added = append(diff.Added, entry)
+		case !inSrc:
+			diff.Removed = append(diff.Removed, entry)
+		case sv != tv:
+			diff.Changed = append(diff.Changed, entry)
+		default:
+			diff.Unchanged++
+		}
+	}
+	return diff, nil
+}
+
+func serviceEnvMap(ctx context.Context, client platform.Client, serviceID string) (map[string]string, error) {
+	vars, err := FetchServiceEnv(ctx, client, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[string]string, len(vars))
+	for _, v := range vars {
+		env[v.Key] = v.Content
+	}
+	return env, nil
+}
+
+func envKeyExcluded(key string, exclude []string) bool {
+	for _, pattern := range exclude {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func maskIfSet(v string) string {
+	if v == "" {
+		return ""
+	}
+	return maskedValue
+}
+
+// EnvPromoteResult is the outcome of promoting env vars from source to
+// target. Removed keys are left in the target: promotion only adds and
+// updates.
+type EnvPromoteResult struct {
+	Diff     *EnvDiff          `json:"diff"`
+	Promoted []string          `json:"promoted"`
+	Process  *platform.Process `json:"process,omitempty"`
+	Warnings []string          `json:"warnings,omitempty"`
+}
+
+// EnvPromote copies the added and changed keys of an EnvDiffServices diff
+// (only those in keys, when non-empty) from source to target in a single
+// SetServiceEnvFile call. Values are copied as stored — already expanded —
+// so a promoted secret is byte-identical in both halves. A value that
+// references the source by hostname is promoted but flagged, since the
+// target would then talk to the other half.
+func EnvPromote(ctx context.Context, client platform.Client, projectID, source, target string, keys, exclude []string) (*EnvPromoteResult, error) {
+	diff, err := EnvDiffServices(ctx, client, projectID, source, target, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	promotable := make(map[string]bool, len(diff.Added)+len(diff.Changed))
+	for _, e := range slices.Concat(diff.Added, diff.Changed) {
+		promotable[e.Key] = true
+	}
+	selected := keys
+	if len(selected) == 0 {
+		selected = slices.Sorted(maps.Keys(promotable))
+	}
+	for _, k := range selected {
+		if promotable[k] {
+			continue
+		}
+		reason := "does not differ between the two services"
+		switch {
+		case slices.Contains(diff.Excluded, k):
+			reason = "is excluded"
+		case slices.ContainsFunc(diff.Removed, func(e EnvDiffEntry) bool { return e.Key == k }):
+			reason = "exists only in " + diff.Target + "; promote never deletes"
+		default:
+			if _, ok := diff.sourceEnv[k]; !ok {
+				reason = "is not set on " + diff.Source
+			}
+		}
+		return nil, platform.NewPlatformError(platform.ErrInvalidParameter,
+			fmt.Sprintf("cannot promote %s: key %s", k, reason),
+			"Run zerops_env action=diff to list added and changed keys")
+	}
+
+	result := &EnvPromoteResult{Diff: diff, Promoted: []string{}}
+	if len(selected) == 0 {
+		return result, nil
+	}
+
+	sourceRef := regexp.MustCompile(`(^|[^A-Za-z0-9])` + regexp.QuoteMeta(diff.Source) + `([^A-Za-z0-9]|$)`)
+	pairs := make([]envPair, 0, len(selected))
+	for _, k := range selected {
+		v := diff.sourceEnv[k]
+		pairs = append(pairs, envPair{Key: k, Value: v})
+		if sourceRef.MatchString(v) {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("%s references %s; after promotion %s points at it too — exclude the key or set it per half", k, diff.Source, diff.Target))
+		}
+	}
+
+	proc, err := client.SetServiceEnvFile(ctx, diff.targetID, buildQuotedEnvFileContent(pairs))
+	if err != nil {
+		return nil, err
+	}
+	result.Process = proc
+	result.Promoted = selected
+	return result, nil
+}
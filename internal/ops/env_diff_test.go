@@ -0,0 +1,160 @@
+// Tests for: ops/env_diff.go — env diff and promotion between services.
+package ops
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// envFileMock captures the content of SetServiceEnvFile calls.
+type envFileMock struct {
+	*platform.Mock
+	files map[string][]string
+}
+
+func (m *envFileMock) SetServiceEnvFile(ctx context.Context, serviceID, content string) (*platform.Process, error) {
+	m.files[serviceID] = append(m.files[serviceID], content)
+	return m.Mock.SetServiceEnvFile(ctx, serviceID, content)
+}
+
+func envPairMock() *envFileMock {
+	return &envFileMock{files: map[string][]string{}, Mock: platform.NewMock().
+		WithServices([]platform.ServiceStack{
+			{ID: "svc-dev", Name: "appdev", Status: "ACTIVE"},
+			{ID: "svc-stage", Name: "appstage", Status: "ACTIVE"},
+		}).
+		WithServiceEnv("svc-dev", []platform.EnvVar{
+			{Key: "APP_KEY", Content: "dev-secret"},
+			{Key: "FEATURE_X", Content: "on"},
+			{Key: "LOG_LEVEL", Content: "debug"},
+			{Key: "APP_URL", Content: "https://appdev.example"},
+			{Key: "SAME", Content: "1"},
+			{Key: "SELF_URL", Content: "http://${appdev_hostname}:3000"},
+		}).
+		WithServiceEnv("svc-stage", []platform.EnvVar{
+			{Key: "APP_KEY", Content: "stage-secret"},
+			{Key: "LOG_LEVEL", Content: "info"},
+			{Key: "APP_URL", Content: "https://appstage.example"},
+			{Key: "SAME", Content: "1"},
+			{Key: "LEGACY", Content: "x"},
+		}),
+	}
+}
+
+func TestEnvDiffServices(t *testing.T) {
+	t.Parallel()
+
+	diff, err := EnvDiffServices(context.Background(), envPairMock(), "proj-1", "appdev", "appstage", []string{"*_URL"})
+	if err != nil {
+		t.Fatalf("EnvDiffServices: %v", err)
+	}
+	keys := func(entries []EnvDiffEntry) string {
+		var out []string
+		for _, e := range entries {
+			out = append(out, e.Key)
+		}
+		return strings.Join(out, ",")
+	}
+	if got := keys(diff.Added); got != "FEATURE_X" {
+		t.Errorf("added = %s", got)
+	}
+	if got := keys(diff.Removed); got != "LEGACY" {
+		t.Errorf("removed = %s", got)
+	}
+	if got := keys(diff.Changed); got != "APP_KEY,LOG_LEVEL" {
+		t.Errorf("changed = %s", got)
+	}
+	if strings.Join(diff.Excluded, ",") != "APP_URL,SELF_URL" || diff.Unchanged != 1 {
+		t.Errorf("excluded = %v, unchanged = %d", diff.Excluded, diff.Unchanged)
+	}
+	appKey := diff.Changed[0]
+	if !appKey.Sensitive || appKey.Source != maskedValue || appKey.Target != maskedValue {
+		t.Errorf("APP_KEY = %+v, want masked", appKey)
+	}
+	if logLevel := diff.Changed[1]; logLevel.Source != "debug" || logLevel.Target != "info" {
+		t.Errorf("LOG_LEVEL = %+v", logLevel)
+	}
+
+	if _, err := EnvDiffServices(context.Background(), envPairMock(), "proj-1", "appdev", "appdev", nil); err == nil {
+		t.Error("same source and target should be rejected")
+	}
+	if _, err := EnvDiffServices(context.Background(), envPairMock(), "proj-1", "appdev", "appstage", []string{"["}); err == nil {
+		t.Error("malformed exclude pattern should be rejected")
+	}
+}
+
+func TestEnvPromote(t *testing.T) {
+	t.Parallel()
+
+	mock := envPairMock()
+	result, err := EnvPromote(context.Background(), mock, "proj-1", "appdev", "appstage", nil, []string{"APP_URL", "APP_KEY"})
+	if err != nil {
+		t.Fatalf("EnvPromote: %v", err)
+	}
+	if strings.Join(result.Promoted, ",") != "FEATURE_X,LOG_LEVEL,SELF_URL" || result.Process == nil {
+		t.Errorf("result = %+v", result)
+	}
+	files := mock.files["svc-stage"]
+	if len(files) != 1 {
+		t.Fatalf("SetServiceEnvFile calls = %d, want one", len(files))
+	}
+	if files[0] != "FEATURE_X=on\nLOG_LEVEL=debug\nSELF_URL=http://${appdev_hostname}:3000\n" {
+		t.Errorf("env file = %q", files[0])
+	}
+	if len(result.Warnings) != 1 || !strings.HasPrefix(result.Warnings[0], "SELF_URL references appdev") {
+		t.Errorf("warnings = %v", result.Warnings)
+	}
+}
+
+func TestEnvPromote_MultilineValue(t *testing.T) {
+	t.Parallel()
+
+	pem := "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----"
+	mock := &envFileMock{files: map[string][]string{}, Mock: platform.NewMock().
+		WithServices([]platform.ServiceStack{
+			{ID: "svc-dev", Name: "appdev", Status: "ACTIVE"},
+			{ID: "svc-stage", Name: "appstage", Status: "ACTIVE"},
+		}).
+		WithServiceEnv("svc-dev", []platform.EnvVar{{Key: "TLS_CERT", Content: pem}})}
+	if _, err := EnvPromote(context.Background(), mock, "proj-1", "appdev", "appstage", nil, nil); err != nil {
+		t.Fatalf("EnvPromote: %v", err)
+	}
+	files := mock.files["svc-stage"]
+	if len(files) != 1 {
+		t.Fatalf("SetServiceEnvFile calls = %d, want one", len(files))
+	}
+	entries, err := ParseDotenv(files[0])
+	if err != nil || len(entries) != 1 || entries[0].Key != "TLS_CERT" || entries[0].Value != pem {
+		t.Errorf("env file %q parses to %+v, %v; want the certificate as one value", files[0], entries, err)
+	}
+}
+
+func TestEnvPromote_Selection(t *testing.T) {
+	t.Parallel()
+
+	mock := envPairMock()
+	result, err := EnvPromote(context.Background(), mock, "proj-1", "appdev", "appstage", []string{"LOG_LEVEL"}, nil)
+	if err != nil || strings.Join(result.Promoted, ",") != "LOG_LEVEL" {
+		t.Fatalf("EnvPromote = %+v, %v", result, err)
+	}
+	if got := mock.files["svc-stage"]; len(got) != 1 || got[0] != "LOG_LEVEL=debug\n" {
+		t.Errorf("env file = %q", got)
+	}
+
+	for key, want := range map[string]string{
+		"SAME":    "does not differ",
+		"LEGACY":  "exists only in appstage",
+		"APP_URL": "is excluded",
+		"NOPE":    "is not set on appdev",
+	} {
+		_, err := EnvPromote(context.Background(), envPairMock(), "proj-1", "appdev", "appstage", []string{key}, []string{"APP_URL"})
+		var pe *platform.PlatformError
+		if !errors.As(err, &pe) || !strings.Contains(pe.Message, want) {
+			t.Errorf("%s: err = %v, want %q", key, err, want)
+		}
+	}
+}
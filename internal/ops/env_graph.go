@@ -0,0 +1,371 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// Env graph finding kinds.
+const (
+	EnvFindingDangling      = "dangling"
+	EnvFindingCycle         = "cycle"
+	EnvFindingSelfShadow    = "self_shadow"
+	EnvFindingProjectShadow = "project_shadow"
+	EnvFindingStoppedTarget = "stopped_target"
+	EnvFindingDeletedTarget = "deleted_target"
+)
+
+// Env var origins.
+const (
+	EnvOriginService    = "service"
+	EnvOriginZeropsYaml = "zerops.yaml"
+	EnvOriginProject    = "project"
+)
+
+// envGraphProject is the Service of project-level env var nodes. The
+// leading $ keeps it from colliding with a hostname.
+const envGraphProject = "$project"
+
+// maxEdgeLabelKeys caps the variable names printed on one rendered edge.
+const maxEdgeLabelKeys = 3
+
+// EnvGraphNode is one env var. ID is "<service>.<key>".
+type EnvGraphNode struct {
+	ID      string `json:"id"`
+	Service string `json:"service"`
+	Key     string `json:"key"`
+	Origin  string `json:"origin"`
+}
+
+// EnvGraphEdge is one ${...} reference from a variable to the variable it
+// resolves to. To is "<host>.<var>" even when that node does not exist.
+type EnvGraphEdge struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Reference string `json:"reference"`
+}
+
+// EnvGraphFinding is one problem in the graph. Cycle lists the node IDs
+// of a reference cycle in order.
+type EnvGraphFinding struct {
+	Kind      string   `json:"kind"`
+	Variable  string   `json:"variable"`
+	Reference string   `json:"reference,omitempty"`
+	Detail    string   `json:"detail"`
+	Cycle     []string `json:"cycle,omitempty"`
+}
+
+// EnvGraph is the project-wide ${host_var} reference graph.
+type EnvGraph struct {
+	Summary  string            `json:"summary"`
+	Findings []EnvGraphFinding `json:"findings"`
+	Nodes    []EnvGraphNode    `json:"nodes"`
+	Edges    []EnvGraphEdge    `json:"edges"`
+}
+
+// BuildEnvGraph loads every service's env vars and the project env vars,
+// overlays declaredEnv (zerops.yaml run.envVariables per runtime, which the
+// API does not expose) and resolves every ${...} reference the way the
+// platform does: a service's own variable first, then a project variable,
+// then ${hostname_var} on another service. knownHostnames are services ZCP
+// has tracked (service metas); one that is no longer live is reported as
+// deleted rather than unknown.
+func BuildEnvGraph(
+	ctx context.Context,
+	client platform.Client,
+	projectID string,
+	declaredEnv map[string]map[string]string,
+	knownHostnames []string,
+) (*EnvGraph, error) {
+	services, err := ListProjectServices(ctx, client, projectID)
+	if err != nil {
+		return nil, err
+	}
+	projectVars, err := client.GetProjectEnv(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &envGraphBuilder{
+		env:    make(map[string]map[string]string),
+		origin: make(map[string]string),
+		status: make(map[string]string),
+		known:  knownHostnames,
+	}
+	g.env[envGraphProject] = make(map[string]string, len(projectVars))
+	for _, v := range projectVars {
+		g.add(envGraphProject, v.Key, v.Content, EnvOriginProject)
+	}
+	for i := range services {
+		svc := &services[i]
+		if svc.IsSystem() {
+			continue
+		}
+		g.status[svc.Name] = svc.Status
+		g.env[svc.Name] = make(map[string]string)
+		vars, err := FetchServiceEnv(ctx, client, svc.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range vars {
+			g.add(svc.Name, v.Key, v.Content, EnvOriginService)
+		}
+		for k, v := range declaredEnv[svc.Name] {
+			g.add(svc.Name, k, v, EnvOriginZeropsYaml)
+		}
+	}
+	return g.build(), nil
+}
+
+type envGraphBuilder struct {
+	env    map[string]map[string]string // service → key → value
+	origin map[string]string            // node ID → origin
+	status map[string]string            // live hostname → status
+	known  []string
+
+	graph EnvGraph
+}
+
+func (g *envGraphBuilder) add(service, key, value, origin string) {
+	g.env[service][key] = value
+	g.origin[service+"."+key] = origin
+}
+
+func (g *envGraphBuilder) build() *EnvGraph {
+	adj := make(map[string][]string)
+	for _, service := range slices.Sorted(maps.Keys(g.env)) {
+		env := g.env[service]
+		for _, key := range slices.Sorted(maps.Keys(env)) {
+			id := service + "." + key
+			g.graph.Nodes = append(g.graph.Nodes, EnvGraphNode{ID: id, Service: service, Key: key, Origin: g.origin[id]})
+			if service != envGraphProject {
+				if _, ok := g.env[envGraphProject][key]; ok {
+					g.finding(EnvFindingProjectShadow, id, "", fmt.Sprintf("%s defines %s, hiding the project variable of the same name", service, key))
+				}
+				if isSelfShadow(key, env[key]) {
+					g.finding(EnvFindingSelfShadow, id, env[key],
+						"value is a reference to itself and resolves to the literal string; reference the source variable (e.g. ${db_hostname}) under a different key")
+					continue
+				}
+			}
+			for _, ref := range scanEnvRefs(env[key]) {
+				if to := g.resolve(service, id, ref); to != "" {
+					g.graph.Edges = append(g.graph.Edges, EnvGraphEdge{From: id, To: to, Reference: "${" + ref + "}"})
+					adj[id] = append(adj[id], to)
+				}
+			}
+		}
+	}
+	g.findCycles(adj)
+
+	counts := make(map[string]int)
+	for _, f := range g.graph.Findings {
+		counts[f.Kind]++
+	}
+	g.graph.Summary = fmt.Sprintf("%d variable(s), %d reference(s)", len(g.graph.Nodes), len(g.graph.Edges))
+	if len(counts) == 0 {
+		g.graph.Summary += ", no problems"
+	}
+	for _, kind := range slices.Sorted(maps.Keys(counts)) {
+		g.graph.Summary += fmt.Sprintf(", %d %s", counts[kind], kind)
+	}
+	if g.graph.Findings == nil {
+		g.graph.Findings = []EnvGraphFinding{}
+	}
+	return &g.graph
+}
+
+// resolve maps one ${inner} reference in service to the node it points
+// at, recording findings for references that cannot resolve cleanly.
+// Returns "" when there is no edge to draw.
+func (g *envGraphBuilder) resolve(service, from, inner string) string {
+	if service != envGraphProject {
+		if _, ok := g.env[service][inner]; ok {
+			return service + "." + inner
+		}
+	}
+	if _, ok := g.env[envGraphProject][inner]; ok {
+		return envGraphProject + "." + inner
+	}
+	host, varName, ok := strings.Cut(inner, "_")
+	reference := "${" + inner + "}"
+	if !ok || host == "" || varName == "" {
+		g.finding(EnvFindingDangling, from, reference, "no service or project variable named "+inner)
+		return ""
+	}
+	to := host + "." + varName
+	status, live := g.status[host]
+	switch {
+	case !live && slices.Contains(g.known, host):
+		g.finding(EnvFindingDeletedTarget, from, reference, fmt.Sprintf("service %s no longer exists", host))
+		return to
+	case !live:
+		g.finding(EnvFindingDangling, from, reference, fmt.Sprintf("unknown hostname %q", host))
+		return ""
+	}
+	if _, ok := g.env[host][varName]; !ok {
+		g.finding(EnvFindingDangling, from, reference, fmt.Sprintf("unknown variable %q on hostname %q", varName, host))
+		return ""
+	}
+	switch status {
+	case platform.ServiceStatusActive, platform.ServiceStatusRunning, platform.ServiceStatusNew, platform.ServiceStatusReadyToDeploy:
+	default:
+		g.finding(EnvFindingStoppedTarget, from, reference, fmt.Sprintf("service %s is %s", host, status))
+	}
+	return to
+}
+
+func (g *envGraphBuilder) finding(kind, variable, reference, detail string) {
+	g.graph.Findings = append(g.graph.Findings, EnvGraphFinding{Kind: kind, Variable: variable, Reference: reference, Detail: detail})
+}
+
+// findCycles reports the cycle closed by each DFS back edge, once,
+// rotated to start at its smallest node ID. Nodes are not revisited once
+// done, so this finds at least one cycle in every cyclic component but
+// not every elementary cycle; that is enough to flag the variables that
+// never resolve.
+func (g *envGraphBuilder) findCycles(adj map[string][]string) {
+	const (
+		unvisited = iota
+		onStack
+		done
+	)
+	state := make(map[string]int)
+	seen := make(map[string]bool)
+	var stack []string
+	var visit func(n string)
+	visit = func(n string) {
+		state[n] = onStack
+		stack = append(stack, n)
+		for _, next := range adj[n] {
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case onStack:
+				start := slices.Index(stack, next)
+				cycle := slices.Clone(stack[start:])
+				low := slices.Index(cycle, slices.Min(cycle))
+				cycle = append(cycle[low:], cycle[:low]...)
+				if sig := strings.Join(cycle, ">"); !seen[sig] {
+					seen[sig] = true
+					g.graph.Findings = append(g.graph.Findings, EnvGraphFinding{
+						Kind: EnvFindingCycle, Variable: cycle[0], Cycle: cycle,
+						Detail: strings.Join(append(cycle, cycle[0]), " → ") + " never resolves",
+					})
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[n] = done
+	}
+	for _, n := range slices.Sorted(maps.Keys(adj)) {
+		if state[n] == unvisited {
+			visit(n)
+		}
+	}
+}
+
+// serviceEdge is a service → service edge labelled with the referencing
+// keys. broken marks an edge carrying a dangling, deleted or stopped ref.
+type serviceEdge struct {
+	from, to string
+	keys     []string
+	broken   bool
+}
+
+// serviceEdges collapses variable edges into service-level edges for the
+// compact renderings. Same-service references are dropped.
+func (g *EnvGraph) serviceEdges() []serviceEdge {
+	broken := make(map[string]bool)
+	for _, f := range g.Findings {
+		if f.Kind == EnvFindingDangling || f.Kind == EnvFindingDeletedTarget || f.Kind == EnvFindingStoppedTarget {
+			broken[f.Variable+"|"+f.Reference] = true
+		}
+	}
+	byPair := make(map[[2]string]*serviceEdge)
+	add := func(from, to, key string, bad bool) {
+		k := [2]string{from, to}
+		e := byPair[k]
+		if e == nil {
+			e = &serviceEdge{from: from, to: to}
+			byPair[k] = e
+		}
+		if !slices.Contains(e.keys, key) {
+			e.keys = append(e.keys, key)
+		}
+		e.broken = e.broken || bad
+	}
+	for _, e := range g.Edges {
+		fromSvc, key, _ := strings.Cut(e.From, ".")
+		toSvc, _, _ := strings.Cut(e.To, ".")
+		if fromSvc != toSvc {
+			add(fromSvc, toSvc, key, broken[e.From+"|"+e.Reference])
+		}
+	}
+	// Dangling references have no edge; draw them to a "?" node.
+	for _, f := range g.Findings {
+		if f.Kind == EnvFindingDangling {
+			fromSvc, key, _ := strings.Cut(f.Variable, ".")
+			add(fromSvc, "?", key, true)
+		}
+	}
+	out := make([]serviceEdge, 0, len(byPair))
+	for _, k := range slices.SortedFunc(maps.Keys(byPair), func(a, b [2]string) int {
+		return strings.Compare(a[0]+"\x00"+a[1], b[0]+"\x00"+b[1])
+	}) {
+		out = append(out, *byPair[k])
+	}
+	return out
+}
+
+func (e serviceEdge) label() string {
+	if len(e.keys) <= maxEdgeLabelKeys {
+		return strings.Join(e.keys, ", ")
+	}
+	return strings.Join(e.keys[:maxEdgeLabelKeys], ", ") + fmt.Sprintf(" +%d", len(e.keys)-maxEdgeLabelKeys)
+}
+
+// DOT renders the service-level graph in Graphviz DOT. Edges carrying a
+// dangling, deleted or stopped reference are dashed red.
+func (g *EnvGraph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph env {\n  rankdir=LR;\n")
+	for _, e := range g.serviceEdges() {
+		attrs := fmt.Sprintf("label=%q", e.label())
+		if e.broken {
+			attrs += ", style=dashed, color=red"
+		}
+		fmt.Fprintf(&b, "  %q -> %q [%s];\n", e.from, e.to, attrs)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the service-level graph as a mermaid flowchart. Broken
+// edges are dotted.
+func (g *EnvGraph) Mermaid() string {
+	id := func(s string) string {
+		switch s {
+		case envGraphProject:
+			return "project_env[(project)]"
+		case "?":
+			return "unresolved{{?}}"
+		}
+		return "svc_" + s + "[" + s + "]"
+	}
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for _, e := range g.serviceEdges() {
+		arrow := "-->"
+		if e.broken {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "  %s %s|%s| %s\n", id(e.from), arrow, strings.ReplaceAll(e.label(), "|", "/"), id(e.to))
+	}
+	return b.String()
+}
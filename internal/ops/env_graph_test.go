@@ -0,0 +1,113 @@
+// Tests for: ops/env_graph.go — project env reference graph.
+package ops
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+func envGraphMock() *platform.Mock {
+	return platform.NewMock().
+		WithServices([]platform.ServiceStack{
+			{ID: "svc-api", Name: "api", Status: "RUNNING"},
+			{ID: "svc-db", Name: "db", Status: "ACTIVE"},
+			{ID: "svc-cache", Name: "cache", Status: "STOPPED"},
+		}).
+		WithProjectEnv([]platform.EnvVar{{Key: "REGION", Content: "eu"}, {Key: "LOG_LEVEL", Content: "info"}}).
+		WithServiceEnv("svc-api", []platform.EnvVar{
+			{Key: "DATABASE_URL", Content: "postgres://${db_user}:${db_password}@${db_hostname}/app"},
+			{Key: "REDIS_URL", Content: "redis://${cache_hostname}"},
+			{Key: "LOG_LEVEL", Content: "debug"},
+			{Key: "A", Content: "${B}"},
+			{Key: "B", Content: "x${A}"},
+			{Key: "SEARCH", Content: "${search_hostname}"},
+			{Key: "TYPO", Content: "${db_pasword}"},
+			{Key: "WHERE", Content: "${REGION}"},
+		}).
+		WithServiceEnv("svc-db", []platform.EnvVar{
+			{Key: "user", Content: "db"},
+			{Key: "password", Content: "pw"},
+			{Key: "hostname", Content: "db"},
+		}).
+		WithServiceEnv("svc-cache", []platform.EnvVar{{Key: "hostname", Content: "cache"}})
+}
+
+func TestBuildEnvGraph(t *testing.T) {
+	t.Parallel()
+
+	declared := map[string]map[string]string{"api": {"db_hostname": "${db_hostname}", "GHOST": "${ghost_port}"}}
+	graph, err := BuildEnvGraph(context.Background(), envGraphMock(), "proj-1", declared, []string{"api", "search"})
+	if err != nil {
+		t.Fatalf("BuildEnvGraph: %v", err)
+	}
+
+	got := make([]string, 0, len(graph.Findings))
+	for _, f := range graph.Findings {
+		got = append(got, f.Kind+" "+f.Variable+" "+f.Reference)
+	}
+	slices.Sort(got)
+	want := []string{
+		"cycle api.A ",
+		"dangling api.GHOST ${ghost_port}",
+		"dangling api.TYPO ${db_pasword}",
+		"deleted_target api.SEARCH ${search_hostname}",
+		"project_shadow api.LOG_LEVEL ",
+		"self_shadow api.db_hostname ${db_hostname}",
+		"stopped_target api.REDIS_URL ${cache_hostname}",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("findings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	for _, f := range graph.Findings {
+		if f.Kind == EnvFindingCycle && strings.Join(f.Cycle, ",") != "api.A,api.B" {
+			t.Errorf("cycle = %v", f.Cycle)
+		}
+	}
+	var origin string
+	for _, n := range graph.Nodes {
+		if n.ID == "api.GHOST" {
+			origin = n.Origin
+		}
+	}
+	if origin != EnvOriginZeropsYaml {
+		t.Errorf("api.GHOST origin = %q", origin)
+	}
+	if !strings.HasPrefix(graph.Summary, "16 variable(s), 8 reference(s)") {
+		t.Errorf("summary = %s", graph.Summary)
+	}
+
+	dot := graph.DOT()
+	for _, line := range []string{
+		`"api" -> "db" [label="DATABASE_URL"];`,
+		`"api" -> "cache" [label="REDIS_URL", style=dashed, color=red];`,
+		`"api" -> "$project" [label="WHERE"];`,
+		`"api" -> "?" [label="GHOST, TYPO", style=dashed, color=red];`,
+	} {
+		if !strings.Contains(dot, line) {
+			t.Errorf("DOT missing %s:\n%s", line, dot)
+		}
+	}
+	if mermaid := graph.Mermaid(); !strings.Contains(mermaid, "svc_api[api] -.->|SEARCH| svc_search[search]") {
+		t.Errorf("mermaid:\n%s", mermaid)
+	}
+}
+
+func TestBuildEnvGraph_Clean(t *testing.T) {
+	t.Parallel()
+
+	mock := platform.NewMock().
+		WithServices([]platform.ServiceStack{{ID: "svc-db", Name: "db", Status: "ACTIVE"}}).
+		WithServiceEnv("svc-db", []platform.EnvVar{{Key: "hostname", Content: "db"}})
+	graph, err := BuildEnvGraph(context.Background(), mock, "proj-1", nil, nil)
+	if err != nil {
+		t.Fatalf("BuildEnvGraph: %v", err)
+	}
+	if graph.Summary != "1 variable(s), 0 reference(s), no problems" || graph.Findings == nil {
+		t.Errorf("graph = %+v", graph)
+	}
+}
@@ -0,0 +1,300 @@
+package ops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// Env import entry statuses.
+const (
+	EnvImportAdded     = "added"
+	EnvImportChanged   = "changed"
+	EnvImportUnchanged = "unchanged"
+	EnvImportKept      = "kept"
+)
+
+// generatedValue replaces a preprocessor-minted value in import output so
+// the secret never reaches the conversation.
+const generatedValue = "[generated]"
+
+var dotenvKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// DotenvEntry is one KEY=value assignment of a dotenv file. Literal is set
+// for single-quoted values, which are taken verbatim: no escapes and no
+// preprocessor expansion.
+type DotenvEntry struct {
+	Key     string
+	Value   string
+	Line    int
+	Literal bool
+}
+
+// ParseDotenv parses dotenv syntax: blank lines and # comments, an
+// optional `export ` prefix, unquoted values with trailing ` # comments`,
+// and single- or double-quoted values that may span lines. Double quotes
+// understand \n, \r, \t, \" and \\. A key assigned twice is an error
+// rather than last-wins, so a stray duplicate cannot silently replace a
+// secret.
+func ParseDotenv(content string) ([]DotenvEntry, error) {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	var entries []DotenvEntry
+	firstLine := make(map[string]int)
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		key, rest, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !dotenvKeyPattern.MatchString(key) {
+			return nil, dotenvError(lineNo, "expected KEY=value")
+		}
+		if first, dup := firstLine[key]; dup {
+			return nil, dotenvError(lineNo, fmt.Sprintf("duplicate key %s (first set on line %d)", key, first))
+		}
+		firstLine[key] = lineNo
+
+		entry := DotenvEntry{Key: key, Line: lineNo}
+		rest = strings.TrimSpace(rest)
+		if rest == "" || (rest[0] != '"' && rest[0] != '\'') {
+			if idx := strings.Index(rest, " #"); idx >= 0 {
+				rest = rest[:idx]
+			}
+			entry.Value = strings.TrimSpace(rest)
+			entries = append(entries, entry)
+			continue
+		}
+
+		quote := rest[0]
+		entry.Literal = quote == '\''
+		// Quoted values may span lines: join the remaining input and find
+		// the closing quote, then resume after the line it sits on.
+		remaining := strings.Join(append([]string{rest[1:]}, lines[i+1:]...), "\n")
+		value, consumed, closed := scanQuoted(remaining, quote)
+		if !closed {
+			return nil, dotenvError(lineNo, fmt.Sprintf("unterminated %c-quoted value for %s", quote, key))
+		}
+		tail := remaining[consumed:]
+		newlines := strings.Count(remaining[:consumed], "\n")
+		if nl := strings.IndexByte(tail, '\n'); nl >= 0 {
+			tail = tail[:nl]
+		}
+		if tail = strings.TrimSpace(tail); tail != "" && !strings.HasPrefix(tail, "#") {
+			return nil, dotenvError(lineNo+newlines, fmt.Sprintf("unexpected %q after quoted value of %s", tail, key))
+		}
+		i += newlines
+		entry.Value = value
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// scanQuoted reads s up to the closing quote. consumed includes the quote.
+func scanQuoted(s string, quote byte) (value string, consumed int, closed bool) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote:
+			return b.String(), i + 1, true
+		case c == '\\' && quote == '"' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\':
+				b.WriteByte(s[i])
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", len(s), false
+}
+
+func dotenvError(line int, msg string) error {
+	return platform.NewPlatformError(platform.ErrInvalidEnvFormat,
+		fmt.Sprintf("dotenv line %d: %s", line, msg),
+		"Use KEY=value, KEY=\"quoted value\" or KEY='literal value'; comments start with #")
+}
+
+// EnvImportEntry is one key of an import. Values of sensitive keys are
+// masked and generated values are never shown.
+type EnvImportEntry struct {
+	Key       string `json:"key"`
+	Status    string `json:"status"`
+	Value     string `json:"value,omitempty"`
+	Current   string `json:"current,omitempty"`
+	Generated bool   `json:"generated,omitempty"`
+	Sensitive bool   `json:"sensitive,omitempty"`
+}
+
+// EnvImportResult is the preview or outcome of a dotenv import.
+type EnvImportResult struct {
+	File    string            `json:"file"`
+	Target  string            `json:"target"`
+	Summary string            `json:"summary"`
+	DryRun  bool              `json:"dryRun,omitempty"`
+	Entries []EnvImportEntry  `json:"entries"`
+	Process *platform.Process `json:"process,omitempty"`
+}
+
+// EnvImport loads a dotenv file into a service's env vars (or the
+// project's, when isProject). Values containing <@...> are generate
+// directives: they are expanded locally through the preprocessor, so a
+// secret is minted without ever passing through the conversation, and a
+// generated key that is already set is kept rather than rotated. The
+// base64:<@...> antipattern is refused exactly as in EnvSet. Keys whose
+// value already matches are not written. With dryRun the diff is returned
+// and nothing is written.
+func EnvImport(
+	ctx context.Context,
+	client platform.Client,
+	projectID string,
+	hostname string,
+	isProject bool,
+	filePath string,
+	dryRun bool,
+) (*EnvImportResult, error) {
+	if hostname == "" && !isProject {
+		return nil, platform.NewPlatformError(platform.ErrInvalidUsage,
+			"Provide serviceHostname or set project=true", "")
+	}
+	if filePath == "" {
+		return nil, platform.NewPlatformError(platform.ErrInvalidUsage,
+			"import requires filePath", "Pass filePath=.env (a dotenv file on the machine running ZCP)")
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, platform.NewPlatformError(platform.ErrFileNotFound,
+				fmt.Sprintf("file not found: %s", filePath), "Check the file path")
+		}
+		return nil, platform.NewPlatformError(platform.ErrFileNotFound,
+			fmt.Sprintf("read file: %v", err), "Check file permissions")
+	}
+	entries, err := ParseDotenv(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, platform.NewPlatformError(platform.ErrInvalidEnvFormat,
+			fmt.Sprintf("%s contains no variables", filePath), "")
+	}
+
+	result := &EnvImportResult{File: filePath, Target: hostname, DryRun: dryRun, Entries: make([]EnvImportEntry, 0, len(entries))}
+	var current map[string]string
+	var serviceID string
+	if isProject {
+		result.Target = "project"
+		vars, err := client.GetProjectEnv(ctx, projectID)
+		if err != nil {
+			return nil, err
+		}
+		current = make(map[string]string, len(vars))
+		for _, v := range vars {
+			current[v.Key] = v.Content
+		}
+	} else {
+		svc, err := resolveService(ctx, client, projectID, hostname)
+		if err != nil {
+			return nil, err
+		}
+		serviceID = svc.ID
+		if current, err = serviceEnvMap(ctx, client, svc.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Only generate directives go through the preprocessor; everything
+	// else is stored exactly as written, even if it happens to contain
+	// preprocessor-looking text inside single quotes.
+	var gen []envPair
+	var genRaw []string
+	for _, e := range entries {
+		if !e.Literal && strings.Contains(e.Value, "<@") {
+			gen = append(gen, envPair{Key: e.Key, Value: e.Value})
+			genRaw = append(genRaw, e.Value)
+		}
+	}
+	if len(gen) > 0 {
+		if err := expandPairs(ctx, gen); err != nil {
+			return nil, err
+		}
+		if err := rejectEncodingPrefixedSecrets(gen, genRaw); err != nil {
+			return nil, err
+		}
+	}
+	generated := make(map[string]string, len(gen))
+	for _, p := range gen {
+		generated[p.Key] = p.Value
+	}
+
+	var write []envPair
+	counts := make(map[string]int)
+	for _, e := range entries {
+		cur, exists := current[e.Key]
+		isGenerate := !e.Literal && strings.Contains(e.Value, "<@")
+		entry := EnvImportEntry{Key: e.Key, Value: e.Value, Current: cur, Generated: isGenerate, Sensitive: isSensitiveEnvKey(e.Key)}
+		value := e.Value
+		switch {
+		case isGenerate && exists:
+			entry.Status = EnvImportKept
+		case isGenerate:
+			value = generated[e.Key]
+			entry.Status = EnvImportAdded
+		case !exists:
+			entry.Status = EnvImportAdded
+		case cur != e.Value:
+			entry.Status = EnvImportChanged
+		default:
+			entry.Status = EnvImportUnchanged
+		}
+		if entry.Status == EnvImportAdded || entry.Status == EnvImportChanged {
+			write = append(write, envPair{Key: e.Key, Value: value})
+		}
+		switch {
+		case isGenerate:
+			entry.Value, entry.Current = generatedValue, maskIfSet(cur)
+		case entry.Sensitive:
+			entry.Value, entry.Current = maskIfSet(e.Value), maskIfSet(cur)
+		}
+		counts[entry.Status]++
+		result.Entries = append(result.Entries, entry)
+	}
+	result.Summary = fmt.Sprintf("%d added, %d changed, %d unchanged, %d kept",
+		counts[EnvImportAdded], counts[EnvImportChanged], counts[EnvImportUnchanged], counts[EnvImportKept])
+
+	if dryRun || len(write) == 0 {
+		return result, nil
+	}
+	if isProject {
+		setResult, err := setProjectEnvs(ctx, client, projectID, write)
+		if err != nil {
+			return nil, err
+		}
+		result.Process = setResult.Process
+		return result, nil
+	}
+	proc, err := client.SetServiceEnvFile(ctx, serviceID, buildQuotedEnvFileContent(write))
+	if err != nil {
+		return nil, err
+	}
+	result.Process = proc
+	return result, nil
+}
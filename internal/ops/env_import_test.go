@@ -0,0 +1,141 @@
+// Tests for: ops/env_import.go — dotenv parsing and import.
+package ops
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+func TestParseDotenv(t *testing.T) {
+	t.Parallel()
+
+	entries, err := ParseDotenv(`# app settings
+export APP_NAME=demo   # trailing comment
+EMPTY=
+URL=https://example.com/#anchor
+GREETING="hello \"you\"\nbye"  # comment
+RAW='no \n escapes <@x>'
+PEM="-----BEGIN KEY-----
+abc
+-----END KEY-----"
+AFTER = spaced
+`)
+	if err != nil {
+		t.Fatalf("ParseDotenv: %v", err)
+	}
+	want := []DotenvEntry{
+		{Key: "APP_NAME", Value: "demo", Line: 2},
+		{Key: "EMPTY", Value: "", Line: 3},
+		{Key: "URL", Value: "https://example.com/#anchor", Line: 4},
+		{Key: "GREETING", Value: "hello \"you\"\nbye", Line: 5},
+		{Key: "RAW", Value: `no \n escapes <@x>`, Line: 6, Literal: true},
+		{Key: "PEM", Value: "-----BEGIN KEY-----\nabc\n-----END KEY-----", Line: 7},
+		{Key: "AFTER", Value: "spaced", Line: 10},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("entries = %+v", entries)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+
+	for content, wantErr := range map[string]string{
+		"A=1\nB\n":           "line 2: expected KEY=value",
+		"A=1\nA=2\n":         "line 2: duplicate key A (first set on line 1)",
+		"A=\"open\nB=1\n":    "line 1: unterminated \"-quoted value",
+		"A='x' trailing\n":   `line 1: unexpected "trailing"`,
+		"1BAD=x\n":           "expected KEY=value",
+		"A=\"x\"\nB=\"y\" z": `line 2: unexpected "z"`,
+	} {
+		if _, err := ParseDotenv(content); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("%q: err = %v, want %q", content, err, wantErr)
+		}
+	}
+}
+
+func TestEnvImport(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(`LOG_LEVEL=debug
+SAME=1
+NEW_FLAG=on
+SESSION_SECRET=<@generateRandomString(<32>)>
+APP_KEY=<@generateRandomString(<32>)>
+CERT="line1
+line2"
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	newMock := func() *envFileMock {
+		return &envFileMock{files: map[string][]string{}, Mock: platform.NewMock().
+			WithServices([]platform.ServiceStack{{ID: "svc-api", Name: "api", Status: "ACTIVE"}}).
+			WithServiceEnv("svc-api", []platform.EnvVar{
+				{Key: "LOG_LEVEL", Content: "info"},
+				{Key: "SAME", Content: "1"},
+				{Key: "APP_KEY", Content: "already-minted"},
+			})}
+	}
+
+	mock := newMock()
+	preview, err := EnvImport(context.Background(), mock, "proj-1", "api", false, path, true)
+	if err != nil {
+		t.Fatalf("EnvImport dry run: %v", err)
+	}
+	if preview.Summary != "3 added, 1 changed, 1 unchanged, 1 kept" || len(mock.files) != 0 {
+		t.Errorf("preview = %+v, writes = %v", preview, mock.files)
+	}
+	for _, e := range preview.Entries {
+		if e.Key == "SESSION_SECRET" && (e.Value != generatedValue || !e.Generated) {
+			t.Errorf("SESSION_SECRET entry = %+v; generated values must never be shown", e)
+		}
+		if e.Key == "APP_KEY" && (e.Status != EnvImportKept || e.Current != maskedValue) {
+			t.Errorf("APP_KEY entry = %+v", e)
+		}
+	}
+
+	result, err := EnvImport(context.Background(), mock, "proj-1", "api", false, path, false)
+	if err != nil || result.Process == nil {
+		t.Fatalf("EnvImport = %+v, %v", result, err)
+	}
+	files := mock.files["svc-api"]
+	if len(files) != 1 {
+		t.Fatalf("SetServiceEnvFile calls = %d, want one", len(files))
+	}
+	lines := strings.Split(files[0], "\n")
+	if lines[0] != "LOG_LEVEL=debug" || lines[1] != "NEW_FLAG=on" || !strings.HasPrefix(lines[2], "SESSION_SECRET=") ||
+		len(strings.TrimPrefix(lines[2], "SESSION_SECRET=")) != 32 || !strings.Contains(files[0], "CERT=\"line1\nline2\"\n") {
+		t.Errorf("env file = %q", files[0])
+	}
+	if strings.Contains(files[0], "APP_KEY") || strings.Contains(files[0], "SAME") {
+		t.Errorf("kept and unchanged keys must not be written: %q", files[0])
+	}
+}
+
+func TestEnvImport_RejectsEncodingPrefix(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("APP_KEY=base64:<@generateRandomString(<32>)>\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	mock := platform.NewMock().WithServices([]platform.ServiceStack{{ID: "svc-api", Name: "api", Status: "ACTIVE"}})
+	_, err := EnvImport(context.Background(), mock, "proj-1", "api", false, path, true)
+	var pe *platform.PlatformError
+	if !errors.As(err, &pe) || !strings.Contains(pe.Message, `starts with "base64"`) {
+		t.Errorf("err = %v, want base64 prefix rejection", err)
+	}
+
+	_, err = EnvImport(context.Background(), mock, "proj-1", "api", false, filepath.Join(t.TempDir(), "missing"), true)
+	if !errors.As(err, &pe) || pe.Code != platform.ErrFileNotFound {
+		t.Errorf("missing file err = %v", err)
+	}
+}
@@ -0,0 +1,430 @@
+package ops
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// RotationHistoryFile is the append-only rotation log under the state dir.
+const RotationHistoryFile = "rotations.jsonl"
+
+// RotationKeyFile holds the per-project random key the rotation history
+// fingerprints values with. It stays next to the history and is never
+// written to the platform.
+const RotationKeyFile = "rotation.key"
+
+// rotationKeySize is the length of the HMAC key in bytes.
+const rotationKeySize = 32
+
+// DefaultRotateGenerator mints the replacement value when none is given.
+const DefaultRotateGenerator = "<@generateRandomString(<32>)>"
+
+// Rotation step outcomes.
+const (
+	RotateStepRestarted = "restarted"
+	RotateStepSkipped   = "skipped"
+	RotateStepFailed    = "failed"
+	RotateStepPending   = "pending"
+)
+
+// EnvRotateOptions selects the key to rotate. A project key (Project) is
+// consumed by every runtime that inherits it and every service that
+// references ${KEY}; a service key by the owner and every service that
+// references ${host_KEY}.
+type EnvRotateOptions struct {
+	Key       string
+	Hostname  string
+	Project   bool
+	Generator string
+	// DeclaredEnv is zerops.yaml run.envVariables per runtime hostname,
+	// which the API does not expose.
+	DeclaredEnv map[string]map[string]string
+	// SelfHostname is the service running ZCP; restarting it would drop
+	// the MCP connection, so it is skipped with a note.
+	SelfHostname string
+	DryRun       bool
+}
+
+// RotateStep is one consumer's restart and verify outcome, in order.
+type RotateStep struct {
+	Hostname string `json:"serviceHostname"`
+	Reason   string `json:"reason"`
+	Status   string `json:"status"`
+	Verify   string `json:"verify,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+
+	serviceID string
+}
+
+// EnvRotateResult is the outcome (or, with DryRun, the plan) of a rotation.
+// The new value is never returned.
+type EnvRotateResult struct {
+	Key      string            `json:"key"`
+	Scope    string            `json:"scope"`
+	Summary  string            `json:"summary"`
+	DryRun   bool              `json:"dryRun,omitempty"`
+	Steps    []RotateStep      `json:"steps"`
+	Halted   string            `json:"halted,omitempty"`
+	Process  *platform.Process `json:"process,omitempty"`
+	History  string            `json:"history,omitempty"`
+	Warnings []string          `json:"warnings,omitempty"`
+}
+
+// RotationRecord is one line of the rotation history. Values are recorded
+// as HMAC-SHA256 fingerprints keyed with RotationKeyFile, so the log can
+// prove which value was replaced without holding either, and a leaked log
+// cannot be brute-forced without the key.
+type RotationRecord struct {
+	Time      string   `json:"time"`
+	Key       string   `json:"key"`
+	Scope     string   `json:"scope"`
+	OldHash   string   `json:"oldHash"`
+	NewHash   string   `json:"newHash"`
+	Restarted []string `json:"restarted"`
+	Halted    string   `json:"halted,omitempty"`
+}
+
+// EnvRotate replaces a secret with a freshly generated value and rolls it
+// out: the value is written once, then each consumer is restarted and
+// verified in order — owner first, then the rest by hostname. An
+// unhealthy verify or a failed restart halts the rollout; the remaining
+// consumers stay pending and keep the old value until they restart. Each
+// completed or halted rotation is appended to RotationHistoryFile in
+// stateDir when one is given.
+func EnvRotate(
+	ctx context.Context,
+	client platform.Client,
+	projectID string,
+	stateDir string,
+	opts EnvRotateOptions,
+	poll func(context.Context, *platform.Process) *platform.Process,
+	verify func(ctx context.Context, hostname string) (*VerifyResult, error),
+) (*EnvRotateResult, error) {
+	if opts.Key == "" {
+		return nil, platform.NewPlatformError(platform.ErrInvalidParameter,
+			"rotate requires key", "Pass key=<NAME> of an existing env var, e.g. key=APP_KEY project=true")
+	}
+	if opts.Hostname == "" && !opts.Project {
+		return nil, platform.NewPlatformError(platform.ErrInvalidUsage,
+			"Provide serviceHostname or set project=true", "")
+	}
+	generator := opts.Generator
+	if generator == "" {
+		generator = DefaultRotateGenerator
+	}
+	if !strings.Contains(generator, "<@") {
+		return nil, platform.NewPlatformError(platform.ErrInvalidParameter,
+			"rotate generates the new value; generator must be a <@...> preprocessor expression",
+			"Omit generator for "+DefaultRotateGenerator+", or pass e.g. <@generateRandomString(<64>)>. To set a known value use action=set.")
+	}
+
+	services, err := ListProjectServices(ctx, client, projectID)
+	if err != nil {
+		return nil, err
+	}
+	result := &EnvRotateResult{Key: opts.Key, Scope: "project", DryRun: opts.DryRun}
+	var oldValue, ownerID string
+	if opts.Project {
+		vars, err := client.GetProjectEnv(ctx, projectID)
+		if err != nil {
+			return nil, err
+		}
+		i := slices.IndexFunc(vars, func(v platform.EnvVar) bool { return v.Key == opts.Key })
+		if i < 0 {
+			return nil, rotateKeyMissing(opts.Key, "project")
+		}
+		oldValue = vars[i].Content
+	} else {
+		owner, err := FindService(services, opts.Hostname)
+		if err != nil {
+			return nil, err
+		}
+		if isManagedCategory(owner.ServiceStackTypeInfo.ServiceStackTypeCategoryName) {
+			return nil, platform.NewPlatformError(platform.ErrInvalidParameter,
+				fmt.Sprintf("%s is a managed service; its credentials are owned by the service, not by its env vars", owner.Name),
+				"Rotate the credential in the service itself; rotate applies to secrets your runtimes define")
+		}
+		env, err := serviceEnvMap(ctx, client, owner.ID)
+		if err != nil {
+			return nil, err
+		}
+		value, ok := env[opts.Key]
+		if !ok {
+			return nil, rotateKeyMissing(opts.Key, owner.Name)
+		}
+		oldValue, ownerID, result.Scope = value, owner.ID, owner.Name
+	}
+
+	result.Steps, err = rotateConsumers(ctx, client, services, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	pair := []envPair{{Key: opts.Key, Value: generator}}
+	if err := expandPairs(ctx, pair); err != nil {
+		return nil, err
+	}
+	if err := rejectEncodingPrefixedSecrets(pair, []string{generator}); err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		result.Summary = fmt.Sprintf("would rotate %s and restart %d of %d consumer(s)", opts.Key, countSteps(result.Steps, RotateStepPending), len(result.Steps))
+		return result, nil
+	}
+
+	var proc *platform.Process
+	if opts.Project {
+		set, err := setProjectEnvs(ctx, client, projectID, pair)
+		if err != nil {
+			return nil, err
+		}
+		proc = set.Process
+	} else if proc, err = client.SetServiceEnvFile(ctx, ownerID, buildEnvFileContent(pair)); err != nil {
+		return nil, err
+	}
+	if proc != nil {
+		proc = poll(ctx, proc)
+		result.Process = proc
+		if proc.Status != statusFinished {
+			return nil, platform.NewPlatformError(platform.ErrAPIError,
+				fmt.Sprintf("writing the new %s value ended %s", opts.Key, proc.Status),
+				"Check zerops_process; no service was restarted")
+		}
+	}
+
+	rolloutRotation(ctx, client, result, poll, verify)
+
+	if stateDir != "" {
+		path, err := recordRotation(stateDir, opts.Key, result, oldValue, pair[0].Value)
+		if err != nil {
+			result.Warnings = append(result.Warnings, "rotation applied but history not written: "+err.Error())
+		} else {
+			result.History = path
+		}
+	}
+	return result, nil
+}
+
+func rotateKeyMissing(key, scope string) error {
+	return platform.NewPlatformError(platform.ErrInvalidParameter,
+		fmt.Sprintf("%s has no env var %s", scope, key),
+		"rotate replaces an existing secret; create it first with action=set or action=import")
+}
+
+// rotateConsumers lists the services that must restart to pick up the
+// new value, owner first and the rest by hostname. Managed services never
+// consume user secrets; stopped services pick the value up on start.
+func rotateConsumers(ctx context.Context, client platform.Client, services []platform.ServiceStack, opts EnvRotateOptions) ([]RotateStep, error) {
+	ref := opts.Key
+	if !opts.Project {
+		ref = opts.Hostname + "_" + opts.Key
+	}
+	var owner, rest []RotateStep
+	for i := range services {
+		svc := &services[i]
+		if svc.IsSystem() || isManagedCategory(svc.ServiceStackTypeInfo.ServiceStackTypeCategoryName) {
+			continue
+		}
+		env, err := serviceEnvMap(ctx, client, svc.ID)
+		if err != nil {
+			return nil, err
+		}
+		step := RotateStep{Hostname: svc.Name, serviceID: svc.ID}
+		_, shadows := env[opts.Key]
+		switch {
+		case !opts.Project && svc.Name == opts.Hostname:
+			step.Reason = "owns " + opts.Key
+		case envReferences(env, ref):
+			step.Reason = "env references ${" + ref + "}"
+		case envReferences(opts.DeclaredEnv[svc.Name], ref):
+			step.Reason = "zerops.yaml references ${" + ref + "}"
+		case opts.Project && !shadows:
+			step.Reason = "inherits project env " + opts.Key
+		default:
+			continue
+		}
+		switch {
+		case svc.Name == opts.SelfHostname:
+			step.Status, step.Detail = RotateStepSkipped, "runs ZCP; restart it manually to pick up the new value"
+		case checkServiceRunning(svc).Status != CheckPass:
+			step.Status, step.Detail = RotateStepSkipped, fmt.Sprintf("%s; picks up the new value when started", svc.Status)
+		default:
+			step.Status = RotateStepPending
+		}
+		if !opts.Project && svc.Name == opts.Hostname {
+			owner = append(owner, step)
+		} else {
+			rest = append(rest, step)
+		}
+	}
+	slices.SortFunc(rest, func(a, b RotateStep) int { return strings.Compare(a.Hostname, b.Hostname) })
+	return append(owner, rest...), nil
+}
+
+func envReferences(env map[string]string, ref string) bool {
+	for _, v := range env {
+		if slices.Contains(scanEnvRefs(v), ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// rolloutRotation restarts and verifies pending steps one at a time,
+// stopping at the first failure.
+func rolloutRotation(
+	ctx context.Context,
+	client platform.Client,
+	result *EnvRotateResult,
+	poll func(context.Context, *platform.Process) *platform.Process,
+	verify func(ctx context.Context, hostname string) (*VerifyResult, error),
+) {
+	for i := range result.Steps {
+		step := &result.Steps[i]
+		if step.Status != RotateStepPending || result.Halted != "" {
+			continue
+		}
+		proc, err := client.RestartService(ctx, step.serviceID)
+		if err == nil && proc != nil {
+			if proc = poll(ctx, proc); proc.Status != statusFinished {
+				err = fmt.Errorf("restart ended %s", proc.Status)
+			}
+		}
+		if err != nil {
+			step.Status, step.Detail = RotateStepFailed, err.Error()
+			result.Halted = step.Hostname
+			continue
+		}
+		step.Status = RotateStepRestarted
+		vr, err := verify(ctx, step.Hostname)
+		switch {
+		case err != nil:
+			step.Verify, step.Detail = StatusUnhealthy, "verify failed: "+err.Error()
+		case vr != nil:
+			step.Verify = vr.Status
+		}
+		if step.Verify == StatusUnhealthy {
+			result.Halted = step.Hostname
+		}
+	}
+
+	restarted := countSteps(result.Steps, RotateStepRestarted)
+	result.Summary = fmt.Sprintf("rotated %s; %d of %d consumer(s) restarted", result.Key, restarted, len(result.Steps))
+	if result.Halted != "" {
+		result.Summary += fmt.Sprintf(", halted at %s with %d pending — they still run the old value until restarted",
+			result.Halted, countSteps(result.Steps, RotateStepPending))
+	}
+}
+
+func countSteps(steps []RotateStep, status string) int {
+	n := 0
+	for _, s := range steps {
+		if s.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// recordRotation appends the outcome of a rotation to the history in
+// stateDir and returns the history path.
+func recordRotation(stateDir, key string, result *EnvRotateResult, oldValue, newValue string) (string, error) {
+	hmacKey, err := rotationKey(stateDir)
+	if err != nil {
+		return "", err
+	}
+	record := RotationRecord{
+		Time:    time.Now().UTC().Format(time.RFC3339),
+		Key:     key,
+		Scope:   result.Scope,
+		OldHash: fingerprintSecret(hmacKey, oldValue),
+		NewHash: fingerprintSecret(hmacKey, newValue),
+		Halted:  result.Halted,
+	}
+	for _, s := range result.Steps {
+		if s.Status == RotateStepRestarted {
+			record.Restarted = append(record.Restarted, s.Hostname)
+		}
+	}
+	path := filepath.Join(stateDir, RotationHistoryFile)
+	return path, AppendRotationRecord(path, record)
+}
+
+// rotationKey reads the project's history key from stateDir, creating it
+// on first use. An unreadable key is an error rather than replaced, since
+// a new key would make every earlier fingerprint incomparable.
+func rotationKey(stateDir string) ([]byte, error) {
+	path := filepath.Join(stateDir, RotationKeyFile)
+	data, err := os.ReadFile(path)
+	if err == nil {
+		key, decodeErr := hex.DecodeString(strings.TrimSpace(string(data)))
+		if decodeErr != nil || len(key) != rotationKeySize {
+			return nil, fmt.Errorf("rotation key %s is malformed; move it aside to start a new history", path)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("rotation key read: %w", err)
+	}
+
+	key := make([]byte, rotationKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("rotation key generate: %w", err)
+	}
+	if err := os.MkdirAll(stateDir, 0o755); err != nil {
+		return nil, fmt.Errorf("rotation key dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		// A concurrent rotation created it first; use theirs.
+		return rotationKey(stateDir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rotation key create: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(hex.EncodeToString(key) + "\n"); err != nil {
+		return nil, fmt.Errorf("rotation key write: %w", err)
+	}
+	return key, nil
+}
+
+// fingerprintSecret is the HMAC-SHA256 of v under the project's rotation key.
+func fingerprintSecret(key []byte, v string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(v))
+	return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// AppendRotationRecord appends rec as one JSON line to path.
+func AppendRotationRecord(path string, rec RotationRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("rotation record marshal: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("rotation record dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("rotation record open: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("rotation record write: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+// Tests for: ops/env_rotate.go — secret rotation with ordered restarts.
+package ops
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+func rotateMock() *platform.Mock {
+	runtime := platform.ServiceTypeInfo{ServiceStackTypeVersionName: "nodejs@22", ServiceStackTypeCategoryName: "USER"}
+	return platform.NewMock().
+		WithServices([]platform.ServiceStack{
+			{ID: "svc-worker", Name: "worker", Status: "ACTIVE", ServiceStackTypeInfo: runtime},
+			{ID: "svc-api", Name: "api", Status: "ACTIVE", ServiceStackTypeInfo: runtime},
+			{ID: "svc-web", Name: "web", Status: "ACTIVE", ServiceStackTypeInfo: runtime},
+			{ID: "svc-old", Name: "old", Status: "STOPPED", ServiceStackTypeInfo: runtime},
+			{ID: "svc-db", Name: "db", Status: "ACTIVE",
+				ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "postgresql@16", ServiceStackTypeCategoryName: "STANDARD"}},
+		}).
+		WithProjectEnv([]platform.EnvVar{{ID: "env-1", Key: "APP_KEY", Content: "old-secret"}}).
+		WithServiceEnv("svc-api", []platform.EnvVar{{Key: "JWT", Content: "x", ID: "ud-1"}}).
+		WithServiceEnv("svc-web", []platform.EnvVar{{Key: "APP_KEY", Content: "own"}}).
+		WithServiceEnv("svc-worker", []platform.EnvVar{{Key: "SIGNING", Content: "${api_JWT}"}})
+}
+
+func finishedPoll(_ context.Context, p *platform.Process) *platform.Process {
+	return &platform.Process{ID: p.ID, Status: statusFinished}
+}
+
+func TestEnvRotate_Project(t *testing.T) {
+	t.Parallel()
+
+	stateDir := t.TempDir()
+	var verified []string
+	verify := func(_ context.Context, host string) (*VerifyResult, error) {
+		verified = append(verified, host)
+		if host == "api" {
+			return &VerifyResult{Hostname: host, Status: StatusUnhealthy}, nil
+		}
+		return &VerifyResult{Hostname: host, Status: StatusHealthy}, nil
+	}
+	result, err := EnvRotate(context.Background(), rotateMock(), "proj-1", stateDir,
+		EnvRotateOptions{Key: "APP_KEY", Project: true}, finishedPoll, verify)
+	if err != nil {
+		t.Fatalf("EnvRotate: %v", err)
+	}
+
+	var got []string
+	for _, s := range result.Steps {
+		got = append(got, s.Hostname+":"+s.Status)
+	}
+	// web shadows APP_KEY with its own value and db is managed, so neither
+	// consumes the project key; the rollout halts at the unhealthy api.
+	if strings.Join(got, ",") != "api:restarted,old:skipped,worker:pending" || result.Halted != "api" {
+		t.Errorf("steps = %v, halted = %q", got, result.Halted)
+	}
+	if strings.Join(verified, ",") != "api" {
+		t.Errorf("verified = %v; nothing after a failed verify may run", verified)
+	}
+	if !strings.Contains(result.Summary, "halted at api with 1 pending") {
+		t.Errorf("summary = %s", result.Summary)
+	}
+
+	data, err := os.ReadFile(filepath.Join(stateDir, RotationHistoryFile))
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	var rec RotationRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		t.Fatalf("history line: %v", err)
+	}
+	key, err := rotationKey(stateDir)
+	if err != nil {
+		t.Fatalf("rotation key: %v", err)
+	}
+	if rec.Key != "APP_KEY" || rec.OldHash != fingerprintSecret(key, "old-secret") || rec.Halted != "api" || strings.Contains(string(data), "old-secret") {
+		t.Errorf("history = %s", data)
+	}
+	if info, err := os.Stat(filepath.Join(stateDir, RotationKeyFile)); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("rotation key file = %v, %v; want mode 0600", info, err)
+	}
+}
+
+func TestRotationKey_FingerprintsArePerProject(t *testing.T) {
+	t.Parallel()
+
+	dirA, dirB := t.TempDir(), t.TempDir()
+	keyA, err := rotationKey(dirA)
+	if err != nil {
+		t.Fatalf("rotationKey: %v", err)
+	}
+	again, err := rotationKey(dirA)
+	if err != nil || fingerprintSecret(again, "s") != fingerprintSecret(keyA, "s") {
+		t.Errorf("second read = %x, %v; the key must persist", again, err)
+	}
+	keyB, err := rotationKey(dirB)
+	if err != nil {
+		t.Fatalf("rotationKey: %v", err)
+	}
+	if fingerprintSecret(keyA, "s") == fingerprintSecret(keyB, "s") {
+		t.Error("two projects fingerprint the same value identically")
+	}
+
+	if err := os.WriteFile(filepath.Join(dirB, RotationKeyFile), []byte("short\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rotationKey(dirB); err == nil || !strings.Contains(err.Error(), "malformed") {
+		t.Errorf("malformed key err = %v", err)
+	}
+}
+
+func TestEnvRotate_ServiceKeyDryRun(t *testing.T) {
+	t.Parallel()
+
+	result, err := EnvRotate(context.Background(), rotateMock(), "proj-1", "",
+		EnvRotateOptions{Key: "JWT", Hostname: "api", DryRun: true}, finishedPoll, nil)
+	if err != nil {
+		t.Fatalf("EnvRotate: %v", err)
+	}
+	if len(result.Steps) != 2 || result.Steps[0].Reason != "owns JWT" || result.Steps[1].Reason != "env references ${api_JWT}" {
+		t.Errorf("steps = %+v", result.Steps)
+	}
+	if result.Summary != "would rotate JWT and restart 2 of 2 consumer(s)" || result.Process != nil {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestEnvRotate_Errors(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		opts EnvRotateOptions
+		want string
+	}{
+		"missing key":     {EnvRotateOptions{Key: "NOPE", Project: true}, "project has no env var NOPE"},
+		"literal value":   {EnvRotateOptions{Key: "APP_KEY", Project: true, Generator: "hunter2"}, "must be a <@...> preprocessor expression"},
+		"encoding prefix": {EnvRotateOptions{Key: "APP_KEY", Project: true, Generator: "base64:<@generateRandomString(<32>)>"}, `starts with "base64"`},
+		"managed owner":   {EnvRotateOptions{Key: "password", Hostname: "db"}, "db is a managed service"},
+	} {
+		_, err := EnvRotate(context.Background(), rotateMock(), "proj-1", "", tc.opts, finishedPoll, nil)
+		var pe *platform.PlatformError
+		if !errors.As(err, &pe) || !strings.Contains(pe.Message, tc.want) {
+			t.Errorf("%s: err = %v, want %q", name, err, tc.want)
+		}
+	}
+}
@@ -324,3 +324,37 @@ func TestEnvDelete_Project(t *testing.T) {
 		t.Fatal("expected non-nil process")
 	}
 }
+
+// TestBuildQuotedEnvFileContent_RoundTrip: multiline values are written
+// in the double-quoted syntax ParseDotenv reads, so imported and promoted
+// values reach the platform unchanged.
+func TestBuildQuotedEnvFileContent_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	pairs := []envPair{
+		{Key: "PLAIN", Value: "demo"},
+		{Key: "PEM", Value: "-----BEGIN KEY-----\nabc\n-----END KEY-----"},
+		{Key: "TRICKY", Value: "say \"hi\"\nC:\\path\\n\nend"},
+	}
+	content := buildQuotedEnvFileContent(pairs)
+	if !strings.HasPrefix(content, "PLAIN=demo\nPEM=\"-----BEGIN KEY-----\n") {
+		t.Errorf("content = %q", content)
+	}
+	entries, err := ParseDotenv(content)
+	if err != nil {
+		t.Fatalf("ParseDotenv: %v", err)
+	}
+	if len(entries) != len(pairs) {
+		t.Fatalf("entries = %+v", entries)
+	}
+	for i, p := range pairs {
+		if entries[i].Key != p.Key || entries[i].Value != p.Value {
+			t.Errorf("entry %d = %q=%q, want %q=%q", i, entries[i].Key, entries[i].Value, p.Key, p.Value)
+		}
+	}
+
+	// zerops_env set keeps writing values bare.
+	if got := buildEnvFileContent(pairs[1:2]); got != "PEM="+pairs[1].Value+"\n" {
+		t.Errorf("buildEnvFileContent = %q, want the value unquoted", got)
+	}
+}
@@ -77,29 +77,9 @@ func Import(
 		return nil, err
 	}
 
-	// Parse YAML into generic map for the two ZCP-specific preflights.
-	var doc map[string]any
-	if err := yaml.Unmarshal([]byte(yamlContent), &doc); err != nil {
-		return nil, platform.NewPlatformError(
-			platform.ErrInvalidImportYml,
-			fmt.Sprintf("invalid YAML: %v", err),
-			"Check YAML syntax",
-		)
-	}
-
-	// Check for project: key — K12 in the validation-plumbing plan. The
-	// platform's projectImportInvalidParameter for this case is generic;
-	// the specific code IMPORT_HAS_PROJECT is clearer. Recovery hint
-	// names the env-var-first path explicitly so agents who copied a
-	// recipe template (which DOES carry `project:` for the create-new-
-	// project flow) recover one-shot instead of asking what to do with
-	// the project-level envVariables they just stripped.
-	if _, ok := doc["project"]; ok {
-		return nil, platform.NewPlatformError(
-			platform.ErrImportHasProject,
-			"import YAML must not contain a 'project:' section — zerops_import operates within the existing project",
-			"Strip the 'project:' block, then resubmit. If it carried envVariables, set them FIRST via `zerops_env action=\"set\" scope=\"project\" key=\"<KEY>\" value=\"<value>\"` (preprocessor directives like `<@generateRandomString(<32>)>` are passed literally and evaluated server-side).",
-		)
+	doc, err := parseImportDoc(yamlContent)
+	if err != nil {
+		return nil, err
 	}
 
 	// When override is requested, set `override: true` on each service and
@@ -138,29 +118,7 @@ func Import(
 		yamlContent = string(remarshaled)
 	}
 
-	// Sole retained client-side warning — K1 in the plan: the API accepts
-	// service-level `envVariables:` then silently discards it, producing
-	// neither an error nor a meta entry. ZCP is the only place this can
-	// surface.
-	var warnings []string
-	if raw, ok := doc["services"]; ok {
-		if servicesList, ok := raw.([]any); ok {
-			for _, svc := range servicesList {
-				svcMap, ok := svc.(map[string]any)
-				if !ok {
-					continue
-				}
-				if _, has := svcMap["envVariables"]; !has {
-					continue
-				}
-				hostname, _ := svcMap["hostname"].(string)
-				warnings = append(warnings, fmt.Sprintf(
-					"service %q: 'envVariables' at service level is silently dropped by the API. Use 'envSecrets' for import-time secrets, or zerops.yaml run.envVariables for runtime config.",
-					hostname,
-				))
-			}
-		}
-	}
+	warnings := serviceEnvVariablesWarnings(doc)
 
 	// B10: surface the destructive blast radius of override=true. Replacing
 	// a service stack tears down its container, deployed code, env vars,
@@ -355,3 +313,68 @@ func resolveInput(content, filePath string) (string, error) {
 	}
 	return content, nil
 }
+
+// parseImportDoc parses import YAML into a generic map and runs the
+// ZCP-specific 'project:' preflight. Shared by Import and PlanImport so a
+// dry run refuses exactly what the real import refuses.
+func parseImportDoc(yamlContent string) (map[string]any, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal([]byte(yamlContent), &doc); err != nil {
+		return nil, platform.NewPlatformError(
+			platform.ErrInvalidImportYml,
+			fmt.Sprintf("invalid YAML: %v", err),
+			"Check YAML syntax",
+		)
+	}
+
+	// Check for project: key — K12 in the validation-plumbing plan. The
+	// platform's projectImportInvalidParameter for this case is generic;
+	// the specific code IMPORT_HAS_PROJECT is clearer. Recovery hint
+	// names the env-var-first path explicitly so agents who copied a
+	// recipe template (which DOES carry `project:` for the create-new-
+	// project flow) recover one-shot instead of asking what to do with
+	// the project-level envVariables they just stripped.
+	if _, ok := doc["project"]; ok {
+		return nil, platform.NewPlatformError(
+			platform.ErrImportHasProject,
+			"import YAML must not contain a 'project:' section — zerops_import operates within the existing project",
+			"Strip the 'project:' block, then resubmit. If it carried envVariables, set them FIRST via `zerops_env action=\"set\" scope=\"project\" key=\"<KEY>\" value=\"<value>\"` (preprocessor directives like `<@generateRandomString(<32>)>` are passed literally and evaluated server-side).",
+		)
+	}
+	return doc, nil
+}
+
+// serviceEnvVariablesWarnings is the sole retained client-side warning —
+// K1 in the plan: the API accepts service-level `envVariables:` then
+// silently discards it, producing neither an error nor a meta entry. ZCP
+// is the only place this can surface.
+func serviceEnvVariablesWarnings(doc map[string]any) []string {
+	var warnings []string
+	for _, svcMap := range importServices(doc) {
+		if _, has := svcMap["envVariables"]; !has {
+			continue
+		}
+		hostname, _ := svcMap["hostname"].(string)
+		warnings = append(warnings, fmt.Sprintf(
+			"service %q: 'envVariables' at service level is silently dropped by the API. Use 'envSecrets' for import-time secrets, or zerops.yaml run.envVariables for runtime config.",
+			hostname,
+		))
+	}
+	return warnings
+}
+
+// importServices returns the service entries of a parsed import document,
+// skipping anything that is not a mapping.
+func importServices(doc map[string]any) []map[string]any {
+	raw, ok := doc["services"].([]any)
+	if !ok {
+		return nil
+	}
+	out := make([]map[string]any, 0, len(raw))
+	for _, svc := range raw {
+		if svcMap, ok := svc.(map[string]any); ok {
+			out = append(out, svcMap)
+		}
+	}
+	return out
+}
@@ -0,0 +1,385 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/joho/godotenv"
+
+	"github.com/zeropsio/zcp/internal/platform"
+	"github.com/zeropsio/zcp/internal/preprocess"
+	"github.com/zeropsio/zcp/internal/schema"
+)
+
+// Planned actions per service in an ImportPlan.
+const (
+	PlanActionCreate  = "create"
+	PlanActionReplace = "replace" // hostname exists, override=true
+	PlanActionReject  = "reject"  // hostname exists, override=false — API refuses
+)
+
+// ImportPlan is the dry-run result of zerops_import: what the real import
+// would do, computed without submitting anything.
+type ImportPlan struct {
+	DryRun       bool               `json:"dryRun"`
+	Preprocessed bool               `json:"preprocessed"`
+	Services     []PlannedService   `json:"services"`
+	Collisions   []ServiceCollision `json:"collisions,omitempty"`
+	EnvShadows   []EnvShadow        `json:"envShadows,omitempty"`
+	SchemaErrors []string           `json:"schemaErrors,omitempty"`
+	Warnings     []string           `json:"warnings,omitempty"`
+	Summary      string             `json:"summary"`
+	NextActions  string             `json:"nextActions,omitempty"`
+}
+
+// PlannedService is one service entry of the import YAML as the platform
+// would receive it. EnvSecrets carry preprocessor-expanded values when
+// the YAML has the preprocessor header.
+type PlannedService struct {
+	Hostname   string            `json:"hostname"`
+	Type       string            `json:"type,omitempty"`
+	Mode       string            `json:"mode,omitempty"`
+	Action     string            `json:"action"`
+	Scaling    map[string]any    `json:"scaling,omitempty"`
+	EnvSecrets map[string]string `json:"envSecrets,omitempty"`
+}
+
+// ServiceCollision describes a declared hostname that already exists.
+// Differences compare the declaration against the live ServiceStack —
+// with override=true these are the changes the replacement would make.
+type ServiceCollision struct {
+	Hostname    string      `json:"hostname"`
+	ServiceID   string      `json:"serviceId"`
+	Status      string      `json:"status"`
+	Action      string      `json:"action"`
+	Differences []FieldDiff `json:"differences,omitempty"`
+}
+
+// FieldDiff is one declared-vs-live difference.
+type FieldDiff struct {
+	Field    string `json:"field"`
+	Live     any    `json:"live"`
+	Declared any    `json:"declared"`
+}
+
+// EnvShadow is a declared env secret whose key already exists at project
+// level (the service-level value will shadow it) or on the existing
+// service of the same hostname (its value will be replaced).
+type EnvShadow struct {
+	Service string `json:"service"`
+	Key     string `json:"key"`
+	Shadows string `json:"shadows"` // "project" | "service"
+}
+
+// PlanImport computes what Import would do with the same input, without
+// calling ImportServices. It runs the same parse and 'project:' preflight
+// as Import, validates against the embedded import schema
+// (schema.ValidateImportYAML — advisory; the API stays authoritative),
+// expands preprocessor expressions through preprocess.Batch exactly like
+// the platform would when the header is present, and compares every
+// declared hostname against live services and env vars.
+//
+// Generated values (generateRandomString etc.) are fresh per expansion,
+// so the plan shows their shape, not the exact bytes the real import
+// will produce.
+func PlanImport(
+	ctx context.Context,
+	client platform.Client,
+	projectID string,
+	content string,
+	filePath string,
+	override bool,
+) (*ImportPlan, error) {
+	yamlContent, err := resolveInput(content, filePath)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parseImportDoc(yamlContent)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ImportPlan{
+		DryRun:       true,
+		Preprocessed: hasPreprocessorHeader(yamlContent),
+		Warnings:     serviceEnvVariablesWarnings(doc),
+	}
+	for _, ve := range schema.ValidateImportYAML(yamlContent) {
+		plan.SchemaErrors = append(plan.SchemaErrors, ve.Error())
+	}
+
+	services, err := client.ListServices(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	live := make(map[string]*platform.ServiceStack, len(services))
+	for i := range services {
+		live[services[i].Name] = &services[i]
+	}
+
+	projectEnv, err := client.GetProjectEnv(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	projectKeys := envKeySet(projectEnv)
+
+	declared := importServices(doc)
+	secrets, err := declaredSecrets(ctx, declared, plan.Preprocessed)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(declared))
+	for i, svcMap := range declared {
+		hostname, _ := svcMap["hostname"].(string)
+		if seen[hostname] {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("hostname %q is declared more than once; the API rejects duplicate hostnames", hostname))
+		}
+		seen[hostname] = true
+
+		ps := PlannedService{
+			Hostname:   hostname,
+			Action:     PlanActionCreate,
+			EnvSecrets: secrets[i],
+			Scaling:    declaredScaling(svcMap),
+		}
+		ps.Type, _ = svcMap["type"].(string)
+		ps.Mode, _ = svcMap["mode"].(string)
+
+		for _, key := range sortedKeys(secrets[i]) {
+			if projectKeys[key] {
+				plan.EnvShadows = append(plan.EnvShadows, EnvShadow{Service: hostname, Key: key, Shadows: "project"})
+			}
+		}
+
+		if svc, exists := live[hostname]; exists {
+			ps.Action = PlanActionReject
+			if override {
+				ps.Action = PlanActionReplace
+			}
+			plan.Collisions = append(plan.Collisions, ServiceCollision{
+				Hostname:    hostname,
+				ServiceID:   svc.ID,
+				Status:      svc.Status,
+				Action:      ps.Action,
+				Differences: diffServiceDeclaration(svc, ps),
+			})
+			if len(secrets[i]) > 0 {
+				svcEnv, err := client.GetServiceEnv(ctx, svc.ID)
+				if err != nil {
+					return nil, err
+				}
+				svcKeys := envKeySet(svcEnv)
+				for _, key := range sortedKeys(secrets[i]) {
+					if svcKeys[key] {
+						plan.EnvShadows = append(plan.EnvShadows, EnvShadow{Service: hostname, Key: key, Shadows: "service"})
+					}
+				}
+			}
+		}
+		plan.Services = append(plan.Services, ps)
+	}
+
+	plan.Summary, plan.NextActions = summarizeImportPlan(plan, override)
+	return plan, nil
+}
+
+func hasPreprocessorHeader(yamlContent string) bool {
+	first, _, _ := strings.Cut(yamlContent, "\n")
+	return strings.TrimSpace(first) == strings.TrimSpace(preprocessorHeader)
+}
+
+// declaredSecrets collects envSecrets and dotEnvSecrets per service (in
+// document order) and, when the preprocessor is on, expands all values in
+// one preprocess.Batch call so setVar/getVar correlate across services
+// the same way they do in the platform's single-pass preprocessing.
+func declaredSecrets(ctx context.Context, declared []map[string]any, expand bool) ([]map[string]string, error) {
+	type ref struct {
+		svc int
+		key string
+	}
+	out := make([]map[string]string, len(declared))
+	var (
+		order []string
+		refs  = map[string]ref{}
+	)
+	inputs := map[string]string{}
+	for i, svcMap := range declared {
+		hostname, _ := svcMap["hostname"].(string)
+		vals := map[string]string{}
+		if dotEnv, ok := svcMap["dotEnvSecrets"].(string); ok && dotEnv != "" {
+			parsed, err := godotenv.Unmarshal(dotEnv)
+			if err != nil {
+				return nil, platform.NewPlatformError(platform.ErrInvalidImportYml,
+					fmt.Sprintf("service %q: dotEnvSecrets is not valid .env content: %v", hostname, err),
+					"Use KEY=value lines")
+			}
+			for k, v := range parsed {
+				vals[k] = v
+			}
+		}
+		if env, ok := svcMap["envSecrets"].(map[string]any); ok {
+			for k, v := range env {
+				vals[k] = fmt.Sprint(v)
+			}
+		}
+		if len(vals) == 0 {
+			continue
+		}
+		out[i] = vals
+		for _, k := range sortedKeys(vals) {
+			// Batch errors name the failing key, so key by hostname.
+			batchKey := hostname + "/" + k
+			if _, dup := refs[batchKey]; dup {
+				batchKey = fmt.Sprintf("%s#%d/%s", hostname, i, k)
+			}
+			order = append(order, batchKey)
+			refs[batchKey] = ref{svc: i, key: k}
+			inputs[batchKey] = vals[k]
+		}
+	}
+	if !expand || len(order) == 0 {
+		return out, nil
+	}
+
+	expanded, err := preprocess.Batch(ctx, order, inputs)
+	if err != nil {
+		return nil, platform.NewPlatformError(platform.ErrInvalidImportYml,
+			fmt.Sprintf("preprocessor expansion failed: %v", err),
+			"Check the <@...> syntax in envSecrets / dotEnvSecrets")
+	}
+	for _, batchKey := range order {
+		r := refs[batchKey]
+		out[r.svc][r.key] = expanded[batchKey]
+	}
+	return out, nil
+}
+
+// scalingFields are the import YAML scaling keys compared against live
+// autoscaling, with the ServiceStack accessor for each.
+var scalingFields = []struct {
+	key  string
+	live func(*platform.CustomAutoscaling) any
+}{
+	{"cpuMode", func(a *platform.CustomAutoscaling) any { return a.CPUMode }},
+	{"minCpu", func(a *platform.CustomAutoscaling) any { return float64(a.MinCPU) }},
+	{"maxCpu", func(a *platform.CustomAutoscaling) any { return float64(a.MaxCPU) }},
+	{"startCpuCoreCount", func(a *platform.CustomAutoscaling) any { return float64(a.StartCPUCoreCount) }},
+	{"minRam", func(a *platform.CustomAutoscaling) any { return a.MinRAM }},
+	{"maxRam", func(a *platform.CustomAutoscaling) any { return a.MaxRAM }},
+	{"minDisk", func(a *platform.CustomAutoscaling) any { return a.MinDisk }},
+	{"maxDisk", func(a *platform.CustomAutoscaling) any { return a.MaxDisk }},
+	{"minContainers", func(a *platform.CustomAutoscaling) any { return float64(a.HorizontalMinCount) }},
+	{"maxContainers", func(a *platform.CustomAutoscaling) any { return float64(a.HorizontalMaxCount) }},
+}
+
+// declaredScaling flattens verticalAutoscaling plus min/maxContainers
+// into one map. Fixed cpu/ram/disk values expand to equal min and max so
+// they compare against live limits like any other bound.
+func declaredScaling(svcMap map[string]any) map[string]any {
+	out := map[string]any{}
+	if va, ok := svcMap["verticalAutoscaling"].(map[string]any); ok {
+		for k, v := range va {
+			switch k {
+			case "cpu":
+				out["minCpu"], out["maxCpu"] = v, v
+			case "ram":
+				out["minRam"], out["maxRam"] = v, v
+			case "disk":
+				out["minDisk"], out["maxDisk"] = v, v
+			default:
+				out[k] = v
+			}
+		}
+	}
+	for _, k := range []string{"minContainers", "maxContainers"} {
+		if v, ok := svcMap[k]; ok {
+			out[k] = v
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func diffServiceDeclaration(svc *platform.ServiceStack, ps PlannedService) []FieldDiff {
+	var diffs []FieldDiff
+	if liveType := svc.ServiceStackTypeInfo.ServiceStackTypeVersionName; ps.Type != "" && liveType != "" && liveType != ps.Type {
+		diffs = append(diffs, FieldDiff{Field: "type", Live: liveType, Declared: ps.Type})
+	}
+	if ps.Mode != "" && svc.Mode != "" && svc.Mode != ps.Mode {
+		diffs = append(diffs, FieldDiff{Field: "mode", Live: svc.Mode, Declared: ps.Mode})
+	}
+	cur := svc.CustomAutoscaling
+	if cur == nil {
+		cur = svc.CurrentAutoscaling
+	}
+	if cur == nil {
+		return diffs
+	}
+	for _, f := range scalingFields {
+		want, ok := ps.Scaling[f.key]
+		if !ok {
+			continue
+		}
+		have := f.live(cur)
+		if !scalarEqual(have, want) {
+			diffs = append(diffs, FieldDiff{Field: f.key, Live: have, Declared: want})
+		}
+	}
+	return diffs
+}
+
+// scalarEqual compares a live value against a YAML-decoded one, treating
+// every number as float64 (yaml.v3 decodes integers as int).
+func scalarEqual(live, declared any) bool {
+	switch d := declared.(type) {
+	case int:
+		return live == float64(d)
+	case float64:
+		return live == d
+	default:
+		return fmt.Sprint(live) == fmt.Sprint(declared)
+	}
+}
+
+func envKeySet(vars []platform.EnvVar) map[string]bool {
+	out := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		out[v.Key] = true
+	}
+	return out
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func summarizeImportPlan(plan *ImportPlan, override bool) (string, string) {
+	counts := map[string]int{}
+	for _, s := range plan.Services {
+		counts[s.Action]++
+	}
+	summary := fmt.Sprintf("Dry run — nothing submitted. %d to create, %d to replace, %d rejected (hostname exists).",
+		counts[PlanActionCreate], counts[PlanActionReplace], counts[PlanActionReject])
+	if len(plan.EnvShadows) > 0 {
+		summary += fmt.Sprintf(" %d env secret(s) shadow existing vars.", len(plan.EnvShadows))
+	}
+
+	switch {
+	case len(plan.SchemaErrors) > 0:
+		return summary, "Fix schemaErrors, then re-run with dryRun=true."
+	case counts[PlanActionReject] > 0 && !override:
+		return summary, "Rename the colliding hostnames, or pass override=true to REPLACE them (destructive), then import without dryRun."
+	default:
+		return summary, "Review the plan, then call zerops_import with the same input and without dryRun to apply it."
+	}
+}
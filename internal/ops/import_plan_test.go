@@ -0,0 +1,180 @@
+// Tests for: ops/import_plan.go — zerops_import dry-run plan.
+package ops
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+func planMock() *platform.Mock {
+	return platform.NewMock().
+		WithServices([]platform.ServiceStack{
+			{
+				ID: "svc-db", Name: "db", ProjectID: "proj-1", Status: "ACTIVE", Mode: "NON_HA",
+				ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "postgresql@16"},
+			},
+			{
+				ID: "svc-api", Name: "api", ProjectID: "proj-1", Status: "ACTIVE", Mode: "NON_HA",
+				ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "nodejs@20"},
+				CustomAutoscaling: &platform.CustomAutoscaling{
+					CPUMode: "SHARED", MinCPU: 1, MaxCPU: 2, MinRAM: 0.5, MaxRAM: 2, HorizontalMinCount: 1, HorizontalMaxCount: 1,
+				},
+			},
+		}).
+		WithProjectEnv([]platform.EnvVar{{Key: "APP_ENV", Content: "prod"}}).
+		WithServiceEnv("svc-api", []platform.EnvVar{{Key: "JWT_SECRET", Content: "old"}})
+}
+
+func TestPlanImport(t *testing.T) {
+	t.Parallel()
+
+	content := `#zeropsPreprocessor=on
+services:
+  - hostname: api
+    type: nodejs@22
+    mode: NON_HA
+    minContainers: 1
+    maxContainers: 3
+    verticalAutoscaling:
+      maxRam: 2
+      cpuMode: DEDICATED
+    envSecrets:
+      JWT_SECRET: <@generateRandomString(<32>)>
+      APP_ENV: dev
+  - hostname: cache
+    type: valkey@7.2
+    mode: NON_HA
+    dotEnvSecrets: |
+      CACHE_KEY=<@generateRandomString(<16>)>
+`
+	mock := planMock()
+	plan, err := PlanImport(context.Background(), mock, "proj-1", content, "", false)
+	if err != nil {
+		t.Fatalf("PlanImport: %v", err)
+	}
+	if mock.CallCounts["ImportServices"] != 0 {
+		t.Fatal("dry run submitted the import")
+	}
+	if !plan.DryRun || !plan.Preprocessed {
+		t.Errorf("DryRun=%v Preprocessed=%v, want both true", plan.DryRun, plan.Preprocessed)
+	}
+
+	if len(plan.Services) != 2 {
+		t.Fatalf("services = %+v, want 2", plan.Services)
+	}
+	api, cache := plan.Services[0], plan.Services[1]
+	if api.Action != PlanActionReject || cache.Action != PlanActionCreate {
+		t.Errorf("actions = %s/%s, want reject/create", api.Action, cache.Action)
+	}
+	if got := api.EnvSecrets["JWT_SECRET"]; len(got) != 32 || strings.Contains(got, "<@") {
+		t.Errorf("JWT_SECRET not expanded: %q", got)
+	}
+	if got := cache.EnvSecrets["CACHE_KEY"]; len(got) != 16 {
+		t.Errorf("CACHE_KEY from dotEnvSecrets not expanded: %q", got)
+	}
+
+	if len(plan.Collisions) != 1 || plan.Collisions[0].ServiceID != "svc-api" {
+		t.Fatalf("collisions = %+v, want api only", plan.Collisions)
+	}
+	diffs := map[string]FieldDiff{}
+	for _, d := range plan.Collisions[0].Differences {
+		diffs[d.Field] = d
+	}
+	for _, field := range []string{"type", "cpuMode", "maxContainers"} {
+		if _, ok := diffs[field]; !ok {
+			t.Errorf("missing %s difference in %+v", field, plan.Collisions[0].Differences)
+		}
+	}
+	for _, field := range []string{"mode", "maxRam", "minContainers"} {
+		if d, ok := diffs[field]; ok {
+			t.Errorf("unexpected difference for unchanged %s: %+v", field, d)
+		}
+	}
+
+	wantShadows := []EnvShadow{
+		{Service: "api", Key: "APP_ENV", Shadows: "project"},
+		{Service: "api", Key: "JWT_SECRET", Shadows: "service"},
+	}
+	if len(plan.EnvShadows) != len(wantShadows) {
+		t.Fatalf("envShadows = %+v, want %+v", plan.EnvShadows, wantShadows)
+	}
+	for i, want := range wantShadows {
+		if plan.EnvShadows[i] != want {
+			t.Errorf("envShadows[%d] = %+v, want %+v", i, plan.EnvShadows[i], want)
+		}
+	}
+	if !strings.Contains(plan.NextActions, "override=true") {
+		t.Errorf("nextActions should explain the collision: %q", plan.NextActions)
+	}
+}
+
+func TestPlanImport_OverrideReplaces(t *testing.T) {
+	t.Parallel()
+
+	content := "services:\n  - hostname: db\n    type: postgresql@16\n    mode: HA\n"
+	plan, err := PlanImport(context.Background(), planMock(), "proj-1", content, "", true)
+	if err != nil {
+		t.Fatalf("PlanImport: %v", err)
+	}
+	if plan.Preprocessed {
+		t.Error("Preprocessed = true without the header")
+	}
+	if len(plan.Collisions) != 1 || plan.Collisions[0].Action != PlanActionReplace {
+		t.Fatalf("collisions = %+v, want db replace", plan.Collisions)
+	}
+	diffs := plan.Collisions[0].Differences
+	if len(diffs) != 1 || diffs[0].Field != "mode" || diffs[0].Live != "NON_HA" || diffs[0].Declared != "HA" {
+		t.Errorf("differences = %+v, want only mode NON_HA→HA", diffs)
+	}
+}
+
+func TestPlanImport_LiteralWithoutHeader(t *testing.T) {
+	t.Parallel()
+
+	content := "services:\n  - hostname: app\n    type: go@1\n    envSecrets:\n      KEY: <@generateRandomString(<8>)>\n"
+	plan, err := PlanImport(context.Background(), planMock(), "proj-1", content, "", false)
+	if err != nil {
+		t.Fatalf("PlanImport: %v", err)
+	}
+	if got := plan.Services[0].EnvSecrets["KEY"]; got != "<@generateRandomString(<8>)>" {
+		t.Errorf("without the header the platform stores values literally; plan shows %q", got)
+	}
+}
+
+func TestPlanImport_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		content  string
+		mock     *platform.Mock
+		wantCode string
+	}{
+		{"project block", "project:\n  name: x\nservices: []\n", planMock(), platform.ErrImportHasProject},
+		{"invalid yaml", "services: [\n", planMock(), platform.ErrInvalidImportYml},
+		{
+			"bad dotenv",
+			"services:\n  - hostname: app\n    type: go@1\n    dotEnvSecrets: \"KEY='unterminated\"\n",
+			planMock(), platform.ErrInvalidImportYml,
+		},
+		{
+			"list services fails", "services:\n  - hostname: app\n    type: go@1\n",
+			planMock().WithError("ListServices", platform.NewPlatformError(platform.ErrAPIError, "boom", "")),
+			platform.ErrAPIError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := PlanImport(context.Background(), tt.mock, "proj-1", tt.content, "", false)
+			var pe *platform.PlatformError
+			if !errors.As(err, &pe) || pe.Code != tt.wantCode {
+				t.Fatalf("err = %v, want code %s", err, tt.wantCode)
+			}
+		})
+	}
+}
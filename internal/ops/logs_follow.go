@@ -0,0 +1,335 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// Follow-mode bounds. A tool call blocks for the whole follow, so the
+// duration is capped well below typical MCP client request timeouts.
+const (
+	defaultFollowDuration = 60 * time.Second
+	maxFollowDuration     = 10 * time.Minute
+	defaultFollowMaxLines = 500
+	maxFollowMaxLines     = 5000
+	followPollInterval    = 2 * time.Second
+)
+
+// Follow stop reasons.
+const (
+	FollowStopDuration  = "duration"
+	FollowStopPattern   = "pattern"
+	FollowStopMaxLines  = "maxLines"
+	FollowStopCancelled = "cancelled"
+	FollowStopError     = "error"
+)
+
+// FollowParams configures FollowLogs.
+type FollowParams struct {
+	Services []string
+	Severity string
+	Search   string
+	// Since selects the initial backlog. Empty starts at the moment the
+	// follow begins — only new lines are returned.
+	Since    string
+	Duration string // Go duration, e.g. 60s, 5m
+	StopOn   string // regular expression; the first matching line ends the follow
+	MaxLines int
+}
+
+// FollowResult is the interleaved output of a follow session.
+type FollowResult struct {
+	Services   []string      `json:"services"`
+	Entries    []FollowEntry `json:"entries"`
+	StopReason string        `json:"stopReason"`
+	StopDetail string        `json:"stopDetail,omitempty"`
+	Polls      int           `json:"polls"`
+	Elapsed    string        `json:"elapsed"`
+}
+
+// FollowEntry is a log line tagged with the service it came from.
+type FollowEntry struct {
+	Timestamp string `json:"timestamp"`
+	Hostname  string `json:"hostname"`
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
+	Container string `json:"container,omitempty"`
+}
+
+// followCursor tracks one service's position in its log stream. The
+// backend's since filter is inclusive, so lines at the cursor timestamp
+// come back on the next poll; seen holds their keys to drop the repeats.
+type followCursor struct {
+	hostname  string
+	serviceID string
+	since     time.Time
+	seen      map[string]time.Time
+}
+
+// FollowLogs tails application logs of one or more services until the
+// duration elapses, a line matches StopOn, MaxLines lines were collected
+// or ctx is cancelled. Each poll's new lines are merged across services
+// in timestamp order and reported through onChunk (may be nil) as one
+// formatted block, so an MCP client sees the stream live via progress
+// notifications; the full interleaved list is returned at the end.
+func FollowLogs(
+	ctx context.Context,
+	client platform.Client,
+	fetcher platform.LogFetcher,
+	projectID string,
+	params FollowParams,
+	onChunk ProgressCallback,
+) (*FollowResult, error) {
+	return followLogs(ctx, client, fetcher, projectID, params, onChunk, followPollInterval)
+}
+
+func followLogs(
+	ctx context.Context,
+	client platform.Client,
+	fetcher platform.LogFetcher,
+	projectID string,
+	params FollowParams,
+	onChunk ProgressCallback,
+	interval time.Duration,
+) (*FollowResult, error) {
+	duration, maxLines, stopOn, err := validateFollowParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	since := start
+	if params.Since != "" {
+		if since, err = parseSince(params.Since); err != nil {
+			return nil, platform.NewPlatformError(platform.ErrInvalidParameter,
+				fmt.Sprintf("Invalid since value: %v", err),
+				"Use formats like 30s, 5m, 1h, 7d, or ISO 8601 (RFC3339)")
+		}
+	}
+
+	services, err := client.ListServices(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	cursors := make([]*followCursor, 0, len(params.Services))
+	for _, hostname := range params.Services {
+		svc, err := FindService(services, hostname)
+		if err != nil {
+			return nil, err
+		}
+		cursors = append(cursors, &followCursor{hostname: hostname, serviceID: svc.ID, since: since, seen: map[string]time.Time{}})
+	}
+
+	access, err := client.GetProjectLog(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FollowResult{Services: params.Services, Entries: []FollowEntry{}}
+	deadline := start.Add(duration)
+	timer := time.NewTimer(0) // first poll immediately
+	defer timer.Stop()
+
+	for result.StopReason == "" {
+		select {
+		case <-ctx.Done():
+			result.StopReason = FollowStopCancelled
+			continue
+		case <-timer.C:
+		}
+
+		batch, err := pollFollowCursors(ctx, fetcher, access, params, cursors)
+		result.Polls++
+		if err != nil {
+			if ctx.Err() != nil {
+				result.StopReason = FollowStopCancelled
+				break
+			}
+			if result.Polls == 1 {
+				return nil, err
+			}
+			// Keep what was collected; a transient backend error late in
+			// a long follow should not throw the session away.
+			result.StopReason, result.StopDetail = FollowStopError, err.Error()
+			break
+		}
+
+		for i, e := range batch {
+			result.Entries = append(result.Entries, e)
+			if stopOn != nil && stopOn.MatchString(e.Message) {
+				result.StopReason, result.StopDetail = FollowStopPattern, e.Hostname+": "+e.Message
+			} else if len(result.Entries) >= maxLines {
+				result.StopReason = FollowStopMaxLines
+			}
+			if result.StopReason != "" {
+				batch = batch[:i+1]
+				break
+			}
+		}
+		if onChunk != nil && len(batch) > 0 {
+			onChunk(FormatFollowEntries(batch), float64(len(result.Entries)), float64(maxLines))
+		}
+
+		if result.StopReason == "" {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				result.StopReason = FollowStopDuration
+				break
+			}
+			timer.Reset(min(interval, remaining))
+		}
+	}
+
+	result.Elapsed = time.Since(start).Round(time.Second).String()
+	return result, nil
+}
+
+func validateFollowParams(params FollowParams) (time.Duration, int, *regexp.Regexp, error) {
+	if len(params.Services) == 0 {
+		return 0, 0, nil, platform.NewPlatformError(platform.ErrInvalidParameter,
+			"follow needs at least one service", "Pass services=[\"app\"] (or serviceHostname) with follow=true")
+	}
+	if dup := firstDuplicate(params.Services); dup != "" {
+		return 0, 0, nil, platform.NewPlatformError(platform.ErrInvalidParameter,
+			fmt.Sprintf("service %q listed twice", dup), "List each hostname once")
+	}
+
+	duration := defaultFollowDuration
+	if params.Duration != "" {
+		d, err := time.ParseDuration(params.Duration)
+		if err != nil || d <= 0 || d > maxFollowDuration {
+			return 0, 0, nil, platform.NewPlatformError(platform.ErrInvalidParameter,
+				fmt.Sprintf("Invalid duration %q", params.Duration),
+				fmt.Sprintf("Use a positive Go duration up to %s, e.g. 30s or 2m", maxFollowDuration))
+		}
+		duration = d
+	}
+
+	maxLines := params.MaxLines
+	if maxLines <= 0 {
+		maxLines = defaultFollowMaxLines
+	}
+	if maxLines > maxFollowMaxLines {
+		return 0, 0, nil, platform.NewPlatformError(platform.ErrInvalidParameter,
+			fmt.Sprintf("maxLines %d exceeds %d", maxLines, maxFollowMaxLines),
+			"Lower maxLines, or narrow the stream with severity / search")
+	}
+
+	var stopOn *regexp.Regexp
+	if params.StopOn != "" {
+		re, err := regexp.Compile(params.StopOn)
+		if err != nil {
+			return 0, 0, nil, platform.NewPlatformError(platform.ErrInvalidParameter,
+				fmt.Sprintf("Invalid stopOn pattern: %v", err),
+				"stopOn is a Go regular expression, e.g. `listening on|ready`")
+		}
+		stopOn = re
+	}
+	return duration, maxLines, stopOn, nil
+}
+
+func firstDuplicate(items []string) string {
+	seen := make(map[string]bool, len(items))
+	for _, s := range items {
+		if seen[s] {
+			return s
+		}
+		seen[s] = true
+	}
+	return ""
+}
+
+// pollFollowCursors fetches every cursor once and returns the new lines
+// from all of them, interleaved by timestamp (hostname breaks ties so
+// the order is stable).
+func pollFollowCursors(
+	ctx context.Context,
+	fetcher platform.LogFetcher,
+	access *platform.LogAccess,
+	params FollowParams,
+	cursors []*followCursor,
+) ([]FollowEntry, error) {
+	type stamped struct {
+		at    time.Time
+		entry FollowEntry
+	}
+	var batch []stamped
+	for _, c := range cursors {
+		entries, err := fetcher.FetchLogs(ctx, access, platform.LogFetchParams{
+			ServiceID: c.serviceID,
+			Severity:  params.Severity,
+			Facility:  "application",
+			Since:     c.since,
+			Limit:     platform.LogLimitMax,
+			Search:    params.Search,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("fetch logs for %s: %w", c.hostname, err)
+		}
+		for _, e := range entries {
+			at, err := time.Parse(time.RFC3339, e.Timestamp)
+			if err != nil {
+				continue
+			}
+			key := e.ID
+			if key == "" {
+				key = e.Timestamp + "\x00" + e.ContainerID + "\x00" + e.Message
+			}
+			if _, dup := c.seen[key]; dup {
+				continue
+			}
+			c.seen[key] = at
+			if at.After(c.since) {
+				c.since = at
+			}
+			batch = append(batch, stamped{at, FollowEntry{
+				Timestamp: e.Timestamp,
+				Hostname:  c.hostname,
+				Severity:  e.Severity,
+				Message:   e.Message,
+				Container: e.Container,
+			}})
+		}
+		// Only keys at the cursor can come back; forget the rest.
+		for key, at := range c.seen {
+			if at.Before(c.since) {
+				delete(c.seen, key)
+			}
+		}
+	}
+
+	slices.SortStableFunc(batch, func(a, b stamped) int {
+		if c := a.at.Compare(b.at); c != 0 {
+			return c
+		}
+		return strings.Compare(a.entry.Hostname, b.entry.Hostname)
+	})
+	out := make([]FollowEntry, len(batch))
+	for i, s := range batch {
+		out[i] = s.entry
+	}
+	return out, nil
+}
+
+// FormatFollowEntries renders entries as aligned "timestamp host | message"
+// lines — the shape streamed in progress notifications.
+func FormatFollowEntries(entries []FollowEntry) string {
+	width := 0
+	for _, e := range entries {
+		width = max(width, len(e.Hostname))
+	}
+	var b strings.Builder
+	for i, e := range entries {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%s %-*s | %s", e.Timestamp, width, e.Hostname, e.Message)
+	}
+	return b.String()
+}
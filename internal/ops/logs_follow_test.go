@@ -0,0 +1,187 @@
+// Tests for: ops/logs_follow.go — multi-service log follow.
+package ops
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// growingLogFetcher serves a per-service log that gains lines on every
+// poll, applying the same inclusive since filter as the real backend.
+type growingLogFetcher struct {
+	mu     sync.Mutex
+	logs   map[string][]platform.LogEntry // serviceID → full log
+	reveal map[string][]int               // serviceID → visible length per poll round
+	calls  map[string]int
+	failAt int // global call number that fails; 0 = never
+	total  int
+}
+
+func (f *growingLogFetcher) FetchLogs(_ context.Context, _ *platform.LogAccess, p platform.LogFetchParams) ([]platform.LogEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.total++
+	if f.failAt > 0 && f.total >= f.failAt {
+		return nil, errors.New("backend unavailable")
+	}
+	round := f.calls[p.ServiceID]
+	f.calls[p.ServiceID]++
+	steps := f.reveal[p.ServiceID]
+	visible := steps[min(round, len(steps)-1)]
+	var out []platform.LogEntry
+	for _, e := range f.logs[p.ServiceID][:visible] {
+		at, _ := time.Parse(time.RFC3339, e.Timestamp)
+		if !at.Before(p.Since) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func followTestMock() *platform.Mock {
+	return platform.NewMock().
+		WithServices([]platform.ServiceStack{
+			{ID: "svc-web", Name: "web", Status: "ACTIVE"},
+			{ID: "svc-worker", Name: "worker", Status: "ACTIVE"},
+		}).
+		WithLogAccess(&platform.LogAccess{URL: "https://logs.example"})
+}
+
+func logAt(base time.Time, offset int, msg string) platform.LogEntry {
+	return platform.LogEntry{
+		Timestamp: base.Add(time.Duration(offset) * time.Second).UTC().Format(time.RFC3339),
+		Severity:  "INFO",
+		Message:   msg,
+	}
+}
+
+func TestFollowLogs_InterleavesAndDedupes(t *testing.T) {
+	t.Parallel()
+
+	base := time.Now().Add(time.Minute).Truncate(time.Second)
+	fetcher := &growingLogFetcher{
+		logs: map[string][]platform.LogEntry{
+			// Two lines share a timestamp: the inclusive since filter
+			// returns both again on every later poll.
+			"svc-web":    {logAt(base, 0, "web GET /"), logAt(base, 2, "web GET /a"), logAt(base, 2, "web GET /b")},
+			"svc-worker": {logAt(base, 1, "worker job 1"), logAt(base, 3, "worker job 2")},
+		},
+		reveal: map[string][]int{"svc-web": {1, 3}, "svc-worker": {1, 2}},
+		calls:  map[string]int{},
+	}
+
+	var chunks []string
+	result, err := followLogs(context.Background(), followTestMock(), fetcher, "proj-1",
+		FollowParams{Services: []string{"web", "worker"}, Duration: "200ms"},
+		func(msg string, _, _ float64) { chunks = append(chunks, msg) },
+		time.Millisecond)
+	if err != nil {
+		t.Fatalf("followLogs: %v", err)
+	}
+
+	var got []string
+	for _, e := range result.Entries {
+		got = append(got, e.Hostname+":"+e.Message)
+	}
+	want := []string{"web:web GET /", "worker:worker job 1", "web:web GET /a", "web:web GET /b", "worker:worker job 2"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("entries = %v\nwant      %v", got, want)
+	}
+	if result.StopReason != FollowStopDuration {
+		t.Errorf("stopReason = %q, want duration", result.StopReason)
+	}
+	if len(chunks) != 2 || !strings.Contains(chunks[0], "worker | worker job 1") {
+		t.Errorf("progress chunks = %q, want two formatted blocks", chunks)
+	}
+}
+
+func TestFollowLogs_StopConditions(t *testing.T) {
+	t.Parallel()
+
+	base := time.Now().Add(time.Minute).Truncate(time.Second)
+	newFetcher := func() *growingLogFetcher {
+		return &growingLogFetcher{
+			logs: map[string][]platform.LogEntry{
+				"svc-web": {logAt(base, 0, "booting"), logAt(base, 1, "listening on :3000"), logAt(base, 2, "GET /")},
+			},
+			reveal: map[string][]int{"svc-web": {3}},
+			calls:  map[string]int{},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		params     FollowParams
+		wantReason string
+		wantLines  int
+	}{
+		{"pattern", FollowParams{Services: []string{"web"}, StopOn: "listening on"}, FollowStopPattern, 2},
+		{"max lines", FollowParams{Services: []string{"web"}, MaxLines: 1}, FollowStopMaxLines, 1},
+		{"duration", FollowParams{Services: []string{"web"}, Duration: "20ms"}, FollowStopDuration, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := followLogs(context.Background(), followTestMock(), newFetcher(), "proj-1", tt.params, nil, time.Millisecond)
+			if err != nil {
+				t.Fatalf("followLogs: %v", err)
+			}
+			if result.StopReason != tt.wantReason || len(result.Entries) != tt.wantLines {
+				t.Errorf("stop = %s with %d lines, want %s with %d", result.StopReason, len(result.Entries), tt.wantReason, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestFollowLogs_ErrorAfterFirstPollKeepsEntries(t *testing.T) {
+	t.Parallel()
+
+	base := time.Now().Add(time.Minute).Truncate(time.Second)
+	fetcher := &growingLogFetcher{
+		logs:   map[string][]platform.LogEntry{"svc-web": {logAt(base, 0, "one")}},
+		reveal: map[string][]int{"svc-web": {1}},
+		calls:  map[string]int{},
+		failAt: 2,
+	}
+	result, err := followLogs(context.Background(), followTestMock(), fetcher, "proj-1",
+		FollowParams{Services: []string{"web"}, Duration: "1s"}, nil, time.Millisecond)
+	if err != nil {
+		t.Fatalf("followLogs: %v", err)
+	}
+	if result.StopReason != FollowStopError || len(result.Entries) != 1 || !strings.Contains(result.StopDetail, "backend unavailable") {
+		t.Errorf("result = %+v, want error stop keeping the first line", result)
+	}
+}
+
+func TestFollowLogs_InvalidParams(t *testing.T) {
+	t.Parallel()
+
+	for name, params := range map[string]FollowParams{
+		"no services":     {},
+		"duplicate":       {Services: []string{"web", "web"}},
+		"bad duration":    {Services: []string{"web"}, Duration: "forever"},
+		"too long":        {Services: []string{"web"}, Duration: "1h"},
+		"too many lines":  {Services: []string{"web"}, MaxLines: 100000},
+		"bad stop regexp": {Services: []string{"web"}, StopOn: "("},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, err := FollowLogs(context.Background(), followTestMock(), platform.NewMockLogFetcher(), "proj-1", params, nil)
+			var pe *platform.PlatformError
+			if !errors.As(err, &pe) || pe.Code != platform.ErrInvalidParameter {
+				t.Errorf("err = %v, want INVALID_PARAMETER", err)
+			}
+		})
+	}
+
+	_, err := FollowLogs(context.Background(), followTestMock(), platform.NewMockLogFetcher(), "proj-1", FollowParams{Services: []string{"ghost"}}, nil)
+	if err == nil {
+		t.Error("unknown service should fail before polling")
+	}
+}
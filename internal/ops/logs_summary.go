@@ -0,0 +1,245 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zeropsio/zcp/internal/platform"
+	"github.com/zeropsio/zcp/internal/topology"
+)
+
+const (
+	defaultSummaryTop    = 10
+	maxSummaryTop        = 50
+	maxSummaryTextLength = 300
+)
+
+// LogSummary is the clustered view of a service's log window.
+type LogSummary struct {
+	Service  string       `json:"service"`
+	Scanned  int          `json:"scanned"`
+	HasMore  bool         `json:"hasMore"`
+	Clusters int          `json:"clusters"`
+	Patterns []LogPattern `json:"patterns"`
+}
+
+// LogPattern is one message template with its occurrences. Variable parts
+// (numbers, UUIDs, IPs, timestamps, hex ids) are masked in Template;
+// Exemplar is the first raw message that produced it.
+type LogPattern struct {
+	Template   string         `json:"template"`
+	Count      int            `json:"count"`
+	FirstSeen  string         `json:"firstSeen"`
+	LastSeen   string         `json:"lastSeen"`
+	Severities map[string]int `json:"severities"`
+	Exemplar   string         `json:"exemplar"`
+	Signal     *LogSignal     `json:"signal,omitempty"`
+}
+
+// LogSignal tags a pattern that matches an entry of the deploy failure
+// signal library, carrying the library's diagnosis so the agent does not
+// have to re-derive it from the exemplar.
+type LogSignal struct {
+	ID              string                `json:"id"`
+	Category        topology.FailureClass `json:"category"`
+	LikelyCause     string                `json:"likelyCause,omitempty"`
+	SuggestedAction string                `json:"suggestedAction,omitempty"`
+}
+
+// logMasks replace the variable parts of a message, most specific first —
+// a timestamp or UUID must not be eaten piecewise by the hex or number
+// masks. keep, when set, vetoes a match and leaves it verbatim.
+var logMasks = []struct {
+	re   *regexp.Regexp
+	mask string
+	keep func(match string) bool
+}{
+	{re: regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?`), mask: "<ts>"},
+	{re: regexp.MustCompile(`\d{2}:\d{2}:\d{2}(?:\.\d+)?`), mask: "<ts>"},
+	{re: regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), mask: "<uuid>"},
+	{re: regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}(?::\d+)?\b`), mask: "<ip>"},
+	// Commit SHAs, request and container ids. Plain words ("deadbeef",
+	// "accepted") and plain numbers are left to the later masks.
+	{re: regexp.MustCompile(`(?i)\b(?:0x[0-9a-f]+|[0-9a-f]{8,})\b`), mask: "<hex>", keep: func(id string) bool {
+		lower := strings.ToLower(id)
+		return !strings.HasPrefix(lower, "0x") &&
+			(!strings.ContainsAny(lower, "0123456789") || !strings.ContainsAny(lower, "abcdef"))
+	}},
+	// No trailing \b, so numbers with a unit glued on ("13.5ms") match too.
+	{re: regexp.MustCompile(`\b\d+(?:\.\d+)?`), mask: "<num>"},
+}
+
+// logTemplate masks the variable parts of a log message so lines that
+// differ only in ids, durations or addresses share a template.
+func logTemplate(message string) string {
+	t := strings.TrimSpace(message)
+	for _, m := range logMasks {
+		t = m.re.ReplaceAllStringFunc(t, func(match string) string {
+			if m.keep != nil && m.keep(match) {
+				return match
+			}
+			return m.mask
+		})
+	}
+	return t
+}
+
+// SummarizeLogs fetches up to limit application log lines of a service and
+// clusters them into message templates. The top patterns by count are
+// returned; patterns tagged with a failure signal are always kept, even
+// below the cut, since a single crash line matters more than the access
+// log around it.
+func SummarizeLogs(
+	ctx context.Context,
+	client platform.Client,
+	fetcher platform.LogFetcher,
+	projectID string,
+	hostname string,
+	severity string,
+	since string,
+	limit int,
+	search string,
+	top int,
+) (*LogSummary, error) {
+	if limit <= 0 {
+		limit = platform.LogLimitMax
+	}
+	if top <= 0 {
+		top = defaultSummaryTop
+	}
+	if top > maxSummaryTop {
+		return nil, platform.NewPlatformError(platform.ErrInvalidParameter,
+			fmt.Sprintf("top %d exceeds %d", top, maxSummaryTop),
+			"Lower top; the summary already ranks the noisiest patterns first")
+	}
+
+	raw, err := FetchLogs(ctx, client, fetcher, projectID, hostname, severity, since, limit, search)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := clusterLogEntries(raw.Entries)
+	summary := &LogSummary{
+		Service:  hostname,
+		Scanned:  len(raw.Entries),
+		HasMore:  raw.HasMore,
+		Clusters: len(patterns),
+		Patterns: []LogPattern{},
+	}
+	for i, p := range patterns {
+		if i < top || p.Signal != nil {
+			summary.Patterns = append(summary.Patterns, p)
+		}
+	}
+	return summary, nil
+}
+
+// clusterLogEntries groups entries by template and ranks the clusters by
+// count (first occurrence breaks ties).
+func clusterLogEntries(entries []LogEntryOutput) []LogPattern {
+	type cluster struct {
+		pattern       LogPattern
+		first, last   time.Time
+		firstPosition int
+	}
+	byTemplate := make(map[string]*cluster)
+	var order []*cluster
+	for i, e := range entries {
+		tmpl := logTemplate(e.Message)
+		at, _ := time.Parse(time.RFC3339, e.Timestamp)
+		c, ok := byTemplate[tmpl]
+		if !ok {
+			c = &cluster{
+				pattern: LogPattern{
+					Template:   truncateSummaryText(tmpl),
+					FirstSeen:  e.Timestamp,
+					LastSeen:   e.Timestamp,
+					Severities: map[string]int{},
+					Exemplar:   truncateSummaryText(e.Message),
+				},
+				first:         at,
+				last:          at,
+				firstPosition: i,
+			}
+			byTemplate[tmpl] = c
+			order = append(order, c)
+		}
+		c.pattern.Count++
+		c.pattern.Severities[severityLabel(e.Severity)]++
+		if at.Before(c.first) {
+			c.first, c.pattern.FirstSeen = at, e.Timestamp
+		}
+		if at.After(c.last) {
+			c.last, c.pattern.LastSeen = at, e.Timestamp
+		}
+	}
+
+	slices.SortFunc(order, func(a, b *cluster) int {
+		if a.pattern.Count != b.pattern.Count {
+			return b.pattern.Count - a.pattern.Count
+		}
+		return a.firstPosition - b.firstPosition
+	})
+	out := make([]LogPattern, len(order))
+	for i, c := range order {
+		c.pattern.Signal = matchLogSignal(c.pattern.Exemplar)
+		out[i] = c.pattern
+	}
+	return out
+}
+
+// logSignalPhases is the phase precedence for tagging runtime logs: a
+// running container's output most often carries init-class failures
+// (port in use, DB refused), so those win over their build twins.
+// Transport and preflight signals describe deploy tooling, not app
+// output, and are never applied.
+var logSignalPhases = []DeployFailurePhase{PhaseInit, PhaseBuild, PhasePrepare}
+
+// matchLogSignal returns the first log-pattern signal from the failure
+// library that matches message. Signals scoped to a deploy strategy or an
+// API error code need context a log line cannot supply and are skipped.
+func matchLogSignal(message string) *LogSignal {
+	in := FailureInput{RuntimeLogs: []string{message}}
+	for _, phase := range logSignalPhases {
+		for _, s := range failureSignals() {
+			if !s.appliesToPhase(phase) || len(s.strategies) > 0 || s.apiCode != "" {
+				continue
+			}
+			match := s.matchLogs(in)
+			if match == "" {
+				continue
+			}
+			signal := &LogSignal{ID: s.id}
+			if c := s.build(match); c != nil {
+				signal.Category = c.Category
+				signal.LikelyCause = c.LikelyCause
+				signal.SuggestedAction = c.SuggestedAction
+			}
+			return signal
+		}
+	}
+	return nil
+}
+
+func severityLabel(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return strings.ToLower(s)
+}
+
+func truncateSummaryText(s string) string {
+	if len(s) <= maxSummaryTextLength {
+		return s
+	}
+	cut := maxSummaryTextLength
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…"
+}
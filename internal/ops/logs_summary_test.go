@@ -0,0 +1,107 @@
+// Tests for: ops/logs_summary.go — log template clustering.
+package ops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+func TestLogTemplate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in, want string
+	}{
+		{"GET /users/42 200 in 13.5ms", "GET /users/<num> <num> in <num>ms"},
+		{"request 550e8400-e29b-41d4-a716-446655440000 done", "request <uuid> done"},
+		{"client 10.0.3.17:51234 connected", "client <ip> connected"},
+		{"2026-04-23T10:00:00.123Z worker started at 10:00:01", "<ts> worker started at <ts>"},
+		{"commit 3f9a2b1c deployed, ptr 0x7ffe", "commit <hex> deployed, ptr <hex>"},
+		{"deadbeef is a word, 12345678 a number", "deadbeef is a word, <num> a number"},
+		{"  padded  ", "padded"},
+	}
+	for _, tt := range tests {
+		if got := logTemplate(tt.in); got != tt.want {
+			t.Errorf("logTemplate(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSummarizeLogs(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	ts := func(s int) string { return now.Add(time.Duration(s-600) * time.Second).Format(time.RFC3339Nano) }
+	var entries []platform.LogEntry
+	for i := range 20 {
+		entries = append(entries, platform.LogEntry{
+			Timestamp: ts(i), Severity: "Informational", Facility: "local0",
+			Message: fmt.Sprintf("GET /items/%d 200 in %dms", i, i*3),
+		})
+	}
+	for i := range 5 {
+		entries = append(entries, platform.LogEntry{
+			Timestamp: ts(30 + i), Severity: "Warning", Facility: "local0",
+			Message: fmt.Sprintf("slow query took %dms", 500+i),
+		})
+	}
+	entries = append(entries,
+		platform.LogEntry{Timestamp: ts(40), Severity: "Error", Facility: "local0", Message: "Error: listen EADDRINUSE: address already in use :::3000"},
+		platform.LogEntry{Timestamp: ts(41), Severity: "Informational", Facility: "local0", Message: "cache warmed"},
+	)
+
+	mock := platform.NewMock().
+		WithServices([]platform.ServiceStack{{ID: "svc-1", Name: "api"}}).
+		WithLogAccess(&platform.LogAccess{URL: "https://logs.example"})
+	fetcher := platform.NewMockLogFetcher().WithEntries(entries)
+
+	summary, err := SummarizeLogs(context.Background(), mock, fetcher, "proj-1", "api", "", "1h", 0, "", 2)
+	if err != nil {
+		t.Fatalf("SummarizeLogs: %v", err)
+	}
+	if summary.Scanned != len(entries) || summary.Clusters != 4 {
+		t.Errorf("scanned=%d clusters=%d, want %d and 4", summary.Scanned, summary.Clusters, len(entries))
+	}
+	// Top 2 by count plus the signal-tagged crash line below the cut.
+	if len(summary.Patterns) != 3 {
+		t.Fatalf("patterns = %+v, want 3", summary.Patterns)
+	}
+
+	access := summary.Patterns[0]
+	if access.Template != "GET /items/<num> <num> in <num>ms" || access.Count != 20 {
+		t.Errorf("top pattern = %q x%d", access.Template, access.Count)
+	}
+	if access.FirstSeen != ts(0) || access.LastSeen != ts(19) || access.Exemplar != "GET /items/0 200 in 0ms" {
+		t.Errorf("first/last/exemplar = %s / %s / %q", access.FirstSeen, access.LastSeen, access.Exemplar)
+	}
+	if access.Severities["informational"] != 20 {
+		t.Errorf("severities = %v", access.Severities)
+	}
+
+	crash := summary.Patterns[2]
+	if crash.Signal == nil || crash.Signal.ID != "init:port-in-use" {
+		t.Fatalf("crash pattern signal = %+v, want init:port-in-use", crash.Signal)
+	}
+	if crash.Signal.SuggestedAction == "" {
+		t.Error("signal should carry the library's suggested action")
+	}
+	for _, p := range summary.Patterns[:2] {
+		if p.Signal != nil {
+			t.Errorf("pattern %q should not be tagged: %+v", p.Template, p.Signal)
+		}
+	}
+}
+
+func TestSummarizeLogs_TopTooLarge(t *testing.T) {
+	t.Parallel()
+
+	_, err := SummarizeLogs(context.Background(), platform.NewMock(), platform.NewMockLogFetcher(), "proj-1", "api", "", "", 0, "", 500)
+	if err == nil || !strings.Contains(err.Error(), "top") {
+		t.Errorf("err = %v, want top bound error", err)
+	}
+}
@@ -0,0 +1,303 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// Watchdog timing defaults.
+const (
+	MountWatchInterval   = 30 * time.Second
+	mountRemountBackoff  = 10 * time.Second
+	mountRemountMaxDelay = 5 * time.Minute
+	mountWatchHistoryMax = 20
+)
+
+// Watched mount states.
+const (
+	MountWatchActive = "active"
+	MountWatchStale  = "stale"
+	MountWatchFailed = "remount-failed"
+	MountWatchGone   = "gone"
+)
+
+// MountWatchEvent is one state change or remount attempt of a watched
+// mount. Level follows MCP logging levels (info, warning, error).
+type MountWatchEvent struct {
+	Time     time.Time `json:"time"`
+	Hostname string    `json:"hostname"`
+	From     string    `json:"from,omitempty"`
+	To       string    `json:"to"`
+	Level    string    `json:"level"`
+	Message  string    `json:"message"`
+}
+
+// MountWatch is the watchdog's view of one mount.
+type MountWatch struct {
+	Hostname    string            `json:"hostname"`
+	State       string            `json:"state"`
+	LastCheck   time.Time         `json:"lastCheck"`
+	LastChange  time.Time         `json:"lastChange"`
+	Failures    int               `json:"consecutiveFailures,omitempty"`
+	NextAttempt *time.Time        `json:"nextRemountAttempt,omitempty"`
+	History     []MountWatchEvent `json:"history,omitempty"`
+}
+
+// MountWatchdogStatus is a snapshot of the watchdog for zerops_mount status.
+type MountWatchdogStatus struct {
+	Interval  string       `json:"interval"`
+	LastCheck *time.Time   `json:"lastCheck,omitempty"`
+	Mounts    []MountWatch `json:"mounts"`
+}
+
+// MountWatchdog periodically checks every SSHFS mount under /var/www and
+// remounts stale ones. A target redeploy replaces the container behind
+// the mount and leaves it stale; without the watchdog that only surfaces
+// when a file write fails. Remount attempts back off exponentially per
+// mount. A mount that is deliberately unmounted (no FUSE entry, no unit)
+// is dropped from the watch list; one whose service was deleted is left
+// for zerops_mount unmount rather than retried forever.
+type MountWatchdog struct {
+	client    platform.Client
+	projectID string
+	mounter   Mounter
+	notify    func(MountWatchEvent)
+	interval  time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	lastCheck time.Time
+	mounts    map[string]*MountWatch
+}
+
+// NewMountWatchdog creates a watchdog. notify (may be nil) receives every
+// event as it happens.
+func NewMountWatchdog(client platform.Client, projectID string, mounter Mounter, notify func(MountWatchEvent)) *MountWatchdog {
+	return &MountWatchdog{
+		client:    client,
+		projectID: projectID,
+		mounter:   mounter,
+		notify:    notify,
+		interval:  MountWatchInterval,
+		now:       time.Now,
+		mounts:    make(map[string]*MountWatch),
+	}
+}
+
+// Run checks on every interval until ctx is canceled.
+func (w *MountWatchdog) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check runs one pass: discover mounts, record their state and remount
+// the stale ones whose backoff has elapsed.
+func (w *MountWatchdog) Check(ctx context.Context) {
+	now := w.now()
+	dirs, _ := w.mounter.ListMountDirs(ctx, mountBase)
+
+	w.mu.Lock()
+	hosts := slices.Clone(dirs)
+	for host := range w.mounts {
+		if !slices.Contains(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	w.lastCheck = now
+	w.mu.Unlock()
+	slices.Sort(hosts)
+
+	var services []platform.ServiceStack
+	var servicesErr error
+	listed := false
+	for _, host := range hosts {
+		state, err := w.mounter.CheckMount(ctx, filepath.Join(mountBase, host))
+		if err != nil {
+			continue
+		}
+		// A failed remount may leave nothing mounted at all; that host is
+		// still owed a retry, unlike one that was unmounted on purpose.
+		failed := w.stateOf(host) == MountWatchFailed
+		switch {
+		case state == platform.MountStateActive:
+			w.observe(host, MountWatchActive, now, "info", "mount is active")
+		case state == platform.MountStateNotMounted && !failed:
+			w.forget(ctx, host)
+		default:
+			if !w.dueForRemount(host, now) {
+				continue
+			}
+			if !listed {
+				services, servicesErr = w.client.ListServices(ctx, w.projectID)
+				listed = true
+			}
+			if servicesErr == nil {
+				if _, err := FindService(services, host); err != nil {
+					w.observe(host, MountWatchGone, now, "warning", "service no longer exists; run zerops_mount action=unmount to clean up")
+					continue
+				}
+			}
+			if !failed {
+				w.observe(host, MountWatchStale, now, "warning", "mount went stale (transport disconnected), remounting")
+			}
+			w.remount(ctx, host)
+		}
+	}
+}
+
+// Status returns a snapshot, limited to hostname when non-empty.
+func (w *MountWatchdog) Status(hostname string) *MountWatchdogStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	status := &MountWatchdogStatus{Interval: w.interval.String(), Mounts: []MountWatch{}}
+	if !w.lastCheck.IsZero() {
+		last := w.lastCheck
+		status.LastCheck = &last
+	}
+	for _, host := range slices.Sorted(maps.Keys(w.mounts)) {
+		if hostname != "" && host != hostname {
+			continue
+		}
+		m := *w.mounts[host]
+		m.History = slices.Clone(m.History)
+		status.Mounts = append(status.Mounts, m)
+	}
+	return status
+}
+
+// Forget stops watching hostname. zerops_mount unmount calls it so a
+// mount whose remount kept failing is not retried after the agent gave
+// up on it.
+func (w *MountWatchdog) Forget(hostname string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.mounts, hostname)
+}
+
+func (w *MountWatchdog) stateOf(host string) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if m, ok := w.mounts[host]; ok {
+		return m.State
+	}
+	return ""
+}
+
+// observe records the current state of host, emitting an event when it
+// differs from the last one seen. The first sighting of an active mount
+// is recorded silently.
+func (w *MountWatchdog) observe(host, state string, now time.Time, level, message string) {
+	w.mu.Lock()
+	m, ok := w.mounts[host]
+	if !ok {
+		m = &MountWatch{Hostname: host, LastChange: now}
+		w.mounts[host] = m
+	}
+	m.LastCheck = now
+	from := m.State
+	if from == state || (!ok && state == MountWatchActive) {
+		m.State = state
+		w.mu.Unlock()
+		return
+	}
+	m.State = state
+	m.LastChange = now
+	ev := w.record(m, MountWatchEvent{Time: now, Hostname: host, From: from, To: state, Level: level, Message: message})
+	w.mu.Unlock()
+	w.emit(ev)
+}
+
+// dueForRemount reports whether a stale host's backoff has elapsed.
+func (w *MountWatchdog) dueForRemount(host string, now time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	m, ok := w.mounts[host]
+	if !ok || m.NextAttempt == nil {
+		return true
+	}
+	if now.Before(*m.NextAttempt) {
+		m.LastCheck = now
+		return false
+	}
+	return true
+}
+
+func (w *MountWatchdog) remount(ctx context.Context, host string) {
+	_, err := MountService(ctx, w.client, w.projectID, w.mounter, host)
+	now := w.now()
+
+	w.mu.Lock()
+	m, ok := w.mounts[host]
+	if !ok {
+		// Forgotten by an unmount while the remount was running.
+		w.mu.Unlock()
+		return
+	}
+	var ev MountWatchEvent
+	if err != nil {
+		m.Failures++
+		delay := mountRemountMaxDelay
+		if m.Failures <= 5 {
+			delay = min(mountRemountBackoff<<(m.Failures-1), mountRemountMaxDelay)
+		}
+		next := now.Add(delay)
+		m.NextAttempt = &next
+		m.State, m.LastChange = MountWatchFailed, now
+		ev = w.record(m, MountWatchEvent{Time: now, Hostname: host, From: MountWatchStale, To: MountWatchFailed, Level: "error",
+			Message: fmt.Sprintf("remount attempt %d failed: %v; retrying in %s", m.Failures, err, delay)})
+	} else {
+		m.Failures, m.NextAttempt = 0, nil
+		m.State, m.LastChange = MountWatchActive, now
+		ev = w.record(m, MountWatchEvent{Time: now, Hostname: host, From: MountWatchStale, To: MountWatchActive, Level: "info",
+			Message: "remounted"})
+	}
+	w.mu.Unlock()
+	w.emit(ev)
+}
+
+// forget drops a host that is no longer mounted. A leftover systemd unit
+// means the mount is half-created, not removed, so it stays watched.
+func (w *MountWatchdog) forget(ctx context.Context, host string) {
+	w.mu.Lock()
+	_, watched := w.mounts[host]
+	w.mu.Unlock()
+	if !watched {
+		return
+	}
+	if hasUnit, _ := w.mounter.HasUnit(ctx, host); hasUnit {
+		return
+	}
+	w.mu.Lock()
+	delete(w.mounts, host)
+	w.mu.Unlock()
+}
+
+// record appends ev to the mount's bounded history. Caller holds w.mu.
+func (w *MountWatchdog) record(m *MountWatch, ev MountWatchEvent) MountWatchEvent {
+	m.History = append(m.History, ev)
+	if len(m.History) > mountWatchHistoryMax {
+		m.History = m.History[len(m.History)-mountWatchHistoryMax:]
+	}
+	return ev
+}
+
+func (w *MountWatchdog) emit(ev MountWatchEvent) {
+	if w.notify != nil {
+		w.notify(ev)
+	}
+}
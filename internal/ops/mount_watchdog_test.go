@@ -0,0 +1,78 @@
+// Tests for: ops/mount_watchdog.go — background stale-mount remounting.
+package ops
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+func TestMountWatchdog_RemountWithBackoff(t *testing.T) {
+	t.Parallel()
+
+	mounter := newMockMounter()
+	mounter.mountDirs = []string{".claude", "app", "ghost", "worker"}
+	mounter.states["/var/www/app"] = platform.MountStateActive
+	mounter.states["/var/www/worker"] = platform.MountStateActive
+	mounter.states["/var/www/ghost"] = platform.MountStateStale
+
+	var events []string
+	w := NewMountWatchdog(platform.NewMock().WithServices(testServices()), "proj-1", mounter, func(ev MountWatchEvent) {
+		events = append(events, ev.Hostname+":"+ev.From+"→"+ev.To+":"+ev.Level)
+	})
+	clock := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	w.now = func() time.Time { return clock }
+	ctx := context.Background()
+
+	w.Check(ctx)
+	if strings.Join(events, ",") != "ghost:→gone:warning" {
+		t.Fatalf("first pass events = %v; active mounts are recorded silently, deleted services are not remounted", events)
+	}
+
+	// Target redeployed: app goes stale and the first remount fails.
+	events = nil
+	mounter.states["/var/www/app"] = platform.MountStateStale
+	mounter.mountErr = errors.New("connection refused")
+	w.Check(ctx)
+	if strings.Join(events, ",") != "app:active→stale:warning,app:stale→remount-failed:error" {
+		t.Fatalf("stale pass events = %v", events)
+	}
+
+	// Within the backoff window nothing is retried.
+	events = nil
+	clock = clock.Add(5 * time.Second)
+	mounter.mountErr = nil
+	w.Check(ctx)
+	if len(events) != 0 {
+		t.Fatalf("events inside backoff = %v", events)
+	}
+
+	// After the backoff the remount succeeds even though the failed
+	// attempt left nothing mounted.
+	clock = clock.Add(6 * time.Second)
+	w.Check(ctx)
+	if strings.Join(events, ",") != "app:stale→active:info" {
+		t.Fatalf("retry events = %v", events)
+	}
+
+	// A deliberate unmount drops the mount from the watch list.
+	delete(mounter.states, "/var/www/worker")
+	w.Check(ctx)
+
+	status := w.Status("")
+	var hosts []string
+	for _, m := range status.Mounts {
+		hosts = append(hosts, m.Hostname+":"+m.State)
+	}
+	if strings.Join(hosts, ",") != "app:active,ghost:gone" || status.LastCheck == nil || !status.LastCheck.Equal(clock) {
+		t.Errorf("status = %+v", status)
+	}
+	app := w.Status("app").Mounts[0]
+	if len(app.History) != 3 || app.Failures != 0 || app.NextAttempt != nil {
+		t.Errorf("app = %+v", app)
+	}
+}
@@ -0,0 +1,648 @@
+package ops
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/zeropsio/zcp/internal/platform"
+	"gopkg.in/yaml.v3"
+)
+
+// ScalingFileName is the project scaling-profile file inside the .zcp
+// directory.
+const ScalingFileName = "scaling.yaml"
+
+// Scaling profile service classes.
+const (
+	ScaleClassRuntime = "runtime"
+	ScaleClassManaged = "managed"
+)
+
+// ScalingConfig is .zcp/scaling.yaml: named profiles zerops_scale applies
+// across services. A profile with the same name as a built-in tier
+// replaces it.
+//
+//	profiles:
+//	  dev:
+//	    runtime: {minRam: 0.25, maxRam: 1, minContainers: 1, maxContainers: 1}
+//	    managed: {minRam: 0.25}
+//	  prod:
+//	    mode: HA
+//	    runtime: {cpuMode: DEDICATED, minRam: 1, maxRam: 8, minContainers: 2, maxContainers: 6}
+//	    managed: {minRam: 1, minFreeRamGB: 0.5}
+//	    services:
+//	      search: {minRam: 4}
+type ScalingConfig struct {
+	Profiles map[string]ScalingProfile `yaml:"profiles"`
+}
+
+// ScalingProfile sizes every service of a class; Services overrides
+// individual fields per hostname on top of the class defaults.
+type ScalingProfile struct {
+	// Mode is the service mode (HA / NON_HA) the profile assumes. Mode is
+	// fixed at creation, so a mismatch is reported as a warning only.
+	Mode     string                  `yaml:"mode"`
+	Runtime  *ProfileScale           `yaml:"runtime"`
+	Managed  *ProfileScale           `yaml:"managed"`
+	Services map[string]ProfileScale `yaml:"services"`
+}
+
+// ProfileScale is one set of scaling values in a profile. Keys match the
+// zerops_scale parameters; unset fields leave the service's value alone.
+type ProfileScale struct {
+	CPUMode           *string  `yaml:"cpuMode"`
+	MinCPU            *int     `yaml:"minCpu"`
+	MaxCPU            *int     `yaml:"maxCpu"`
+	StartCPU          *int     `yaml:"startCpu"`
+	MinRAM            *float64 `yaml:"minRam"`
+	MaxRAM            *float64 `yaml:"maxRam"`
+	MinDisk           *float64 `yaml:"minDisk"`
+	MaxDisk           *float64 `yaml:"maxDisk"`
+	MinContainers     *int     `yaml:"minContainers"`
+	MaxContainers     *int     `yaml:"maxContainers"`
+	MinFreeRAMGB      *float64 `yaml:"minFreeRamGB"`
+	MinFreeRAMPercent *float64 `yaml:"minFreeRamPercent"`
+	MinFreeCPUCores   *float64 `yaml:"minFreeCpuCores"`
+	MinFreeCPUPercent *float64 `yaml:"minFreeCpuPercent"`
+}
+
+// Params converts the profile values to ScaleParams.
+func (s ProfileScale) Params() ScaleParams {
+	return ScaleParams(s)
+}
+
+// override returns s with every field set in o replacing its counterpart.
+func (s ProfileScale) override(o ProfileScale) ProfileScale {
+	pick := func(dst **float64, src *float64) {
+		if src != nil {
+			*dst = src
+		}
+	}
+	pickInt := func(dst **int, src *int) {
+		if src != nil {
+			*dst = src
+		}
+	}
+	if o.CPUMode != nil {
+		s.CPUMode = o.CPUMode
+	}
+	pickInt(&s.MinCPU, o.MinCPU)
+	pickInt(&s.MaxCPU, o.MaxCPU)
+	pickInt(&s.StartCPU, o.StartCPU)
+	pick(&s.MinRAM, o.MinRAM)
+	pick(&s.MaxRAM, o.MaxRAM)
+	pick(&s.MinDisk, o.MinDisk)
+	pick(&s.MaxDisk, o.MaxDisk)
+	pickInt(&s.MinContainers, o.MinContainers)
+	pickInt(&s.MaxContainers, o.MaxContainers)
+	pick(&s.MinFreeRAMGB, o.MinFreeRAMGB)
+	pick(&s.MinFreeRAMPercent, o.MinFreeRAMPercent)
+	pick(&s.MinFreeCPUCores, o.MinFreeCPUCores)
+	pick(&s.MinFreeCPUPercent, o.MinFreeCPUPercent)
+	return s
+}
+
+// ScalingConfigPath returns the scaling-profile file location for a state
+// dir (.zcp/state → .zcp/scaling.yaml).
+func ScalingConfigPath(stateDir string) string {
+	return filepath.Join(filepath.Dir(stateDir), ScalingFileName)
+}
+
+// LoadScalingConfig reads and validates the scaling-profile file. A
+// missing file returns (nil, nil).
+func LoadScalingConfig(path string) (*ScalingConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	var cfg ScalingConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		if errors.Is(err, io.EOF) {
+			return &cfg, nil
+		}
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for name, p := range cfg.Profiles {
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("parse %s: profiles.%s: %w", path, name, err)
+		}
+	}
+	return &cfg, nil
+}
+
+func (p ScalingProfile) validate() error {
+	if p.Mode != "" && p.Mode != "HA" && p.Mode != "NON_HA" {
+		return fmt.Errorf("mode %q: use HA or NON_HA", p.Mode)
+	}
+	if p.Runtime == nil && p.Managed == nil && len(p.Services) == 0 {
+		return errors.New("defines no runtime, managed or services sizing")
+	}
+	check := func(where string, s *ProfileScale) error {
+		if s == nil {
+			return nil
+		}
+		if err := validateScaleParams(s.Params()); err != nil {
+			return fmt.Errorf("%s: %w", where, err)
+		}
+		return nil
+	}
+	if err := check(ScaleClassRuntime, p.Runtime); err != nil {
+		return err
+	}
+	if err := check(ScaleClassManaged, p.Managed); err != nil {
+		return err
+	}
+	for host, s := range p.Services {
+		if err := check("services."+host, &s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveScalingProfile looks name up in the project file first, then in
+// builtins. source is ScalingFileName or "builtin".
+func ResolveScalingProfile(cfg *ScalingConfig, builtins map[string]ScalingProfile, name string) (ScalingProfile, string, error) {
+	if cfg != nil {
+		if p, ok := cfg.Profiles[name]; ok {
+			return p, ScalingFileName, nil
+		}
+	}
+	if p, ok := builtins[name]; ok {
+		return p, "builtin", nil
+	}
+	names := slices.Collect(maps.Keys(builtins))
+	if cfg != nil {
+		for n := range cfg.Profiles {
+			if !slices.Contains(names, n) {
+				names = append(names, n)
+			}
+		}
+	}
+	slices.Sort(names)
+	return ScalingProfile{}, "", platform.NewPlatformError(platform.ErrInvalidParameter,
+		fmt.Sprintf("Unknown scaling profile %q", name),
+		"Available profiles: "+strings.Join(names, ", "))
+}
+
+// ScaleFieldChange is one scaling value a profile changes.
+type ScaleFieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"` // "" when the service reports no value
+	To    string `json:"to"`
+}
+
+// ScaleProfileEntry is the planned change for one service. Params holds
+// only the changed fields — what Apply sends.
+type ScaleProfileEntry struct {
+	Hostname  string             `json:"serviceHostname"`
+	ServiceID string             `json:"serviceId"`
+	Class     string             `json:"class,omitempty"`
+	Changes   []ScaleFieldChange `json:"changes,omitempty"`
+	Skipped   string             `json:"skipped,omitempty"`
+	Warnings  []string           `json:"warnings,omitempty"`
+	Params    ScaleParams        `json:"-"`
+
+	mode    string
+	current *platform.CustomAutoscaling // nil when the service reported none
+}
+
+// ScaleProfilePlan is the dry-run diff of a profile against the services'
+// active autoscaling (GetService detail).
+type ScaleProfilePlan struct {
+	Profile  string              `json:"profile"`
+	Source   string              `json:"source"`
+	Summary  string              `json:"summary"`
+	Services []ScaleProfileEntry `json:"services"`
+}
+
+// PlanScaleProfile diffs profile against each service in hostnames (every
+// non-system service when empty). Bounds the profile leaves unset are
+// widened when the new value would cross them (minRam above the current
+// maxRam raises maxRam too), so every planned change is valid on its own.
+func PlanScaleProfile(
+	ctx context.Context,
+	client platform.Client,
+	projectID string,
+	name, source string,
+	profile ScalingProfile,
+	hostnames []string,
+) (*ScaleProfilePlan, error) {
+	services, err := ListProjectServices(ctx, client, projectID)
+	if err != nil {
+		return nil, err
+	}
+	var targets []*platform.ServiceStack
+	if len(hostnames) == 0 {
+		for i := range services {
+			if !services[i].IsSystem() {
+				targets = append(targets, &services[i])
+			}
+		}
+	} else {
+		for _, h := range hostnames {
+			svc, err := FindService(services, h)
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, svc)
+		}
+	}
+
+	plan := &ScaleProfilePlan{Profile: name, Source: source}
+	changed, unchanged, skipped := 0, 0, 0
+	for _, svc := range targets {
+		if !svc.IsSystem() {
+			// ListServices omits active autoscaling; fetch detail.
+			detail, err := client.GetService(ctx, svc.ID)
+			if err != nil {
+				return nil, fmt.Errorf("get service %s: %w", svc.Name, err)
+			}
+			svc = detail
+		}
+		entry := planScaleEntry(svc, profile)
+		switch {
+		case entry.Skipped != "":
+			skipped++
+		case len(entry.Changes) == 0:
+			unchanged++
+		default:
+			changed++
+		}
+		plan.Services = append(plan.Services, entry)
+	}
+	plan.Summary = fmt.Sprintf("%d service(s) to change, %d unchanged, %d skipped", changed, unchanged, skipped)
+	return plan, nil
+}
+
+func planScaleEntry(svc *platform.ServiceStack, profile ScalingProfile) ScaleProfileEntry {
+	entry := ScaleProfileEntry{Hostname: svc.Name, ServiceID: svc.ID, mode: svc.Mode}
+	typeName := svc.ServiceStackTypeInfo.ServiceStackTypeVersionName
+	category := svc.ServiceStackTypeInfo.ServiceStackTypeCategoryName
+	switch {
+	case svc.IsSystem():
+		entry.Skipped = "system service"
+		return entry
+	case category == "OBJECT_STORAGE":
+		entry.Skipped = "object storage has no autoscaling"
+		return entry
+	case strings.HasPrefix(strings.ToLower(typeName), "docker"):
+		entry.Skipped = "docker services have no autoscaling"
+		return entry
+	case isManagedCategory(category):
+		entry.Class = ScaleClassManaged
+	default:
+		entry.Class = ScaleClassRuntime
+	}
+
+	var want ProfileScale
+	base := profile.Runtime
+	if entry.Class == ScaleClassManaged {
+		base = profile.Managed
+	}
+	override, hasOverride := profile.Services[svc.Name]
+	if base == nil && !hasOverride {
+		entry.Skipped = fmt.Sprintf("profile defines no %s sizing", entry.Class)
+		return entry
+	}
+	if base != nil {
+		want = *base
+	}
+	want = want.override(override)
+
+	if entry.Class == ScaleClassManaged && (want.MinContainers != nil || want.MaxContainers != nil) {
+		want.MinContainers, want.MaxContainers = nil, nil
+		entry.Warnings = append(entry.Warnings, "container count of managed services is fixed by mode; minContainers/maxContainers ignored")
+	}
+	if profile.Mode != "" && svc.Mode != "" && profile.Mode != svc.Mode {
+		entry.Warnings = append(entry.Warnings, fmt.Sprintf(
+			"profile assumes mode %s but service is %s; mode is fixed at creation", profile.Mode, svc.Mode))
+	}
+
+	cur := svc.CurrentAutoscaling
+	if cur == nil {
+		cur = svc.CustomAutoscaling
+	}
+	entry.current = cur
+	if cur == nil {
+		cur = &platform.CustomAutoscaling{}
+		entry.Warnings = append(entry.Warnings, "service reports no current autoscaling; every profile value is listed as a change")
+	}
+	entry.Params, entry.Changes = diffScale(want, cur)
+	return entry
+}
+
+// diffScale returns the fields of want that differ from cur as both the
+// ScaleParams to send and their rendered changes.
+func diffScale(want ProfileScale, cur *platform.CustomAutoscaling) (ScaleParams, []ScaleFieldChange) {
+	widenInt(&want.MinCPU, &want.MaxCPU, cur.MinCPU, cur.MaxCPU)
+	widenInt(&want.MinContainers, &want.MaxContainers, cur.HorizontalMinCount, cur.HorizontalMaxCount)
+	widenFloat(&want.MinRAM, &want.MaxRAM, cur.MinRAM, cur.MaxRAM)
+	widenFloat(&want.MinDisk, &want.MaxDisk, cur.MinDisk, cur.MaxDisk)
+
+	var p ScaleParams
+	var changes []ScaleFieldChange
+	diffInt := func(field string, want *int, have int32, dst **int) {
+		if want != nil && int32(*want) != have { //nolint:gosec // scaling values are small
+			*dst = want
+			changes = append(changes, ScaleFieldChange{Field: field, From: fmt.Sprint(have), To: fmt.Sprint(*want)})
+		}
+	}
+	diffFloat := func(field string, want *float64, have float64, dst **float64) {
+		if want != nil && *want != have {
+			*dst = want
+			changes = append(changes, ScaleFieldChange{Field: field, From: fmt.Sprintf("%g", have), To: fmt.Sprintf("%g", *want)})
+		}
+	}
+	if want.CPUMode != nil && *want.CPUMode != cur.CPUMode {
+		p.CPUMode = want.CPUMode
+		changes = append(changes, ScaleFieldChange{Field: "cpuMode", From: cur.CPUMode, To: *want.CPUMode})
+	}
+	diffInt("minCpu", want.MinCPU, cur.MinCPU, &p.MinCPU)
+	diffInt("maxCpu", want.MaxCPU, cur.MaxCPU, &p.MaxCPU)
+	diffInt("startCpu", want.StartCPU, cur.StartCPUCoreCount, &p.StartCPU)
+	diffFloat("minRam", want.MinRAM, cur.MinRAM, &p.MinRAM)
+	diffFloat("maxRam", want.MaxRAM, cur.MaxRAM, &p.MaxRAM)
+	diffFloat("minDisk", want.MinDisk, cur.MinDisk, &p.MinDisk)
+	diffFloat("maxDisk", want.MaxDisk, cur.MaxDisk, &p.MaxDisk)
+	diffInt("minContainers", want.MinContainers, cur.HorizontalMinCount, &p.MinContainers)
+	diffInt("maxContainers", want.MaxContainers, cur.HorizontalMaxCount, &p.MaxContainers)
+	diffFloat("minFreeRamGB", want.MinFreeRAMGB, cur.MinFreeRAMGB, &p.MinFreeRAMGB)
+	diffFloat("minFreeRamPercent", want.MinFreeRAMPercent, cur.MinFreeRAMPercent, &p.MinFreeRAMPercent)
+	diffFloat("minFreeCpuCores", want.MinFreeCPUCores, cur.MinFreeCPUCores, &p.MinFreeCPUCores)
+	diffFloat("minFreeCpuPercent", want.MinFreeCPUPercent, cur.MinFreeCPUPercent, &p.MinFreeCPUPercent)
+	return p, changes
+}
+
+// widenInt moves the unset bound of a min/max pair when the set one would
+// cross the service's current value. A zero current max means unknown.
+func widenInt(minV, maxV **int, curMin, curMax int32) {
+	switch {
+	case *minV != nil && *maxV == nil && curMax > 0 && int32(**minV) > curMax: //nolint:gosec // scaling values are small
+		v := **minV
+		*maxV = &v
+	case *maxV != nil && *minV == nil && int32(**maxV) < curMin: //nolint:gosec // scaling values are small
+		v := **maxV
+		*minV = &v
+	}
+}
+
+func widenFloat(minV, maxV **float64, curMin, curMax float64) {
+	switch {
+	case *minV != nil && *maxV == nil && curMax > 0 && **minV > curMax:
+		v := **minV
+		*maxV = &v
+	case *maxV != nil && *minV == nil && **maxV < curMin:
+		v := **maxV
+		*minV = &v
+	}
+}
+
+// ScaleProfileEntryResult is the outcome of applying one plan entry.
+// RolledBack is set when the entry was applied and then reverted because
+// another service failed.
+type ScaleProfileEntryResult struct {
+	ScaleResult
+	Changes       []ScaleFieldChange `json:"changes"`
+	Error         string             `json:"error,omitempty"`
+	RolledBack    bool               `json:"rolledBack,omitempty"`
+	RollbackError string             `json:"rollbackError,omitempty"`
+}
+
+// ScaleProfileResult aggregates a profile apply. Succeeded counts the
+// services whose new scaling stayed in place.
+type ScaleProfileResult struct {
+	Profile    string                    `json:"profile"`
+	Source     string                    `json:"source"`
+	Summary    string                    `json:"summary"`
+	Succeeded  int                       `json:"succeeded"`
+	Failed     int                       `json:"failed"`
+	RolledBack int                       `json:"rolledBack,omitempty"`
+	Services   []ScaleProfileEntryResult `json:"services"`
+	Skipped    []ScaleProfileEntry       `json:"skipped,omitempty"`
+}
+
+// ApplyScaleProfile applies the plan all-or-nothing as far as the API
+// allows. Every changed entry is validated against the service's current
+// scaling first; one invalid entry aborts before anything is sent. The
+// entries are then sent in parallel and, when poll is non-nil, each
+// scaling process is awaited through it. If any entry fails, the entries
+// that did apply are reverted to their pre-apply values; a revert that
+// fails itself is reported in RollbackError. Unchanged and skipped
+// entries are reported under Skipped.
+func ApplyScaleProfile(
+	ctx context.Context,
+	client platform.Client,
+	plan *ScaleProfilePlan,
+	poll func(context.Context, *platform.Process) *platform.Process,
+) (*ScaleProfileResult, error) {
+	out := &ScaleProfileResult{Profile: plan.Profile, Source: plan.Source}
+	var pending []ScaleProfileEntry
+	for _, e := range plan.Services {
+		if e.Skipped != "" || len(e.Changes) == 0 {
+			if e.Skipped == "" {
+				e.Skipped = "already matches profile"
+			}
+			out.Skipped = append(out.Skipped, e)
+			continue
+		}
+		if err := validateScaleParams(effectiveScale(e.Params, e.current)); err != nil {
+			var pe *platform.PlatformError
+			if errors.As(err, &pe) {
+				return nil, platform.NewPlatformError(pe.Code,
+					fmt.Sprintf("%s: %s; no service was scaled", e.Hostname, pe.Message),
+					"Adjust the profile or its services override for this service")
+			}
+			return nil, err
+		}
+		pending = append(pending, e)
+	}
+
+	out.Services = make([]ScaleProfileEntryResult, len(pending))
+	runScaleEntries(len(pending), func(i int) {
+		e := pending[i]
+		r := ScaleProfileEntryResult{
+			ScaleResult: ScaleResult{Hostname: e.Hostname, ServiceID: e.ServiceID},
+			Changes:     e.Changes,
+		}
+		r.Process, r.Message, r.Error = sendScale(ctx, client, e.ServiceID, e.mode, e.Params, poll)
+		out.Services[i] = r
+	})
+
+	for _, r := range out.Services {
+		if r.Error != "" {
+			out.Failed++
+		}
+	}
+	if out.Failed > 0 {
+		rollbackScaleEntries(ctx, client, pending, out.Services, poll)
+	}
+	for _, r := range out.Services {
+		switch {
+		case r.Error != "":
+		case r.RolledBack:
+			out.RolledBack++
+		default:
+			out.Succeeded++
+		}
+	}
+
+	out.Summary = fmt.Sprintf("%d/%d scaled", out.Succeeded, len(pending))
+	if out.Failed > 0 {
+		out.Summary += fmt.Sprintf(", %d failed, %d rolled back", out.Failed, out.RolledBack)
+		if stuck := len(pending) - out.Failed - out.RolledBack - out.Succeeded; stuck > 0 {
+			out.Summary += fmt.Sprintf(", %d could not be rolled back", stuck)
+		}
+	}
+	return out, nil
+}
+
+// rollbackScaleEntries reverts every applied entry of results to the
+// values it had before the apply, in parallel.
+func rollbackScaleEntries(
+	ctx context.Context,
+	client platform.Client,
+	entries []ScaleProfileEntry,
+	results []ScaleProfileEntryResult,
+	poll func(context.Context, *platform.Process) *platform.Process,
+) {
+	runScaleEntries(len(entries), func(i int) {
+		e, r := entries[i], &results[i]
+		if r.Error != "" {
+			return
+		}
+		if e.current == nil {
+			r.RollbackError = "previous scaling unknown; revert manually"
+			return
+		}
+		if _, _, errMsg := sendScale(ctx, client, e.ServiceID, e.mode, revertScale(e.Params, e.current), poll); errMsg != "" {
+			r.RollbackError = errMsg
+			return
+		}
+		r.RolledBack = true
+	})
+}
+
+// runScaleEntries calls fn for each index in its own goroutine and waits.
+func runScaleEntries(n int, fn func(i int)) {
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() { fn(i) })
+	}
+	wg.Wait()
+}
+
+// sendScale sends one SetAutoscaling call and awaits its process through
+// poll. A non-empty errMsg means the change did not take effect.
+func sendScale(
+	ctx context.Context,
+	client platform.Client,
+	serviceID, mode string,
+	params ScaleParams,
+	poll func(context.Context, *platform.Process) *platform.Process,
+) (proc *platform.Process, message, errMsg string) {
+	apiParams := buildAutoscalingParams(params)
+	apiParams.ServiceMode = mode
+	proc, err := client.SetAutoscaling(ctx, serviceID, apiParams)
+	if err != nil {
+		return nil, "", err.Error()
+	}
+	if proc == nil {
+		return nil, "Scaling parameters updated", ""
+	}
+	if poll != nil {
+		proc = poll(ctx, proc)
+	}
+	if proc.Status == statusFailed || proc.Status == statusCanceled {
+		return proc, "", fmt.Sprintf("scaling process %s", strings.ToLower(proc.Status))
+	}
+	return proc, "", ""
+}
+
+// effectiveScale is the scaling a service ends up with once p is applied
+// on top of cur, limited to the fields validateScaleParams checks.
+func effectiveScale(p ScaleParams, cur *platform.CustomAutoscaling) ScaleParams {
+	if cur == nil {
+		return p
+	}
+	intOr := func(v *int, have int32) *int {
+		if v != nil {
+			return v
+		}
+		return ptrTo(int(have))
+	}
+	floatOr := func(v *float64, have float64) *float64 {
+		if v != nil {
+			return v
+		}
+		return ptrTo(have)
+	}
+	eff := p
+	eff.MinCPU, eff.MaxCPU = intOr(p.MinCPU, cur.MinCPU), intOr(p.MaxCPU, cur.MaxCPU)
+	eff.MinRAM, eff.MaxRAM = floatOr(p.MinRAM, cur.MinRAM), floatOr(p.MaxRAM, cur.MaxRAM)
+	eff.MinDisk, eff.MaxDisk = floatOr(p.MinDisk, cur.MinDisk), floatOr(p.MaxDisk, cur.MaxDisk)
+	eff.MinContainers = intOr(p.MinContainers, cur.HorizontalMinCount)
+	eff.MaxContainers = intOr(p.MaxContainers, cur.HorizontalMaxCount)
+	// A zero current max means unknown, not a bound.
+	if p.MaxCPU == nil && cur.MaxCPU == 0 {
+		eff.MaxCPU = nil
+	}
+	if p.MaxRAM == nil && cur.MaxRAM == 0 {
+		eff.MaxRAM = nil
+	}
+	if p.MaxDisk == nil && cur.MaxDisk == 0 {
+		eff.MaxDisk = nil
+	}
+	if p.MaxContainers == nil && cur.HorizontalMaxCount == 0 {
+		eff.MaxContainers = nil
+	}
+	return eff
+}
+
+// revertScale sets every field p changes back to its value in cur.
+func revertScale(p ScaleParams, cur *platform.CustomAutoscaling) ScaleParams {
+	var r ScaleParams
+	revInt := func(v *int, have int32) *int {
+		if v == nil {
+			return nil
+		}
+		return ptrTo(int(have))
+	}
+	revFloat := func(v *float64, have float64) *float64 {
+		if v == nil {
+			return nil
+		}
+		return ptrTo(have)
+	}
+	if p.CPUMode != nil && cur.CPUMode != "" {
+		r.CPUMode = ptrTo(cur.CPUMode)
+	}
+	r.MinCPU, r.MaxCPU = revInt(p.MinCPU, cur.MinCPU), revInt(p.MaxCPU, cur.MaxCPU)
+	r.StartCPU = revInt(p.StartCPU, cur.StartCPUCoreCount)
+	r.MinRAM, r.MaxRAM = revFloat(p.MinRAM, cur.MinRAM), revFloat(p.MaxRAM, cur.MaxRAM)
+	r.MinDisk, r.MaxDisk = revFloat(p.MinDisk, cur.MinDisk), revFloat(p.MaxDisk, cur.MaxDisk)
+	r.MinContainers = revInt(p.MinContainers, cur.HorizontalMinCount)
+	r.MaxContainers = revInt(p.MaxContainers, cur.HorizontalMaxCount)
+	r.MinFreeRAMGB = revFloat(p.MinFreeRAMGB, cur.MinFreeRAMGB)
+	r.MinFreeRAMPercent = revFloat(p.MinFreeRAMPercent, cur.MinFreeRAMPercent)
+	r.MinFreeCPUCores = revFloat(p.MinFreeCPUCores, cur.MinFreeCPUCores)
+	r.MinFreeCPUPercent = revFloat(p.MinFreeCPUPercent, cur.MinFreeCPUPercent)
+	return r
+}
+
+func ptrTo[T any](v T) *T { return &v }
@@ -0,0 +1,328 @@
+// Tests for: ops/scale_profile.go — named scaling profiles.
+package ops
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// recordingScaleMock records SetAutoscaling calls per service ID and
+// fails the ones listed in fail.
+type recordingScaleMock struct {
+	*platform.Mock
+	mu    sync.Mutex
+	calls map[string][]platform.AutoscalingParams
+	fail  map[string]bool
+}
+
+func (m *recordingScaleMock) SetAutoscaling(ctx context.Context, serviceID string, params platform.AutoscalingParams) (*platform.Process, error) {
+	m.mu.Lock()
+	m.calls[serviceID] = append(m.calls[serviceID], params)
+	m.mu.Unlock()
+	if m.fail[serviceID] {
+		return nil, errors.New("boom")
+	}
+	return m.Mock.SetAutoscaling(ctx, serviceID, params)
+}
+
+func scaleProfileMock() *platform.Mock {
+	return platform.NewMock().WithServices([]platform.ServiceStack{
+		{ID: "svc-api", Name: "api", Mode: "NON_HA",
+			ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "nodejs@22", ServiceStackTypeCategoryName: "USER"},
+			CurrentAutoscaling: &platform.CustomAutoscaling{
+				CPUMode: "SHARED", MinCPU: 1, MaxCPU: 5, MinRAM: 0.25, MaxRAM: 0.5, HorizontalMinCount: 1, HorizontalMaxCount: 1,
+			}},
+		{ID: "svc-db", Name: "db", Mode: "NON_HA",
+			ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "postgresql@16", ServiceStackTypeCategoryName: "STANDARD"},
+			CurrentAutoscaling:   &platform.CustomAutoscaling{CPUMode: "SHARED", MinRAM: 0.25, MaxRAM: 4}},
+		{ID: "svc-files", Name: "files",
+			ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "object-storage", ServiceStackTypeCategoryName: "OBJECT_STORAGE"}},
+		{ID: "svc-l7", Name: "l7",
+			ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "l7-http-balancer", ServiceStackTypeCategoryName: "HTTP_L7_BALANCER"}},
+	})
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func prodProfile() ScalingProfile {
+	return ScalingProfile{
+		Mode:    "HA",
+		Runtime: &ProfileScale{CPUMode: ptr("DEDICATED"), MinRAM: ptr(1.0), MinContainers: ptr(2)},
+		Managed: &ProfileScale{MinRAM: ptr(0.25), MinContainers: ptr(3)},
+	}
+}
+
+func TestPlanScaleProfile(t *testing.T) {
+	t.Parallel()
+
+	plan, err := PlanScaleProfile(context.Background(), scaleProfileMock(), "proj-1", "prod", ScalingFileName, prodProfile(), nil)
+	if err != nil {
+		t.Fatalf("PlanScaleProfile: %v", err)
+	}
+	if plan.Summary != "1 service(s) to change, 1 unchanged, 1 skipped" || len(plan.Services) != 3 {
+		t.Fatalf("plan = %+v", plan)
+	}
+
+	api := plan.Services[0]
+	got := make([]string, 0, len(api.Changes))
+	for _, c := range api.Changes {
+		got = append(got, c.Field+" "+c.From+"→"+c.To)
+	}
+	// minRam 1 crosses the current maxRam 0.5 and minContainers 2 the
+	// current max 1, so both upper bounds follow.
+	want := "cpuMode SHARED→DEDICATED, minRam 0.25→1, maxRam 0.5→1, minContainers 1→2, maxContainers 1→2"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("api changes = %s\nwant %s", strings.Join(got, ", "), want)
+	}
+	if api.Class != ScaleClassRuntime || api.Params.MaxRAM == nil || *api.Params.MaxRAM != 1 || api.Params.MinCPU != nil {
+		t.Errorf("api entry = %+v, params = %+v", api, api.Params)
+	}
+	if len(api.Warnings) != 1 || !strings.Contains(api.Warnings[0], "mode HA but service is NON_HA") {
+		t.Errorf("api warnings = %v", api.Warnings)
+	}
+
+	db := plan.Services[1]
+	if db.Class != ScaleClassManaged || len(db.Changes) != 0 || len(db.Warnings) != 2 {
+		t.Errorf("db entry = %+v; containers must be dropped for managed services", db)
+	}
+	if plan.Services[2].Skipped != "object storage has no autoscaling" {
+		t.Errorf("files entry = %+v", plan.Services[2])
+	}
+}
+
+func TestPlanScaleProfile_ServiceOverrideAndUnknownHost(t *testing.T) {
+	t.Parallel()
+
+	profile := ScalingProfile{
+		Managed:  &ProfileScale{MinRAM: ptr(0.5)},
+		Services: map[string]ProfileScale{"db": {MaxRAM: ptr(16.0)}},
+	}
+	plan, err := PlanScaleProfile(context.Background(), scaleProfileMock(), "proj-1", "x", "builtin", profile, []string{"db", "api"})
+	if err != nil {
+		t.Fatalf("PlanScaleProfile: %v", err)
+	}
+	if n := len(plan.Services[0].Changes); n != 2 {
+		t.Errorf("db changes = %+v, want minRam and maxRam", plan.Services[0].Changes)
+	}
+	if plan.Services[1].Skipped != "profile defines no runtime sizing" {
+		t.Errorf("api entry = %+v", plan.Services[1])
+	}
+
+	_, err = PlanScaleProfile(context.Background(), scaleProfileMock(), "proj-1", "x", "builtin", profile, []string{"nope"})
+	var pe *platform.PlatformError
+	if !errors.As(err, &pe) || pe.Code != platform.ErrServiceNotFound {
+		t.Errorf("unknown host err = %v", err)
+	}
+}
+
+// detailScaleMock mirrors the real client: ListServices carries only the
+// configured CustomAutoscaling, GetService adds CurrentAutoscaling.
+type detailScaleMock struct {
+	*platform.Mock
+	detail map[string]*platform.ServiceStack
+}
+
+func (m *detailScaleMock) GetService(_ context.Context, serviceID string) (*platform.ServiceStack, error) {
+	return m.detail[serviceID], nil
+}
+
+func TestPlanScaleProfile_ReadsCurrentFromServiceDetail(t *testing.T) {
+	t.Parallel()
+
+	current := &platform.CustomAutoscaling{CPUMode: "SHARED", MinRAM: 0.25, MaxRAM: 4}
+	listed := platform.ServiceStack{ID: "svc-db", Name: "db",
+		ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "postgresql@16", ServiceStackTypeCategoryName: "STANDARD"},
+		CustomAutoscaling:    &platform.CustomAutoscaling{MinRAM: 0.5, MaxRAM: 8}}
+	detail := listed
+	detail.CurrentAutoscaling = current
+	client := &detailScaleMock{
+		Mock:   platform.NewMock().WithServices([]platform.ServiceStack{listed}),
+		detail: map[string]*platform.ServiceStack{"svc-db": &detail},
+	}
+
+	profile := ScalingProfile{Managed: &ProfileScale{MinRAM: ptr(0.25), MaxRAM: ptr(4.0)}}
+	plan, err := PlanScaleProfile(context.Background(), client, "proj-1", "x", "builtin", profile, nil)
+	if err != nil {
+		t.Fatalf("PlanScaleProfile: %v", err)
+	}
+	db := plan.Services[0]
+	if len(db.Changes) != 0 || len(db.Warnings) != 0 {
+		t.Errorf("db entry = %+v; current values come from GetService and already match", db)
+	}
+
+	// A service that reports only the configured bounds is diffed against them.
+	detail.CurrentAutoscaling = nil
+	plan, err = PlanScaleProfile(context.Background(), client, "proj-1", "x", "builtin", profile, nil)
+	if err != nil {
+		t.Fatalf("PlanScaleProfile: %v", err)
+	}
+	db = plan.Services[0]
+	got := make([]string, 0, len(db.Changes))
+	for _, c := range db.Changes {
+		got = append(got, c.Field+" "+c.From+"→"+c.To)
+	}
+	if strings.Join(got, ", ") != "minRam 0.5→0.25, maxRam 8→4" || len(db.Warnings) != 0 {
+		t.Errorf("db changes = %v, warnings = %v", got, db.Warnings)
+	}
+}
+
+func TestApplyScaleProfile(t *testing.T) {
+	t.Parallel()
+
+	base := scaleProfileMock().WithAutoscalingProcess(&platform.Process{ID: "proc-1", Status: "PENDING"})
+	profile := ScalingProfile{
+		Runtime: &ProfileScale{MaxRAM: ptr(2.0)},
+		Managed: &ProfileScale{MaxRAM: ptr(8.0)},
+	}
+	plan, err := PlanScaleProfile(context.Background(), base, "proj-1", "big", "builtin", profile, nil)
+	if err != nil {
+		t.Fatalf("PlanScaleProfile: %v", err)
+	}
+	finish := func(_ context.Context, p *platform.Process) *platform.Process {
+		return &platform.Process{ID: p.ID, Status: statusFinished}
+	}
+
+	t.Run("all applied", func(t *testing.T) {
+		t.Parallel()
+		client := &recordingScaleMock{Mock: base, calls: map[string][]platform.AutoscalingParams{}}
+		var polled sync.Map
+		result, err := ApplyScaleProfile(context.Background(), client, plan, func(ctx context.Context, p *platform.Process) *platform.Process {
+			polled.Store(p.ID, true)
+			return finish(ctx, p)
+		})
+		if err != nil {
+			t.Fatalf("ApplyScaleProfile: %v", err)
+		}
+		if result.Summary != "2/2 scaled" || result.Succeeded != 2 || result.Failed != 0 || result.RolledBack != 0 {
+			t.Errorf("result = %+v", result)
+		}
+		api := result.Services[0]
+		if api.Hostname != "api" || api.Process == nil || api.Process.Status != statusFinished || api.Error != "" {
+			t.Errorf("api result = %+v", api)
+		}
+		if len(result.Skipped) != 1 || result.Skipped[0].Hostname != "files" {
+			t.Errorf("skipped = %+v", result.Skipped)
+		}
+		sent := client.calls["svc-api"]
+		if len(sent) != 1 || sent[0].VerticalMaxRAM == nil || *sent[0].VerticalMaxRAM != 2 || sent[0].VerticalMinRAM != nil || sent[0].ServiceMode != "NON_HA" {
+			t.Errorf("api params = %+v; only changed fields are sent", sent)
+		}
+		if _, ok := polled.Load("proc-1"); !ok {
+			t.Error("process was not polled")
+		}
+	})
+
+	t.Run("failure rolls back applied services", func(t *testing.T) {
+		t.Parallel()
+		client := &recordingScaleMock{Mock: base, calls: map[string][]platform.AutoscalingParams{}, fail: map[string]bool{"svc-db": true}}
+		result, err := ApplyScaleProfile(context.Background(), client, plan, finish)
+		if err != nil {
+			t.Fatalf("ApplyScaleProfile: %v", err)
+		}
+		if result.Summary != "0/2 scaled, 1 failed, 1 rolled back" || result.Succeeded != 0 || result.Failed != 1 || result.RolledBack != 1 {
+			t.Errorf("result = %+v", result)
+		}
+		if api := result.Services[0]; !api.RolledBack || api.RollbackError != "" {
+			t.Errorf("api result = %+v", api)
+		}
+		if db := result.Services[1]; db.Error != "boom" || db.RolledBack {
+			t.Errorf("db result = %+v", db)
+		}
+		sent := client.calls["svc-api"]
+		if len(sent) != 2 || sent[1].VerticalMaxRAM == nil || *sent[1].VerticalMaxRAM != 0.5 || sent[1].VerticalMinRAM != nil {
+			t.Errorf("api calls = %+v; second call must restore maxRam 0.5 only", sent)
+		}
+	})
+
+	t.Run("invalid entry sends nothing", func(t *testing.T) {
+		t.Parallel()
+		// An inverted min/max pair on one service aborts the whole apply.
+		bad := ScalingProfile{
+			Runtime:  &ProfileScale{MaxRAM: ptr(2.0)},
+			Services: map[string]ProfileScale{"db": {MinRAM: ptr(8.0), MaxRAM: ptr(4.0)}},
+		}
+		plan, err := PlanScaleProfile(context.Background(), base, "proj-1", "bad", "builtin", bad, nil)
+		if err != nil {
+			t.Fatalf("PlanScaleProfile: %v", err)
+		}
+		client := &recordingScaleMock{Mock: base, calls: map[string][]platform.AutoscalingParams{}}
+		_, err = ApplyScaleProfile(context.Background(), client, plan, finish)
+		var pe *platform.PlatformError
+		if !errors.As(err, &pe) || pe.Code != platform.ErrInvalidScaling || !strings.Contains(pe.Message, "db: minRam must be <= maxRam") {
+			t.Errorf("err = %v, want invalid scaling for db", err)
+		}
+		if len(client.calls) != 0 {
+			t.Errorf("calls = %+v, want none", client.calls)
+		}
+	})
+}
+
+func TestLoadScalingConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	cfg, err := LoadScalingConfig(write("ok.yaml", `profiles:
+  dev:
+    runtime: {minRam: 0.25, maxRam: 1}
+  prod:
+    mode: HA
+    managed: {minRam: 1}
+    services:
+      search: {minRam: 4}
+`))
+	if err != nil {
+		t.Fatalf("LoadScalingConfig: %v", err)
+	}
+	if *cfg.Profiles["dev"].Runtime.MaxRAM != 1 || *cfg.Profiles["prod"].Services["search"].MinRAM != 4 {
+		t.Errorf("cfg = %+v", cfg)
+	}
+
+	if cfg, err := LoadScalingConfig(filepath.Join(dir, "missing.yaml")); cfg != nil || err != nil {
+		t.Errorf("missing file = %v, %v", cfg, err)
+	}
+
+	for body, wantErr := range map[string]string{
+		"profiles:\n  x:\n    runtime: {minRam: 2, maxRam: 1}\n": "profiles.x: runtime: minRam must be <= maxRam",
+		"profiles:\n  x:\n    runtime: {ram: 2}\n":               "field ram not found",
+		"profiles:\n  x:\n    mode: BIG\n":                       `mode "BIG"`,
+		"profiles:\n  x: {}\n":                                   "defines no runtime, managed or services sizing",
+	} {
+		if _, err := LoadScalingConfig(write("bad.yaml", body)); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("%q: err = %v, want %q", body, err, wantErr)
+		}
+	}
+}
+
+func TestResolveScalingProfile(t *testing.T) {
+	t.Parallel()
+
+	builtins := map[string]ScalingProfile{"stage": {Mode: "NON_HA"}, "ha-prod": {Mode: "HA"}}
+	cfg := &ScalingConfig{Profiles: map[string]ScalingProfile{"stage": {Mode: "HA"}, "dev": {}}}
+
+	if p, src, err := ResolveScalingProfile(cfg, builtins, "stage"); err != nil || src != ScalingFileName || p.Mode != "HA" {
+		t.Errorf("project profile must shadow the builtin: %+v %s %v", p, src, err)
+	}
+	if _, src, err := ResolveScalingProfile(nil, builtins, "ha-prod"); err != nil || src != "builtin" {
+		t.Errorf("builtin = %s, %v", src, err)
+	}
+	_, _, err := ResolveScalingProfile(cfg, builtins, "nope")
+	var pe *platform.PlatformError
+	if !errors.As(err, &pe) || pe.Suggestion != "Available profiles: dev, ha-prod, stage" {
+		t.Errorf("unknown profile err = %+v", err)
+	}
+}
@@ -47,6 +47,8 @@ type CheckResult struct {
 	Detail     string    `json:"detail,omitempty"`     // human-readable detail on fail/skip
 	HTTPStatus int       `json:"httpStatus,omitempty"` // HTTP status code (0 = N/A)
 	Recovery   *Recovery `json:"recovery,omitempty"`
+	// Declared marks a check from .zcp/verify.yaml (see VerifyConfig).
+	Declared bool `json:"declared,omitempty"`
 
 	// BodyText is document.body.innerText captured by agent-browser after
 	// the HTTP probe connected. Best-effort: populated only when an actual
@@ -75,7 +77,8 @@ func isManagedCategory(categoryName string) bool {
 	}
 }
 
-// Verify runs health verification checks for a single service.
+// Verify runs health verification checks for a single service. declared
+// (may be nil) adds the service's user-declared checks.
 func Verify(
 	ctx context.Context,
 	client platform.Client,
@@ -83,6 +86,7 @@ func Verify(
 	httpClient HTTPDoer,
 	projectID string,
 	hostname string,
+	declared *VerifyConfig,
 ) (*VerifyResult, error) {
 	services, err := client.ListServices(ctx, projectID)
 	if err != nil {
@@ -92,7 +96,7 @@ func Verify(
 	if err != nil {
 		return nil, err
 	}
-	return verifyService(ctx, client, fetcher, httpClient, projectID, svc)
+	return verifyService(ctx, client, fetcher, httpClient, projectID, svc, declared.checksFor(svc.Name))
 }
 
 // verifyService runs health verification checks for a pre-resolved service.
@@ -104,6 +108,7 @@ func verifyService(
 	httpClient HTTPDoer,
 	projectID string,
 	svc *platform.ServiceStack,
+	declared []DeclaredCheck,
 ) (*VerifyResult, error) {
 	managed := isManagedCategory(svc.ServiceStackTypeInfo.ServiceStackTypeCategoryName)
 
@@ -119,8 +124,15 @@ func verifyService(
 	runningCheck := checkServiceRunning(svc)
 	result.Checks = append(result.Checks, runningCheck)
 
-	// Managed services: only check service_running.
+	// Managed services: service_running plus, once running, a protocol
+	// probe that logs in with the service's own credentials.
 	if managed {
+		if runningCheck.Status == CheckPass {
+			if probeCheck := checkManagedProtocol(ctx, client, httpClient, svc); probeCheck != nil {
+				result.Checks = append(result.Checks, *probeCheck)
+			}
+		}
+		result.Checks = append(result.Checks, skipDeclaredChecks(declared, "declared checks need an HTTP runtime service")...)
 		result.Status = aggregateStatus(result.Checks)
 		return result, nil
 	}
@@ -134,6 +146,7 @@ func verifyService(
 	// If not running, skip remaining checks based on runtime class.
 	if runningCheck.Status != CheckPass {
 		result.Checks = append(result.Checks, skipChecksForClass(rc)...)
+		result.Checks = append(result.Checks, skipDeclaredChecks(declared, "service not running")...)
 		result.Status = aggregateStatus(result.Checks)
 		return result, nil
 	}
@@ -168,11 +181,24 @@ func verifyService(
 	// type contract; bootstrap's workflow guidance explicitly curls
 	// its /status endpoint. Those checks belong to the workflows, not
 	// to a generic "does this service respond?" probe. http_root asks
-	// the single question verify is qualified to answer.
+	// the single question verify is qualified to answer. The user's own
+	// paths come from .zcp/verify.yaml and run after it as declared
+	// checks against the same URL.
 	needHTTP := rc == RuntimeDynamic || rc == RuntimeImplicit || rc == RuntimeStatic
-	if needHTTP {
+	if needHTTP || len(declared) > 0 {
 		wg.Go(func() {
 			subdomainURL := ResolveSubdomainURL(ctx, client, projectID, svc)
+			if !needHTTP {
+				// Worker with declared checks — no http_root row.
+				checks := skipDeclaredChecks(declared, "no subdomain URL to probe (service exposes no ports or subdomain access is off)")
+				if subdomainURL != "" {
+					checks = runDeclaredChecks(ctx, httpClient, svc.Name, subdomainURL, declared)
+				}
+				mu.Lock()
+				httpChecks = checks
+				mu.Unlock()
+				return
+			}
 			var checks []CheckResult
 			if subdomainURL == "" {
 				if svc.SubdomainAccess {
@@ -205,6 +231,11 @@ func verifyService(
 				}
 				checks = append(checks, check)
 			}
+			if subdomainURL == "" {
+				checks = append(checks, skipDeclaredChecks(declared, "no subdomain URL to probe")...)
+			} else {
+				checks = append(checks, runDeclaredChecks(ctx, httpClient, svc.Name, subdomainURL, declared)...)
+			}
 			mu.Lock()
 			httpChecks = checks
 			mu.Unlock()
@@ -259,13 +290,15 @@ type VerifyAllResult struct {
 	Services []VerifyResult `json:"services"`
 }
 
-// VerifyAll runs health verification for all non-system services in a project.
+// VerifyAll runs health verification for all non-system services in a
+// project. declared (may be nil) adds user-declared checks per hostname.
 func VerifyAll(
 	ctx context.Context,
 	client platform.Client,
 	fetcher platform.LogFetcher,
 	httpClient HTTPDoer,
 	projectID string,
+	declared *VerifyConfig,
 ) (*VerifyAllResult, error) {
 	services, err := client.ListServices(ctx, projectID)
 	if err != nil {
@@ -301,7 +334,7 @@ func VerifyAll(
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			r, verifyErr := verifyService(ctx, client, fetcher, httpClient, projectID, &targets[idx])
+			r, verifyErr := verifyService(ctx, client, fetcher, httpClient, projectID, &targets[idx], declared.checksFor(targets[idx].Name))
 			if verifyErr != nil {
 				results[idx] = VerifyResult{
 					Hostname: targets[idx].Name,
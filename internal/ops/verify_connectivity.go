@@ -0,0 +1,310 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// Connectivity edge statuses.
+const (
+	EdgeReachable   = "reachable"
+	EdgeUnreachable = "unreachable"
+	EdgeDNSFail     = "dns_fail"
+	EdgeSkip        = "skip"
+)
+
+const (
+	connectivityDialTimeout = 3 // seconds, per target, inside the container
+	connectivitySSHTimeout  = 90 * time.Second
+	curlExitResolve         = 6
+)
+
+// connectivityEndpointRe guards what reaches the remote shell. Hostnames
+// and ports come from env var values, so anything outside host:port is
+// reported as a skip rather than interpolated.
+var connectivityEndpointRe = regexp.MustCompile(`^[A-Za-z0-9.-]+:[0-9]{1,5}$`)
+
+// ConnectivityEdge is one source runtime → managed target probe. Refs are
+// the env references that imply the edge.
+type ConnectivityEdge struct {
+	Source    string   `json:"source"`
+	Target    string   `json:"target"`
+	Endpoint  string   `json:"endpoint,omitempty"` // host:port dialed from the source container
+	Status    string   `json:"status"`
+	LatencyMS int64    `json:"latencyMs,omitempty"` // TCP connect time incl. DNS
+	Detail    string   `json:"detail,omitempty"`
+	Refs      []string `json:"refs"`
+}
+
+// ConnectivityRefError is an invalid env reference found on a source.
+type ConnectivityRefError struct {
+	Source string `json:"source"`
+	EnvRefError
+}
+
+// ConnectivityResult is the source×target reachability matrix.
+type ConnectivityResult struct {
+	Summary   string                       `json:"summary"`
+	Status    string                       `json:"status"` // healthy/degraded/unhealthy
+	Matrix    map[string]map[string]string `json:"matrix"` // source → target → edge status
+	Edges     []ConnectivityEdge           `json:"edges"`
+	RefErrors []ConnectivityRefError       `json:"refErrors,omitempty"`
+}
+
+// VerifyConnectivity SSHes into each runtime (or only hostname) and, for
+// every managed service its env vars reference, resolves DNS and dials TCP
+// from inside the container. Edges come from ${host_var} references in the
+// runtime's service env vars merged with declaredEnv[runtime] (zerops.yaml
+// run.envVariables, which the API does not expose).
+func VerifyConnectivity(
+	ctx context.Context,
+	client platform.Client,
+	ssh SSHDeployer,
+	projectID string,
+	hostname string,
+	declaredEnv map[string]map[string]string,
+) (*ConnectivityResult, error) {
+	services, err := ListProjectServices(ctx, client, projectID)
+	if err != nil {
+		return nil, err
+	}
+	var sources []*platform.ServiceStack
+	if hostname != "" {
+		svc, err := FindService(services, hostname)
+		if err != nil {
+			return nil, err
+		}
+		if isManagedCategory(svc.ServiceStackTypeInfo.ServiceStackTypeCategoryName) {
+			return nil, platform.NewPlatformError(platform.ErrInvalidParameter,
+				fmt.Sprintf("%s is a managed service; connectivity is probed from runtime services", hostname),
+				"Pass a runtime hostname or omit serviceHostname")
+		}
+		sources = append(sources, svc)
+	}
+
+	liveHostnames := make([]string, 0, len(services))
+	discovered := make(map[string][]string, len(services))
+	envByHost := make(map[string]map[string]string, len(services))
+	managed := make(map[string]*platform.ServiceStack)
+	for i := range services {
+		svc := &services[i]
+		if svc.IsSystem() {
+			continue
+		}
+		liveHostnames = append(liveHostnames, svc.Name)
+		if isManagedCategory(svc.ServiceStackTypeInfo.ServiceStackTypeCategoryName) {
+			managed[svc.Name] = svc
+		} else if hostname == "" {
+			sources = append(sources, svc)
+		}
+		vars, envErr := FetchServiceEnv(ctx, client, svc.ID)
+		if envErr != nil {
+			continue
+		}
+		env := make(map[string]string, len(vars))
+		for _, v := range vars {
+			env[v.Key] = v.Content
+		}
+		envByHost[svc.Name] = env
+		discovered[svc.Name] = slices.Sorted(maps.Keys(env))
+	}
+
+	result := &ConnectivityResult{Matrix: make(map[string]map[string]string)}
+	perSource := make([][]ConnectivityEdge, len(sources))
+	sem := make(chan struct{}, 5)
+	var wg sync.WaitGroup
+	for i, src := range sources {
+		env := make(map[string]string)
+		maps.Copy(env, envByHost[src.Name])
+		maps.Copy(env, declaredEnv[src.Name])
+		for _, e := range ValidateEnvReferences(env, discovered, liveHostnames) {
+			result.RefErrors = append(result.RefErrors, ConnectivityRefError{Source: src.Name, EnvRefError: e})
+		}
+		edges := connectivityEdges(src.Name, env, managed, envByHost)
+		if len(edges) == 0 {
+			continue
+		}
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if checkServiceRunning(src).Status != CheckPass {
+				for j := range edges {
+					if edges[j].Status == "" {
+						edges[j].Status, edges[j].Detail = EdgeSkip, "source service not running"
+					}
+				}
+			} else {
+				probeEdges(ctx, ssh, src.Name, edges)
+			}
+			perSource[i] = edges
+		})
+	}
+	wg.Wait()
+
+	failed, reachable := 0, 0
+	for _, edges := range perSource {
+		for _, e := range edges {
+			if result.Matrix[e.Source] == nil {
+				result.Matrix[e.Source] = make(map[string]string)
+			}
+			result.Matrix[e.Source][e.Target] = e.Status
+			result.Edges = append(result.Edges, e)
+			switch e.Status {
+			case EdgeReachable:
+				reachable++
+			case EdgeUnreachable, EdgeDNSFail:
+				failed++
+			}
+		}
+	}
+	slices.SortFunc(result.RefErrors, func(a, b ConnectivityRefError) int {
+		return strings.Compare(a.Source+a.Variable+a.Reference, b.Source+b.Variable+b.Reference)
+	})
+
+	result.Summary = fmt.Sprintf("%d/%d edges reachable", reachable, len(result.Edges))
+	if len(result.RefErrors) > 0 {
+		result.Summary += fmt.Sprintf(", %d invalid env reference(s)", len(result.RefErrors))
+	}
+	switch {
+	case failed > 0 && reachable == 0:
+		result.Status = StatusUnhealthy
+	case failed > 0 || len(result.RefErrors) > 0:
+		result.Status = StatusDegraded
+	default:
+		result.Status = StatusHealthy
+	}
+	return result, nil
+}
+
+// connectivityEdges derives one edge per managed service that source's env
+// vars reference, resolving the endpoint from the target's own env vars.
+// Edges whose endpoint cannot be determined come back already skipped.
+func connectivityEdges(source string, env map[string]string, managed map[string]*platform.ServiceStack, envByHost map[string]map[string]string) []ConnectivityEdge {
+	refs := make(map[string][]string)
+	for _, value := range env {
+		for _, ref := range parseEnvRefs(value) {
+			if managed[ref.hostname] != nil && !slices.Contains(refs[ref.hostname], ref.raw) {
+				refs[ref.hostname] = append(refs[ref.hostname], ref.raw)
+			}
+		}
+	}
+	edges := make([]ConnectivityEdge, 0, len(refs))
+	for _, target := range slices.Sorted(maps.Keys(refs)) {
+		slices.Sort(refs[target])
+		edge := ConnectivityEdge{Source: source, Target: target, Refs: refs[target]}
+		edge.Endpoint = managedEndpoint(managed[target], envByHost[target])
+		if !connectivityEndpointRe.MatchString(edge.Endpoint) {
+			edge.Status, edge.Detail = EdgeSkip, "cannot determine host:port from the target's env vars"
+			edge.Endpoint = ""
+		}
+		edges = append(edges, edge)
+	}
+	return edges
+}
+
+// managedEndpoint is host:port for a managed service: hostname/port env
+// vars, falling back to the service name and first declared port. Object
+// storage is dialed at its API host.
+func managedEndpoint(svc *platform.ServiceStack, env map[string]string) string {
+	if apiURL := env["apiUrl"]; apiURL != "" {
+		if u, err := url.Parse(apiURL); err == nil && u.Hostname() != "" {
+			port := u.Port()
+			if port == "" {
+				port = "443"
+				if u.Scheme == "http" {
+					port = "80"
+				}
+			}
+			return net.JoinHostPort(u.Hostname(), port)
+		}
+	}
+	host, port := env["hostname"], env["port"]
+	if host == "" {
+		host = svc.Name
+	}
+	if port == "" && len(svc.Ports) > 0 {
+		port = strconv.Itoa(svc.Ports[0].Port)
+	}
+	if port == "" {
+		return ""
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// probeEdges dials every pending edge from inside source in one SSH call.
+// curl's telnet:// scheme does a bare TCP connect and is present on every
+// Zerops runtime image; exit 6 means the name did not resolve.
+func probeEdges(ctx context.Context, ssh SSHDeployer, source string, edges []ConnectivityEdge) {
+	var endpoints []string
+	for _, e := range edges {
+		if e.Status == "" {
+			endpoints = append(endpoints, shellQuote(e.Endpoint))
+		}
+	}
+	if len(endpoints) == 0 {
+		return
+	}
+	script := fmt.Sprintf(
+		`set +e; for t in %s; do `+
+			`r=$(curl -s -o /dev/null --connect-timeout %d -m %d -w '%%{time_namelookup} %%{time_connect}' "telnet://$t" </dev/null 2>/dev/null); `+
+			`echo "$t $? $r"; done`,
+		strings.Join(endpoints, " "), connectivityDialTimeout, connectivityDialTimeout+1,
+	)
+	sshCtx, cancel := context.WithTimeout(ctx, connectivitySSHTimeout)
+	defer cancel()
+	out, err := ssh.ExecSSH(sshCtx, source, script)
+
+	lines := make(map[string][]string)
+	for line := range strings.Lines(string(out)) {
+		if fields := strings.Fields(line); len(fields) >= 2 {
+			lines[fields[0]] = fields[1:]
+		}
+	}
+	for i := range edges {
+		e := &edges[i]
+		if e.Status != "" {
+			continue
+		}
+		fields, ok := lines[e.Endpoint]
+		if !ok {
+			e.Status = EdgeSkip
+			e.Detail = "no probe output from source"
+			if err != nil {
+				e.Detail = fmt.Sprintf("ssh %s: %v", source, err)
+			}
+			continue
+		}
+		classifyEdge(e, fields)
+	}
+}
+
+// classifyEdge maps "<exit> <time_namelookup> <time_connect>" to a status.
+// A nonzero connect time means the TCP handshake completed even when curl
+// later timed out waiting on the protocol.
+func classifyEdge(e *ConnectivityEdge, fields []string) {
+	code, _ := strconv.Atoi(fields[0])
+	var connect float64
+	if len(fields) >= 3 {
+		connect, _ = strconv.ParseFloat(fields[2], 64)
+	}
+	switch {
+	case connect > 0:
+		e.Status = EdgeReachable
+		e.LatencyMS = int64(connect * 1000)
+	case code == curlExitResolve:
+		e.Status, e.Detail = EdgeDNSFail, fmt.Sprintf("%s does not resolve from %s", strings.Split(e.Endpoint, ":")[0], e.Source)
+	default:
+		e.Status, e.Detail = EdgeUnreachable, fmt.Sprintf("TCP connect to %s failed (curl exit %d)", e.Endpoint, code)
+	}
+}
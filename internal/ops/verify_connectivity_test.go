@@ -0,0 +1,184 @@
+// Tests for: ops/verify_connectivity.go — runtime → managed reachability matrix.
+package ops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// matrixSSH answers the connectivity script with canned curl results per
+// endpoint ("<exit> <namelookup> <connect>"), recording which hosts it ran on.
+type matrixSSH struct {
+	mu      sync.Mutex
+	results map[string]string
+	hosts   []string
+	err     error
+}
+
+var quotedEndpointRe = regexp.MustCompile(`'([^']+)'`)
+
+func (s *matrixSSH) ExecSSH(_ context.Context, hostname, command string) ([]byte, error) {
+	s.mu.Lock()
+	s.hosts = append(s.hosts, hostname)
+	s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	var out strings.Builder
+	for _, m := range quotedEndpointRe.FindAllStringSubmatch(command, -1) {
+		if r, ok := s.results[m[1]]; ok {
+			fmt.Fprintf(&out, "%s %s\n", m[1], r)
+		}
+	}
+	return []byte(out.String()), nil
+}
+
+func (s *matrixSSH) ExecSSHBackground(ctx context.Context, hostname, command string, _ time.Duration) ([]byte, error) {
+	return s.ExecSSH(ctx, hostname, command)
+}
+
+func connectivityMock() *platform.Mock {
+	runtime := func(id, name, status string) platform.ServiceStack {
+		return platform.ServiceStack{ID: id, Name: name, Status: status,
+			ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "nodejs@22", ServiceStackTypeCategoryName: "USER"}}
+	}
+	managed := func(id, name, typ string) platform.ServiceStack {
+		return platform.ServiceStack{ID: id, Name: name, Status: "RUNNING",
+			ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: typ, ServiceStackTypeCategoryName: "STANDARD"}}
+	}
+	return platform.NewMock().
+		WithServices([]platform.ServiceStack{
+			runtime("svc-api", "api", "ACTIVE"),
+			runtime("svc-worker", "worker", "ACTIVE"),
+			runtime("svc-old", "old", "STOPPED"),
+			managed("svc-db", "db", "postgresql@16"),
+			managed("svc-cache", "cache", "valkey@7.2"),
+		}).
+		WithServiceEnv("svc-db", []platform.EnvVar{{Key: "hostname", Content: "db"}, {Key: "port", Content: "5432"}, {Key: "password", Content: "x"}}).
+		WithServiceEnv("svc-cache", []platform.EnvVar{{Key: "hostname", Content: "cache"}, {Key: "port", Content: "6379"}}).
+		WithServiceEnv("svc-worker", []platform.EnvVar{{Key: "REDIS_HOST", Content: "${cache_hostname}"}}).
+		WithServiceEnv("svc-old", []platform.EnvVar{{Key: "DB_HOST", Content: "${db_hostname}"}})
+}
+
+func TestVerifyConnectivity(t *testing.T) {
+	t.Parallel()
+
+	ssh := &matrixSSH{results: map[string]string{
+		"db:5432":    "0 0.001 0.004",
+		"cache:6379": "6 0.000 0.000",
+	}}
+	// api's refs live in zerops.yaml only; one of them is a typo.
+	declared := map[string]map[string]string{"api": {
+		"DATABASE_URL": "postgres://${db_user}:${db_password}@${db_hostname}:${db_port}/db",
+		"REDIS_URL":    "redis://${cache_hostname}:${cache_port}",
+	}}
+
+	result, err := VerifyConnectivity(context.Background(), connectivityMock(), ssh, "proj-1", "", declared)
+	if err != nil {
+		t.Fatalf("VerifyConnectivity: %v", err)
+	}
+
+	want := map[string]map[string]string{
+		"api":    {"db": EdgeReachable, "cache": EdgeDNSFail},
+		"worker": {"cache": EdgeDNSFail},
+		"old":    {"db": EdgeSkip},
+	}
+	for src, targets := range want {
+		for tgt, status := range targets {
+			if got := result.Matrix[src][tgt]; got != status {
+				t.Errorf("matrix[%s][%s] = %q, want %q", src, tgt, got, status)
+			}
+		}
+	}
+	if len(result.Edges) != 4 {
+		t.Errorf("edges = %+v", result.Edges)
+	}
+	for _, e := range result.Edges {
+		if e.Source == "api" && e.Target == "db" {
+			if e.LatencyMS != 4 || e.Endpoint != "db:5432" || len(e.Refs) != 4 {
+				t.Errorf("api→db edge = %+v", e)
+			}
+		}
+	}
+	// db_user is not among db's env vars.
+	if len(result.RefErrors) != 1 || result.RefErrors[0].Source != "api" || result.RefErrors[0].Reference != "${db_user}" {
+		t.Errorf("refErrors = %+v", result.RefErrors)
+	}
+	if result.Status != StatusDegraded || result.Summary != "1/4 edges reachable, 1 invalid env reference(s)" {
+		t.Errorf("status = %s, summary = %q", result.Status, result.Summary)
+	}
+	for _, h := range ssh.hosts {
+		if h == "old" {
+			t.Error("stopped runtime must not be SSHed into")
+		}
+	}
+}
+
+func TestVerifyConnectivity_SingleSourceAndErrors(t *testing.T) {
+	t.Parallel()
+
+	ssh := &matrixSSH{err: errors.New("connection refused")}
+	result, err := VerifyConnectivity(context.Background(), connectivityMock(), ssh, "proj-1", "worker", nil)
+	if err != nil {
+		t.Fatalf("VerifyConnectivity: %v", err)
+	}
+	if len(result.Edges) != 1 || result.Edges[0].Status != EdgeSkip || !strings.Contains(result.Edges[0].Detail, "connection refused") {
+		t.Errorf("edges = %+v, want one skipped edge carrying the ssh error", result.Edges)
+	}
+
+	if _, err := VerifyConnectivity(context.Background(), connectivityMock(), ssh, "proj-1", "db", nil); err == nil {
+		t.Error("managed source should be rejected")
+	}
+	if _, err := VerifyConnectivity(context.Background(), connectivityMock(), ssh, "proj-1", "nope", nil); err == nil {
+		t.Error("unknown hostname should be rejected")
+	}
+}
+
+func TestClassifyEdge(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		fields     []string
+		wantStatus string
+		wantMS     int64
+	}{
+		{[]string{"0", "0.002", "0.0125"}, EdgeReachable, 12},
+		{[]string{"28", "0.002", "0.003"}, EdgeReachable, 3}, // connected, then protocol timeout
+		{[]string{"6", "0.000", "0.000"}, EdgeDNSFail, 0},
+		{[]string{"7", "0.001", "0.000"}, EdgeUnreachable, 0},
+		{[]string{"28"}, EdgeUnreachable, 0},
+	}
+	for _, tt := range tests {
+		e := ConnectivityEdge{Source: "api", Endpoint: "db:5432"}
+		classifyEdge(&e, tt.fields)
+		if e.Status != tt.wantStatus || e.LatencyMS != tt.wantMS {
+			t.Errorf("classifyEdge(%v) = %s/%dms, want %s/%dms", tt.fields, e.Status, e.LatencyMS, tt.wantStatus, tt.wantMS)
+		}
+	}
+}
+
+func TestManagedEndpoint(t *testing.T) {
+	t.Parallel()
+
+	svc := &platform.ServiceStack{Name: "db", Ports: []platform.Port{{Port: 5432}}}
+	for _, tt := range []struct {
+		env  map[string]string
+		want string
+	}{
+		{map[string]string{"hostname": "db", "port": "5433"}, "db:5433"},
+		{nil, "db:5432"},
+		{map[string]string{"apiUrl": "https://storage-prg1.zerops.io"}, "storage-prg1.zerops.io:443"},
+	} {
+		if got := managedEndpoint(svc, tt.env); got != tt.want {
+			t.Errorf("managedEndpoint(%v) = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+}
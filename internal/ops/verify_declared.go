@@ -0,0 +1,359 @@
+package ops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VerifyFileName is the declared-checks file inside the .zcp directory.
+const VerifyFileName = "verify.yaml"
+
+const (
+	declaredDefaultTimeout    = 10 * time.Second
+	declaredDefaultRetryDelay = time.Second
+	declaredMaxRetries        = 5
+	declaredBodyReadCap       = 64 << 10
+)
+
+// VerifyConfig is .zcp/verify.yaml: per-hostname HTTP checks zerops_verify
+// runs on top of the generic http_root probe. A nil *VerifyConfig declares
+// nothing.
+//
+//	services:
+//	  api:
+//	    - name: ready
+//	      path: /ready
+//	      expectStatus: 200
+//	      json: {path: checks.db, equals: ok}
+//	      maxLatency: 500ms
+//	      retries: 2
+type VerifyConfig struct {
+	Services map[string][]DeclaredCheck `yaml:"services"`
+}
+
+// DeclaredCheck is one user-declared HTTP check against the service's
+// subdomain URL.
+type DeclaredCheck struct {
+	Name   string `yaml:"name"`   // defaults to path
+	Path   string `yaml:"path"`   // must start with /
+	Method string `yaml:"method"` // GET (default), HEAD, POST, …
+	// ExpectStatus is the exact status code required. Zero accepts any 2xx.
+	ExpectStatus int `yaml:"expectStatus"`
+	// BodyMatches is a regular expression the response body must match.
+	BodyMatches string `yaml:"bodyMatches"`
+	// JSON asserts a value in a JSON response body.
+	JSON *JSONAssertion `yaml:"json"`
+	// MaxLatency fails the attempt when the response takes longer (Go
+	// duration, e.g. 500ms).
+	MaxLatency string `yaml:"maxLatency"`
+	// Retries re-runs a failing check; the check passes on the first
+	// passing attempt. RetryDelay (default 1s) separates attempts.
+	Retries    int    `yaml:"retries"`
+	RetryDelay string `yaml:"retryDelay"`
+
+	bodyRe     *regexp.Regexp
+	maxLatency time.Duration
+	retryDelay time.Duration
+}
+
+// JSONAssertion checks the value at a dotted path ("status",
+// "checks.db", "items.0.id"). With Equals unset the path only has to
+// exist.
+type JSONAssertion struct {
+	Path   string `yaml:"path"`
+	Equals any    `yaml:"equals"`
+}
+
+// VerifyConfigPath returns the declared-checks file location for a state
+// dir (.zcp/state → .zcp/verify.yaml).
+func VerifyConfigPath(stateDir string) string {
+	return filepath.Join(filepath.Dir(stateDir), VerifyFileName)
+}
+
+// LoadVerifyConfig reads and validates the declared-checks file. A
+// missing file returns (nil, nil).
+func LoadVerifyConfig(path string) (*VerifyConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	var cfg VerifyConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		if errors.Is(err, io.EOF) {
+			return &cfg, nil
+		}
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for hostname, checks := range cfg.Services {
+		seen := make(map[string]bool, len(checks))
+		for i := range checks {
+			c := &checks[i]
+			if err := c.prepare(); err != nil {
+				return nil, fmt.Errorf("parse %s: services.%s[%d]: %w", path, hostname, i, err)
+			}
+			if seen[c.Name] {
+				return nil, fmt.Errorf("parse %s: services.%s: duplicate check name %q", path, hostname, c.Name)
+			}
+			seen[c.Name] = true
+		}
+	}
+	return &cfg, nil
+}
+
+// prepare fills defaults and compiles the check; errors name the field.
+func (c *DeclaredCheck) prepare() error {
+	if !strings.HasPrefix(c.Path, "/") {
+		return fmt.Errorf("path %q must start with /", c.Path)
+	}
+	if c.Name == "" {
+		c.Name = c.Path
+	}
+	switch c.Name {
+	case "service_running", checkNameErrorLogs, checkNameHTTPRoot:
+		return fmt.Errorf("name %q is reserved for a built-in check", c.Name)
+	}
+	c.Method = strings.ToUpper(c.Method)
+	if c.Method == "" {
+		c.Method = http.MethodGet
+	}
+	if c.ExpectStatus != 0 && (c.ExpectStatus < 100 || c.ExpectStatus > 599) {
+		return fmt.Errorf("expectStatus %d is not an HTTP status", c.ExpectStatus)
+	}
+	if c.BodyMatches != "" {
+		re, err := regexp.Compile(c.BodyMatches)
+		if err != nil {
+			return fmt.Errorf("bodyMatches: %w", err)
+		}
+		c.bodyRe = re
+	}
+	if c.JSON != nil && c.JSON.Path == "" {
+		return errors.New("json.path is required")
+	}
+	if c.Retries < 0 || c.Retries > declaredMaxRetries {
+		return fmt.Errorf("retries must be 0-%d", declaredMaxRetries)
+	}
+	var err error
+	if c.maxLatency, err = parseOptionalDuration(c.MaxLatency); err != nil {
+		return fmt.Errorf("maxLatency: %w", err)
+	}
+	if c.retryDelay, err = parseOptionalDuration(c.RetryDelay); err != nil {
+		return fmt.Errorf("retryDelay: %w", err)
+	}
+	if c.RetryDelay == "" {
+		c.retryDelay = declaredDefaultRetryDelay
+	}
+	return nil
+}
+
+func parseOptionalDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+// checksFor returns the declared checks of hostname (nil-safe).
+func (c *VerifyConfig) checksFor(hostname string) []DeclaredCheck {
+	if c == nil {
+		return nil
+	}
+	return c.Services[hostname]
+}
+
+// DeclaredChecksPassed reports whether every declared check of r passed.
+// A declared check that was skipped (service down, no subdomain) does not
+// count as passed — the user asked for it to be proven.
+func DeclaredChecksPassed(r *VerifyResult) bool {
+	for _, c := range r.Checks {
+		if c.Declared && c.Status != CheckPass {
+			return false
+		}
+	}
+	return true
+}
+
+// skipDeclaredChecks marks every declared check as skipped with detail.
+func skipDeclaredChecks(checks []DeclaredCheck, detail string) []CheckResult {
+	out := make([]CheckResult, len(checks))
+	for i, c := range checks {
+		out[i] = CheckResult{Name: c.Name, Status: CheckSkip, Detail: detail, Declared: true}
+	}
+	return out
+}
+
+// runDeclaredChecks runs hostname's declared checks against baseURL in
+// declaration order.
+func runDeclaredChecks(ctx context.Context, httpClient HTTPDoer, hostname, baseURL string, checks []DeclaredCheck) []CheckResult {
+	out := make([]CheckResult, 0, len(checks))
+	for _, c := range checks {
+		out = append(out, runDeclaredCheck(ctx, httpClient, hostname, baseURL, c))
+	}
+	return out
+}
+
+func runDeclaredCheck(ctx context.Context, httpClient HTTPDoer, hostname, baseURL string, c DeclaredCheck) CheckResult {
+	var result CheckResult
+	attempts := 1 + c.Retries
+	for attempt := 1; attempt <= attempts; attempt++ {
+		result = declaredAttempt(ctx, httpClient, baseURL, c)
+		if result.Status == CheckPass {
+			break
+		}
+		if attempt < attempts {
+			select {
+			case <-ctx.Done():
+				attempt = attempts
+			case <-time.After(c.retryDelay):
+			}
+		}
+	}
+	result.Name = c.Name
+	result.Declared = true
+	if result.Status != CheckPass {
+		if attempts > 1 {
+			result.Detail = fmt.Sprintf("%s (after %d attempts)", result.Detail, attempts)
+		}
+		result.Recovery = &Recovery{
+			Tool:   "zerops_logs",
+			Action: "summary",
+			Args:   map[string]string{"serviceHostname": hostname, "mode": "summary", "since": "15m"},
+		}
+	}
+	return result
+}
+
+// declaredAttempt performs one request and evaluates every assertion,
+// reporting the first one that fails.
+func declaredAttempt(ctx context.Context, httpClient HTTPDoer, baseURL string, c DeclaredCheck) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, max(declaredDefaultTimeout, c.maxLatency))
+	defer cancel()
+
+	url := strings.TrimSuffix(baseURL, "/") + c.Path
+	req, err := http.NewRequestWithContext(ctx, c.Method, url, nil)
+	if err != nil {
+		return CheckResult{Status: CheckFail, Detail: fmt.Sprintf("request failed: %v", err)}
+	}
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return CheckResult{Status: CheckFail, Detail: fmt.Sprintf("%s %s: %v", c.Method, c.Path, err)}
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, declaredBodyReadCap))
+	latency := time.Since(start)
+
+	fail := func(format string, args ...any) CheckResult {
+		return CheckResult{Status: CheckFail, HTTPStatus: resp.StatusCode,
+			Detail: fmt.Sprintf("%s %s: ", c.Method, c.Path) + fmt.Sprintf(format, args...)}
+	}
+
+	switch {
+	case c.ExpectStatus != 0 && resp.StatusCode != c.ExpectStatus:
+		return fail("HTTP %d, want %d%s", resp.StatusCode, c.ExpectStatus, bodyExcerpt(body))
+	case c.ExpectStatus == 0 && (resp.StatusCode < 200 || resp.StatusCode > 299):
+		return fail("HTTP %d, want 2xx%s", resp.StatusCode, bodyExcerpt(body))
+	}
+	if c.bodyRe != nil && !c.bodyRe.Match(body) {
+		return fail("body does not match %q%s", c.BodyMatches, bodyExcerpt(body))
+	}
+	if c.JSON != nil {
+		if msg := checkJSONAssertion(body, c.JSON); msg != "" {
+			return fail("%s", msg)
+		}
+	}
+	if c.maxLatency > 0 && latency > c.maxLatency {
+		return fail("latency %s exceeds budget %s", latency.Round(time.Millisecond), c.maxLatency)
+	}
+	return CheckResult{
+		Status:     CheckPass,
+		HTTPStatus: resp.StatusCode,
+		Detail:     fmt.Sprintf("HTTP %d in %s", resp.StatusCode, latency.Round(time.Millisecond)),
+	}
+}
+
+func bodyExcerpt(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	return ": " + truncateBody(body, 200)
+}
+
+// checkJSONAssertion returns a failure message, or "" when the assertion
+// holds.
+func checkJSONAssertion(body []byte, a *JSONAssertion) string {
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return fmt.Sprintf("body is not JSON (%v)", err)
+	}
+	got, ok := jsonPathValue(doc, a.Path)
+	if !ok {
+		return fmt.Sprintf("json path %q not found", a.Path)
+	}
+	if a.Equals != nil && !jsonScalarEqual(got, a.Equals) {
+		return fmt.Sprintf("json path %q = %v, want %v", a.Path, got, a.Equals)
+	}
+	return ""
+}
+
+// jsonPathValue walks a dotted path through objects and (numeric
+// segments) arrays.
+func jsonPathValue(doc any, path string) (any, bool) {
+	cur := doc
+	for seg := range strings.SplitSeq(path, ".") {
+		switch node := cur.(type) {
+		case map[string]any:
+			v, ok := node[seg]
+			if !ok {
+				return nil, false
+			}
+			cur = v
+		case []any:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			cur = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// jsonScalarEqual compares a decoded JSON value with a YAML-declared one.
+// Numbers compare by value (YAML 1 vs JSON 1.0); everything else by its
+// printed form, which covers strings, booleans and null.
+func jsonScalarEqual(got, want any) bool {
+	if g, ok := got.(float64); ok {
+		switch w := want.(type) {
+		case int:
+			return g == float64(w)
+		case float64:
+			return g == w
+		}
+	}
+	return fmt.Sprint(got) == fmt.Sprint(want)
+}
@@ -0,0 +1,211 @@
+// Tests for: ops/verify_declared.go — user-declared verify checks.
+package ops
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// handlerDoer serves every request from an http.Handler in-process, so
+// checks against https://<host>-<sub>.prg1.zerops.app reach the test app.
+type handlerDoer struct{ h http.Handler }
+
+func (d handlerDoer) Do(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	rec := httptest.NewRecorder()
+	d.h.ServeHTTP(rec, req)
+	return rec.Result(), nil
+}
+
+func writeVerifyConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), VerifyFileName)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadVerifyConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := LoadVerifyConfig(writeVerifyConfig(t, `
+services:
+  api:
+    - path: /healthz
+    - name: ready
+      path: /ready
+      method: head
+      expectStatus: 204
+      json: {path: checks.db, equals: ok}
+      maxLatency: 500ms
+      retries: 2
+`))
+	if err != nil {
+		t.Fatalf("LoadVerifyConfig: %v", err)
+	}
+	checks := cfg.checksFor("api")
+	if len(checks) != 2 || checks[0].Name != "/healthz" || checks[0].Method != http.MethodGet {
+		t.Fatalf("defaults not applied: %+v", checks)
+	}
+	if checks[1].Method != http.MethodHead || checks[1].maxLatency != 500*time.Millisecond || checks[1].retryDelay != time.Second {
+		t.Errorf("ready check = %+v", checks[1])
+	}
+
+	if cfg, err := LoadVerifyConfig(filepath.Join(t.TempDir(), "absent.yaml")); cfg != nil || err != nil {
+		t.Errorf("missing file = (%v, %v), want (nil, nil)", cfg, err)
+	}
+	if (*VerifyConfig)(nil).checksFor("api") != nil {
+		t.Error("nil config declares no checks")
+	}
+
+	for name, content := range map[string]string{
+		"relative path":  "services: {api: [{path: healthz}]}",
+		"reserved name":  "services: {api: [{name: http_root, path: /}]}",
+		"duplicate name": "services: {api: [{path: /a}, {path: /a}]}",
+		"bad regexp":     "services: {api: [{path: /, bodyMatches: '('}]}",
+		"bad latency":    "services: {api: [{path: /, maxLatency: fast}]}",
+		"too many tries": "services: {api: [{path: /, retries: 50}]}",
+		"json w/o path":  "services: {api: [{path: /, json: {equals: 1}}]}",
+		"unknown field":  "services: {api: [{path: /, expect: 200}]}",
+	} {
+		if _, err := LoadVerifyConfig(writeVerifyConfig(t, content)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestRunDeclaredCheck(t *testing.T) {
+	t.Parallel()
+
+	var flaky atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte("ok")) })
+	mux.HandleFunc("/status", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"degraded","checks":{"db":"ok","workers":3},"items":[{"id":7}]}`))
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/flaky", func(w http.ResponseWriter, _ *http.Request) {
+		if flaky.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	doer := handlerDoer{mux}
+
+	tests := []struct {
+		name       string
+		check      DeclaredCheck
+		wantStatus string
+		wantDetail string
+	}{
+		{"2xx default", DeclaredCheck{Path: "/healthz"}, CheckPass, "HTTP 200"},
+		{"body regexp", DeclaredCheck{Path: "/healthz", BodyMatches: "^ok$"}, CheckPass, ""},
+		{"body mismatch", DeclaredCheck{Path: "/healthz", BodyMatches: "ready"}, CheckFail, `body does not match "ready"`},
+		{"missing route", DeclaredCheck{Path: "/ready"}, CheckFail, "HTTP 404, want 2xx"},
+		{"exact status", DeclaredCheck{Path: "/healthz", ExpectStatus: 204}, CheckFail, "HTTP 200, want 204"},
+		{"json nested", DeclaredCheck{Path: "/status", JSON: &JSONAssertion{Path: "checks.db", Equals: "ok"}}, CheckPass, ""},
+		{"json number", DeclaredCheck{Path: "/status", JSON: &JSONAssertion{Path: "checks.workers", Equals: 3}}, CheckPass, ""},
+		{"json array", DeclaredCheck{Path: "/status", JSON: &JSONAssertion{Path: "items.0.id"}}, CheckPass, ""},
+		{"json value", DeclaredCheck{Path: "/status", JSON: &JSONAssertion{Path: "status", Equals: "ok"}}, CheckFail, `json path "status" = degraded, want ok`},
+		{"json missing", DeclaredCheck{Path: "/status", JSON: &JSONAssertion{Path: "checks.cache"}}, CheckFail, `json path "checks.cache" not found`},
+		{"not json", DeclaredCheck{Path: "/healthz", JSON: &JSONAssertion{Path: "status"}}, CheckFail, "body is not JSON"},
+		{"latency budget", DeclaredCheck{Path: "/slow", MaxLatency: "5ms"}, CheckFail, "exceeds budget 5ms"},
+		{"retries", DeclaredCheck{Path: "/flaky", Retries: 2, RetryDelay: "1ms"}, CheckPass, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.check
+			if err := c.prepare(); err != nil {
+				t.Fatalf("prepare: %v", err)
+			}
+			got := runDeclaredCheck(context.Background(), doer, "api", "https://api.example", c)
+			if got.Status != tt.wantStatus || !strings.Contains(got.Detail, tt.wantDetail) {
+				t.Errorf("result = %s %q, want %s containing %q", got.Status, got.Detail, tt.wantStatus, tt.wantDetail)
+			}
+			if !got.Declared || got.Name != c.Name {
+				t.Errorf("declared=%v name=%q", got.Declared, got.Name)
+			}
+			if (got.Status == CheckFail) != (got.Recovery != nil) {
+				t.Errorf("recovery = %+v; failing declared checks carry one, passing ones do not", got.Recovery)
+			}
+		})
+	}
+}
+
+func TestVerify_DeclaredChecks(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+	mux.HandleFunc("/ready", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusServiceUnavailable) })
+
+	running := platform.ServiceStack{ID: "svc-1", Name: "api", ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "nodejs@22", ServiceStackTypeCategoryName: "USER"}, Status: "RUNNING", SubdomainAccess: true, Ports: []platform.Port{{Port: 3000}}}
+	stopped := running
+	stopped.Status = "STOPPED"
+	cfg := &VerifyConfig{Services: map[string][]DeclaredCheck{"api": {{Path: "/"}, {Path: "/ready"}}}}
+	for i := range cfg.Services["api"] {
+		if err := cfg.Services["api"][i].prepare(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name        string
+		svc         platform.ServiceStack
+		wantStatus  string
+		wantDetails []string // per declared check, in order
+	}{
+		{"running", running, StatusDegraded, []string{"HTTP 200", "HTTP 503, want 2xx"}},
+		{"stopped", stopped, StatusUnhealthy, []string{"service not running", "service not running"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			mock := platform.NewMock().
+				WithServices([]platform.ServiceStack{tc.svc}).
+				WithProject(&platform.Project{ID: "proj-1", SubdomainHost: "1df2.prg1.zerops.app"}).
+				WithLogAccess(&platform.LogAccess{URL: "http://logs.test"})
+
+			result, err := Verify(context.Background(), mock, platform.NewMockLogFetcher(), handlerDoer{mux}, "proj-1", "api", cfg)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if result.Status != tc.wantStatus {
+				t.Errorf("status = %s, want %s; checks: %+v", result.Status, tc.wantStatus, result.Checks)
+			}
+			var declared []CheckResult
+			for _, c := range result.Checks {
+				if c.Declared {
+					declared = append(declared, c)
+				}
+			}
+			if len(declared) != len(tc.wantDetails) {
+				t.Fatalf("declared checks = %+v", declared)
+			}
+			for i, want := range tc.wantDetails {
+				if !strings.Contains(declared[i].Detail, want) {
+					t.Errorf("declared[%d].detail = %q, want %q", i, declared[i].Detail, want)
+				}
+			}
+			if DeclaredChecksPassed(result) {
+				t.Error("DeclaredChecksPassed should be false")
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package ops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/zeropsio/zcp/internal/platform"
+	"github.com/zeropsio/zcp/internal/probe"
+)
+
+const probeTimeout = 10 * time.Second
+
+// managedProbe is the protocol check for one managed service family. needs
+// lists the service's own env vars (exposed to other services as
+// ${<hostname>_<key>}) the probe cannot run without.
+type managedProbe struct {
+	check string
+	needs []string
+	run   func(ctx context.Context, httpClient HTTPDoer, env map[string]string) (string, error)
+}
+
+// managedProbes maps a service type base (the part before "@") to its probe.
+// Families without one (rabbitmq, kafka, shared-storage, ...) keep the bare
+// service_running check.
+var managedProbes = map[string]managedProbe{
+	"postgresql": {check: "postgresql_query", needs: []string{"hostname", "port", "user", "password"}, run: probeSQL(probe.Postgres)},
+	"mariadb":    {check: "mariadb_query", needs: []string{"hostname", "port", "user", "password"}, run: probeSQL(probe.MariaDB)},
+	"valkey":     {check: "valkey_ping", needs: []string{"hostname", "port"}, run: probeValkey},
+	"keydb":      {check: "keydb_ping", needs: []string{"hostname", "port"}, run: probeValkey},
+	"nats":       {check: "nats_connect", needs: []string{"hostname", "port"}, run: probeNATS},
+	"object-storage": {check: "storage_head_bucket", needs: []string{"apiUrl", "accessKeyId", "secretAccessKey", "bucketName"},
+		run: func(ctx context.Context, httpClient HTTPDoer, env map[string]string) (string, error) {
+			return probe.S3HeadBucket(ctx, httpClient, env["apiUrl"], env["accessKeyId"], env["secretAccessKey"], env["bucketName"])
+		}},
+	"meilisearch": {check: "meilisearch_health", needs: []string{"hostname", "port"},
+		run: func(ctx context.Context, httpClient HTTPDoer, env map[string]string) (string, error) {
+			return probe.Meilisearch(ctx, httpClient, "http://"+probeAddr(env), env["masterKey"])
+		}},
+	"elasticsearch": {check: "elasticsearch_health", needs: []string{"hostname", "port"},
+		run: func(ctx context.Context, httpClient HTTPDoer, env map[string]string) (string, error) {
+			return probe.Elasticsearch(ctx, httpClient, "http://"+probeAddr(env), "elastic", env["password"])
+		}},
+}
+
+func probeSQL(fn func(ctx context.Context, addr, user, password, database string) (string, error)) func(context.Context, HTTPDoer, map[string]string) (string, error) {
+	return func(ctx context.Context, _ HTTPDoer, env map[string]string) (string, error) {
+		return fn(ctx, probeAddr(env), env["user"], env["password"], env["dbName"])
+	}
+}
+
+func probeValkey(ctx context.Context, _ HTTPDoer, env map[string]string) (string, error) {
+	return probe.Valkey(ctx, probeAddr(env), env["user"], env["password"])
+}
+
+func probeNATS(ctx context.Context, _ HTTPDoer, env map[string]string) (string, error) {
+	return probe.NATS(ctx, probeAddr(env), env["user"], env["password"])
+}
+
+func probeAddr(env map[string]string) string {
+	return net.JoinHostPort(env["hostname"], env["port"])
+}
+
+// managedProbeFor returns the probe for a service type like "postgresql@16".
+func managedProbeFor(typeVersion string) (managedProbe, bool) {
+	base, _, _ := strings.Cut(strings.ToLower(typeVersion), "@")
+	p, ok := managedProbes[base]
+	return p, ok
+}
+
+// checkManagedProtocol opens a real protocol session to a running managed
+// service with the credentials from its env vars. Returns nil for families
+// without a probe. A hostname that does not resolve (local mode without
+// the VPN) is a skip, not a failure.
+func checkManagedProtocol(ctx context.Context, client platform.Client, httpClient HTTPDoer, svc *platform.ServiceStack) *CheckResult {
+	p, ok := managedProbeFor(svc.ServiceStackTypeInfo.ServiceStackTypeVersionName)
+	if !ok {
+		return nil
+	}
+	result := &CheckResult{Name: p.check}
+
+	vars, err := client.GetServiceEnv(ctx, svc.ID)
+	if err != nil {
+		result.Status, result.Detail = CheckSkip, fmt.Sprintf("cannot read service env vars: %v", err)
+		return result
+	}
+	env := make(map[string]string, len(vars))
+	for _, v := range vars {
+		env[v.Key] = v.Content
+	}
+	var missing []string
+	for _, k := range p.needs {
+		if env[k] == "" {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) > 0 {
+		result.Status, result.Detail = CheckSkip, "missing env vars: "+strings.Join(missing, ", ")
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	start := time.Now()
+	detail, err := p.run(ctx, httpClient, env)
+	if err == nil {
+		result.Status, result.Detail = CheckPass, fmt.Sprintf("%s in %s", detail, time.Since(start).Round(time.Millisecond))
+		return result
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		result.Status, result.Detail = CheckSkip, fmt.Sprintf("%s does not resolve from here (run inside the project or connect the VPN)", dnsErr.Name)
+		return result
+	}
+	result.Status, result.Detail = CheckFail, err.Error()
+	result.Recovery = &Recovery{
+		Tool:   "zerops_logs",
+		Action: "summary",
+		Args:   map[string]string{"serviceHostname": svc.Name, "mode": "summary", "since": "15m"},
+	}
+	return result
+}
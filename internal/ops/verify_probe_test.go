@@ -0,0 +1,155 @@
+// Tests for: ops/verify_probe.go — managed-service protocol checks.
+package ops
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// pongServer is a stand-in Valkey that answers every RESP command with
+// +PONG after draining it.
+func pongServer(t *testing.T) (host, port string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				r := bufio.NewReader(c)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if strings.HasPrefix(line, "*") {
+						continue
+					}
+					if strings.HasPrefix(line, "PING") {
+						_, _ = c.Write([]byte("+PONG\r\n"))
+					}
+				}
+			}()
+		}
+	}()
+	host, port, _ = net.SplitHostPort(ln.Addr().String())
+	return host, port
+}
+
+func closedPort(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	ln.Close()
+	return port
+}
+
+func TestVerify_ManagedProtocolProbe(t *testing.T) {
+	t.Parallel()
+
+	host, port := pongServer(t)
+	cache := func(typ string) platform.ServiceStack {
+		return platform.ServiceStack{ID: "svc-cache", Name: "cache", Status: "RUNNING",
+			ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: typ, ServiceStackTypeCategoryName: "STANDARD"}}
+	}
+	env := func(host, port string) []platform.EnvVar {
+		return []platform.EnvVar{{Key: "hostname", Content: host}, {Key: "port", Content: port}}
+	}
+
+	tests := []struct {
+		name       string
+		svc        platform.ServiceStack
+		env        []platform.EnvVar
+		wantCheck  string // "" = no probe row
+		wantStatus string
+		wantDetail string
+		wantResult string
+	}{
+		{"valkey pong", cache("valkey@7.2"), env(host, port), "valkey_ping", CheckPass, "PING ok", StatusHealthy},
+		{"keydb pong", cache("keydb@6"), env(host, port), "keydb_ping", CheckPass, "PING ok", StatusHealthy},
+		{"refused", cache("valkey@7.2"), env("127.0.0.1", closedPort(t)), "valkey_ping", CheckFail, "unreachable", StatusDegraded},
+		{"no env", cache("valkey@7.2"), env("", port), "valkey_ping", CheckSkip, "missing env vars: hostname", StatusHealthy},
+		{"no probe", cache("rabbitmq@3.9"), nil, "", "", "", StatusHealthy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mock := platform.NewMock().
+				WithServices([]platform.ServiceStack{tt.svc}).
+				WithServiceEnv("svc-cache", tt.env)
+
+			result, err := Verify(context.Background(), mock, platform.NewMockLogFetcher(), handlerDoer{}, "proj-1", "cache", nil)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if result.Status != tt.wantResult {
+				t.Errorf("status = %s, want %s; checks: %+v", result.Status, tt.wantResult, result.Checks)
+			}
+			if tt.wantCheck == "" {
+				if len(result.Checks) != 1 {
+					t.Errorf("checks = %v, want service_running only", checkNames(result.Checks))
+				}
+				return
+			}
+			c := findCheck(t, result, tt.wantCheck, tt.wantStatus)
+			if !strings.Contains(c.Detail, tt.wantDetail) {
+				t.Errorf("detail = %q, want %q", c.Detail, tt.wantDetail)
+			}
+			if (c.Status == CheckFail) != (c.Recovery != nil) {
+				t.Errorf("recovery = %+v; only failing probes carry one", c.Recovery)
+			}
+		})
+	}
+}
+
+func TestVerify_ManagedProbeSkippedWhenNotRunning(t *testing.T) {
+	t.Parallel()
+
+	host, port := pongServer(t)
+	mock := platform.NewMock().
+		WithServices([]platform.ServiceStack{{ID: "svc-cache", Name: "cache", Status: "STOPPED",
+			ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "valkey@7.2", ServiceStackTypeCategoryName: "STANDARD"}}}).
+		WithServiceEnv("svc-cache", []platform.EnvVar{{Key: "hostname", Content: host}, {Key: "port", Content: port}})
+
+	result, err := Verify(context.Background(), mock, platform.NewMockLogFetcher(), handlerDoer{}, "proj-1", "cache", nil)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if len(result.Checks) != 1 || result.Status != StatusUnhealthy {
+		t.Errorf("checks = %v status = %s, want service_running fail only", checkNames(result.Checks), result.Status)
+	}
+}
+
+func TestManagedProbeFor(t *testing.T) {
+	t.Parallel()
+
+	for typ, want := range map[string]string{
+		"postgresql@16":   "postgresql_query",
+		"MariaDB@10.6":    "mariadb_query",
+		"nats@2.10":       "nats_connect",
+		"object-storage":  "storage_head_bucket",
+		"meilisearch@1":   "meilisearch_health",
+		"elasticsearch@8": "elasticsearch_health",
+		"shared-storage":  "",
+	} {
+		p, ok := managedProbeFor(typ)
+		if ok != (want != "") || p.check != want {
+			t.Errorf("managedProbeFor(%q) = %q, %v; want %q", typ, p.check, ok, want)
+		}
+	}
+}
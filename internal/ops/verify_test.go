@@ -171,7 +171,7 @@ func TestVerify_DynamicRuntime_AllChecks(t *testing.T) {
 		return nil, nil
 	}}
 
-	result, err := Verify(context.Background(), mock, fetcher, http.DefaultClient, "proj-1", "app")
+	result, err := Verify(context.Background(), mock, fetcher, http.DefaultClient, "proj-1", "app", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -203,7 +203,7 @@ func TestVerify_RuntimeStopped(t *testing.T) {
 			{ID: "svc-1", Name: "app", ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "nodejs@22", ServiceStackTypeCategoryName: "USER"}, Status: "READY_TO_DEPLOY", Ports: []platform.Port{{Port: 3000}}},
 		})
 
-	result, err := Verify(context.Background(), mock, platform.NewMockLogFetcher(), http.DefaultClient, "proj-1", "app")
+	result, err := Verify(context.Background(), mock, platform.NewMockLogFetcher(), http.DefaultClient, "proj-1", "app", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -248,7 +248,7 @@ func TestVerify_RuntimeErrorLogs(t *testing.T) {
 		return nil, nil
 	}}
 
-	result, err := Verify(context.Background(), mock, fetcher, http.DefaultClient, "proj-1", "app")
+	result, err := Verify(context.Background(), mock, fetcher, http.DefaultClient, "proj-1", "app", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -275,7 +275,7 @@ func TestVerify_RuntimeNoSubdomain(t *testing.T) {
 		return nil, nil
 	}}
 
-	result, err := Verify(context.Background(), mock, fetcher, http.DefaultClient, "proj-1", "app")
+	result, err := Verify(context.Background(), mock, fetcher, http.DefaultClient, "proj-1", "app", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -318,7 +318,7 @@ func TestVerify_RuntimeSubdomainAccessUnresolved_SkipsHTTPRoot(t *testing.T) {
 		return nil, nil
 	}}
 
-	result, err := Verify(context.Background(), mock, fetcher, http.DefaultClient, "proj-1", "app")
+	result, err := Verify(context.Background(), mock, fetcher, http.DefaultClient, "proj-1", "app", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -362,7 +362,7 @@ func TestVerify_RuntimeCrashLoop(t *testing.T) {
 		return nil, nil
 	}}
 
-	result, err := Verify(context.Background(), mock, fetcher, http.DefaultClient, "proj-1", "app")
+	result, err := Verify(context.Background(), mock, fetcher, http.DefaultClient, "proj-1", "app", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -389,7 +389,7 @@ func TestVerify_StaticRuntime_SkipsStatusAndStartup(t *testing.T) {
 		}).
 		WithLogAccess(&platform.LogAccess{URL: "http://logs.test"})
 
-	result, err := Verify(context.Background(), mock, platform.NewMockLogFetcher(), http.DefaultClient, "proj-1", "web")
+	result, err := Verify(context.Background(), mock, platform.NewMockLogFetcher(), http.DefaultClient, "proj-1", "web", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -418,7 +418,7 @@ func TestVerify_ImplicitWebserver_SkipsStartup(t *testing.T) {
 		return nil, nil
 	}}
 
-	result, err := Verify(context.Background(), mock, fetcher, http.DefaultClient, "proj-1", "phpapp")
+	result, err := Verify(context.Background(), mock, fetcher, http.DefaultClient, "proj-1", "phpapp", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -448,7 +448,7 @@ func TestVerify_WorkerRuntime_NoHTTPChecks(t *testing.T) {
 		return nil, nil
 	}}
 
-	result, err := Verify(context.Background(), mock, fetcher, http.DefaultClient, "proj-1", "worker")
+	result, err := Verify(context.Background(), mock, fetcher, http.DefaultClient, "proj-1", "worker", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -479,7 +479,7 @@ func TestVerify_ManagedRunning(t *testing.T) {
 			{ID: "svc-1", Name: "db", ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "postgresql@16", ServiceStackTypeCategoryName: "STANDARD"}, Status: "RUNNING"},
 		})
 
-	result, err := Verify(context.Background(), mock, platform.NewMockLogFetcher(), http.DefaultClient, "proj-1", "db")
+	result, err := Verify(context.Background(), mock, platform.NewMockLogFetcher(), http.DefaultClient, "proj-1", "db", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -493,8 +493,8 @@ func TestVerify_ManagedRunning(t *testing.T) {
 	if result.Status != "healthy" {
 		t.Errorf("Status = %q, want healthy", result.Status)
 	}
-	if len(result.Checks) != 1 {
-		t.Fatalf("Checks count = %d, want 1", len(result.Checks))
+	if len(result.Checks) != 2 {
+		t.Fatalf("Checks count = %d, want 2; checks: %v", len(result.Checks), checkNames(result.Checks))
 	}
 	if result.Checks[0].Name != "service_running" {
 		t.Errorf("Check name = %q, want service_running", result.Checks[0].Name)
@@ -502,6 +502,8 @@ func TestVerify_ManagedRunning(t *testing.T) {
 	if result.Checks[0].Status != "pass" {
 		t.Errorf("Check status = %q, want pass", result.Checks[0].Status)
 	}
+	// No env vars in the mock — the protocol probe cannot log in.
+	findCheck(t, result, "postgresql_query", "skip")
 }
 
 func TestVerify_ManagedStopped(t *testing.T) {
@@ -512,7 +514,7 @@ func TestVerify_ManagedStopped(t *testing.T) {
 			{ID: "svc-1", Name: "db", ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "postgresql@16", ServiceStackTypeCategoryName: "STANDARD"}, Status: "RESTARTING"},
 		})
 
-	result, err := Verify(context.Background(), mock, platform.NewMockLogFetcher(), http.DefaultClient, "proj-1", "db")
+	result, err := Verify(context.Background(), mock, platform.NewMockLogFetcher(), http.DefaultClient, "proj-1", "db", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -537,7 +539,7 @@ func TestVerify_LogFetchError(t *testing.T) {
 		}).
 		WithError("GetProjectLog", fmt.Errorf("log backend down"))
 
-	result, err := Verify(context.Background(), mock, platform.NewMockLogFetcher(), http.DefaultClient, "proj-1", "app")
+	result, err := Verify(context.Background(), mock, platform.NewMockLogFetcher(), http.DefaultClient, "proj-1", "app", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -563,7 +565,7 @@ func TestVerify_ServiceNotFound(t *testing.T) {
 			{ID: "svc-1", Name: "app"},
 		})
 
-	_, err := Verify(context.Background(), mock, platform.NewMockLogFetcher(), http.DefaultClient, "proj-1", "nonexistent")
+	_, err := Verify(context.Background(), mock, platform.NewMockLogFetcher(), http.DefaultClient, "proj-1", "nonexistent", nil)
 	if err == nil {
 		t.Fatal("expected error for nonexistent service")
 	}
@@ -923,7 +925,7 @@ func TestVerifyAll_AllHealthy(t *testing.T) {
 		return nil, nil
 	}}
 
-	result, err := VerifyAll(context.Background(), mock, fetcher, http.DefaultClient, "proj-1")
+	result, err := VerifyAll(context.Background(), mock, fetcher, http.DefaultClient, "proj-1", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -948,7 +950,7 @@ func TestVerifyAll_MixedResults(t *testing.T) {
 			{ID: "svc-2", Name: "db", ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "postgresql@16", ServiceStackTypeCategoryName: "STANDARD"}, Status: "RUNNING"},
 		})
 
-	result, err := VerifyAll(context.Background(), mock, platform.NewMockLogFetcher(), http.DefaultClient, "proj-1")
+	result, err := VerifyAll(context.Background(), mock, platform.NewMockLogFetcher(), http.DefaultClient, "proj-1", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -970,7 +972,7 @@ func TestVerifyAll_EmptyProject(t *testing.T) {
 	mock := platform.NewMock().
 		WithServices([]platform.ServiceStack{})
 
-	result, err := VerifyAll(context.Background(), mock, platform.NewMockLogFetcher(), http.DefaultClient, "proj-1")
+	result, err := VerifyAll(context.Background(), mock, platform.NewMockLogFetcher(), http.DefaultClient, "proj-1", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -1003,7 +1005,7 @@ func TestVerifyAll_SingleListServicesCall(t *testing.T) {
 		return nil, nil
 	}}
 
-	result, err := VerifyAll(context.Background(), mock, fetcher, http.DefaultClient, "proj-1")
+	result, err := VerifyAll(context.Background(), mock, fetcher, http.DefaultClient, "proj-1", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
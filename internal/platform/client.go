@@ -1,6 +1,9 @@
 package platform
 
-import "context"
+import (
+	"context"
+	"io"
+)
 
 // Client is the interface for Zerops API operations.
 // Mocked in tests, real implementation wraps zerops-go SDK.
@@ -72,6 +75,18 @@ type Client interface {
 	SearchProcesses(ctx context.Context, projectID string, limit int) ([]ProcessEvent, error)
 	SearchAppVersions(ctx context.Context, projectID string, limit int) ([]AppVersionEvent, error)
 
+	// DeployAppVersion re-deploys an already-built app version onto its
+	// service (async -- return process). No build runs; the platform swaps
+	// the runtime containers to the stored artifact. Used for rollback.
+	DeployAppVersion(ctx context.Context, appVersionID string) (*Process, error)
+
+	// Native push (3-step, what `zcli push` does): create an app version
+	// to get an upload URL, PUT the tar.gz archive there, then trigger
+	// build + deploy with the zerops.yaml content (async -- return process).
+	CreateAppVersion(ctx context.Context, serviceID string) (*AppVersionUpload, error)
+	UploadAppVersion(ctx context.Context, upload *AppVersionUpload, archive io.Reader, size int64) error
+	BuildAndDeployAppVersion(ctx context.Context, appVersionID, zeropsYaml, setup string) (*Process, error)
+
 	// Service stack types (public, no auth required for search)
 	ListServiceStackTypes(ctx context.Context) ([]ServiceStackType, error)
 }
@@ -48,6 +48,10 @@ const (
 	ErrMissingEvidence        = "MISSING_EVIDENCE"
 	ErrTopicEmpty             = "TOPIC_EMPTY"
 	ErrWorkSessionCorrupt     = "WORK_SESSION_CORRUPT"
+	// ErrPolicyDenied signals a call refused by .zcp/policy.yaml (read-only
+	// mode, protected hostname, tool allow/deny list). Not retryable — the
+	// suggestion names the allowed alternative.
+	ErrPolicyDenied = "POLICY_DENIED"
 	// ErrPreflightFailed signals a deploy preflight check failure. Carried
 	// alongside structured CheckWire entries so the agent can re-run the
 	// failed check or fix the underlying issue. Replaces the legacy
@@ -39,6 +39,18 @@ type Mock struct {
 	// so deploy-flow tests can assert call ordering / field propagation.
 	CapturedValidateZeropsYaml []ValidateZeropsYamlInput
 
+	// CapturedDeployAppVersionIDs stores app version IDs passed to
+	// DeployAppVersion, in call order.
+	CapturedDeployAppVersionIDs []string
+
+	// CapturedUploads stores the archive bytes passed to UploadAppVersion,
+	// keyed by app version ID.
+	CapturedUploads map[string][]byte
+
+	// CapturedBuildAndDeploy stores BuildAndDeployAppVersion inputs, in
+	// call order.
+	CapturedBuildAndDeploy []MockBuildAndDeployCall
+
 	// CallCounts tracks how many times each method was called.
 	CallCounts map[string]int
 
@@ -3,6 +3,7 @@ package platform
 import (
 	"context"
 	"fmt"
+	"io"
 )
 
 // ValidateZeropsYaml records the call (capturing inputs for test assertions)
@@ -152,6 +153,7 @@ func (m *Mock) DisconnectSharedStorage(_ context.Context, serviceID, _ string) (
 }
 
 func (m *Mock) SetAutoscaling(_ context.Context, _ string, _ AutoscalingParams) (*Process, error) {
+	m.trackCall("SetAutoscaling")
 	if err := m.getError("SetAutoscaling"); err != nil {
 		return nil, err
 	}
@@ -370,6 +372,82 @@ func (m *Mock) SearchAppVersions(_ context.Context, _ string, _ int) ([]AppVersi
 	return m.appVersionEvents, nil
 }
 
+// DeployAppVersion records the reactivated app version ID and returns a
+// PENDING process keyed "proc-deploy-version-<id>". Tests that poll the
+// result register the process (and optionally a ProcessScenario) under
+// that ID.
+func (m *Mock) DeployAppVersion(_ context.Context, appVersionID string) (*Process, error) {
+	m.trackCall("DeployAppVersion")
+	if err := m.getError("DeployAppVersion"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	m.CapturedDeployAppVersionIDs = append(m.CapturedDeployAppVersionIDs, appVersionID)
+	m.mu.Unlock()
+	return &Process{
+		ID:         "proc-deploy-version-" + appVersionID,
+		ActionName: "stack.deploy",
+		Status:     "PENDING",
+	}, nil
+}
+
+// MockBuildAndDeployCall is one captured BuildAndDeployAppVersion call.
+type MockBuildAndDeployCall struct {
+	AppVersionID string
+	ZeropsYaml   string
+	Setup        string
+}
+
+// CreateAppVersion returns app version "av-<serviceID>" with a mock://
+// upload URL. The ID is stable per service so tests can key process
+// registrations on it.
+func (m *Mock) CreateAppVersion(_ context.Context, serviceID string) (*AppVersionUpload, error) {
+	m.trackCall("CreateAppVersion")
+	if err := m.getError("CreateAppVersion"); err != nil {
+		return nil, err
+	}
+	id := "av-" + serviceID
+	return &AppVersionUpload{ID: id, ServiceStackID: serviceID, UploadURL: "mock://upload/" + id}, nil
+}
+
+// UploadAppVersion drains the archive into CapturedUploads.
+func (m *Mock) UploadAppVersion(_ context.Context, upload *AppVersionUpload, archive io.Reader, _ int64) error {
+	m.trackCall("UploadAppVersion")
+	if err := m.getError("UploadAppVersion"); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(archive)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	if m.CapturedUploads == nil {
+		m.CapturedUploads = make(map[string][]byte)
+	}
+	m.CapturedUploads[upload.ID] = data
+	m.mu.Unlock()
+	return nil
+}
+
+// BuildAndDeployAppVersion records the call and returns a PENDING process
+// keyed "proc-build-<appVersionID>".
+func (m *Mock) BuildAndDeployAppVersion(_ context.Context, appVersionID, zeropsYaml, setup string) (*Process, error) {
+	m.trackCall("BuildAndDeployAppVersion")
+	if err := m.getError("BuildAndDeployAppVersion"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	m.CapturedBuildAndDeploy = append(m.CapturedBuildAndDeploy, MockBuildAndDeployCall{
+		AppVersionID: appVersionID, ZeropsYaml: zeropsYaml, Setup: setup,
+	})
+	m.mu.Unlock()
+	return &Process{
+		ID:         "proc-build-" + appVersionID,
+		ActionName: "stack.build",
+		Status:     "PENDING",
+	}, nil
+}
+
 func (m *Mock) ListServiceStackTypes(_ context.Context) ([]ServiceStackType, error) {
 	if err := m.getError("ListServiceStackTypes"); err != nil {
 		return nil, err
@@ -179,3 +179,34 @@ func TestScenarioStatusAt(t *testing.T) {
 		}
 	}
 }
+
+func TestMockDeployAppVersion_PairsWithScenario(t *testing.T) {
+	t.Parallel()
+
+	mock := NewMock().
+		WithProcess(&Process{ID: "proc-deploy-version-av-1", ActionName: "stack.deploy"}).
+		WithProcessScenario("proc-deploy-version-av-1", ProcessScenario{
+			InitialStatus: "PENDING",
+			Transitions:   []ProcessTransition{{AtCall: 2, Status: "FINISHED"}},
+		})
+
+	proc, err := mock.DeployAppVersion(context.Background(), "av-1")
+	if err != nil {
+		t.Fatalf("DeployAppVersion: %v", err)
+	}
+	if proc.ID != "proc-deploy-version-av-1" {
+		t.Fatalf("process ID = %q, want proc-deploy-version-av-1", proc.ID)
+	}
+	if got := mock.CapturedDeployAppVersionIDs; len(got) != 1 || got[0] != "av-1" {
+		t.Errorf("captured IDs = %v, want [av-1]", got)
+	}
+	for i, want := range []string{"PENDING", "FINISHED"} {
+		got, err := mock.GetProcess(context.Background(), proc.ID)
+		if err != nil {
+			t.Fatalf("call %d: GetProcess: %v", i+1, err)
+		}
+		if got.Status != want {
+			t.Errorf("call %d: status = %q, want %q", i+1, got.Status, want)
+		}
+	}
+}
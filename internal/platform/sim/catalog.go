@@ -0,0 +1,159 @@
+package sim
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// Service categories, as ServiceStackTypeCategoryName reports them.
+const (
+	categoryUser          = "USER"
+	categoryStandard      = "STANDARD"
+	categoryObjectStorage = "OBJECT_STORAGE"
+	categorySharedStorage = "SHARED_STORAGE"
+)
+
+// managedPorts maps managed service type prefixes to their primary port.
+var managedPorts = map[string]int{
+	"postgresql":    5432,
+	"mariadb":       3306,
+	"valkey":        6379,
+	"keydb":         6379,
+	"elasticsearch": 9200,
+	"meilisearch":   7700,
+	"rabbitmq":      5672,
+	"kafka":         9092,
+	"nats":          4222,
+	"clickhouse":    9000,
+	"qdrant":        6333,
+	"typesense":     8108,
+}
+
+// category classifies a service type the way the platform does.
+func category(serviceType string) string {
+	base, _, _ := strings.Cut(strings.ToLower(serviceType), "@")
+	switch {
+	case strings.HasPrefix(base, "object-storage"):
+		return categoryObjectStorage
+	case strings.HasPrefix(base, "shared-storage"):
+		return categorySharedStorage
+	}
+	if _, ok := managedPorts[base]; ok {
+		return categoryStandard
+	}
+	return categoryUser
+}
+
+// defaultHTTPPort is the port a runtime deployed from git listens on when
+// the simulator has no zerops.yaml to read it from.
+func defaultHTTPPort(serviceType string) int {
+	if hasWebServer(serviceType) {
+		return 80
+	}
+	return 3000
+}
+
+// secret derives a stable credential so repeated runs of a scenario see
+// the same values.
+func secret(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "/")))
+	return hex.EncodeToString(sum[:])[:24]
+}
+
+// generateManagedEnv adds the variables the platform generates for a
+// managed service. connectionString keeps its references, as stored.
+func (st *State) generateManagedEnv(svc *Service) {
+	t := svc.ServiceStackTypeInfo.ServiceStackTypeVersionName
+	base, _, _ := strings.Cut(strings.ToLower(t), "@")
+	st.setEnv(svc, "hostname", svc.Name)
+	switch category(t) {
+	case categoryStandard:
+		st.setEnv(svc, "port", strconv.Itoa(managedPorts[base]))
+		st.setEnv(svc, "user", svc.Name)
+		st.setEnv(svc, "password", secret(st.Project.ID, svc.Name, "password"))
+		scheme := base
+		if scheme == "valkey" || scheme == "keydb" {
+			scheme = "redis"
+		}
+		st.setEnv(svc, "connectionString", scheme+"://${user}:${password}@${hostname}:${port}")
+	case categoryObjectStorage:
+		st.setEnv(svc, "apiUrl", "https://storage-prg1.zerops.io")
+		st.setEnv(svc, "accessKeyId", strings.ToUpper(secret(st.Project.ID, svc.Name, "key")[:20]))
+		st.setEnv(svc, "secretAccessKey", secret(st.Project.ID, svc.Name, "secret"))
+		st.setEnv(svc, "bucketName", svc.Name+"-"+secret(st.Project.ID, svc.Name)[:6])
+	}
+}
+
+// stackTypes is the catalogue ListServiceStackTypes serves.
+var stackTypes = []struct {
+	name, category string
+	versions       []string
+	build          bool
+}{
+	{"Node.js", categoryUser, []string{"nodejs@20", "nodejs@22"}, true},
+	{"Bun", categoryUser, []string{"bun@1.2"}, true},
+	{"Deno", categoryUser, []string{"deno@2"}, true},
+	{"Go", categoryUser, []string{"go@1"}, true},
+	{"Python", categoryUser, []string{"python@3.12"}, true},
+	{"PHP + Nginx", categoryUser, []string{"php-nginx@8.3", "php-nginx@8.4"}, false},
+	{"PHP + Apache", categoryUser, []string{"php-apache@8.3", "php-apache@8.4"}, false},
+	{"PHP", categoryUser, []string{"php@8.3", "php@8.4"}, true},
+	{"Java", categoryUser, []string{"java@21"}, true},
+	{"Rust", categoryUser, []string{"rust@1"}, true},
+	{"Static", categoryUser, []string{"static"}, false},
+	{"Nginx", categoryUser, []string{"nginx@1.22"}, false},
+	{"Alpine", categoryUser, []string{"alpine@3.21"}, false},
+	{"Ubuntu", categoryUser, []string{"ubuntu@24.04"}, false},
+	{"PostgreSQL", categoryStandard, []string{"postgresql@16", "postgresql@17", "postgresql@18"}, false},
+	{"MariaDB", categoryStandard, []string{"mariadb@10.6"}, false},
+	{"Valkey", categoryStandard, []string{"valkey@7.2"}, false},
+	{"KeyDB", categoryStandard, []string{"keydb@6"}, false},
+	{"Meilisearch", categoryStandard, []string{"meilisearch@1.10"}, false},
+	{"Typesense", categoryStandard, []string{"typesense@27.1"}, false},
+	{"NATS", categoryStandard, []string{"nats@2.10"}, false},
+	{"RabbitMQ", categoryStandard, []string{"rabbitmq@3.9"}, false},
+	{"Elasticsearch", categoryStandard, []string{"elasticsearch@8.16"}, false},
+	{"ClickHouse", categoryStandard, []string{"clickhouse@25.3"}, false},
+	{"Qdrant", categoryStandard, []string{"qdrant@1.12"}, false},
+	{"Kafka", categoryStandard, []string{"kafka@3.8"}, false},
+	{"Object Storage", categoryObjectStorage, []string{"object-storage"}, false},
+	{"Shared Storage", categorySharedStorage, []string{"shared-storage"}, false},
+}
+
+// knownType reports whether serviceType is in the catalogue.
+func knownType(serviceType string) bool {
+	for _, st := range stackTypes {
+		for _, v := range st.versions {
+			if v == serviceType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// typeID is the opaque stack-type ID ValidateZeropsYaml requires.
+func typeID(serviceType string) string { return "type-" + secret(serviceType)[:12] }
+
+// ListServiceStackTypes serves the simulated catalogue. Runtimes with a
+// build image list it as an extra IsBuild version.
+func (s *Sim) ListServiceStackTypes(_ context.Context) ([]platform.ServiceStackType, error) {
+	_ = s.call(func(*State) error { return nil })
+	out := make([]platform.ServiceStackType, 0, len(stackTypes))
+	for _, t := range stackTypes {
+		st := platform.ServiceStackType{Name: t.name, Category: t.category}
+		for _, v := range t.versions {
+			st.Versions = append(st.Versions, platform.ServiceStackTypeVersion{Name: v, Status: platform.ServiceStatusActive})
+			if t.build {
+				st.Versions = append(st.Versions, platform.ServiceStackTypeVersion{Name: v, IsBuild: true, Status: platform.ServiceStatusActive})
+			}
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
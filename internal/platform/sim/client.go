@@ -0,0 +1,409 @@
+package sim
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/zeropsio/zcp/internal/platform"
+	"gopkg.in/yaml.v3"
+)
+
+func (s *Sim) GetUserInfo(_ context.Context) (*platform.UserInfo, error) {
+	var out platform.UserInfo
+	_ = s.call(func(st *State) error { out = st.User; return nil })
+	return &out, nil
+}
+
+func (s *Sim) ListProjects(_ context.Context, _ string) ([]platform.Project, error) {
+	var out []platform.Project
+	_ = s.call(func(st *State) error { out = []platform.Project{st.Project}; return nil })
+	return out, nil
+}
+
+func (s *Sim) GetProject(_ context.Context, projectID string) (*platform.Project, error) {
+	var out platform.Project
+	err := s.call(func(st *State) error {
+		out = st.Project
+		return st.checkProject(projectID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *Sim) ListServices(_ context.Context, projectID string) ([]platform.ServiceStack, error) {
+	var out []platform.ServiceStack
+	err := s.call(func(st *State) error {
+		if err := st.checkProject(projectID); err != nil {
+			return err
+		}
+		for _, svc := range st.Services {
+			if !svc.Deleted {
+				out = append(out, st.serviceView(svc))
+			}
+		}
+		return nil
+	})
+	return out, err
+}
+
+func (s *Sim) GetService(_ context.Context, serviceID string) (*platform.ServiceStack, error) {
+	var out platform.ServiceStack
+	err := s.call(func(st *State) error {
+		svc, err := st.service(serviceID)
+		if err != nil {
+			return err
+		}
+		out = st.serviceView(svc)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// serviceView copies the API-visible part of svc.
+func (st *State) serviceView(svc *Service) platform.ServiceStack {
+	out := svc.ServiceStack
+	out.Ports = slices.Clone(svc.Ports)
+	if svc.CustomAutoscaling != nil {
+		as := *svc.CustomAutoscaling
+		out.CustomAutoscaling = &as
+		out.CurrentAutoscaling = &as
+	}
+	return out
+}
+
+// lifecycle queues a start/stop/restart/reload process on serviceID.
+func (s *Sim) lifecycle(serviceID, action string) (*platform.Process, error) {
+	var out *platform.Process
+	err := s.call(func(st *State) error {
+		svc, err := st.service(serviceID)
+		if err != nil {
+			return err
+		}
+		if svc.Status == statusDeleting {
+			return platform.NewPlatformError(platform.ErrInvalidParameter,
+				fmt.Sprintf("service %s is being deleted", svc.Name), "")
+		}
+		out = st.processView(st.newProcess(action, svc.ID, lifecycleTime))
+		return nil
+	})
+	return out, err
+}
+
+func (s *Sim) StartService(_ context.Context, serviceID string) (*platform.Process, error) {
+	return s.lifecycle(serviceID, actionStart)
+}
+
+func (s *Sim) StopService(_ context.Context, serviceID string) (*platform.Process, error) {
+	return s.lifecycle(serviceID, actionStop)
+}
+
+func (s *Sim) RestartService(_ context.Context, serviceID string) (*platform.Process, error) {
+	return s.lifecycle(serviceID, actionRestart)
+}
+
+func (s *Sim) ReloadService(_ context.Context, serviceID string) (*platform.Process, error) {
+	return s.lifecycle(serviceID, actionReload)
+}
+
+func (s *Sim) storage(serviceID, storageID, action string) (*platform.Process, error) {
+	var out *platform.Process
+	err := s.call(func(st *State) error {
+		svc, err := st.service(serviceID)
+		if err != nil {
+			return err
+		}
+		storage, err := st.service(storageID)
+		if err != nil {
+			return err
+		}
+		if storage.ServiceStackTypeInfo.ServiceStackTypeCategoryName != categorySharedStorage {
+			return platform.NewPlatformError(platform.ErrInvalidParameter,
+				fmt.Sprintf("service %s is not a shared storage", storage.Name), "")
+		}
+		p := st.newProcess(action, svc.ID, storageTime)
+		p.Target = storage.ID
+		out = st.processView(p)
+		return nil
+	})
+	return out, err
+}
+
+func (s *Sim) ConnectSharedStorage(_ context.Context, serviceID, storageID string) (*platform.Process, error) {
+	return s.storage(serviceID, storageID, actionStorageConnect)
+}
+
+func (s *Sim) DisconnectSharedStorage(_ context.Context, serviceID, storageID string) (*platform.Process, error) {
+	return s.storage(serviceID, storageID, actionStorageDisconn)
+}
+
+// SetAutoscaling applies synchronously (nil process), like most scaling
+// changes on the platform.
+func (s *Sim) SetAutoscaling(_ context.Context, serviceID string, params platform.AutoscalingParams) (*platform.Process, error) {
+	return nil, s.call(func(st *State) error {
+		svc, err := st.service(serviceID)
+		if err != nil {
+			return err
+		}
+		as := platform.CustomAutoscaling{HorizontalMinCount: 1, HorizontalMaxCount: 1, CPUMode: "SHARED", MinCPU: 1, MaxCPU: 5, MinRAM: 0.25, MaxRAM: 32, MinDisk: 1, MaxDisk: 100}
+		if svc.CustomAutoscaling != nil {
+			as = *svc.CustomAutoscaling
+		}
+		setInt := func(dst *int32, v *int32) {
+			if v != nil {
+				*dst = *v
+			}
+		}
+		setFloat := func(dst *float64, v *float64) {
+			if v != nil {
+				*dst = *v
+			}
+		}
+		setInt(&as.HorizontalMinCount, params.HorizontalMinCount)
+		setInt(&as.HorizontalMaxCount, params.HorizontalMaxCount)
+		setInt(&as.StartCPUCoreCount, params.VerticalStartCPU)
+		setInt(&as.MinCPU, params.VerticalMinCPU)
+		setInt(&as.MaxCPU, params.VerticalMaxCPU)
+		setFloat(&as.MinRAM, params.VerticalMinRAM)
+		setFloat(&as.MaxRAM, params.VerticalMaxRAM)
+		setFloat(&as.MinDisk, params.VerticalMinDisk)
+		setFloat(&as.MaxDisk, params.VerticalMaxDisk)
+		setFloat(&as.MinFreeRAMGB, params.VerticalMinFreeRAMGB)
+		setFloat(&as.MinFreeRAMPercent, params.VerticalMinFreeRAMPct)
+		setFloat(&as.MinFreeCPUCores, params.VerticalMinFreeCPUCores)
+		setFloat(&as.MinFreeCPUPercent, params.VerticalMinFreeCPUPct)
+		if params.VerticalCPUMode != nil {
+			as.CPUMode = *params.VerticalCPUMode
+		}
+		if params.VerticalSwapEnabled != nil {
+			as.SwapEnabled = *params.VerticalSwapEnabled
+		}
+		if as.HorizontalMinCount > as.HorizontalMaxCount || as.MinRAM > as.MaxRAM || as.MinCPU > as.MaxCPU {
+			return platform.NewPlatformError(platform.ErrInvalidScaling, "minimum exceeds maximum", "")
+		}
+		svc.CustomAutoscaling = &as
+		return nil
+	})
+}
+
+// exportService is one service in a re-importable export.
+type exportService struct {
+	Hostname              string            `yaml:"hostname"`
+	Type                  string            `yaml:"type"`
+	Mode                  string            `yaml:"mode,omitempty"`
+	EnableSubdomainAccess bool              `yaml:"enableSubdomainAccess,omitempty"`
+	EnvSecrets            map[string]string `yaml:"envSecrets,omitempty"`
+}
+
+func (st *State) exportService(svc *Service) exportService {
+	out := exportService{
+		Hostname:              svc.Name,
+		Type:                  svc.ServiceStackTypeInfo.ServiceStackTypeVersionName,
+		EnableSubdomainAccess: svc.SubdomainAccess,
+	}
+	if category(out.Type) != categoryUser {
+		out.Mode = svc.Mode
+		return out
+	}
+	for _, e := range svc.Env {
+		if e.Key == "hostname" || e.Key == envZeropsSubdomain {
+			continue
+		}
+		if out.EnvSecrets == nil {
+			out.EnvSecrets = make(map[string]string)
+		}
+		out.EnvSecrets[e.Key] = e.Content
+	}
+	return out
+}
+
+func (s *Sim) GetProjectExport(_ context.Context, projectID string) (string, error) {
+	var out string
+	err := s.call(func(st *State) error {
+		if err := st.checkProject(projectID); err != nil {
+			return err
+		}
+		doc := struct {
+			Project struct {
+				Name string `yaml:"name"`
+			} `yaml:"project"`
+			Services []exportService `yaml:"services"`
+		}{}
+		doc.Project.Name = st.Project.Name
+		for _, svc := range st.Services {
+			if !svc.Deleted {
+				doc.Services = append(doc.Services, st.exportService(svc))
+			}
+		}
+		data, err := yaml.Marshal(doc)
+		out = string(data)
+		return err
+	})
+	return out, err
+}
+
+func (s *Sim) GetServiceStackExport(_ context.Context, serviceID string) (string, error) {
+	var out string
+	err := s.call(func(st *State) error {
+		svc, err := st.service(serviceID)
+		if err != nil {
+			return err
+		}
+		data, err := yaml.Marshal(struct {
+			Services []exportService `yaml:"services"`
+		}{[]exportService{st.exportService(svc)}})
+		out = string(data)
+		return err
+	})
+	return out, err
+}
+
+// ValidateZeropsYaml checks what the platform validator rejects before a
+// build: unparseable YAML, a missing setup and a build.base the
+// catalogue does not know.
+func (s *Sim) ValidateZeropsYaml(_ context.Context, in platform.ValidateZeropsYamlInput) error {
+	return s.call(func(*State) error {
+		zs, err := parseSetup(in.ZeropsYaml, in.ServiceStackName)
+		if err != nil {
+			return err
+		}
+		for _, base := range zs.Build.Base {
+			if !knownType(base) {
+				pe := invalidYAML("zeropsYamlInvalidParameter", fmt.Sprintf("build.base %q is not a known build base", base))
+				pe.APIMeta = []platform.APIMetaItem{{Code: "zeropsYamlInvalidParameter", Error: "invalid build.base",
+					Metadata: map[string][]string{"build.base": {base}}}}
+				return pe
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Sim) DeleteService(_ context.Context, serviceID string) (*platform.Process, error) {
+	var out *platform.Process
+	err := s.call(func(st *State) error {
+		svc, err := st.service(serviceID)
+		if err != nil {
+			return err
+		}
+		svc.Status = statusDeleting
+		out = st.processView(st.newProcess(actionDelete, svc.ID, deleteTime))
+		return nil
+	})
+	return out, err
+}
+
+func (s *Sim) GetProcess(_ context.Context, processID string) (*platform.Process, error) {
+	var out *platform.Process
+	err := s.call(func(st *State) error {
+		p, err := st.process(processID)
+		if err != nil {
+			return err
+		}
+		out = st.processView(p)
+		return nil
+	})
+	return out, err
+}
+
+func (s *Sim) CancelProcess(_ context.Context, processID string) (*platform.Process, error) {
+	var out *platform.Process
+	err := s.call(func(st *State) error {
+		p, err := st.process(processID)
+		if err != nil {
+			return err
+		}
+		switch p.status(st.Now) {
+		case platform.ProcessStatusPending, platform.ProcessStatusRunning:
+		default:
+			return platform.NewPlatformError(platform.ErrProcessAlreadyTerminal,
+				fmt.Sprintf("process %s is already %s", p.ID, p.status(st.Now)), "")
+		}
+		p.Canceled = true
+		if p.Action == actionDelete {
+			if svc, err := st.service(p.ServiceID); err == nil {
+				svc.Status = platform.ServiceStatusActive
+			}
+		}
+		out = st.processView(p)
+		return nil
+	})
+	return out, err
+}
+
+// SearchProcesses returns the newest processes first.
+func (s *Sim) SearchProcesses(_ context.Context, projectID string, limit int) ([]platform.ProcessEvent, error) {
+	var out []platform.ProcessEvent
+	err := s.call(func(st *State) error {
+		if err := st.checkProject(projectID); err != nil {
+			return err
+		}
+		for i := len(st.Processes) - 1; i >= 0 && (limit <= 0 || len(out) < limit); i-- {
+			out = append(out, st.processEvent(st.Processes[i]))
+		}
+		return nil
+	})
+	return out, err
+}
+
+// SearchAppVersions returns the newest app versions first.
+func (s *Sim) SearchAppVersions(_ context.Context, projectID string, limit int) ([]platform.AppVersionEvent, error) {
+	var out []platform.AppVersionEvent
+	err := s.call(func(st *State) error {
+		if err := st.checkProject(projectID); err != nil {
+			return err
+		}
+		for _, av := range st.appVersionsNewestFirst() {
+			if limit > 0 && len(out) >= limit {
+				break
+			}
+			out = append(out, st.appVersionEvent(av))
+		}
+		return nil
+	})
+	return out, err
+}
+
+// DeployAppVersion re-activates a built version (rollback). Only a
+// version that built successfully can be deployed.
+func (s *Sim) DeployAppVersion(_ context.Context, appVersionID string) (*platform.Process, error) {
+	var out *platform.Process
+	err := s.call(func(st *State) error {
+		av := st.appVersion(appVersionID)
+		if av == nil {
+			return platform.NewPlatformError(platform.ErrInvalidParameter, "app version "+appVersionID+" not found", "")
+		}
+		if av.Outcome != avActive || av.status(st.Now) != platform.AppVersionStatusBackup && av.status(st.Now) != avActive {
+			return platform.NewPlatformError(platform.ErrInvalidParameter,
+				fmt.Sprintf("app version %s has no deployable artifact (status %s)", av.ID, av.status(st.Now)), "")
+		}
+		svc, err := st.service(av.ServiceID)
+		if err != nil {
+			return err
+		}
+		p := st.newProcess(actionDeployVersion, svc.ID, redeployTime)
+		p.Target = av.ID
+		out = st.processView(p)
+		return nil
+	})
+	return out, err
+}
+
+// ServiceByName returns the service with hostname, for assertions and
+// tooling around the simulator.
+func (s *Sim) ServiceByName(hostname string) (*platform.ServiceStack, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+	svc := s.st.serviceByName(hostname)
+	if svc == nil {
+		return nil, false
+	}
+	out := s.st.serviceView(svc)
+	return &out, true
+}
@@ -0,0 +1,383 @@
+package sim
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeropsio/zcp/internal/platform"
+	"gopkg.in/yaml.v3"
+)
+
+// App version sources and the statuses the simulator reports beyond the
+// platform constants.
+const (
+	sourceCLI  = "CLI"
+	sourceGit  = "GIT"
+	sourceNone = "NONE"
+
+	avUploading = "UPLOADING"
+	avWaiting   = "WAITING_TO_BUILD"
+	avDeploying = "DEPLOYING"
+	avActive    = "ACTIVE"
+)
+
+// AppVersion is a simulated build + deploy. The outcome is decided when
+// the version is created; the virtual clock reveals it step by step.
+type AppVersion struct {
+	ID        string    `json:"id"`
+	ServiceID string    `json:"serviceId"`
+	Source    string    `json:"source"`
+	Sequence  int       `json:"sequence"`
+	Setup     string    `json:"setup,omitempty"`
+	Created   time.Time `json:"created"`
+	BuildAt   time.Time `json:"buildAt"`  // pipeline start
+	DeployAt  time.Time `json:"deployAt"` // build done, runtime container creation
+	DoneAt    time.Time `json:"doneAt"`
+	// Outcome is the terminal status: ACTIVE, BUILD_FAILED or DEPLOY_FAILED.
+	Outcome string `json:"outcome"`
+	// Backup marks a formerly active version kept for rollback.
+	Backup  bool `json:"backup,omitempty"`
+	Applied bool `json:"applied,omitempty"`
+	NoBuild bool `json:"noBuild,omitempty"` // startWithoutCode: no pipeline
+
+	Start   string            `json:"start,omitempty"`
+	Ports   []platform.Port   `json:"ports,omitempty"`
+	YAMLEnv map[string]string `json:"yamlEnv,omitempty"`
+}
+
+// zeropsYAML is the subset of zerops.yaml the simulated builder reads.
+type zeropsYAML struct {
+	Zerops []zeropsSetup `yaml:"zerops"`
+}
+
+type zeropsSetup struct {
+	Setup string `yaml:"setup"`
+	Build struct {
+		Base          yamlStrings `yaml:"base"`
+		BuildCommands []string    `yaml:"buildCommands"`
+		DeployFiles   yamlStrings `yaml:"deployFiles"`
+	} `yaml:"build"`
+	Run struct {
+		Base  string `yaml:"base"`
+		Ports []struct {
+			Port        int    `yaml:"port"`
+			Protocol    string `yaml:"protocol"`
+			HTTPSupport bool   `yaml:"httpSupport"`
+		} `yaml:"ports"`
+		Start        string            `yaml:"start"`
+		InitCommands []string          `yaml:"initCommands"`
+		EnvVariables map[string]string `yaml:"envVariables"`
+	} `yaml:"run"`
+}
+
+// yamlStrings accepts a scalar or a sequence of scalars, like the
+// zerops.yaml fields that take either form.
+type yamlStrings []string
+
+func (y *yamlStrings) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*y = []string{node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*y = list
+	return nil
+}
+
+// parseSetup finds setup in a zerops.yaml document.
+func parseSetup(doc, setup string) (*zeropsSetup, error) {
+	var parsed zeropsYAML
+	if err := yaml.Unmarshal([]byte(doc), &parsed); err != nil {
+		return nil, invalidYAML("yamlValidationInvalidYaml", "zerops.yaml is not valid YAML: "+err.Error())
+	}
+	names := make([]string, 0, len(parsed.Zerops))
+	for i := range parsed.Zerops {
+		if parsed.Zerops[i].Setup == setup {
+			return &parsed.Zerops[i], nil
+		}
+		names = append(names, parsed.Zerops[i].Setup)
+	}
+	return nil, invalidYAML("zeropsYamlSetupNotFound",
+		fmt.Sprintf("setup %q not found in zerops.yaml (found: %s)", setup, strings.Join(names, ", ")))
+}
+
+func invalidYAML(apiCode, msg string) *platform.PlatformError {
+	pe := platform.NewPlatformError(platform.ErrInvalidZeropsYml, msg, "Fix zerops.yaml and deploy again.")
+	pe.APICode = apiCode
+	return pe
+}
+
+// Push submits a build of zeropsYAML's setup to the service, the way
+// `zcli push` does. It fails fast on what the platform rejects before a
+// build starts (unknown service, missing setup, non-runtime target);
+// everything else becomes an app version whose outcome the virtual
+// clock reveals:
+//
+//   - build.base missing, deployFiles missing, or a buildCommand that
+//     always fails (`false`, `exit N` with N ≠ 0) → BUILD_FAILED;
+//   - an initCommand that always fails, or no run.start on a runtime
+//     without a built-in web server → DEPLOY_FAILED;
+//   - otherwise ACTIVE, with ports and run.envVariables taken from the
+//     setup. Env references that do not resolve are logged as warnings.
+func (s *Sim) Push(serviceID, setup, zeropsYAML string) (*platform.AppVersionEvent, error) {
+	var out *platform.AppVersionEvent
+	err := s.call(func(st *State) error {
+		svc, err := st.service(serviceID)
+		if err != nil {
+			return err
+		}
+		if category(svc.ServiceStackTypeInfo.ServiceStackTypeVersionName) != categoryUser {
+			return platform.NewPlatformError(platform.ErrInvalidParameter,
+				fmt.Sprintf("service %s is not a runtime service and cannot be deployed to", svc.Name), "")
+		}
+		if setup == "" {
+			setup = svc.Name
+		}
+		zs, err := parseSetup(zeropsYAML, setup)
+		if err != nil {
+			return err
+		}
+		av := st.newBuild(st.nextID("av"), svc, sourceCLI, setup, zs)
+		ev := st.appVersionEvent(av)
+		out = &ev
+		return nil
+	})
+	return out, err
+}
+
+// newBuild plans the pipeline for zs on svc and records its logs.
+func (st *State) newBuild(id string, svc *Service, source, setup string, zs *zeropsSetup) *AppVersion {
+	av := &AppVersion{
+		ID:        id,
+		ServiceID: svc.ID,
+		Source:    source,
+		Sequence:  st.nextSequence(svc.ID),
+		Setup:     setup,
+		Created:   st.Now,
+		BuildAt:   st.Now.Add(2 * queueDelay),
+	}
+	st.AppVersions = append(st.AppVersions, av)
+	tag := "zbuilder@" + av.ID
+	buildStack := svc.ID + "-build"
+	t := av.BuildAt
+	logf := func(severity, format string, args ...any) {
+		t = t.Add(time.Second)
+		st.addLog(buildStack, platform.LogEntry{
+			Timestamp: stamp(t), Severity: severity, Facility: facilityApp, Tag: tag,
+			Message: fmt.Sprintf(format, args...), Container: svc.Name + "-build",
+		})
+	}
+
+	var base string
+	if len(zs.Build.Base) > 0 {
+		base = zs.Build.Base[0]
+	}
+	buildEnd := av.BuildAt.Add(buildBaseTime)
+	switch {
+	case base == "":
+		logf("Error", "build.base is required in setup %q", setup)
+		av.Outcome = platform.BuildStatusBuildFailed
+	case len(zs.Build.DeployFiles) == 0:
+		logf("Informational", "preparing build container %s", strings.Join(zs.Build.Base, ", "))
+		logf("Error", "build.deployFiles is required in setup %q", setup)
+		av.Outcome = platform.BuildStatusBuildFailed
+	default:
+		logf("Informational", "preparing build container %s", strings.Join(zs.Build.Base, ", "))
+		for _, cmd := range zs.Build.BuildCommands {
+			buildEnd = buildEnd.Add(buildCommandTime)
+			logf("Informational", "$ %s", cmd)
+			if code := failingExit(cmd); code != 0 {
+				logf("Error", "command %q exited with status %d", cmd, code)
+				av.Outcome = platform.BuildStatusBuildFailed
+				break
+			}
+		}
+		if av.Outcome == "" {
+			logf("Informational", "creating deploy artifact from %s", strings.Join(zs.Build.DeployFiles, ", "))
+		}
+	}
+	av.DeployAt = buildEnd
+	if av.Outcome == platform.BuildStatusBuildFailed {
+		av.DoneAt = buildEnd
+		return av
+	}
+
+	av.DoneAt = buildEnd.Add(deployTime)
+	av.Start = zs.Run.Start
+	av.YAMLEnv = zs.Run.EnvVariables
+	for _, p := range zs.Run.Ports {
+		proto := strings.ToUpper(p.Protocol)
+		if proto == "" {
+			proto = "TCP"
+		}
+		av.Ports = append(av.Ports, platform.Port{Port: p.Port, Protocol: proto, Public: true, HTTPSupport: p.HTTPSupport})
+	}
+	t = av.DeployAt
+	runtimeLog := func(severity, format string, args ...any) {
+		t = t.Add(time.Second)
+		st.addLog(svc.ID, platform.LogEntry{
+			Timestamp: stamp(t), Severity: severity, Facility: facilityApp,
+			Message: fmt.Sprintf(format, args...), Container: svc.Name + "-1",
+		})
+	}
+	for _, cmd := range zs.Run.InitCommands {
+		runtimeLog("Informational", "init: $ %s", cmd)
+		if code := failingExit(cmd); code != 0 {
+			runtimeLog("Error", "init command %q exited with status %d", cmd, code)
+			av.Outcome = platform.BuildStatusDeployFailed
+			return av
+		}
+	}
+	if av.Start == "" && !hasWebServer(svc.ServiceStackTypeInfo.ServiceStackTypeVersionName) {
+		runtimeLog("Error", "run.start is required for %s", svc.ServiceStackTypeInfo.ServiceStackTypeVersionName)
+		av.Outcome = platform.BuildStatusDeployFailed
+		return av
+	}
+	av.Outcome = avActive
+	return av
+}
+
+// failingExit reports the exit status of a command that always fails:
+// `false`, or `exit N` with N ≠ 0. Anything else is assumed to succeed.
+func failingExit(cmd string) int {
+	fields := strings.Fields(cmd)
+	switch {
+	case len(fields) == 0:
+		return 0
+	case fields[0] == "false":
+		return 1
+	case fields[0] == "exit" && len(fields) > 1:
+		n, _ := strconv.Atoi(fields[1])
+		return n
+	}
+	return 0
+}
+
+// hasWebServer reports whether the runtime serves HTTP without run.start.
+func hasWebServer(serviceType string) bool {
+	t := strings.ToLower(serviceType)
+	return strings.HasPrefix(t, "php") || strings.HasPrefix(t, "static") || strings.HasPrefix(t, "nginx")
+}
+
+func (st *State) nextSequence(serviceID string) int {
+	n := 0
+	for _, av := range st.AppVersions {
+		if av.ServiceID == serviceID {
+			n = max(n, av.Sequence)
+		}
+	}
+	return n + 1
+}
+
+func (st *State) appVersion(id string) *AppVersion {
+	for _, av := range st.AppVersions {
+		if av.ID == id {
+			return av
+		}
+	}
+	return nil
+}
+
+// applyBuild finishes a build whose DoneAt has passed.
+func (st *State) applyBuild(av *AppVersion) {
+	av.Applied = true
+	if av.Outcome != avActive {
+		return
+	}
+	if svc, err := st.service(av.ServiceID); err == nil {
+		st.activate(svc, av, av.DoneAt)
+	}
+}
+
+// activate makes av the version serving svc; the previous one becomes a
+// rollback candidate.
+func (st *State) activate(svc *Service, av *AppVersion, at time.Time) {
+	if prev := st.appVersion(svc.ActiveVersion); prev != nil && prev != av {
+		prev.Backup = true
+	}
+	av.Backup = false
+	svc.ActiveVersion = av.ID
+	svc.Status = platform.ServiceStatusActive
+	svc.YAMLEnv = av.YAMLEnv
+	if len(av.Ports) > 0 || !av.NoBuild {
+		svc.Ports = av.Ports
+	}
+	svc.LastUpdate = stamp(at)
+	if svc.SubdomainPending && hasHTTPPort(svc) {
+		svc.SubdomainPending = false
+		st.enableSubdomain(svc)
+	}
+	st.logRuntimeStart(svc, at)
+}
+
+// status derives the app version status at now.
+func (av *AppVersion) status(now time.Time) string {
+	switch {
+	case av.Backup:
+		return platform.AppVersionStatusBackup
+	case !now.Before(av.DoneAt):
+		return av.Outcome
+	case now.Before(av.Created.Add(queueDelay)):
+		return avUploading
+	case now.Before(av.BuildAt):
+		return avWaiting
+	case now.Before(av.DeployAt):
+		return platform.BuildStatusBuilding
+	default:
+		return avDeploying
+	}
+}
+
+func (st *State) appVersionEvent(av *AppVersion) platform.AppVersionEvent {
+	ev := platform.AppVersionEvent{
+		ID:             av.ID,
+		ProjectID:      st.Project.ID,
+		ServiceStackID: av.ServiceID,
+		Source:         av.Source,
+		Status:         av.status(st.Now),
+		Sequence:       av.Sequence,
+		Created:        stamp(av.Created),
+		LastUpdate:     stamp(minTime(st.Now, av.DoneAt)),
+	}
+	if av.NoBuild {
+		return ev
+	}
+	buildStack := av.ServiceID + "-build"
+	buildName := "build-" + av.ServiceID
+	b := &platform.BuildInfo{ServiceStackID: &buildStack, ServiceStackName: &buildName}
+	if !st.Now.Before(av.BuildAt) {
+		b.PipelineStart = stampPtr(av.BuildAt)
+	}
+	if !st.Now.Before(av.DeployAt) && av.Outcome != platform.BuildStatusBuildFailed {
+		b.ContainerCreationStart = stampPtr(av.DeployAt)
+	}
+	if !st.Now.Before(av.DoneAt) {
+		if av.Outcome == avActive {
+			b.PipelineFinish = stampPtr(av.DoneAt)
+		} else {
+			b.PipelineFailed = stampPtr(av.DoneAt)
+		}
+	}
+	ev.Build = b
+	return ev
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// appVersionsNewestFirst returns the app versions, newest first.
+func (st *State) appVersionsNewestFirst() []*AppVersion {
+	out := slices.Clone(st.AppVersions)
+	slices.Reverse(out)
+	return out
+}
@@ -0,0 +1,213 @@
+package sim
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+const appYAML = `zerops:
+  - setup: app
+    build:
+      base: nodejs@22
+      buildCommands:
+        - npm ci
+      deployFiles: ./
+    run:
+      ports:
+        - port: 3000
+          httpSupport: true
+      envVariables:
+        DB_URL: ${db_connectionString}
+      start: node server.js
+`
+
+func TestPush_Outcomes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		yaml    string
+		want    string
+		wantLog string
+	}{
+		{"success", appYAML, avActive, "listening on port 3000"},
+		{"build command fails", strings.Replace(appYAML, "npm ci", "exit 2", 1), platform.BuildStatusBuildFailed, `"exit 2" exited with status 2`},
+		{"missing deployFiles", strings.Replace(appYAML, "      deployFiles: ./\n", "", 1), platform.BuildStatusBuildFailed, "build.deployFiles is required"},
+		{"missing start", strings.Replace(appYAML, "      start: node server.js\n", "", 1), platform.BuildStatusDeployFailed, "run.start is required"},
+		{"init command fails", strings.Replace(appYAML, "      start:", "      initCommands:\n        - \"false\"\n      start:", 1), platform.BuildStatusDeployFailed, `init command "false" exited`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			s := newTestSim(t)
+			svc := importOne(t, s, "services:\n  - hostname: app\n    type: nodejs@22\n")
+			if svc.Status != platform.ServiceStatusReadyToDeploy {
+				t.Fatalf("imported runtime status = %s, want READY_TO_DEPLOY", svc.Status)
+			}
+
+			ev, err := s.Push(svc.ID, "app", tt.yaml)
+			if err != nil {
+				t.Fatalf("Push: %v", err)
+			}
+			if ev.Status == tt.want {
+				t.Fatalf("status %s visible before the pipeline ran", ev.Status)
+			}
+			s.Advance(buildBaseTime + buildCommandTime + deployTime + 10*queueDelay)
+
+			events, _ := s.SearchAppVersions(ctx, s.ProjectID(), 1)
+			if len(events) != 1 || events[0].ID != ev.ID {
+				t.Fatalf("SearchAppVersions = %+v", events)
+			}
+			if events[0].Status != tt.want {
+				t.Errorf("status = %s, want %s", events[0].Status, tt.want)
+			}
+			b := events[0].Build
+			if b == nil || b.PipelineStart == nil {
+				t.Fatalf("build info = %+v, want pipeline start", b)
+			}
+			if (tt.want == avActive) != (b.PipelineFinish != nil) || (tt.want != avActive) != (b.PipelineFailed != nil) {
+				t.Errorf("pipeline finish=%v failed=%v for outcome %s", b.PipelineFinish, b.PipelineFailed, tt.want)
+			}
+
+			got, _ := s.GetService(ctx, svc.ID)
+			wantStatus := platform.ServiceStatusReadyToDeploy
+			if tt.want == avActive {
+				wantStatus = platform.ServiceStatusActive
+			}
+			if got.Status != wantStatus {
+				t.Errorf("service status = %s, want %s", got.Status, wantStatus)
+			}
+
+			if !strings.Contains(allLogs(t, s, svc.ID, b), tt.wantLog) {
+				t.Errorf("logs do not contain %q:\n%s", tt.wantLog, allLogs(t, s, svc.ID, b))
+			}
+		})
+	}
+}
+
+// allLogs concatenates the build log (as ops fetches it) and the runtime log.
+func allLogs(t *testing.T, s *Sim, serviceID string, b *platform.BuildInfo) string {
+	t.Helper()
+	ctx := context.Background()
+	access, err := s.GetProjectLog(ctx, s.ProjectID())
+	if err != nil {
+		t.Fatalf("GetProjectLog: %v", err)
+	}
+	var lines []string
+	for _, params := range []platform.LogFetchParams{
+		{ServiceID: *b.ServiceStackID, Facility: "application", Limit: 100},
+		{ServiceID: serviceID, Facility: "application", Limit: 100},
+	} {
+		entries, err := s.FetchLogs(ctx, access, params)
+		if err != nil {
+			t.Fatalf("FetchLogs: %v", err)
+		}
+		for _, e := range entries {
+			lines = append(lines, e.Message)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestPush_RejectedBeforeBuild(t *testing.T) {
+	t.Parallel()
+	s := newTestSim(t)
+	app := importOne(t, s, "services:\n  - hostname: app\n    type: nodejs@22\n")
+	db := importOne(t, s, "services:\n  - hostname: db\n    type: postgresql@16\n")
+
+	tests := []struct {
+		name, serviceID, setup, yaml, wantCode, wantAPICode string
+	}{
+		{"setup not found", app.ID, "web", appYAML, platform.ErrInvalidZeropsYml, "zeropsYamlSetupNotFound"},
+		{"invalid yaml", app.ID, "app", "zerops: [", platform.ErrInvalidZeropsYml, "yamlValidationInvalidYaml"},
+		{"managed target", db.ID, "app", appYAML, platform.ErrInvalidParameter, ""},
+		{"unknown service", "svc-404", "app", appYAML, platform.ErrServiceNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Push(tt.serviceID, tt.setup, tt.yaml)
+			if platformCode(err) != tt.wantCode {
+				t.Fatalf("err = %v, want code %s", err, tt.wantCode)
+			}
+			var pe *platform.PlatformError
+			if errors.As(err, &pe) && pe.APICode != tt.wantAPICode {
+				t.Errorf("APICode = %q, want %q", pe.APICode, tt.wantAPICode)
+			}
+		})
+	}
+}
+
+func TestFetchLogs_WithholdsFutureEntries(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := newTestSim(t)
+	svc := importOne(t, s, "services:\n  - hostname: app\n    type: nodejs@22\n")
+	ev, err := s.Push(svc.ID, "app", appYAML)
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	access, _ := s.GetProjectLog(ctx, s.ProjectID())
+	params := platform.LogFetchParams{
+		ServiceID: svc.ID + "-build", Facility: "application", Tags: []string{"zbuilder@" + ev.ID}, Limit: 100,
+	}
+	early, _ := s.FetchLogs(ctx, access, params)
+	s.Advance(buildBaseTime + buildCommandTime)
+	late, _ := s.FetchLogs(ctx, access, params)
+	if len(early) >= len(late) || len(late) == 0 {
+		t.Errorf("build log entries early=%d late=%d, want the log to grow with the clock", len(early), len(late))
+	}
+
+	if _, err := s.FetchLogs(ctx, &platform.LogAccess{URL: "https://logs.example"}, params); platformCode(err) != platform.ErrNetworkError {
+		t.Errorf("foreign access err = %v, want %s", err, platform.ErrNetworkError)
+	}
+}
+
+func TestDeployAppVersion_Rollback(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := newTestSim(t)
+	svc := importOne(t, s, "services:\n  - hostname: app\n    type: nodejs@22\n")
+
+	first, _ := s.Push(svc.ID, "app", appYAML)
+	s.Advance(buildBaseTime + buildCommandTime + deployTime + 10*queueDelay)
+	second, _ := s.Push(svc.ID, "app", strings.Replace(appYAML, "port: 3000", "port: 8080", 1))
+	s.Advance(buildBaseTime + buildCommandTime + deployTime + 10*queueDelay)
+
+	if got, _ := s.GetService(ctx, svc.ID); got.Ports[0].Port != 8080 {
+		t.Fatalf("port after second deploy = %d, want 8080", got.Ports[0].Port)
+	}
+	events, _ := s.SearchAppVersions(ctx, s.ProjectID(), 0)
+	statuses := map[string]string{}
+	for _, e := range events {
+		statuses[e.ID] = e.Status
+	}
+	if statuses[first.ID] != platform.AppVersionStatusBackup || statuses[second.ID] != avActive {
+		t.Fatalf("statuses = %v, want first BACKUP and second ACTIVE", statuses)
+	}
+
+	if _, err := s.DeployAppVersion(ctx, first.ID); err != nil {
+		t.Fatalf("DeployAppVersion: %v", err)
+	}
+	s.Advance(redeployTime + queueDelay)
+	if got, _ := s.GetService(ctx, svc.ID); got.Ports[0].Port != 3000 {
+		t.Errorf("port after rollback = %d, want 3000", got.Ports[0].Port)
+	}
+}
+
+func TestValidateZeropsYaml(t *testing.T) {
+	t.Parallel()
+	s := newTestSim(t)
+	in := platform.ValidateZeropsYamlInput{ServiceStackName: "app", ZeropsYaml: appYAML}
+	if err := s.ValidateZeropsYaml(context.Background(), in); err != nil {
+		t.Fatalf("valid yaml: %v", err)
+	}
+	in.ZeropsYaml = strings.Replace(appYAML, "base: nodejs@22", "base: cobol@1", 1)
+	if err := s.ValidateZeropsYaml(context.Background(), in); platformCode(err) != platform.ErrInvalidZeropsYml {
+		t.Errorf("unknown base err = %v, want %s", err, platform.ErrInvalidZeropsYml)
+	}
+}
@@ -0,0 +1,289 @@
+package sim
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+const envZeropsSubdomain = "zeropsSubdomain"
+
+// envRef matches ${name} references: a variable of the same service, a
+// project variable, or hostname_KEY for another service's variable.
+var envRef = regexp.MustCompile(`\$\{([A-Za-z0-9_-]+)\}`)
+
+// Unresolved is an env reference that names no variable.
+type Unresolved struct {
+	Key string // variable holding the reference
+	Ref string // referenced name, without ${}
+}
+
+// ResolvedEnv returns the environment a runtime container of hostname
+// sees: project variables, the service's own variables and the active
+// deploy's run.envVariables, with ${ref} references expanded. References
+// that name no variable stay literal, as on the platform, and are
+// reported.
+func (s *Sim) ResolvedEnv(hostname string) (map[string]string, []Unresolved, error) {
+	var (
+		env        map[string]string
+		unresolved []Unresolved
+	)
+	err := s.call(func(st *State) error {
+		svc := st.serviceByName(hostname)
+		if svc == nil {
+			return platform.NewPlatformError(platform.ErrServiceNotFound,
+				fmt.Sprintf("service %s not found", hostname), "")
+		}
+		env, unresolved = st.resolveEnv(svc)
+		return nil
+	})
+	return env, unresolved, err
+}
+
+// resolveEnv expands svc's environment. Own variables shadow project
+// variables; expansion is repeated so references may chain, bounded so
+// a cycle cannot loop forever.
+func (st *State) resolveEnv(svc *Service) (map[string]string, []Unresolved) {
+	env := make(map[string]string)
+	for _, e := range st.ProjectEnv {
+		env[e.Key] = e.Content
+	}
+	for _, e := range svc.Env {
+		env[e.Key] = e.Content
+	}
+	for k, v := range svc.YAMLEnv {
+		env[k] = v
+	}
+
+	lookup := func(name string) (string, bool) {
+		if v, ok := env[name]; ok {
+			return v, true
+		}
+		for _, other := range st.Services {
+			if other.Deleted || other.ID == svc.ID {
+				continue
+			}
+			key, ok := strings.CutPrefix(name, other.Name+"_")
+			if !ok {
+				continue
+			}
+			for _, e := range other.Env {
+				if e.Key == key {
+					return st.expandOwn(other, e.Content), true
+				}
+			}
+		}
+		return "", false
+	}
+
+	var unresolved []Unresolved
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		v := env[k]
+		for range 5 {
+			expanded := envRef.ReplaceAllStringFunc(v, func(m string) string {
+				name := envRef.FindStringSubmatch(m)[1]
+				if name == k {
+					return m
+				}
+				if val, ok := lookup(name); ok {
+					return val
+				}
+				return m
+			})
+			if expanded == v {
+				break
+			}
+			v = expanded
+		}
+		for _, m := range envRef.FindAllStringSubmatch(v, -1) {
+			unresolved = append(unresolved, Unresolved{Key: k, Ref: m[1]})
+		}
+		env[k] = v
+	}
+	return env, unresolved
+}
+
+// expandOwn expands references to svc's own variables, which is how the
+// platform renders a generated value such as connectionString.
+func (st *State) expandOwn(svc *Service, v string) string {
+	return envRef.ReplaceAllStringFunc(v, func(m string) string {
+		name := envRef.FindStringSubmatch(m)[1]
+		for _, e := range svc.Env {
+			if e.Key == name {
+				return e.Content
+			}
+		}
+		return m
+	})
+}
+
+// setEnv upserts a variable on svc.
+func (st *State) setEnv(svc *Service, key, content string) {
+	for i := range svc.Env {
+		if svc.Env[i].Key == key {
+			svc.Env[i].Content = content
+			return
+		}
+	}
+	svc.Env = append(svc.Env, platform.EnvVar{ID: st.nextID("env"), Key: key, Content: content})
+}
+
+func (st *State) unsetEnv(svc *Service, key string) {
+	svc.Env = slices.DeleteFunc(svc.Env, func(e platform.EnvVar) bool { return e.Key == key })
+}
+
+// parseEnvFile parses the dotenv content SetServiceEnvFile receives:
+// KEY=value lines, values optionally double-quoted with \" and \\
+// escapes (the form ops uses for multi-line values).
+func parseEnvFile(content string) ([][2]string, error) {
+	var out [][2]string
+	rest := content
+	for rest != "" {
+		var line string
+		line, rest, _ = strings.Cut(rest, "\n")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("line %q is not KEY=value", line)
+		}
+		if strings.HasPrefix(value, `"`) {
+			value = value[1:]
+			var b strings.Builder
+			for {
+				i := strings.IndexAny(value, `"\`)
+				if i < 0 {
+					if rest == "" {
+						return nil, fmt.Errorf("unterminated quoted value for %s", key)
+					}
+					b.WriteString(value)
+					b.WriteByte('\n')
+					value, rest, _ = strings.Cut(rest, "\n")
+					continue
+				}
+				b.WriteString(value[:i])
+				if value[i] == '"' {
+					break
+				}
+				if i+1 < len(value) {
+					b.WriteByte(value[i+1])
+					value = value[i+2:]
+				} else {
+					value = value[i+1:]
+				}
+			}
+			value = b.String()
+		}
+		out = append(out, [2]string{strings.TrimSpace(key), value})
+	}
+	return out, nil
+}
+
+// GetServiceEnv returns the stored variables, references unexpanded.
+func (s *Sim) GetServiceEnv(_ context.Context, serviceID string) ([]platform.EnvVar, error) {
+	var out []platform.EnvVar
+	err := s.call(func(st *State) error {
+		svc, err := st.service(serviceID)
+		if err != nil {
+			return err
+		}
+		out = slices.Clone(svc.Env)
+		return nil
+	})
+	return out, err
+}
+
+// SetServiceEnvFile upserts the variables in content.
+func (s *Sim) SetServiceEnvFile(_ context.Context, serviceID, content string) (*platform.Process, error) {
+	var out *platform.Process
+	err := s.call(func(st *State) error {
+		svc, err := st.service(serviceID)
+		if err != nil {
+			return err
+		}
+		pairs, err := parseEnvFile(content)
+		if err != nil {
+			return platform.NewPlatformError(platform.ErrInvalidEnvFormat, err.Error(), "")
+		}
+		for _, p := range pairs {
+			st.setEnv(svc, p[0], p[1])
+		}
+		out = st.processView(st.newProcess(actionEnvFile, svc.ID, envUpdateTime))
+		return nil
+	})
+	return out, err
+}
+
+// DeleteUserData removes one service variable by ID.
+func (s *Sim) DeleteUserData(_ context.Context, userDataID string) (*platform.Process, error) {
+	var out *platform.Process
+	err := s.call(func(st *State) error {
+		for _, svc := range st.Services {
+			if svc.Deleted {
+				continue
+			}
+			for _, e := range svc.Env {
+				if e.ID == userDataID {
+					st.unsetEnv(svc, e.Key)
+					out = st.processView(st.newProcess(actionUserDataDelete, svc.ID, envUpdateTime))
+					return nil
+				}
+			}
+		}
+		return platform.NewPlatformError(platform.ErrInvalidParameter, "user data "+userDataID+" not found", "")
+	})
+	return out, err
+}
+
+func (s *Sim) GetProjectEnv(_ context.Context, projectID string) ([]platform.EnvVar, error) {
+	var out []platform.EnvVar
+	err := s.call(func(st *State) error {
+		if err := st.checkProject(projectID); err != nil {
+			return err
+		}
+		out = slices.Clone(st.ProjectEnv)
+		return nil
+	})
+	return out, err
+}
+
+func (s *Sim) CreateProjectEnv(_ context.Context, projectID, key, content string, _ bool) (*platform.Process, error) {
+	var out *platform.Process
+	err := s.call(func(st *State) error {
+		if err := st.checkProject(projectID); err != nil {
+			return err
+		}
+		if slices.ContainsFunc(st.ProjectEnv, func(e platform.EnvVar) bool { return e.Key == key }) {
+			return platform.NewPlatformError(platform.ErrInvalidParameter,
+				fmt.Sprintf("project env %s already exists", key), "Delete it first; the API has no update.")
+		}
+		st.ProjectEnv = append(st.ProjectEnv, platform.EnvVar{ID: st.nextID("penv"), Key: key, Content: content})
+		out = st.processView(st.newProcess(actionProjectEnvCreate, "", envUpdateTime))
+		return nil
+	})
+	return out, err
+}
+
+func (s *Sim) DeleteProjectEnv(_ context.Context, envID string) (*platform.Process, error) {
+	var out *platform.Process
+	err := s.call(func(st *State) error {
+		n := len(st.ProjectEnv)
+		st.ProjectEnv = slices.DeleteFunc(st.ProjectEnv, func(e platform.EnvVar) bool { return e.ID == envID })
+		if len(st.ProjectEnv) == n {
+			return platform.NewPlatformError(platform.ErrInvalidParameter, "project env "+envID+" not found", "")
+		}
+		out = st.processView(st.newProcess(actionProjectEnvDelete, "", envUpdateTime))
+		return nil
+	})
+	return out, err
+}
@@ -0,0 +1,105 @@
+package sim
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+func TestResolvedEnv_References(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := newTestSim(t)
+	importOne(t, s, "services:\n  - hostname: db\n    type: postgresql@16\n")
+	app := importOne(t, s, `services:
+  - hostname: app
+    type: nodejs@22
+    envSecrets:
+      APP_KEY: <@generateRandomString(<32>)>
+`)
+	if _, err := s.CreateProjectEnv(ctx, s.ProjectID(), "REGION", "prg1", false); err != nil {
+		t.Fatalf("CreateProjectEnv: %v", err)
+	}
+	if _, err := s.SetServiceEnvFile(ctx, app.ID, "DATABASE_URL=${db_connectionString}\nWHERE=${REGION}\nBROKEN=${cache_hostname}\n"); err != nil {
+		t.Fatalf("SetServiceEnvFile: %v", err)
+	}
+
+	env, unresolved, err := s.ResolvedEnv("app")
+	if err != nil {
+		t.Fatalf("ResolvedEnv: %v", err)
+	}
+	if got := env["DATABASE_URL"]; !strings.HasPrefix(got, "postgresql://db:") || !strings.HasSuffix(got, "@db:5432") {
+		t.Errorf("DATABASE_URL = %q, want the expanded connection string", got)
+	}
+	if env["WHERE"] != "prg1" {
+		t.Errorf("WHERE = %q, want project env value", env["WHERE"])
+	}
+	if len(env["APP_KEY"]) != 32 || strings.Contains(env["APP_KEY"], "<@") {
+		t.Errorf("APP_KEY = %q, want a 32-char generated value", env["APP_KEY"])
+	}
+	if len(unresolved) != 1 || unresolved[0] != (Unresolved{Key: "BROKEN", Ref: "cache_hostname"}) {
+		t.Errorf("unresolved = %+v, want BROKEN → cache_hostname", unresolved)
+	}
+	if env["BROKEN"] != "${cache_hostname}" {
+		t.Errorf("BROKEN = %q, want the literal reference", env["BROKEN"])
+	}
+
+	stored, _ := s.GetServiceEnv(ctx, app.ID)
+	for _, e := range stored {
+		if e.Key == "DATABASE_URL" && e.Content != "${db_connectionString}" {
+			t.Errorf("stored DATABASE_URL = %q, want it unexpanded", e.Content)
+		}
+	}
+}
+
+func TestSetServiceEnvFile_QuotedAndDelete(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := newTestSim(t)
+	app := importOne(t, s, "services:\n  - hostname: app\n    type: nodejs@22\n")
+
+	if _, err := s.SetServiceEnvFile(ctx, app.ID, "CERT=\"line1\nline2 \\\"q\\\"\"\nPLAIN=x\n"); err != nil {
+		t.Fatalf("SetServiceEnvFile: %v", err)
+	}
+	if _, err := s.SetServiceEnvFile(ctx, app.ID, "no equals sign"); platformCode(err) != platform.ErrInvalidEnvFormat {
+		t.Errorf("malformed env err = %v, want %s", err, platform.ErrInvalidEnvFormat)
+	}
+	vars, _ := s.GetServiceEnv(ctx, app.ID)
+	byKey := map[string]platform.EnvVar{}
+	for _, e := range vars {
+		byKey[e.Key] = e
+	}
+	if got := byKey["CERT"].Content; got != "line1\nline2 \"q\"" {
+		t.Errorf("CERT = %q", got)
+	}
+	if _, err := s.DeleteUserData(ctx, byKey["PLAIN"].ID); err != nil {
+		t.Fatalf("DeleteUserData: %v", err)
+	}
+	vars, _ = s.GetServiceEnv(ctx, app.ID)
+	for _, e := range vars {
+		if e.Key == "PLAIN" {
+			t.Error("PLAIN still present after DeleteUserData")
+		}
+	}
+}
+
+func TestProjectEnv_CreateTwiceFails(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := newTestSim(t)
+	if _, err := s.CreateProjectEnv(ctx, s.ProjectID(), "K", "v", false); err != nil {
+		t.Fatalf("CreateProjectEnv: %v", err)
+	}
+	if _, err := s.CreateProjectEnv(ctx, s.ProjectID(), "K", "w", false); platformCode(err) != platform.ErrInvalidParameter {
+		t.Errorf("duplicate err = %v, want %s", err, platform.ErrInvalidParameter)
+	}
+	vars, _ := s.GetProjectEnv(ctx, s.ProjectID())
+	if _, err := s.DeleteProjectEnv(ctx, vars[0].ID); err != nil {
+		t.Fatalf("DeleteProjectEnv: %v", err)
+	}
+	if vars, _ := s.GetProjectEnv(ctx, s.ProjectID()); len(vars) != 0 {
+		t.Errorf("project env after delete = %+v", vars)
+	}
+}
@@ -0,0 +1,164 @@
+package sim
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/zeropsio/zcp/internal/platform"
+	"gopkg.in/yaml.v3"
+)
+
+// importYAML is the subset of import.yaml the simulator reads.
+type importYAML struct {
+	Services []importService `yaml:"services"`
+}
+
+type importService struct {
+	Hostname              string            `yaml:"hostname"`
+	Type                  string            `yaml:"type"`
+	Mode                  string            `yaml:"mode"`
+	BuildFromGit          string            `yaml:"buildFromGit"`
+	StartWithoutCode      bool              `yaml:"startWithoutCode"`
+	EnableSubdomainAccess bool              `yaml:"enableSubdomainAccess"`
+	ZeropsSetup           string            `yaml:"zeropsSetup"`
+	EnvSecrets            map[string]string `yaml:"envSecrets"`
+	DotEnvSecrets         string            `yaml:"dotEnvSecrets"`
+}
+
+// randomString matches the one preprocessor function fixtures rely on.
+var randomString = regexp.MustCompile(`<@generateRandomString\(<(\d+)>\)>`)
+
+// ImportServices creates the services in an import.yaml. Each service
+// gets a stack.import process; when it finishes the service is ACTIVE
+// (managed services, startWithoutCode, buildFromGit — which also runs a
+// build) or READY_TO_DEPLOY (runtimes waiting for their first push).
+// Hostnames that already exist fail per service, as on the platform.
+func (s *Sim) ImportServices(_ context.Context, projectID, importDoc string) (*platform.ImportResult, error) {
+	var out *platform.ImportResult
+	err := s.call(func(st *State) error {
+		if err := st.checkProject(projectID); err != nil {
+			return err
+		}
+		var doc importYAML
+		if err := yaml.Unmarshal([]byte(importDoc), &doc); err != nil {
+			return platform.NewPlatformError(platform.ErrInvalidImportYml,
+				"import.yaml is not valid YAML: "+err.Error(), "")
+		}
+		if len(doc.Services) == 0 {
+			return platform.NewPlatformError(platform.ErrInvalidImportYml,
+				"import.yaml has no services", "")
+		}
+		out = &platform.ImportResult{ProjectID: st.Project.ID, ProjectName: st.Project.Name}
+		for _, is := range doc.Services {
+			out.ServiceStacks = append(out.ServiceStacks, st.importService(is))
+		}
+		return nil
+	})
+	return out, err
+}
+
+func (st *State) importService(is importService) platform.ImportedServiceStack {
+	fail := func(code, msg string) platform.ImportedServiceStack {
+		return platform.ImportedServiceStack{Name: is.Hostname, Error: &platform.APIError{Code: code, Message: msg}}
+	}
+	switch {
+	case is.Hostname == "":
+		return fail("serviceStackNameInvalid", "hostname is required")
+	case st.serviceByName(is.Hostname) != nil:
+		return fail("serviceStackNameUnavailable", fmt.Sprintf("service %s already exists", is.Hostname))
+	case !knownType(is.Type):
+		return fail("serviceStackTypeNotFound", fmt.Sprintf("service type %q is not available", is.Type))
+	}
+	mode := is.Mode
+	if mode == "" {
+		mode = "NON_HA"
+	}
+	svc := &Service{
+		ServiceStack: platform.ServiceStack{
+			ID:        st.nextID("svc"),
+			Name:      is.Hostname,
+			ProjectID: st.Project.ID,
+			ServiceStackTypeInfo: platform.ServiceTypeInfo{
+				ServiceStackTypeID:           typeID(is.Type),
+				ServiceStackTypeVersionName:  is.Type,
+				ServiceStackTypeCategoryName: category(is.Type),
+			},
+			Status:  statusCreating,
+			Mode:    mode,
+			Created: stamp(st.Now),
+		},
+		Setup: is.ZeropsSetup,
+	}
+	st.Services = append(st.Services, svc)
+	for _, k := range slices.Sorted(maps.Keys(is.EnvSecrets)) {
+		st.setEnv(svc, k, st.preprocess(svc, is.EnvSecrets[k]))
+	}
+	if pairs, err := parseEnvFile(is.DotEnvSecrets); err == nil {
+		for _, p := range pairs {
+			st.setEnv(svc, p[0], st.preprocess(svc, p[1]))
+		}
+	}
+	p := st.newProcess(actionImport, svc.ID, importTime)
+	if is.BuildFromGit != "" {
+		p.Target = is.BuildFromGit
+	}
+	if is.StartWithoutCode {
+		p.Target = sourceNone
+	}
+	svc.SubdomainPending = is.EnableSubdomainAccess
+	return platform.ImportedServiceStack{ID: svc.ID, Name: svc.Name, Processes: []platform.Process{*st.processView(p)}}
+}
+
+// preprocess expands <@generateRandomString(<N>)> deterministically.
+func (st *State) preprocess(svc *Service, v string) string {
+	return randomString.ReplaceAllStringFunc(v, func(m string) string {
+		n, _ := strconv.Atoi(randomString.FindStringSubmatch(m)[1])
+		var b strings.Builder
+		for i := 0; b.Len() < n; i++ {
+			b.WriteString(secret(st.Project.ID, svc.Name, v, strconv.Itoa(i)))
+		}
+		return b.String()[:n]
+	})
+}
+
+// finishImport brings an imported service to its first running state.
+// p.Target carries the code source: a git URL, sourceNone for
+// startWithoutCode, or empty.
+func (st *State) finishImport(svc *Service, p *Process) {
+	if category(svc.ServiceStackTypeInfo.ServiceStackTypeVersionName) != categoryUser {
+		st.generateManagedEnv(svc)
+		svc.Status = platform.ServiceStatusActive
+		return
+	}
+	st.setEnv(svc, "hostname", svc.Name)
+	switch p.Target {
+	case "":
+		svc.Status = platform.ServiceStatusReadyToDeploy
+	case sourceNone:
+		av := &AppVersion{
+			ID: st.nextID("av"), ServiceID: svc.ID, Source: sourceNone, Sequence: st.nextSequence(svc.ID),
+			Created: p.Due, BuildAt: p.Due, DeployAt: p.Due, DoneAt: p.Due, Outcome: avActive, NoBuild: true, Applied: true,
+		}
+		st.AppVersions = append(st.AppVersions, av)
+		st.activate(svc, av, p.Due)
+	default:
+		// No zerops.yaml to read: a git build succeeds and listens on the
+		// runtime's usual HTTP port.
+		port := defaultHTTPPort(svc.ServiceStackTypeInfo.ServiceStackTypeVersionName)
+		av := &AppVersion{
+			ID: st.nextID("av"), ServiceID: svc.ID, Source: sourceGit, Sequence: st.nextSequence(svc.ID), Setup: svc.Setup,
+			Created: p.Due, BuildAt: p.Due, DeployAt: p.Due.Add(buildBaseTime), DoneAt: p.Due.Add(buildBaseTime + deployTime),
+			Outcome: avActive, Ports: []platform.Port{{Port: port, Protocol: "TCP", Public: true, HTTPSupport: true}},
+		}
+		st.AppVersions = append(st.AppVersions, av)
+		st.addLog(svc.ID+"-build", platform.LogEntry{
+			Timestamp: stamp(p.Due.Add(queueDelay)), Severity: "Informational", Facility: facilityApp,
+			Tag: "zbuilder@" + av.ID, Message: "cloning " + p.Target, Container: svc.Name + "-build",
+		})
+	}
+}
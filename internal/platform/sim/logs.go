@@ -0,0 +1,79 @@
+package sim
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// facilityApp is the syslog facility label of application output.
+const facilityApp = "local0"
+
+func (st *State) addLog(serviceID string, e platform.LogEntry) {
+	e.ID = st.nextID("log")
+	st.Logs = append(st.Logs, LogRecord{ServiceID: serviceID, LogEntry: e})
+}
+
+// logRuntimeStart records a runtime container boot at t: the start
+// command, one warning per env reference that does not resolve, and the
+// listening line for each port.
+func (st *State) logRuntimeStart(svc *Service, t time.Time) {
+	logf := func(severity, format string, args ...any) {
+		t = t.Add(time.Second)
+		st.addLog(svc.ID, platform.LogEntry{
+			Timestamp: stamp(t), Severity: severity, Facility: facilityApp,
+			Message: fmt.Sprintf(format, args...), Container: svc.Name + "-1",
+		})
+	}
+	av := st.appVersion(svc.ActiveVersion)
+	if av != nil && av.Start != "" {
+		logf("Informational", "starting: %s", av.Start)
+	}
+	_, unresolved := st.resolveEnv(svc)
+	for _, u := range unresolved {
+		logf("Warning", "env %s references ${%s}, which is not defined; the literal value is used", u.Key, u.Ref)
+	}
+	for _, p := range svc.Ports {
+		logf("Informational", "listening on port %d", p.Port)
+	}
+}
+
+// GetProjectLog returns access to the simulated log backend.
+func (s *Sim) GetProjectLog(_ context.Context, projectID string) (*platform.LogAccess, error) {
+	var out *platform.LogAccess
+	err := s.call(func(st *State) error {
+		if err := st.checkProject(projectID); err != nil {
+			return err
+		}
+		out = &platform.LogAccess{AccessToken: simToken, Expiration: stamp(st.Now.Add(time.Hour)), URL: simLogURL}
+		return nil
+	})
+	return out, err
+}
+
+// FetchLogs serves the simulated log backend. Entries stamped after the
+// current virtual time have not happened yet and are withheld; the
+// remaining filters are those of platform.MockLogFetcher, which mirrors
+// the production fetcher.
+func (s *Sim) FetchLogs(ctx context.Context, access *platform.LogAccess, params platform.LogFetchParams) ([]platform.LogEntry, error) {
+	if access == nil || access.URL != simLogURL {
+		return nil, platform.NewPlatformError(platform.ErrNetworkError,
+			"simulated log backend only accepts access from the simulated GetProjectLog", "")
+	}
+	var entries []platform.LogEntry
+	_ = s.call(func(st *State) error {
+		for _, r := range st.Logs {
+			if params.ServiceID != "" && r.ServiceID != params.ServiceID {
+				continue
+			}
+			if t, err := time.Parse(time.RFC3339Nano, r.Timestamp); err == nil && t.After(st.Now) {
+				continue
+			}
+			entries = append(entries, r.LogEntry)
+		}
+		return nil
+	})
+	return platform.NewMockLogFetcher().WithEntries(entries).FetchLogs(ctx, access, params)
+}
@@ -0,0 +1,212 @@
+package sim
+
+import (
+	"slices"
+	"time"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// Virtual durations of platform operations.
+const (
+	queueDelay       = time.Second
+	lifecycleTime    = 10 * time.Second
+	envUpdateTime    = 3 * time.Second
+	importTime       = 20 * time.Second
+	deleteTime       = 5 * time.Second
+	subdomainTime    = 5 * time.Second
+	redeployTime     = 15 * time.Second
+	storageTime      = 5 * time.Second
+	buildBaseTime    = 10 * time.Second
+	buildCommandTime = 5 * time.Second
+	deployTime       = 10 * time.Second
+)
+
+// Process action names, as the API reports them.
+const (
+	actionStart            = "stack.start"
+	actionStop             = "stack.stop"
+	actionRestart          = "stack.restart"
+	actionReload           = "stack.reload"
+	actionImport           = "stack.import"
+	actionDelete           = "stack.delete"
+	actionEnvFile          = "stack.userDataFile"
+	actionUserDataDelete   = "userData.delete"
+	actionProjectEnvCreate = "project.envCreate"
+	actionProjectEnvDelete = "project.envDelete"
+	actionSubdomainOn      = "stack.enableSubdomainAccess"
+	actionSubdomainOff     = "stack.disableSubdomainAccess"
+	actionDeployVersion    = "stack.deploy"
+	actionBuild            = "stack.build"
+	actionStorageConnect   = "stack.connectSharedStorage"
+	actionStorageDisconn   = "stack.disconnectSharedStorage"
+)
+
+// Service statuses the simulator moves through beyond the platform constants.
+const (
+	statusStopped  = "STOPPED"
+	statusDeleting = "DELETING"
+	statusCreating = "CREATING"
+)
+
+// newProcess queues an operation that finishes after d of virtual time.
+func (st *State) newProcess(action, serviceID string, d time.Duration) *Process {
+	p := &Process{
+		ID:        st.nextID("proc"),
+		Action:    action,
+		ServiceID: serviceID,
+		Created:   st.Now,
+		Started:   st.Now.Add(queueDelay),
+		Due:       st.Now.Add(queueDelay + d),
+	}
+	st.Processes = append(st.Processes, p)
+	return p
+}
+
+func (st *State) process(id string) (*Process, error) {
+	for _, p := range st.Processes {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return nil, platform.NewPlatformError(platform.ErrProcessNotFound,
+		"process "+id+" not found", "")
+}
+
+// status derives the process status at now.
+func (p *Process) status(now time.Time) string {
+	switch {
+	case p.Canceled:
+		return platform.ProcessStatusCanceled
+	case now.Before(p.Started):
+		return platform.ProcessStatusPending
+	case now.Before(p.Due):
+		return platform.ProcessStatusRunning
+	case p.Fail != "":
+		return platform.ProcessStatusFailed
+	default:
+		return platform.ProcessStatusFinished
+	}
+}
+
+// view renders the process the way the API returns it.
+func (st *State) processView(p *Process) *platform.Process {
+	out := &platform.Process{
+		ID:         p.ID,
+		ActionName: p.Action,
+		Status:     p.status(st.Now),
+		Created:    stamp(p.Created),
+	}
+	if p.ServiceID != "" {
+		ref := platform.ServiceStackRef{ID: p.ServiceID}
+		for _, svc := range st.Services {
+			if svc.ID == p.ServiceID {
+				ref.Name = svc.Name
+			}
+		}
+		out.ServiceStacks = []platform.ServiceStackRef{ref}
+	}
+	if !st.Now.Before(p.Started) {
+		out.Started = stampPtr(p.Started)
+	}
+	switch out.Status {
+	case platform.ProcessStatusFinished, platform.ProcessStatusFailed:
+		out.Finished = stampPtr(p.Due)
+	case platform.ProcessStatusCanceled:
+		out.Finished = stampPtr(st.Now)
+	}
+	if out.Status == platform.ProcessStatusFailed {
+		reason := p.Fail
+		out.FailReason = &reason
+	}
+	return out
+}
+
+func (st *State) processEvent(p *Process) platform.ProcessEvent {
+	v := st.processView(p)
+	return platform.ProcessEvent{
+		ID:            v.ID,
+		ProjectID:     st.Project.ID,
+		ServiceStacks: v.ServiceStacks,
+		ActionName:    v.ActionName,
+		Status:        v.Status,
+		Created:       v.Created,
+		Started:       v.Started,
+		Finished:      v.Finished,
+		FailReason:    v.FailReason,
+		CreatedByUser: &platform.UserRef{FullName: st.User.FullName, Email: st.User.Email},
+	}
+}
+
+// settle applies every process and build whose time has come, in
+// chronological order. Applying one can schedule more work (an import
+// from git starts a build), so it repeats until nothing is due.
+// Caller holds s.mu.
+func (s *Sim) settle() {
+	st := s.st
+	type due struct {
+		at    time.Time
+		apply func()
+	}
+	for {
+		var pending []due
+		for _, p := range st.Processes {
+			if !p.Applied && !p.Canceled && !st.Now.Before(p.Due) {
+				pending = append(pending, due{p.Due, func() { st.applyProcess(p) }})
+			}
+		}
+		for _, av := range st.AppVersions {
+			if !av.Applied && !st.Now.Before(av.DoneAt) {
+				pending = append(pending, due{av.DoneAt, func() { st.applyBuild(av) }})
+			}
+		}
+		if len(pending) == 0 {
+			return
+		}
+		slices.SortStableFunc(pending, func(a, b due) int { return a.at.Compare(b.at) })
+		for _, d := range pending {
+			d.apply()
+		}
+	}
+}
+
+// applyProcess performs the effect of a finished process.
+func (st *State) applyProcess(p *Process) {
+	p.Applied = true
+	if p.Fail != "" {
+		return
+	}
+	svc, err := st.service(p.ServiceID)
+	if err != nil && p.ServiceID != "" {
+		return
+	}
+	switch p.Action {
+	case actionStart, actionRestart, actionReload:
+		if svc.ActiveVersion != "" || svc.Status != platform.ServiceStatusReadyToDeploy {
+			svc.Status = platform.ServiceStatusActive
+		}
+		if svc.ActiveVersion != "" {
+			st.logRuntimeStart(svc, p.Due)
+		}
+	case actionStop:
+		svc.Status = statusStopped
+	case actionImport:
+		st.finishImport(svc, p)
+	case actionDelete:
+		svc.Deleted = true
+	case actionSubdomainOn:
+		st.enableSubdomain(svc)
+	case actionSubdomainOff:
+		st.disableSubdomain(svc)
+	case actionDeployVersion:
+		if av := st.appVersion(p.Target); av != nil {
+			st.activate(svc, av, p.Due)
+		}
+	case actionStorageConnect:
+		if !slices.Contains(svc.Storages, p.Target) {
+			svc.Storages = append(svc.Storages, p.Target)
+		}
+	case actionStorageDisconn:
+		svc.Storages = slices.DeleteFunc(svc.Storages, func(id string) bool { return id == p.Target })
+	}
+}
@@ -0,0 +1,106 @@
+package sim
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// simUploadURL prefixes the upload URLs the simulator hands out; nothing
+// listens there, UploadAppVersion consumes the archive itself.
+const simUploadURL = "sim://upload/"
+
+// Upload is an app version created for a native push, before its build
+// is triggered. The archive is drained and only its size is kept.
+type Upload struct {
+	ID        string `json:"id"`
+	ServiceID string `json:"serviceId"`
+	Size      int64  `json:"size,omitempty"`
+	Uploaded  bool   `json:"uploaded,omitempty"`
+	Built     bool   `json:"built,omitempty"`
+}
+
+// CreateAppVersion opens an upload slot on a runtime service.
+func (s *Sim) CreateAppVersion(_ context.Context, serviceID string) (*platform.AppVersionUpload, error) {
+	var out *platform.AppVersionUpload
+	err := s.call(func(st *State) error {
+		svc, err := st.service(serviceID)
+		if err != nil {
+			return err
+		}
+		if category(svc.ServiceStackTypeInfo.ServiceStackTypeVersionName) != categoryUser {
+			return platform.NewPlatformError(platform.ErrInvalidParameter,
+				fmt.Sprintf("service %s is not a runtime service and cannot be deployed to", svc.Name), "")
+		}
+		up := &Upload{ID: st.nextID("av"), ServiceID: svc.ID}
+		st.Uploads = append(st.Uploads, up)
+		out = &platform.AppVersionUpload{ID: up.ID, ServiceStackID: svc.ID, UploadURL: simUploadURL + up.ID}
+		return nil
+	})
+	return out, err
+}
+
+// UploadAppVersion drains the archive and marks the slot uploaded. The
+// reader is consumed outside the lock so upload progress reporting sees
+// the same streaming it does against the real endpoint.
+func (s *Sim) UploadAppVersion(_ context.Context, upload *platform.AppVersionUpload, archive io.Reader, _ int64) error {
+	n, err := io.Copy(io.Discard, archive)
+	if err != nil {
+		return platform.NewPlatformError(platform.ErrAPIError, "archive upload failed: "+err.Error(), "")
+	}
+	return s.call(func(st *State) error {
+		up, err := st.upload(upload.ID)
+		if err != nil {
+			return err
+		}
+		up.Size, up.Uploaded = n, true
+		return nil
+	})
+}
+
+// BuildAndDeployAppVersion starts the pipeline for an uploaded app
+// version. zeropsYAML is evaluated exactly as Push evaluates it; the
+// returned stack.build process finishes when the pipeline does.
+func (s *Sim) BuildAndDeployAppVersion(_ context.Context, appVersionID, zeropsYAML, setup string) (*platform.Process, error) {
+	var out *platform.Process
+	err := s.call(func(st *State) error {
+		up, err := st.upload(appVersionID)
+		if err != nil {
+			return err
+		}
+		if !up.Uploaded || up.Built {
+			return platform.NewPlatformError(platform.ErrInvalidParameter,
+				fmt.Sprintf("app version %s is not waiting for a build", up.ID), "")
+		}
+		svc, err := st.service(up.ServiceID)
+		if err != nil {
+			return err
+		}
+		if setup == "" {
+			setup = svc.Name
+		}
+		zs, err := parseSetup(zeropsYAML, setup)
+		if err != nil {
+			return err
+		}
+		up.Built = true
+		av := st.newBuild(up.ID, svc, sourceCLI, setup, zs)
+		p := st.newProcess(actionBuild, svc.ID, max(av.DoneAt.Sub(st.Now)-queueDelay, time.Duration(0)))
+		p.Target = av.ID
+		out = st.processView(p)
+		return nil
+	})
+	return out, err
+}
+
+func (st *State) upload(id string) (*Upload, error) {
+	for _, up := range st.Uploads {
+		if up.ID == id {
+			return up, nil
+		}
+	}
+	return nil, platform.NewPlatformError(platform.ErrInvalidParameter, "app version "+id+" not found", "")
+}
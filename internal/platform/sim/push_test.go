@@ -0,0 +1,57 @@
+package sim
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+func TestNativePush(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := newTestSim(t)
+	app := importOne(t, s, "services:\n  - hostname: app\n    type: nodejs@22\n")
+
+	up, err := s.CreateAppVersion(ctx, app.ID)
+	if err != nil {
+		t.Fatalf("CreateAppVersion: %v", err)
+	}
+	if _, err := s.BuildAndDeployAppVersion(ctx, up.ID, appYAML, "app"); err == nil {
+		t.Error("build before upload succeeded, want error")
+	}
+	if err := s.UploadAppVersion(ctx, up, strings.NewReader("archive"), 7); err != nil {
+		t.Fatalf("UploadAppVersion: %v", err)
+	}
+	proc, err := s.BuildAndDeployAppVersion(ctx, up.ID, appYAML, "app")
+	if err != nil {
+		t.Fatalf("BuildAndDeployAppVersion: %v", err)
+	}
+	if _, err := s.BuildAndDeployAppVersion(ctx, up.ID, appYAML, "app"); err == nil {
+		t.Error("second build of one app version succeeded, want error")
+	}
+
+	s.Advance(buildBaseTime + buildCommandTime + deployTime + 10*queueDelay)
+
+	events, _ := s.SearchAppVersions(ctx, s.ProjectID(), 1)
+	if len(events) != 1 || events[0].ID != up.ID || events[0].Status != avActive {
+		t.Fatalf("SearchAppVersions = %+v, want %s ACTIVE", events, up.ID)
+	}
+	got, _ := s.GetProcess(ctx, proc.ID)
+	if got.Status != platform.ProcessStatusFinished {
+		t.Errorf("build process status = %s, want FINISHED", got.Status)
+	}
+	if snap := s.Snapshot(); snap.Uploads[0].Size != 7 {
+		t.Errorf("upload size = %d, want 7", snap.Uploads[0].Size)
+	}
+}
+
+func TestNativePush_RejectsManagedService(t *testing.T) {
+	t.Parallel()
+	s := newTestSim(t)
+	db := importOne(t, s, "services:\n  - hostname: db\n    type: postgresql@16\n    mode: NON_HA\n")
+	if _, err := s.CreateAppVersion(context.Background(), db.ID); err == nil {
+		t.Error("CreateAppVersion on a managed service succeeded, want error")
+	}
+}
@@ -0,0 +1,324 @@
+// Package sim is a stateful, offline stand-in for the Zerops platform.
+//
+// A Sim models one project in memory and implements platform.Client and
+// platform.LogFetcher against that model, so the whole workflow pipeline
+// (import → deploy → verify → subdomain → logs) runs without network
+// access. Unlike platform.Mock, which returns canned answers, the Sim
+// keeps state across calls: an import creates services, a push creates an
+// app version whose outcome is decided by the submitted zerops.yaml, a
+// finished build flips the service to ACTIVE and feeds the log backend.
+//
+// Time is virtual. Every API call advances the clock by one tick and
+// settles whatever became due — processes go PENDING → RUNNING →
+// FINISHED, builds go UPLOADING → BUILDING → DEPLOYING → ACTIVE — so the
+// polling loops in ops observe the same transitions they see in
+// production, only faster. Tests drive the clock with Advance.
+//
+// A Sim opened with a state file saves after every call and reloads when
+// another process wrote the file, which lets `zcp eval scenario
+// --simulate` seed a project that the spawned `zcp serve --simulate`
+// then works on. Writers are expected to take turns; there is no file
+// locking.
+package sim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+// Compile-time interface checks.
+var (
+	_ platform.Client     = (*Sim)(nil)
+	_ platform.LogFetcher = (*Sim)(nil)
+)
+
+// Defaults for a new simulated project.
+const (
+	DefaultTick        = 5 * time.Second
+	DefaultProjectName = "sim-project"
+	simToken           = "sim-token"
+	simLogURL          = "sim://logs"
+)
+
+// Options configure a new simulated project. Zero values pick defaults.
+type Options struct {
+	ProjectName string
+	Start       time.Time     // initial virtual time; defaults to the wall clock
+	Tick        time.Duration // virtual time added per API call; defaults to DefaultTick
+}
+
+// State is the complete persisted model. It is exported so the state
+// file stays plain JSON a developer can read or hand-edit.
+type State struct {
+	Now         time.Time         `json:"now"`
+	Tick        time.Duration     `json:"tick"`
+	Seq         int               `json:"seq"`
+	User        platform.UserInfo `json:"user"`
+	Project     platform.Project  `json:"project"`
+	ProjectEnv  []platform.EnvVar `json:"projectEnv,omitempty"`
+	Services    []*Service        `json:"services,omitempty"`
+	Processes   []*Process        `json:"processes,omitempty"`
+	AppVersions []*AppVersion     `json:"appVersions,omitempty"`
+	Uploads     []*Upload         `json:"uploads,omitempty"`
+	Logs        []LogRecord       `json:"logs,omitempty"`
+}
+
+// Service is a simulated service stack plus what the platform keeps
+// behind it.
+type Service struct {
+	platform.ServiceStack
+
+	// Env holds user data (envSecrets, env files) and the system
+	// variables the platform generates (hostname, port, credentials).
+	Env []platform.EnvVar `json:"env,omitempty"`
+	// YAMLEnv is run.envVariables from the active deploy.
+	YAMLEnv map[string]string `json:"yamlEnv,omitempty"`
+	// Setup is the zerops.yaml setup imported via zeropsSetup.
+	Setup string `json:"setup,omitempty"`
+	// ActiveVersion is the app version serving traffic; empty until the
+	// first successful deploy.
+	ActiveVersion string   `json:"activeVersion,omitempty"`
+	Storages      []string `json:"storages,omitempty"`
+	// SubdomainPending is enableSubdomainAccess from the import, applied
+	// once a deploy gives the service an HTTP port.
+	SubdomainPending bool `json:"subdomainPending,omitempty"`
+	// Deleted services stay in the model so late GetProcess calls on
+	// their delete process still resolve.
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// Process is a simulated async operation. Status is derived from the
+// virtual clock; the effect is applied once when the process finishes.
+type Process struct {
+	ID        string    `json:"id"`
+	Action    string    `json:"action"`
+	ServiceID string    `json:"serviceId,omitempty"`
+	Target    string    `json:"target,omitempty"` // app version for stack.deploy/stack.build, storage for connects
+	Created   time.Time `json:"created"`
+	Started   time.Time `json:"started"`
+	Due       time.Time `json:"due"`
+	Fail      string    `json:"fail,omitempty"`
+	Canceled  bool      `json:"canceled,omitempty"`
+	Applied   bool      `json:"applied,omitempty"`
+}
+
+// LogRecord is a log entry with the service it belongs to; the backend
+// filters on the service stack, which platform.LogEntry does not carry.
+type LogRecord struct {
+	ServiceID string `json:"serviceId"`
+	platform.LogEntry
+}
+
+// Sim is the simulated platform. All methods are safe for concurrent use.
+type Sim struct {
+	mu    sync.Mutex
+	st    *State
+	path  string
+	mtime time.Time
+	size  int64
+}
+
+// New creates an in-memory simulated project.
+func New(opts Options) *Sim {
+	return &Sim{st: newState(opts)}
+}
+
+// Open loads the simulation persisted at path, creating it with opts
+// when the file does not exist yet. Every later call saves back to path.
+func Open(path string, opts Options) (*Sim, error) {
+	s := &Sim{path: path}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		s.st = newState(opts)
+		if err := s.save(); err != nil {
+			return nil, err
+		}
+		return s, nil
+	case err != nil:
+		return nil, fmt.Errorf("read simulation state: %w", err)
+	}
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("parse simulation state %s: %w", path, err)
+	}
+	s.st = &st
+	if info, err := os.Stat(path); err == nil {
+		s.mtime, s.size = info.ModTime(), info.Size()
+	}
+	return s, nil
+}
+
+func newState(opts Options) *State {
+	name := opts.ProjectName
+	if name == "" {
+		name = DefaultProjectName
+	}
+	now := opts.Start
+	if now.IsZero() {
+		now = time.Now().UTC().Truncate(time.Second)
+	}
+	tick := opts.Tick
+	if tick <= 0 {
+		tick = DefaultTick
+	}
+	return &State{
+		Now:     now,
+		Tick:    tick,
+		User:    platform.UserInfo{ID: "sim-user", FullName: "Simulated User", Email: "sim@zerops.local"},
+		Project: platform.Project{ID: "sim-project-1", Name: name, Status: platform.ServiceStatusActive, SubdomainHost: "5a1e.prg1.zerops.app"},
+	}
+}
+
+// ProjectID returns the ID of the simulated project.
+func (s *Sim) ProjectID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+	return s.st.Project.ID
+}
+
+// Token is the API token simulated sessions authenticate with.
+func (s *Sim) Token() string { return simToken }
+
+// Now returns the current virtual time without advancing it.
+func (s *Sim) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+	return s.st.Now
+}
+
+// Advance moves the virtual clock forward by d and settles everything
+// that became due.
+func (s *Sim) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+	s.st.Now = s.st.Now.Add(d)
+	s.settle()
+	s.persist()
+}
+
+// Snapshot returns a deep copy of the model for inspection.
+func (s *Sim) Snapshot() State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+	data, _ := json.Marshal(s.st)
+	var out State
+	_ = json.Unmarshal(data, &out)
+	return out
+}
+
+// call runs fn as one API call: reload shared state, advance the clock by
+// a tick, settle due work, run fn and persist.
+func (s *Sim) call(fn func(st *State) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+	s.st.Now = s.st.Now.Add(s.st.Tick)
+	s.settle()
+	err := fn(s.st)
+	s.persist()
+	return err
+}
+
+// reload re-reads the state file when another process changed it.
+// Caller holds s.mu.
+func (s *Sim) reload() {
+	if s.path == "" {
+		return
+	}
+	info, err := os.Stat(s.path)
+	if err != nil || (info.ModTime().Equal(s.mtime) && info.Size() == s.size) {
+		return
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return
+	}
+	var st State
+	if json.Unmarshal(data, &st) != nil {
+		return
+	}
+	s.st = &st
+	s.mtime, s.size = info.ModTime(), info.Size()
+}
+
+// persist saves the state when the Sim is file-backed. A failed save
+// leaves the in-memory model authoritative for this process.
+func (s *Sim) persist() {
+	if s.path != "" {
+		_ = s.save()
+	}
+}
+
+func (s *Sim) save() error {
+	data, err := json.MarshalIndent(s.st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode simulation state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("create simulation state dir: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write simulation state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("write simulation state: %w", err)
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		s.mtime, s.size = info.ModTime(), info.Size()
+	}
+	return nil
+}
+
+// nextID returns a new ID with the given prefix.
+func (st *State) nextID(prefix string) string {
+	st.Seq++
+	return fmt.Sprintf("%s-%d", prefix, st.Seq)
+}
+
+func (st *State) service(id string) (*Service, error) {
+	for _, svc := range st.Services {
+		if svc.ID == id && !svc.Deleted {
+			return svc, nil
+		}
+	}
+	return nil, platform.NewPlatformError(platform.ErrServiceNotFound,
+		fmt.Sprintf("service %s not found", id), "List services with zerops_discover.")
+}
+
+func (st *State) serviceByName(hostname string) *Service {
+	for _, svc := range st.Services {
+		if svc.Name == hostname && !svc.Deleted {
+			return svc
+		}
+	}
+	return nil
+}
+
+func (st *State) checkProject(projectID string) error {
+	if projectID != st.Project.ID {
+		return platform.NewPlatformError(platform.ErrPermissionDenied,
+			fmt.Sprintf("project %s is not the simulated project %s", projectID, st.Project.ID), "")
+	}
+	return nil
+}
+
+func stamp(t time.Time) string { return t.UTC().Format(time.RFC3339Nano) }
+
+func stampPtr(t time.Time) *string {
+	s := stamp(t)
+	return &s
+}
@@ -0,0 +1,183 @@
+package sim
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+var testStart = time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+
+func newTestSim(t *testing.T) *Sim {
+	t.Helper()
+	return New(Options{Start: testStart, Tick: time.Second})
+}
+
+// importOne imports a single service and settles the import.
+func importOne(t *testing.T, s *Sim, doc string) *platform.ServiceStack {
+	t.Helper()
+	res, err := s.ImportServices(context.Background(), s.ProjectID(), doc)
+	if err != nil {
+		t.Fatalf("ImportServices: %v", err)
+	}
+	if len(res.ServiceStacks) != 1 || res.ServiceStacks[0].Error != nil {
+		t.Fatalf("import result = %+v", res.ServiceStacks)
+	}
+	s.Advance(importTime + 2*queueDelay)
+	svc, ok := s.ServiceByName(res.ServiceStacks[0].Name)
+	if !ok {
+		t.Fatalf("service %s missing after import", res.ServiceStacks[0].Name)
+	}
+	return svc
+}
+
+func platformCode(err error) string {
+	var pe *platform.PlatformError
+	if errors.As(err, &pe) {
+		return pe.Code
+	}
+	return ""
+}
+
+func TestProcess_LifecycleFollowsVirtualClock(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := newTestSim(t)
+	svc := importOne(t, s, "services:\n  - hostname: db\n    type: postgresql@16\n")
+
+	p, err := s.StopService(ctx, svc.ID)
+	if err != nil {
+		t.Fatalf("StopService: %v", err)
+	}
+	if p.Status != platform.ProcessStatusPending {
+		t.Fatalf("new process status = %s, want PENDING", p.Status)
+	}
+
+	got, _ := s.GetProcess(ctx, p.ID)
+	if got.Status != platform.ProcessStatusRunning {
+		t.Fatalf("status after one tick = %s, want RUNNING", got.Status)
+	}
+	if cur, _ := s.GetService(ctx, svc.ID); cur.Status != platform.ServiceStatusActive {
+		t.Errorf("service status while stopping = %s, want ACTIVE", cur.Status)
+	}
+
+	s.Advance(lifecycleTime)
+	got, _ = s.GetProcess(ctx, p.ID)
+	if got.Status != platform.ProcessStatusFinished || got.Finished == nil {
+		t.Fatalf("status after lifecycleTime = %s (finished=%v), want FINISHED", got.Status, got.Finished)
+	}
+	if cur, _ := s.GetService(ctx, svc.ID); cur.Status != statusStopped {
+		t.Errorf("service status = %s, want %s", cur.Status, statusStopped)
+	}
+}
+
+func TestCancelProcess(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := newTestSim(t)
+	svc := importOne(t, s, "services:\n  - hostname: db\n    type: postgresql@16\n")
+
+	p, _ := s.RestartService(ctx, svc.ID)
+	canceled, err := s.CancelProcess(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("CancelProcess: %v", err)
+	}
+	if canceled.Status != platform.ProcessStatusCanceled {
+		t.Errorf("status = %s, want CANCELED", canceled.Status)
+	}
+	if _, err := s.CancelProcess(ctx, p.ID); platformCode(err) != platform.ErrProcessAlreadyTerminal {
+		t.Errorf("second cancel err = %v, want %s", err, platform.ErrProcessAlreadyTerminal)
+	}
+	if _, err := s.GetProcess(ctx, "proc-missing"); platformCode(err) != platform.ErrProcessNotFound {
+		t.Errorf("missing process err = %v, want %s", err, platform.ErrProcessNotFound)
+	}
+}
+
+func TestDeleteService(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := newTestSim(t)
+	svc := importOne(t, s, "services:\n  - hostname: cache\n    type: valkey@7.2\n")
+
+	p, err := s.DeleteService(ctx, svc.ID)
+	if err != nil {
+		t.Fatalf("DeleteService: %v", err)
+	}
+	s.Advance(deleteTime + queueDelay)
+	if got, _ := s.GetProcess(ctx, p.ID); got.Status != platform.ProcessStatusFinished {
+		t.Errorf("delete process status = %s, want FINISHED", got.Status)
+	}
+	if _, err := s.GetService(ctx, svc.ID); platformCode(err) != platform.ErrServiceNotFound {
+		t.Errorf("GetService after delete err = %v, want %s", err, platform.ErrServiceNotFound)
+	}
+	services, _ := s.ListServices(ctx, s.ProjectID())
+	if len(services) != 0 {
+		t.Errorf("ListServices = %d services, want 0", len(services))
+	}
+}
+
+func TestCheckProject(t *testing.T) {
+	t.Parallel()
+	s := newTestSim(t)
+	if _, err := s.ListServices(context.Background(), "other-project"); platformCode(err) != platform.ErrPermissionDenied {
+		t.Errorf("err = %v, want %s", err, platform.ErrPermissionDenied)
+	}
+}
+
+func TestOpen_SharesStateBetweenInstances(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	seeder, err := Open(path, Options{Start: testStart, ProjectName: "shared"})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	server, err := Open(path, Options{})
+	if err != nil {
+		t.Fatalf("Open existing: %v", err)
+	}
+	if got := server.Snapshot().Project.Name; got != "shared" {
+		t.Errorf("project name = %q, want shared", got)
+	}
+
+	if _, err := seeder.ImportServices(ctx, seeder.ProjectID(), "services:\n  - hostname: db\n    type: postgresql@16\n"); err != nil {
+		t.Fatalf("ImportServices: %v", err)
+	}
+	// Same-second writes can keep mtime; the size change still triggers
+	// the reload.
+	services, err := server.ListServices(ctx, server.ProjectID())
+	if err != nil {
+		t.Fatalf("ListServices: %v", err)
+	}
+	if len(services) != 1 || services[0].Name != "db" {
+		t.Fatalf("services seen by second instance = %+v", services)
+	}
+}
+
+func TestSetAutoscaling(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := newTestSim(t)
+	svc := importOne(t, s, "services:\n  - hostname: app\n    type: nodejs@22\n")
+
+	maxRAM := 4.0
+	p, err := s.SetAutoscaling(ctx, svc.ID, platform.AutoscalingParams{VerticalMaxRAM: &maxRAM})
+	if err != nil || p != nil {
+		t.Fatalf("SetAutoscaling = %v, %v; want nil process (sync)", p, err)
+	}
+	got, _ := s.GetService(ctx, svc.ID)
+	if got.CustomAutoscaling == nil || got.CustomAutoscaling.MaxRAM != 4 {
+		t.Errorf("autoscaling = %+v, want MaxRAM 4", got.CustomAutoscaling)
+	}
+
+	minRAM := 8.0
+	_, err = s.SetAutoscaling(ctx, svc.ID, platform.AutoscalingParams{VerticalMinRAM: &minRAM})
+	if platformCode(err) != platform.ErrInvalidScaling {
+		t.Errorf("min > max err = %v, want %s", err, platform.ErrInvalidScaling)
+	}
+}
@@ -0,0 +1,161 @@
+package sim
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+func hasHTTPPort(svc *Service) bool {
+	_, ok := httpPort(svc)
+	return ok
+}
+
+func httpPort(svc *Service) (int, bool) {
+	for _, p := range svc.Ports {
+		if p.HTTPSupport {
+			return p.Port, true
+		}
+	}
+	return 0, false
+}
+
+// subdomainURL renders the zerops.app URL of svc's first HTTP port.
+func (st *State) subdomainURL(svc *Service) string {
+	port, _ := httpPort(svc)
+	prefix, rest, _ := strings.Cut(st.Project.SubdomainHost, ".")
+	if port == 80 {
+		return fmt.Sprintf("https://%s-%s.%s", svc.Name, prefix, rest)
+	}
+	return fmt.Sprintf("https://%s-%s-%d.%s", svc.Name, prefix, port, rest)
+}
+
+func (st *State) enableSubdomain(svc *Service) {
+	svc.SubdomainAccess = true
+	st.setEnv(svc, envZeropsSubdomain, st.subdomainURL(svc))
+}
+
+func (st *State) disableSubdomain(svc *Service) {
+	svc.SubdomainAccess = false
+	st.unsetEnv(svc, envZeropsSubdomain)
+}
+
+// EnableSubdomainAccess rejects services without an HTTP port with the
+// platform's noSubdomainPorts code, like the API does before a deploy.
+func (s *Sim) EnableSubdomainAccess(_ context.Context, serviceID string) (*platform.Process, error) {
+	var out *platform.Process
+	err := s.call(func(st *State) error {
+		svc, err := st.service(serviceID)
+		if err != nil {
+			return err
+		}
+		if !hasHTTPPort(svc) {
+			pe := platform.NewPlatformError(platform.ErrAPIError,
+				fmt.Sprintf("service %s has no ports with httpSupport", svc.Name),
+				"Deploy a zerops.yaml with run.ports[].httpSupport: true first.")
+			pe.APICode = "noSubdomainPorts"
+			return pe
+		}
+		out = st.processView(st.newProcess(actionSubdomainOn, svc.ID, subdomainTime))
+		return nil
+	})
+	return out, err
+}
+
+func (s *Sim) DisableSubdomainAccess(_ context.Context, serviceID string) (*platform.Process, error) {
+	var out *platform.Process
+	err := s.call(func(st *State) error {
+		svc, err := st.service(serviceID)
+		if err != nil {
+			return err
+		}
+		out = st.processView(st.newProcess(actionSubdomainOff, svc.ID, subdomainTime))
+		return nil
+	})
+	return out, err
+}
+
+// Do answers HTTP requests to the simulated project, so the Sim can stand
+// in for the HTTP client of readiness probes and verify checks. A
+// subdomain URL, or http://<hostname>:<port> on the private network,
+// answers 200 when the service runs a deployed app on that port and 502
+// while it does not. Any other host is unreachable.
+func (s *Sim) Do(req *http.Request) (*http.Response, error) {
+	var (
+		status int
+		body   string
+	)
+	err := s.call(func(st *State) error {
+		svc, port, public := st.route(req.URL.Hostname(), req.URL.Port())
+		if svc == nil {
+			return fmt.Errorf("dial tcp: lookup %s: no such host (simulated network)", req.URL.Hostname())
+		}
+		switch {
+		case public && !svc.SubdomainAccess:
+			status, body = http.StatusNotFound, "subdomain access is disabled"
+		case !servesPort(svc, port):
+			status, body = http.StatusBadGateway, fmt.Sprintf("%s does not listen on port %d", svc.Name, port)
+		case svc.Status != platform.ServiceStatusActive || svc.ActiveVersion == "":
+			status, body = http.StatusBadGateway, fmt.Sprintf("%s is not running", svc.Name)
+		default:
+			status, body = http.StatusOK, fmt.Sprintf("%s: ok (app version %s)", svc.Name, svc.ActiveVersion)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"text/plain; charset=utf-8"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// route maps a request host to a service and port. public reports a
+// zerops.app subdomain as opposed to a private hostname.
+func (st *State) route(host, portStr string) (svc *Service, port int, public bool) {
+	prefix, rest, _ := strings.Cut(st.Project.SubdomainHost, ".")
+	if label, ok := strings.CutSuffix(host, "."+rest); ok {
+		name, tail, found := strings.Cut(label, "-"+prefix)
+		if !found {
+			return nil, 0, false
+		}
+		port = 80
+		if p, ok := strings.CutPrefix(tail, "-"); ok {
+			n, err := strconv.Atoi(p)
+			if err != nil {
+				return nil, 0, false
+			}
+			port = n
+		} else if tail != "" {
+			return nil, 0, false
+		}
+		return st.serviceByName(name), port, true
+	}
+	port = 80
+	if portStr != "" {
+		port, _ = strconv.Atoi(portStr)
+	}
+	return st.serviceByName(host), port, false
+}
+
+func servesPort(svc *Service, port int) bool {
+	for _, p := range svc.Ports {
+		if p.Port == port {
+			return true
+		}
+	}
+	return false
+}
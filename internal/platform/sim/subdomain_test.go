@@ -0,0 +1,130 @@
+package sim
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+func get(t *testing.T, s *Sim, url string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	resp, err := s.Do(req)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestSubdomain_EnableNeedsHTTPPort(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := newTestSim(t)
+	app := importOne(t, s, "services:\n  - hostname: app\n    type: nodejs@22\n")
+
+	_, err := s.EnableSubdomainAccess(ctx, app.ID)
+	var pe *platform.PlatformError
+	if platformCode(err) != platform.ErrAPIError || !errors.As(err, &pe) || pe.APICode != "noSubdomainPorts" {
+		t.Fatalf("enable before deploy err = %v, want noSubdomainPorts", err)
+	}
+
+	if _, err := s.Push(app.ID, "app", appYAML); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	s.Advance(buildBaseTime + buildCommandTime + deployTime + 10*queueDelay)
+
+	if code, _ := get(t, s, "http://app:3000/"); code != http.StatusOK {
+		t.Errorf("private GET = %d, want 200", code)
+	}
+	const public = "https://app-5a1e-3000.prg1.zerops.app/"
+	if code, _ := get(t, s, public); code != http.StatusNotFound {
+		t.Errorf("public GET before enable = %d, want 404", code)
+	}
+
+	if _, err := s.EnableSubdomainAccess(ctx, app.ID); err != nil {
+		t.Fatalf("EnableSubdomainAccess: %v", err)
+	}
+	s.Advance(subdomainTime + queueDelay)
+	if code, body := get(t, s, public); code != http.StatusOK {
+		t.Errorf("public GET = %d %q, want 200", code, body)
+	}
+	env, _, _ := s.ResolvedEnv("app")
+	if env[envZeropsSubdomain] != "https://app-5a1e-3000.prg1.zerops.app" {
+		t.Errorf("zeropsSubdomain = %q", env[envZeropsSubdomain])
+	}
+
+	if code, _ := get(t, s, "http://app:8080/"); code != http.StatusBadGateway {
+		t.Errorf("GET on unserved port = %d, want 502", code)
+	}
+	if code, msg := get(t, s, "https://example.com/"); code != 0 {
+		t.Errorf("GET outside the project = %d %q, want a network error", code, msg)
+	}
+}
+
+func TestImport_SubdomainAppliedAfterFirstDeploy(t *testing.T) {
+	t.Parallel()
+	s := newTestSim(t)
+	app := importOne(t, s, "services:\n  - hostname: api\n    type: nodejs@22\n    enableSubdomainAccess: true\n")
+	if app.SubdomainAccess {
+		t.Fatal("subdomain enabled before the service has an HTTP port")
+	}
+	if _, err := s.Push(app.ID, "app", appYAML); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	s.Advance(buildBaseTime + buildCommandTime + deployTime + 10*queueDelay)
+	if got, _ := s.ServiceByName("api"); !got.SubdomainAccess {
+		t.Error("pending enableSubdomainAccess not applied by the first deploy")
+	}
+}
+
+func TestImport_PerServiceErrors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := newTestSim(t)
+	importOne(t, s, "services:\n  - hostname: db\n    type: postgresql@16\n")
+
+	res, err := s.ImportServices(ctx, s.ProjectID(), `services:
+  - hostname: db
+    type: postgresql@16
+  - hostname: x
+    type: cobol@1
+  - hostname: repo
+    type: nodejs@22
+    buildFromGit: https://github.com/example/app
+`)
+	if err != nil {
+		t.Fatalf("ImportServices: %v", err)
+	}
+	codes := []string{}
+	for _, ss := range res.ServiceStacks {
+		if ss.Error != nil {
+			codes = append(codes, ss.Error.Code)
+		} else {
+			codes = append(codes, "ok")
+		}
+	}
+	want := []string{"serviceStackNameUnavailable", "serviceStackTypeNotFound", "ok"}
+	for i := range want {
+		if i >= len(codes) || codes[i] != want[i] {
+			t.Fatalf("codes = %v, want %v", codes, want)
+		}
+	}
+
+	s.Advance(importTime + buildBaseTime + deployTime + 4*queueDelay)
+	repo, _ := s.ServiceByName("repo")
+	if repo.Status != platform.ServiceStatusActive {
+		t.Errorf("buildFromGit service status = %s, want ACTIVE", repo.Status)
+	}
+	if _, err := s.ImportServices(ctx, s.ProjectID(), "services: {"); platformCode(err) != platform.ErrInvalidImportYml {
+		t.Errorf("invalid yaml err = %v, want %s", err, platform.ErrInvalidImportYml)
+	}
+}
@@ -132,6 +132,10 @@ const (
 	BuildStatusPreparingRuntimeFail = "PREPARING_RUNTIME_FAILED"
 	BuildStatusDeployed             = "DEPLOYED"
 
+	// AppVersionStatusBackup marks a previously-active app version whose
+	// built artifact the platform still retains — a rollback candidate.
+	AppVersionStatusBackup = "BACKUP"
+
 	ServiceStatusNew           = "NEW"
 	ServiceStatusActive        = "ACTIVE"
 	ServiceStatusReadyToDeploy = "READY_TO_DEPLOY"
@@ -247,6 +251,15 @@ type ProcessEvent struct {
 	CreatedBySystem bool              `json:"createdBySystem"`
 }
 
+// AppVersionUpload is a freshly created app version waiting for its
+// source archive. UploadURL is pre-signed; the archive goes there as a
+// plain HTTP PUT, not through the API.
+type AppVersionUpload struct {
+	ID             string `json:"id"`
+	ServiceStackID string `json:"serviceStackId"`
+	UploadURL      string `json:"uploadUrl"`
+}
+
 // AppVersionEvent represents a build/deploy event from the search API.
 type AppVersionEvent struct {
 	ID             string     `json:"id"`
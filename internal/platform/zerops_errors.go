@@ -77,6 +77,8 @@ func mapAPIError(apiErr apiError.Error, entityType string) error {
 		switch entityType {
 		case "process":
 			return withAPICode(NewPlatformError(ErrProcessNotFound, msg, "Check process ID"), errCode, meta)
+		case "app version":
+			return withAPICode(NewPlatformError(ErrInvalidParameter, msg, "Check appVersionId — zerops_events lists recent app versions"), errCode, meta)
 		default:
 			return withAPICode(NewPlatformError(ErrServiceNotFound, msg, "Check service hostname"), errCode, meta)
 		}
@@ -2,12 +2,23 @@ package platform
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
 
 	"github.com/zeropsio/zerops-go/dto/input/body"
 	"github.com/zeropsio/zerops-go/dto/input/path"
+	"github.com/zeropsio/zerops-go/types"
 	"github.com/zeropsio/zerops-go/types/uuid"
 )
 
+// appVersionUploadTimeout bounds the archive PUT. Much longer than
+// DefaultAPITimeout: the body is the whole source tree, and slow uplinks
+// are the norm on the laptops local mode runs on.
+const appVersionUploadTimeout = 15 * time.Minute
+
 // ---------------------------------------------------------------------------
 // Lifecycle
 // ---------------------------------------------------------------------------
@@ -150,3 +161,98 @@ func (z *ZeropsClient) SetAutoscaling(ctx context.Context, serviceID string, par
 	proc := mapProcess(*out.Process)
 	return &proc, nil
 }
+
+// ---------------------------------------------------------------------------
+// App versions
+// ---------------------------------------------------------------------------
+
+func (z *ZeropsClient) DeployAppVersion(ctx context.Context, appVersionID string) (*Process, error) {
+	pathParam := path.AppVersionId{Id: uuid.AppVersionId(appVersionID)}
+	// Empty body: reuse the zerops.yaml stored with the app version. Passing
+	// a yaml here would re-resolve the deploy section against current source,
+	// which defeats the point of reactivating a known-good build.
+	resp, err := z.handler.PutAppVersionDeploy(ctx, pathParam, body.PutAppVersionDeploy{})
+	if err != nil {
+		return nil, mapSDKError(err, "app version")
+	}
+	out, err := resp.Output()
+	if err != nil {
+		return nil, mapSDKError(err, "app version")
+	}
+	proc := mapProcess(out)
+	return &proc, nil
+}
+
+func (z *ZeropsClient) CreateAppVersion(ctx context.Context, serviceID string) (*AppVersionUpload, error) {
+	pathParam := path.ServiceStackId{Id: uuid.ServiceStackId(serviceID)}
+	resp, err := z.handler.PostServiceStackAppVersion(ctx, pathParam, body.PostAppVersion{})
+	if err != nil {
+		return nil, mapSDKError(err, "service")
+	}
+	out, err := resp.Output()
+	if err != nil {
+		return nil, mapSDKError(err, "service")
+	}
+	return &AppVersionUpload{
+		ID:             out.Id.TypedString().String(),
+		ServiceStackID: out.ServiceStackId.TypedString().String(),
+		UploadURL:      out.UploadUrl.Native(),
+	}, nil
+}
+
+// UploadAppVersion PUTs the archive to the pre-signed upload URL. The
+// request bypasses the SDK (the URL is not an API endpoint), so errors are
+// mapped here the same way the log fetcher maps its own HTTP failures.
+func (z *ZeropsClient) UploadAppVersion(ctx context.Context, upload *AppVersionUpload, archive io.Reader, size int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, upload.UploadURL, archive)
+	if err != nil {
+		return NewPlatformError(ErrAPIError,
+			fmt.Sprintf("failed to create upload request: %v", err),
+			"The upload URL returned by the API is malformed. Retry the deploy; if persistent, this is a ZCP bug.")
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := (&http.Client{Timeout: appVersionUploadTimeout}).Do(req)
+	if err != nil {
+		if code, isNet := MapNetworkError(err); isNet {
+			return NewPlatformError(code,
+				fmt.Sprintf("archive upload failed: %v", err),
+				"Check network connectivity and retry the deploy. Large archives on slow links: add build output and dependencies to .deployignore.")
+		}
+		return NewPlatformError(ErrAPIError,
+			fmt.Sprintf("archive upload failed: %v", err),
+			"The upload endpoint rejected the request. Retry the deploy; a fresh app version gets a fresh upload URL.")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorResponseBytes))
+		return NewPlatformError(ErrAPIError,
+			fmt.Sprintf("archive upload returned HTTP %d: %s", resp.StatusCode, string(respBody)),
+			"The upload endpoint responded with a non-2xx status. Retry the deploy; a fresh app version gets a fresh upload URL.")
+	}
+	return nil
+}
+
+func (z *ZeropsClient) BuildAndDeployAppVersion(ctx context.Context, appVersionID, zeropsYaml, setup string) (*Process, error) {
+	pathParam := path.AppVersionId{Id: uuid.AppVersionId(appVersionID)}
+	// The endpoint takes the yaml base64-encoded (zcli sends it the same
+	// way); the validate endpoint, by contrast, takes it raw.
+	req := body.PutAppVersionBuildAndDeploy{
+		ZeropsYaml: types.NewMediumText(base64.StdEncoding.EncodeToString([]byte(zeropsYaml))),
+	}
+	if setup != "" {
+		req.ZeropsYamlSetup = types.NewStringNull(setup)
+	}
+	resp, err := z.handler.PutAppVersionBuildAndDeploy(ctx, pathParam, req)
+	if err != nil {
+		return nil, mapSDKError(err, "app version")
+	}
+	out, err := resp.Output()
+	if err != nil {
+		return nil, mapSDKError(err, "app version")
+	}
+	proc := mapProcess(out)
+	return &proc, nil
+}
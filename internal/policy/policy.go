@@ -0,0 +1,168 @@
+// Package policy loads the project-level .zcp/policy.yaml that restricts
+// what a connected agent may do: a global read-only switch, hostnames
+// protected from destructive operations, and per-tool allow/deny lists.
+//
+// A nil *Policy allows everything — that is the state when no policy file
+// exists. Every check returns a *platform.PlatformError with code
+// POLICY_DENIED so the agent sees which rule refused the call and what to
+// do instead.
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/zeropsio/zcp/internal/platform"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the policy file name inside the .zcp directory.
+const FileName = "policy.yaml"
+
+// Protected operations. These are the only operations the protected list
+// blocks; restarts, reloads, deploys and scale-ups stay allowed so a
+// protected production service can still be operated.
+const (
+	OpDelete    = "delete"
+	OpStop      = "stop"
+	OpScaleDown = "scale-down"
+	OpEnvDelete = "env-delete"
+)
+
+// Policy is the parsed policy file.
+type Policy struct {
+	// ReadOnly refuses every call that changes platform state.
+	ReadOnly bool `yaml:"readOnly"`
+	// Protected hostnames cannot be deleted, stopped, scaled down or have
+	// service env vars deleted.
+	Protected []string `yaml:"protected"`
+	// Tools restricts which tools may be called at all.
+	Tools ToolRules `yaml:"tools"`
+
+	// loadErr is set by FailClosed: the file existed but could not be
+	// used, so every mutation is refused with the parse error attached.
+	loadErr error
+}
+
+// ToolRules lists tool names. A non-empty Allow admits only the listed
+// tools; Deny always wins over Allow.
+type ToolRules struct {
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+}
+
+// Path returns the policy file location for a state dir (.zcp/state →
+// .zcp/policy.yaml).
+func Path(stateDir string) string {
+	return filepath.Join(filepath.Dir(stateDir), FileName)
+}
+
+// Load reads and validates the policy file at path. A missing file
+// returns (nil, nil) — no policy, nothing restricted.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	var p Policy
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil {
+		if errors.Is(err, io.EOF) { // empty file
+			return &p, nil
+		}
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for _, name := range append(slices.Clone(p.Tools.Allow), p.Tools.Deny...) {
+		if !strings.HasPrefix(name, "zerops_") {
+			return nil, fmt.Errorf("parse %s: tool %q is not a zerops_* tool name", path, name)
+		}
+	}
+	return &p, nil
+}
+
+// FailClosed returns a policy that refuses every mutation because the
+// policy file could not be loaded. A typo in policy.yaml must not silently
+// lift the restrictions it was meant to impose.
+func FailClosed(err error) *Policy {
+	return &Policy{ReadOnly: true, loadErr: err}
+}
+
+// CheckTool enforces the allow/deny lists.
+func (p *Policy) CheckTool(tool string) error {
+	if p == nil {
+		return nil
+	}
+	if slices.Contains(p.Tools.Deny, tool) {
+		return denied(
+			fmt.Sprintf("Policy denies tool %s (tools.deny in .zcp/policy.yaml)", tool),
+			"This tool is disabled for this project. Ask the user to perform the operation or to change the policy.")
+	}
+	if len(p.Tools.Allow) > 0 && !slices.Contains(p.Tools.Allow, tool) {
+		return denied(
+			fmt.Sprintf("Policy does not allow tool %s (tools.allow in .zcp/policy.yaml)", tool),
+			"Allowed tools: "+strings.Join(p.Tools.Allow, ", ")+".")
+	}
+	return nil
+}
+
+// CheckMutation refuses state-changing calls in read-only mode. what
+// describes the call for the message, e.g. "zerops_env action=set".
+func (p *Policy) CheckMutation(what string) error {
+	if p == nil || !p.ReadOnly {
+		return nil
+	}
+	if p.loadErr != nil {
+		return denied(
+			fmt.Sprintf("%s refused: .zcp/policy.yaml could not be loaded (%v); mutations are blocked until it is fixed", what, p.loadErr),
+			"Fix the policy file and restart zcp. Read-only tools (zerops_discover, zerops_logs, zerops_events, zerops_verify) still work.")
+	}
+	return denied(
+		fmt.Sprintf("%s refused: project is read-only (readOnly: true in .zcp/policy.yaml)", what),
+		"Only read-only tools are available: zerops_discover, zerops_logs, zerops_events, zerops_verify, zerops_knowledge. Describe the change to the user instead of applying it.")
+}
+
+// IsProtected reports whether hostname is on the protected list.
+func (p *Policy) IsProtected(hostname string) bool {
+	return p != nil && hostname != "" && slices.Contains(p.Protected, hostname)
+}
+
+// CheckProtected refuses op on a protected hostname. detail, when
+// non-empty, is appended to the message (e.g. which limits would drop).
+func (p *Policy) CheckProtected(hostname, op, detail string) error {
+	if !p.IsProtected(hostname) {
+		return nil
+	}
+	msg := fmt.Sprintf("Service %s is protected (protected in .zcp/policy.yaml): %s is not allowed", hostname, op)
+	if detail != "" {
+		msg += " — " + detail
+	}
+	return denied(msg, protectedAlternative(op))
+}
+
+func protectedAlternative(op string) string {
+	switch op {
+	case OpStop:
+		return "Use zerops_manage action=restart or action=reload instead, or ask the user to stop the service."
+	case OpScaleDown:
+		return "Scaling up is allowed. Lowering limits on a protected service must be done by the user."
+	case OpEnvDelete:
+		return "Use zerops_env action=set to change the value instead of deleting it, or ask the user."
+	default:
+		return "Protected services can only be removed by the user outside zcp."
+	}
+}
+
+func denied(msg, suggestion string) error {
+	return platform.NewPlatformError(platform.ErrPolicyDenied, msg, suggestion)
+}
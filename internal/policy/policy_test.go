@@ -0,0 +1,126 @@
+// Tests for: policy/policy.go — policy file loading and rule checks.
+package policy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeropsio/zcp/internal/platform"
+)
+
+func writePolicy(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func requireDenied(t *testing.T, err error, wantSubstr string) {
+	t.Helper()
+	var pe *platform.PlatformError
+	if !errors.As(err, &pe) || pe.Code != platform.ErrPolicyDenied {
+		t.Fatalf("expected POLICY_DENIED, got %v", err)
+	}
+	if !strings.Contains(pe.Message, wantSubstr) {
+		t.Errorf("message %q missing %q", pe.Message, wantSubstr)
+	}
+	if pe.Suggestion == "" {
+		t.Error("denial must carry a suggestion")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	t.Run("missing file is no policy", func(t *testing.T) {
+		t.Parallel()
+		p, err := Load(filepath.Join(t.TempDir(), FileName))
+		if err != nil || p != nil {
+			t.Fatalf("Load(missing) = %v, %v; want nil, nil", p, err)
+		}
+	})
+
+	t.Run("full policy", func(t *testing.T) {
+		t.Parallel()
+		p, err := Load(writePolicy(t, `
+readOnly: true
+protected: [db, appstage]
+tools:
+  allow: [zerops_discover, zerops_logs]
+  deny: [zerops_delete]
+`))
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if !p.ReadOnly || len(p.Protected) != 2 || len(p.Tools.Allow) != 2 || p.Tools.Deny[0] != "zerops_delete" {
+			t.Errorf("parsed = %+v", p)
+		}
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		t.Parallel()
+		p, err := Load(writePolicy(t, ""))
+		if err != nil || p == nil || p.ReadOnly {
+			t.Fatalf("Load(empty) = %+v, %v; want zero policy", p, err)
+		}
+	})
+
+	for name, body := range map[string]string{
+		"unknown field":  "readonly: true\n",
+		"bad tool name":  "tools:\n  deny: [delete]\n",
+		"malformed yaml": "protected: [db\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := Load(writePolicy(t, body)); err == nil {
+				t.Errorf("Load(%q) = nil error", body)
+			}
+		})
+	}
+}
+
+func TestNilPolicyAllowsEverything(t *testing.T) {
+	t.Parallel()
+
+	var p *Policy
+	if p.CheckTool("zerops_delete") != nil || p.CheckMutation("zerops_delete") != nil ||
+		p.CheckProtected("db", OpDelete, "") != nil || p.IsProtected("db") {
+		t.Error("nil policy must allow everything")
+	}
+}
+
+func TestCheckTool(t *testing.T) {
+	t.Parallel()
+
+	p := &Policy{Tools: ToolRules{Allow: []string{"zerops_discover", "zerops_delete"}, Deny: []string{"zerops_delete"}}}
+	if err := p.CheckTool("zerops_discover"); err != nil {
+		t.Errorf("allowed tool refused: %v", err)
+	}
+	requireDenied(t, p.CheckTool("zerops_delete"), "denies tool zerops_delete")
+	requireDenied(t, p.CheckTool("zerops_scale"), "does not allow tool zerops_scale")
+}
+
+func TestCheckMutation(t *testing.T) {
+	t.Parallel()
+
+	if err := (&Policy{}).CheckMutation("zerops_scale"); err != nil {
+		t.Errorf("writable policy refused mutation: %v", err)
+	}
+	requireDenied(t, (&Policy{ReadOnly: true}).CheckMutation("zerops_env action=set"), "zerops_env action=set refused: project is read-only")
+	requireDenied(t, FailClosed(errors.New("line 3: bad indent")).CheckMutation("zerops_deploy"), "line 3: bad indent")
+}
+
+func TestCheckProtected(t *testing.T) {
+	t.Parallel()
+
+	p := &Policy{Protected: []string{"db"}}
+	if err := p.CheckProtected("app", OpDelete, ""); err != nil {
+		t.Errorf("unprotected host refused: %v", err)
+	}
+	requireDenied(t, p.CheckProtected("db", OpScaleDown, "would lower maxRam 4→2"), "scale-down is not allowed — would lower maxRam 4→2")
+}
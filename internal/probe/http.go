@@ -0,0 +1,153 @@
+package probe
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// S3Region is the SigV4 region Zerops object storage accepts.
+	S3Region = "us-east-1"
+
+	httpBodyCap = 64 << 10
+	emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// S3HeadBucket sends a SigV4-signed HEAD for bucket at endpoint
+// (path-style), proving both the credentials and the bucket.
+func S3HeadBucket(ctx context.Context, doer Doer, endpoint, accessKey, secretKey, bucket string) (string, error) {
+	u, err := url.Parse(strings.TrimSuffix(endpoint, "/") + "/" + url.PathEscape(bucket))
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	signV4(req, accessKey, secretKey, time.Now().UTC())
+	resp, err := doer.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrUnreachable, err)
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return "HEAD bucket " + bucket + " ok", nil
+	case resp.StatusCode == http.StatusForbidden:
+		return "", fmt.Errorf("HEAD bucket %s: HTTP 403 — credentials rejected or no access", bucket)
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("HEAD bucket %s: HTTP 404 — bucket does not exist", bucket)
+	default:
+		return "", fmt.Errorf("HEAD bucket %s: HTTP %d", bucket, resp.StatusCode)
+	}
+}
+
+// signV4 adds AWS Signature Version 4 headers for an empty-body S3 request.
+func signV4(req *http.Request, accessKey, secretKey string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptySHA256)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + emptySHA256 + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		emptySHA256,
+	}, "\n")
+	scope := day + "/" + S3Region + "/s3/aws4_request"
+	hashed := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	key := []byte("AWS4" + secretKey)
+	for _, part := range []string{day, S3Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, hex.EncodeToString(hmacSHA256(key, toSign))))
+}
+
+// Meilisearch checks GET /health and, with a master key, GET /version —
+// the latter proves the key is accepted.
+func Meilisearch(ctx context.Context, doer Doer, baseURL, masterKey string) (string, error) {
+	var health struct {
+		Status string `json:"status"`
+	}
+	if err := getJSON(ctx, doer, baseURL+"/health", nil, &health); err != nil {
+		return "", err
+	}
+	if health.Status != "available" {
+		return "", fmt.Errorf("GET /health: status %q, want available", health.Status)
+	}
+	if masterKey == "" {
+		return "health available", nil
+	}
+	var version struct {
+		PkgVersion string `json:"pkgVersion"`
+	}
+	if err := getJSON(ctx, doer, baseURL+"/version", func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+masterKey)
+	}, &version); err != nil {
+		return "", err
+	}
+	return "health available, master key accepted (Meilisearch " + version.PkgVersion + ")", nil
+}
+
+// Elasticsearch checks GET /_cluster/health with basic auth. Red fails;
+// yellow passes (single-node clusters are always yellow with replicas).
+func Elasticsearch(ctx context.Context, doer Doer, baseURL, user, password string) (string, error) {
+	var health struct {
+		Status string `json:"status"`
+		Nodes  int    `json:"number_of_nodes"`
+	}
+	err := getJSON(ctx, doer, baseURL+"/_cluster/health", func(r *http.Request) {
+		if password != "" {
+			r.SetBasicAuth(user, password)
+		}
+	}, &health)
+	if err != nil {
+		return "", err
+	}
+	if health.Status != "green" && health.Status != "yellow" {
+		return "", fmt.Errorf("cluster status %q", health.Status)
+	}
+	return fmt.Sprintf("cluster %s, %d node(s)", health.Status, health.Nodes), nil
+}
+
+// getJSON GETs url and decodes a 2xx JSON body into out.
+func getJSON(ctx context.Context, doer Doer, url string, edit func(*http.Request), out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if edit != nil {
+		edit(req)
+	}
+	resp, err := doer.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnreachable, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, httpBodyCap))
+	path := req.URL.Path
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("GET %s: HTTP %d", path, resp.StatusCode)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("GET %s: body is not JSON", path)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Tests for: probe/http.go — object storage and search health probes.
+package probe
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignV4(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodHead, "https://storage-prg1.zerops.io/records", nil)
+	signV4(req, "AKID", "secret", time.Date(2026, 4, 23, 10, 0, 0, 0, time.UTC))
+
+	if got := req.Header.Get("X-Amz-Date"); got != "20260423T100000Z" {
+		t.Errorf("X-Amz-Date = %q", got)
+	}
+	auth := req.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20260423/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=") {
+		t.Errorf("Authorization = %q", auth)
+	}
+	sig := auth[strings.LastIndex(auth, "=")+1:]
+	if len(sig) != 64 {
+		t.Errorf("signature %q is not hex SHA-256", sig)
+	}
+
+	// The signature covers the secret and the path.
+	other := httptest.NewRequest(http.MethodHead, "https://storage-prg1.zerops.io/records", nil)
+	signV4(other, "AKID", "other", time.Date(2026, 4, 23, 10, 0, 0, 0, time.UTC))
+	if other.Header.Get("Authorization") == auth {
+		t.Error("different secret must change the signature")
+	}
+}
+
+func TestS3HeadBucket(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method != http.MethodHead:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case !strings.Contains(r.Header.Get("Authorization"), "Credential=good/"):
+			w.WriteHeader(http.StatusForbidden)
+		case r.URL.Path != "/records":
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		key, bucket, wantErr string
+	}{
+		{"good", "records", ""},
+		{"bad", "records", "HTTP 403"},
+		{"good", "missing", "bucket does not exist"},
+	}
+	for _, tt := range tests {
+		detail, err := S3HeadBucket(probeCtx(t), srv.Client(), srv.URL, tt.key, "secret", tt.bucket)
+		if tt.wantErr == "" {
+			if err != nil || detail != "HEAD bucket records ok" {
+				t.Errorf("%s/%s = %q, %v", tt.key, tt.bucket, detail, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s/%s err = %v, want %q", tt.key, tt.bucket, err, tt.wantErr)
+		}
+	}
+}
+
+func TestMeilisearch(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			_, _ = w.Write([]byte(`{"status":"available"}`))
+		case "/version":
+			if r.Header.Get("Authorization") != "Bearer master" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(`{"pkgVersion":"1.11.0"}`))
+		}
+	}))
+	defer srv.Close()
+
+	if detail, err := Meilisearch(probeCtx(t), srv.Client(), srv.URL, "master"); err != nil || !strings.Contains(detail, "Meilisearch 1.11.0") {
+		t.Errorf("Meilisearch = %q, %v", detail, err)
+	}
+	if _, err := Meilisearch(probeCtx(t), srv.Client(), srv.URL, "wrong"); err == nil || !strings.Contains(err.Error(), "GET /version: HTTP 403") {
+		t.Errorf("wrong key err = %v", err)
+	}
+}
+
+func TestElasticsearch(t *testing.T) {
+	t.Parallel()
+
+	for status, wantErr := range map[string]bool{"green": false, "yellow": false, "red": true} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if user, pass, ok := r.BasicAuth(); !ok || user != "elastic" || pass != "s3cret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"status":"` + status + `","number_of_nodes":1}`))
+		}))
+		detail, err := Elasticsearch(probeCtx(t), srv.Client(), srv.URL, "elastic", "s3cret")
+		if (err != nil) != wantErr {
+			t.Errorf("%s: detail=%q err=%v", status, detail, err)
+		}
+		if _, err := Elasticsearch(probeCtx(t), srv.Client(), srv.URL, "elastic", "nope"); err == nil || !strings.Contains(err.Error(), "HTTP 401") {
+			t.Errorf("%s: bad password err = %v", status, err)
+		}
+		srv.Close()
+	}
+}
@@ -0,0 +1,190 @@
+package probe
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1" //nolint:gosec // mysql_native_password is SHA-1 by definition
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	myClientLongPassword     = 0x00000001
+	myClientConnectWithDB    = 0x00000008
+	myClientProtocol41       = 0x00000200
+	myClientSecureConnection = 0x00008000
+	myClientPluginAuth       = 0x00080000
+	myCharsetUTF8MB4         = 45
+	myNativePassword         = "mysql_native_password"
+	myComQuit                = 0x01
+	myComQuery               = 0x03
+)
+
+// MariaDB logs in to a MariaDB/MySQL server with mysql_native_password,
+// runs SELECT 1 and returns a detail naming the server version.
+func MariaDB(ctx context.Context, addr, user, password, database string) (string, error) {
+	c, err := dial(ctx, addr)
+	if err != nil {
+		return "", err
+	}
+	defer c.Close()
+
+	_, greeting, err := myRead(c)
+	if err != nil {
+		return "", err
+	}
+	version, scramble, err := myParseHandshake(greeting)
+	if err != nil {
+		return "", err
+	}
+
+	caps := uint32(myClientLongPassword | myClientProtocol41 | myClientSecureConnection | myClientPluginAuth)
+	if database != "" {
+		caps |= myClientConnectWithDB
+	}
+	auth := myNativeScramble(scramble, password)
+	var resp []byte
+	resp = binary.LittleEndian.AppendUint32(resp, caps)
+	resp = binary.LittleEndian.AppendUint32(resp, maxFrame)
+	resp = append(resp, myCharsetUTF8MB4)
+	resp = append(resp, make([]byte, 23)...)
+	resp = append(append(resp, user...), 0)
+	resp = append(append(resp, byte(len(auth))), auth...)
+	if database != "" {
+		resp = append(append(resp, database...), 0)
+	}
+	resp = append(append(resp, myNativePassword...), 0)
+	if err := myWrite(c, 1, resp); err != nil {
+		return "", err
+	}
+
+	seq, reply, err := myRead(c)
+	if err != nil {
+		return "", err
+	}
+	if len(reply) > 0 && reply[0] == 0xfe {
+		// Auth switch: the account uses another plugin. Only the native
+		// one is spoken here; MariaDB defaults to it.
+		plugin, data, _ := bytes.Cut(reply[1:], []byte{0})
+		if string(plugin) != myNativePassword {
+			return "", fmt.Errorf("unsupported auth plugin %s", plugin)
+		}
+		if err := myWrite(c, seq+1, myNativeScramble(bytes.TrimSuffix(data, []byte{0}), password)); err != nil {
+			return "", err
+		}
+		if _, reply, err = myRead(c); err != nil {
+			return "", err
+		}
+	}
+	if err := myCheckOK(reply); err != nil {
+		return "", err
+	}
+
+	if err := myWrite(c, 0, append([]byte{myComQuery}, "SELECT 1"...)); err != nil {
+		return "", err
+	}
+	// Result set: column count, column definitions, EOF, rows, EOF.
+	_, first, err := myRead(c)
+	if err != nil {
+		return "", err
+	}
+	if len(first) > 0 && first[0] == 0xff {
+		return "", myCheckOK(first)
+	}
+	row := ""
+	for eofs := 0; eofs < 2; {
+		_, pkt, err := myRead(c)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case len(pkt) > 0 && pkt[0] == 0xff:
+			return "", myCheckOK(pkt)
+		case len(pkt) > 0 && pkt[0] == 0xfe && len(pkt) < 9:
+			eofs++
+		case eofs == 1 && row == "" && len(pkt) > 1:
+			row = string(pkt[1 : 1+min(int(pkt[0]), len(pkt)-1)])
+		}
+	}
+	_ = myWrite(c, 0, []byte{myComQuit})
+	if row != "1" {
+		return "", fmt.Errorf("SELECT 1 returned %q", row)
+	}
+	return "login and SELECT 1 ok (" + version + ")", nil
+}
+
+// myParseHandshake extracts the server version and the 20-byte scramble
+// from an initial handshake (protocol v10).
+func myParseHandshake(p []byte) (string, []byte, error) {
+	if len(p) > 0 && p[0] == 0xff {
+		return "", nil, myCheckOK(p)
+	}
+	if len(p) < 1 || p[0] != 10 {
+		return "", nil, errors.New("unsupported handshake protocol")
+	}
+	version, rest, ok := bytes.Cut(p[1:], []byte{0})
+	// conn id (4) + scramble part 1 (8) + filler (1) + caps (2) + charset (1)
+	// + status (2) + caps upper (2) + auth data len (1) + reserved (10).
+	if !ok || len(rest) < 31 {
+		return "", nil, errors.New("malformed handshake")
+	}
+	scramble := append([]byte{}, rest[4:12]...)
+	part2 := rest[31:]
+	if i := bytes.IndexByte(part2, 0); i >= 0 {
+		part2 = part2[:i]
+	}
+	scramble = append(scramble, part2...)
+	return string(version), scramble, nil
+}
+
+// myNativeScramble computes SHA1(pw) XOR SHA1(scramble + SHA1(SHA1(pw))).
+func myNativeScramble(scramble []byte, password string) []byte {
+	if password == "" {
+		return nil
+	}
+	h1 := sha1.Sum([]byte(password))                                //nolint:gosec // protocol-mandated
+	h2 := sha1.Sum(h1[:])                                           //nolint:gosec // protocol-mandated
+	h3 := sha1.Sum(append(append([]byte{}, scramble...), h2[:]...)) //nolint:gosec // protocol-mandated
+	for i := range h1 {
+		h1[i] ^= h3[i]
+	}
+	return h1[:]
+}
+
+// myCheckOK returns nil for an OK packet and the server message for ERR.
+func myCheckOK(p []byte) error {
+	switch {
+	case len(p) == 0:
+		return errors.New("empty reply")
+	case p[0] == 0x00:
+		return nil
+	case p[0] == 0xff && len(p) >= 3:
+		code := binary.LittleEndian.Uint16(p[1:])
+		msg := p[3:]
+		if len(msg) >= 6 && msg[0] == '#' {
+			msg = msg[6:]
+		}
+		return fmt.Errorf("error %d: %s", code, msg)
+	default:
+		return fmt.Errorf("unexpected reply 0x%02x", p[0])
+	}
+}
+
+func myWrite(c *conn, seq byte, payload []byte) error {
+	hdr := []byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), seq}
+	_, err := c.Write(append(hdr, payload...))
+	return err
+}
+
+func myRead(c *conn) (byte, []byte, error) {
+	hdr, err := c.readFull(4)
+	if err != nil {
+		return 0, nil, err
+	}
+	payload, err := c.readFull(int(hdr[0]) | int(hdr[1])<<8 | int(hdr[2])<<16)
+	if err != nil {
+		return 0, nil, err
+	}
+	return hdr[3], payload, nil
+}
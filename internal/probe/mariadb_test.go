@@ -0,0 +1,126 @@
+// Tests for: probe/mariadb.go — MariaDB login + SELECT 1.
+package probe
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1" //nolint:gosec // protocol-mandated
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func myTestWrite(c net.Conn, seq byte, payload []byte) {
+	hdr := []byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), seq}
+	_, _ = c.Write(append(hdr, payload...))
+}
+
+func myTestRead(r *bufio.Reader) []byte {
+	hdr := make([]byte, 4)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return nil
+	}
+	payload := make([]byte, int(hdr[0])|int(hdr[1])<<8|int(hdr[2])<<16)
+	_, _ = io.ReadFull(r, payload)
+	return payload
+}
+
+// myStandIn is a minimal MariaDB server checking mysql_native_password.
+// With switchAuth it first answers with an auth switch request carrying a
+// fresh scramble, as servers do when the account's plugin differs.
+func myStandIn(t *testing.T, password string, switchAuth bool) string {
+	t.Helper()
+	stage2 := sha1.Sum([]byte(password)) //nolint:gosec // protocol-mandated
+	stored := sha1.Sum(stage2[:])        //nolint:gosec // protocol-mandated
+	check := func(scramble, got []byte) bool {
+		if password == "" {
+			return len(got) == 0
+		}
+		mask := sha1.Sum(append(append([]byte{}, scramble...), stored[:]...)) //nolint:gosec // protocol-mandated
+		if len(got) != len(mask) {
+			return false
+		}
+		candidate := make([]byte, len(got))
+		for i := range got {
+			candidate[i] = got[i] ^ mask[i]
+		}
+		sum := sha1.Sum(candidate) //nolint:gosec // protocol-mandated
+		return bytes.Equal(sum[:], stored[:])
+	}
+
+	return standIn(t, func(c net.Conn, r *bufio.Reader) {
+		scramble := []byte("abcdefghijklmnopqrst")
+		var hs []byte
+		hs = append(hs, 10)
+		hs = append(append(hs, "11.4.2-MariaDB"...), 0)
+		hs = binary.LittleEndian.AppendUint32(hs, 7)
+		hs = append(append(hs, scramble[:8]...), 0)
+		hs = append(hs, 0xff, 0xf7, 45, 2, 0, 0xff, 0x81, 21)
+		hs = append(hs, make([]byte, 10)...)
+		hs = append(append(hs, scramble[8:]...), 0)
+		hs = append(append(hs, myNativePassword...), 0)
+		myTestWrite(c, 0, hs)
+
+		resp := myTestRead(r)
+		rest := resp[32:]
+		_, rest, _ = bytes.Cut(rest, []byte{0}) // user
+		auth := rest[1 : 1+int(rest[0])]
+		seq := byte(2)
+		if switchAuth {
+			scramble = []byte("ABCDEFGHIJKLMNOPQRST")
+			myTestWrite(c, seq, append(append(append([]byte{0xfe}, myNativePassword...), 0), append(scramble, 0)...))
+			auth = myTestRead(r)
+			seq += 2
+		}
+		if !check(scramble, auth) {
+			myTestWrite(c, seq, append([]byte{0xff, 0x15, 0x04}, "#28000Access denied for user 'db'@'%'"...))
+			return
+		}
+		myTestWrite(c, seq, []byte{0, 0, 0, 2, 0, 0, 0})
+
+		if q := myTestRead(r); len(q) == 0 || q[0] != myComQuery || string(q[1:]) != "SELECT 1" {
+			return
+		}
+		myTestWrite(c, 1, []byte{1})
+		myTestWrite(c, 2, []byte{3, 'd', 'e', 'f', 0, 0, 0, 1, '1', 0, 0x0c, 0x3f, 0, 1, 0, 0, 0, 8, 0x81, 0, 0, 0, 0})
+		myTestWrite(c, 3, []byte{0xfe, 0, 0, 2, 0})
+		myTestWrite(c, 4, []byte{1, '1'})
+		myTestWrite(c, 5, []byte{0xfe, 0, 0, 2, 0})
+		myTestRead(r)
+	})
+}
+
+func TestMariaDB(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		password   string
+		switchAuth bool
+		wantErr    string
+	}{
+		{"native", "s3cret", false, ""},
+		{"auth switch", "s3cret", true, ""},
+		{"wrong password", "wrong", false, "error 1045: Access denied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			detail, err := MariaDB(probeCtx(t), myStandIn(t, "s3cret", tt.switchAuth), "db", tt.password, "db")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MariaDB: %v", err)
+			}
+			if detail != "login and SELECT 1 ok (11.4.2-MariaDB)" {
+				t.Errorf("detail = %q", detail)
+			}
+		})
+	}
+}
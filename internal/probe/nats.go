@@ -0,0 +1,65 @@
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// NATS reads the server INFO, sends CONNECT with user/password and a PING,
+// and waits for the PONG that proves the connection was accepted.
+func NATS(ctx context.Context, addr, user, password string) (string, error) {
+	c, err := dial(ctx, addr)
+	if err != nil {
+		return "", err
+	}
+	defer c.Close()
+
+	line, err := readLine(c)
+	if err != nil {
+		return "", err
+	}
+	infoJSON, ok := strings.CutPrefix(line, "INFO ")
+	if !ok {
+		return "", fmt.Errorf("expected INFO, got %q", line)
+	}
+	var info struct {
+		Version string `json:"version"`
+	}
+	_ = json.Unmarshal([]byte(infoJSON), &info)
+
+	connect, err := json.Marshal(struct {
+		Verbose  bool   `json:"verbose"`
+		Pedantic bool   `json:"pedantic"`
+		Name     string `json:"name"`
+		User     string `json:"user,omitempty"`
+		Pass     string `json:"pass,omitempty"`
+	}{Name: "zcp-verify", User: user, Pass: password})
+	if err != nil {
+		return "", err
+	}
+	if _, err := c.Write([]byte("CONNECT " + string(connect) + "\r\nPING\r\n")); err != nil {
+		return "", err
+	}
+	for {
+		line, err := readLine(c)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case line == "PONG":
+			if info.Version == "" {
+				return "CONNECT ok", nil
+			}
+			return "CONNECT ok (nats-server " + info.Version + ")", nil
+		case line == "PING":
+			if _, err := c.Write([]byte("PONG\r\n")); err != nil {
+				return "", err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return "", errors.New(strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")), "'"))
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package probe
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5" //nolint:gosec // md5 auth is part of the PostgreSQL protocol
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	pgProtocolVersion = 196608 // 3.0
+	pgAuthOK          = 0
+	pgAuthCleartext   = 3
+	pgAuthMD5         = 5
+	pgAuthSASL        = 10
+	pgAuthSASLCont    = 11
+	pgAuthSASLFinal   = 12
+	pgSCRAMMechanism  = "SCRAM-SHA-256"
+)
+
+// Postgres logs in to a PostgreSQL server with user/password (SCRAM-SHA-256,
+// md5 or cleartext, whichever the server asks for), runs SELECT 1 and
+// returns a detail naming the server version.
+func Postgres(ctx context.Context, addr, user, password, database string) (string, error) {
+	c, err := dial(ctx, addr)
+	if err != nil {
+		return "", err
+	}
+	defer c.Close()
+
+	var startup []byte
+	startup = binary.BigEndian.AppendUint32(startup, pgProtocolVersion)
+	for _, kv := range [][2]string{{"user", user}, {"database", database}, {"application_name", "zcp-verify"}} {
+		if kv[1] == "" {
+			continue
+		}
+		startup = append(startup, kv[0]...)
+		startup = append(startup, 0)
+		startup = append(startup, kv[1]...)
+		startup = append(startup, 0)
+	}
+	startup = append(startup, 0)
+	if err := pgWrite(c, 0, startup); err != nil {
+		return "", err
+	}
+
+	var scram *scramClient
+	version := ""
+	queried := false
+	for {
+		typ, body, err := pgRead(c)
+		if err != nil {
+			return "", err
+		}
+		switch typ {
+		case 'E':
+			return "", pgError(body)
+		case 'R':
+			if len(body) < 4 {
+				return "", errors.New("malformed authentication message")
+			}
+			if err := pgAuth(c, user, password, binary.BigEndian.Uint32(body), body[4:], &scram); err != nil {
+				return "", err
+			}
+		case 'S':
+			if name, value, ok := pgParameter(body); ok && name == "server_version" {
+				version = value
+			}
+		case 'Z':
+			if queried {
+				// Ready again after the query — the row was not seen.
+				return "", errors.New("SELECT 1 returned no row")
+			}
+			queried = true
+			if err := pgWrite(c, 'Q', append([]byte("SELECT 1"), 0)); err != nil {
+				return "", err
+			}
+		case 'D':
+			if got := pgFirstColumn(body); got != "1" {
+				return "", fmt.Errorf("SELECT 1 returned %q", got)
+			}
+			_ = pgWrite(c, 'X', nil)
+			if version == "" {
+				return "login and SELECT 1 ok", nil
+			}
+			return "login and SELECT 1 ok (PostgreSQL " + version + ")", nil
+		}
+	}
+}
+
+// pgAuth answers one authentication request.
+func pgAuth(c *conn, user, password string, code uint32, data []byte, scram **scramClient) error {
+	switch code {
+	case pgAuthOK:
+		return nil
+	case pgAuthCleartext:
+		return pgWrite(c, 'p', append([]byte(password), 0))
+	case pgAuthMD5:
+		if len(data) < 4 {
+			return errors.New("malformed md5 salt")
+		}
+		inner := md5.Sum([]byte(password + user)) //nolint:gosec // protocol-mandated
+		outer := md5.Sum(append([]byte(hex.EncodeToString(inner[:])), data[:4]...))
+		return pgWrite(c, 'p', append([]byte("md5"+hex.EncodeToString(outer[:])), 0))
+	case pgAuthSASL:
+		if !bytes.Contains(data, []byte(pgSCRAMMechanism+"\x00")) {
+			return fmt.Errorf("server offers no %s mechanism", pgSCRAMMechanism)
+		}
+		s, err := newSCRAMClient(password)
+		if err != nil {
+			return err
+		}
+		*scram = s
+		first := s.clientFirst()
+		var msg []byte
+		msg = append(msg, pgSCRAMMechanism...)
+		msg = append(msg, 0)
+		msg = binary.BigEndian.AppendUint32(msg, uint32(len(first)))
+		msg = append(msg, first...)
+		return pgWrite(c, 'p', msg)
+	case pgAuthSASLCont:
+		if *scram == nil {
+			return errors.New("unexpected SASL continue")
+		}
+		final, err := (*scram).clientFinal(string(data))
+		if err != nil {
+			return err
+		}
+		return pgWrite(c, 'p', []byte(final))
+	case pgAuthSASLFinal:
+		if *scram == nil {
+			return errors.New("unexpected SASL final")
+		}
+		return (*scram).verifyServer(string(data))
+	default:
+		return fmt.Errorf("unsupported authentication method %d", code)
+	}
+}
+
+func pgWrite(c *conn, typ byte, body []byte) error {
+	var msg []byte
+	if typ != 0 {
+		msg = append(msg, typ)
+	}
+	msg = binary.BigEndian.AppendUint32(msg, uint32(len(body)+4))
+	msg = append(msg, body...)
+	_, err := c.Write(msg)
+	return err
+}
+
+func pgRead(c *conn) (byte, []byte, error) {
+	hdr, err := c.readFull(5)
+	if err != nil {
+		return 0, nil, err
+	}
+	body, err := c.readFull(int(binary.BigEndian.Uint32(hdr[1:])) - 4)
+	if err != nil {
+		return 0, nil, err
+	}
+	return hdr[0], body, nil
+}
+
+// pgError renders an ErrorResponse as "SEVERITY CODE: message".
+func pgError(body []byte) error {
+	fields := map[byte]string{}
+	for len(body) > 1 {
+		end := bytes.IndexByte(body[1:], 0)
+		if end < 0 {
+			break
+		}
+		fields[body[0]] = string(body[1 : 1+end])
+		body = body[end+2:]
+	}
+	return fmt.Errorf("%s %s: %s", fields['S'], fields['C'], fields['M'])
+}
+
+func pgParameter(body []byte) (string, string, bool) {
+	parts := bytes.SplitN(body, []byte{0}, 3)
+	if len(parts) < 2 {
+		return "", "", false
+	}
+	return string(parts[0]), string(parts[1]), true
+}
+
+// pgFirstColumn returns the first column of a DataRow.
+func pgFirstColumn(body []byte) string {
+	if len(body) < 6 || binary.BigEndian.Uint16(body) == 0 {
+		return ""
+	}
+	n := int32(binary.BigEndian.Uint32(body[2:]))
+	if n < 0 || int(n) > len(body)-6 {
+		return ""
+	}
+	return string(body[6 : 6+n])
+}
+
+// scramClient is the client half of SCRAM-SHA-256 (RFC 5802/7677) without
+// channel binding.
+type scramClient struct {
+	password    string
+	nonce       string
+	firstBare   string
+	serverSig   []byte
+	authMessage string
+}
+
+func newSCRAMClient(password string) (*scramClient, error) {
+	raw := make([]byte, 18)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	s := &scramClient{password: password, nonce: base64.RawStdEncoding.EncodeToString(raw)}
+	// PostgreSQL takes the user from the startup message, so n= stays empty.
+	s.firstBare = "n=,r=" + s.nonce
+	return s, nil
+}
+
+func (s *scramClient) clientFirst() string { return "n,," + s.firstBare }
+
+func (s *scramClient) clientFinal(serverFirst string) (string, error) {
+	attrs := scramAttrs(serverFirst)
+	salt, err := base64.StdEncoding.DecodeString(attrs["s"])
+	iter, iterErr := strconv.Atoi(attrs["i"])
+	if err != nil || iterErr != nil || iter < 1 || !strings.HasPrefix(attrs["r"], s.nonce) {
+		return "", errors.New("malformed SCRAM server-first message")
+	}
+	salted, err := pbkdf2.Key(sha256.New, s.password, salt, iter, sha256.Size)
+	if err != nil {
+		return "", err
+	}
+	clientKey := hmacSHA256(salted, "Client Key")
+	storedKey := sha256.Sum256(clientKey)
+	withoutProof := "c=biws,r=" + attrs["r"]
+	s.authMessage = s.firstBare + "," + serverFirst + "," + withoutProof
+	proof := hmacSHA256(storedKey[:], s.authMessage)
+	for i := range proof {
+		proof[i] ^= clientKey[i]
+	}
+	s.serverSig = hmacSHA256(hmacSHA256(salted, "Server Key"), s.authMessage)
+	return withoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof), nil
+}
+
+func (s *scramClient) verifyServer(serverFinal string) error {
+	attrs := scramAttrs(serverFinal)
+	if e := attrs["e"]; e != "" {
+		return fmt.Errorf("SCRAM: %s", e)
+	}
+	sig, err := base64.StdEncoding.DecodeString(attrs["v"])
+	if err != nil || !hmac.Equal(sig, s.serverSig) {
+		return errors.New("SCRAM: server signature mismatch")
+	}
+	return nil
+}
+
+func scramAttrs(msg string) map[string]string {
+	attrs := map[string]string{}
+	for part := range strings.SplitSeq(msg, ",") {
+		if k, v, ok := strings.Cut(part, "="); ok {
+			attrs[k] = v
+		}
+	}
+	return attrs
+}
+
+func hmacSHA256(key []byte, msg string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(msg))
+	return h.Sum(nil)
+}
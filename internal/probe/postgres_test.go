@@ -0,0 +1,170 @@
+// Tests for: probe/postgres.go — PostgreSQL login + SELECT 1.
+package probe
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5" //nolint:gosec // protocol-mandated
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func pgTestWrite(c net.Conn, typ byte, body []byte) {
+	msg := append([]byte{typ}, binary.BigEndian.AppendUint32(nil, uint32(len(body)+4))...)
+	_, _ = c.Write(append(msg, body...))
+}
+
+func pgTestRead(r *bufio.Reader) (byte, []byte) {
+	hdr := make([]byte, 5)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return 0, nil
+	}
+	body := make([]byte, binary.BigEndian.Uint32(hdr[1:])-4)
+	_, _ = io.ReadFull(r, body)
+	return hdr[0], body
+}
+
+func pgAuthMsg(code uint32, data []byte) []byte {
+	return append(binary.BigEndian.AppendUint32(nil, code), data...)
+}
+
+// pgStandIn is a minimal PostgreSQL backend that authenticates with method
+// ("scram-sha-256", "md5" or "trust") and answers any query with one row.
+func pgStandIn(t *testing.T, method, password string) string {
+	t.Helper()
+	return standIn(t, func(c net.Conn, r *bufio.Reader) {
+		var n uint32
+		if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+			return
+		}
+		startup := make([]byte, n-4)
+		_, _ = io.ReadFull(r, startup)
+		params := bytes.Split(startup[4:], []byte{0})
+		user := ""
+		for i := 0; i+1 < len(params); i += 2 {
+			if string(params[i]) == "user" {
+				user = string(params[i+1])
+			}
+		}
+		reject := func() {
+			pgTestWrite(c, 'E', []byte("SFATAL\x00C28P01\x00Mpassword authentication failed for user \""+user+"\"\x00\x00"))
+		}
+
+		switch method {
+		case "md5":
+			salt := []byte{1, 2, 3, 4}
+			pgTestWrite(c, 'R', pgAuthMsg(pgAuthMD5, salt))
+			_, got := pgTestRead(r)
+			inner := md5.Sum([]byte(password + user)) //nolint:gosec // protocol-mandated
+			outer := md5.Sum(append([]byte(hex.EncodeToString(inner[:])), salt...))
+			if string(got) != "md5"+hex.EncodeToString(outer[:])+"\x00" {
+				reject()
+				return
+			}
+		case "scram-sha-256":
+			pgTestWrite(c, 'R', pgAuthMsg(pgAuthSASL, []byte(pgSCRAMMechanism+"\x00\x00")))
+			_, initial := pgTestRead(r)
+			clientFirst := string(initial[len(pgSCRAMMechanism)+5:])
+			bare := strings.TrimPrefix(clientFirst, "n,,")
+			salt := []byte("pepper")
+			serverFirst := "r=" + scramAttrs(bare)["r"] + "server,s=" + base64.StdEncoding.EncodeToString(salt) + ",i=4096"
+			pgTestWrite(c, 'R', pgAuthMsg(pgAuthSASLCont, []byte(serverFirst)))
+
+			_, final := pgTestRead(r)
+			withoutProof, proofB64, _ := strings.Cut(string(final), ",p=")
+			proof, _ := base64.StdEncoding.DecodeString(proofB64)
+			authMessage := bare + "," + serverFirst + "," + withoutProof
+			salted, _ := pbkdf2.Key(sha256.New, password, salt, 4096, sha256.Size)
+			storedKey := sha256.Sum256(hmacSHA256(salted, "Client Key"))
+			clientSig := hmacSHA256(storedKey[:], authMessage)
+			if len(proof) != len(clientSig) {
+				reject()
+				return
+			}
+			for i := range proof {
+				proof[i] ^= clientSig[i]
+			}
+			if got := sha256.Sum256(proof); !hmac.Equal(got[:], storedKey[:]) {
+				reject()
+				return
+			}
+			serverSig := hmacSHA256(hmacSHA256(salted, "Server Key"), authMessage)
+			pgTestWrite(c, 'R', pgAuthMsg(pgAuthSASLFinal, []byte("v="+base64.StdEncoding.EncodeToString(serverSig))))
+		}
+		pgTestWrite(c, 'R', pgAuthMsg(pgAuthOK, nil))
+		pgTestWrite(c, 'S', []byte("server_version\x0016.4\x00"))
+		pgTestWrite(c, 'K', make([]byte, 8))
+		pgTestWrite(c, 'Z', []byte("I"))
+
+		if typ, query := pgTestRead(r); typ != 'Q' || string(query) != "SELECT 1\x00" {
+			return
+		}
+		row := binary.BigEndian.AppendUint16(nil, 1)
+		row = binary.BigEndian.AppendUint32(row, 1)
+		pgTestWrite(c, 'D', append(row, '1'))
+		pgTestWrite(c, 'C', []byte("SELECT 1\x00"))
+		pgTestWrite(c, 'Z', []byte("I"))
+		pgTestRead(r)
+	})
+}
+
+func TestPostgres(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		method, password string
+		wantErr          string
+	}{
+		{"scram-sha-256", "s3cret", ""},
+		{"scram-sha-256", "wrong", "28P01: password authentication failed"},
+		{"md5", "s3cret", ""},
+		{"md5", "wrong", "28P01"},
+		{"trust", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+"/"+tt.password, func(t *testing.T) {
+			t.Parallel()
+			addr := pgStandIn(t, tt.method, "s3cret")
+			detail, err := Postgres(probeCtx(t), addr, "db", tt.password, "db")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Postgres: %v", err)
+			}
+			if detail != "login and SELECT 1 ok (PostgreSQL 16.4)" {
+				t.Errorf("detail = %q", detail)
+			}
+		})
+	}
+}
+
+func TestSCRAM_ServerSignatureMismatch(t *testing.T) {
+	t.Parallel()
+
+	s, err := newSCRAMClient("pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverFirst := "r=" + s.nonce + "x,s=" + base64.StdEncoding.EncodeToString([]byte("salt")) + ",i=1"
+	if _, err := s.clientFinal(serverFirst); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.verifyServer("v=" + base64.StdEncoding.EncodeToString([]byte("forged"))); err == nil {
+		t.Error("forged server signature must be rejected")
+	}
+	if _, err := s.clientFinal("r=other,s=c2FsdA==,i=1"); err == nil {
+		t.Error("server nonce must extend the client nonce")
+	}
+}
@@ -0,0 +1,71 @@
+// Package probe speaks just enough of each managed-service wire protocol to
+// prove a client can log in and run a trivial command. It backs the
+// protocol checks in zerops_verify and deliberately carries no driver
+// dependencies: every exchange is a handful of frames over a net.Conn or a
+// single HTTP request.
+package probe
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Doer executes HTTP requests (satisfied by *http.Client).
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// ErrUnreachable wraps dial failures so callers can tell "cannot reach the
+// service from here" apart from a service that answered wrongly. The
+// underlying *net.DNSError / *net.OpError stays in the chain.
+var ErrUnreachable = errors.New("unreachable")
+
+// maxFrame bounds any single protocol frame a probe will buffer; every
+// reply a probe waits for is tiny.
+const maxFrame = 1 << 20
+
+// conn is a dialed probe connection with buffered reads.
+type conn struct {
+	net.Conn
+	r    *bufio.Reader
+	stop func() bool
+}
+
+// dial connects to addr and ties the connection's lifetime to ctx: the
+// context deadline becomes the I/O deadline and cancellation unblocks any
+// pending read or write.
+func dial(ctx context.Context, addr string) (*conn, error) {
+	var d net.Dialer
+	c, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnreachable, err)
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		_ = c.SetDeadline(dl)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = c.SetDeadline(time.Now()) })
+	return &conn{Conn: c, r: bufio.NewReader(c), stop: stop}, nil
+}
+
+func (c *conn) Close() error {
+	c.stop()
+	return c.Conn.Close()
+}
+
+// readFull reads exactly n bytes.
+func (c *conn) readFull(n int) ([]byte, error) {
+	if n < 0 || n > maxFrame {
+		return nil, fmt.Errorf("frame of %d bytes exceeds probe limit", n)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(c.r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
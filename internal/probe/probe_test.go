@@ -0,0 +1,76 @@
+// Tests for: probe/probe.go — dialing and the shared stand-in server harness.
+package probe
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// standIn starts a TCP server on loopback that runs serve once per
+// accepted connection and returns its address.
+func standIn(t *testing.T, serve func(c net.Conn, r *bufio.Reader)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				_ = c.SetDeadline(time.Now().Add(5 * time.Second))
+				serve(c, bufio.NewReader(c))
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func probeCtx(t *testing.T) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestDial_Unreachable(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	_, err = Valkey(probeCtx(t), addr, "", "")
+	if !errors.Is(err, ErrUnreachable) {
+		t.Errorf("err = %v, want ErrUnreachable", err)
+	}
+}
+
+func TestDial_ContextCancelUnblocksRead(t *testing.T) {
+	t.Parallel()
+
+	// A server that accepts and never speaks.
+	addr := standIn(t, func(_ net.Conn, r *bufio.Reader) { _, _ = r.ReadByte() })
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := NATS(ctx, addr, "", ""); err == nil {
+		t.Fatal("expected error from silent server")
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Error("probe should give up when the context expires")
+	}
+}
@@ -0,0 +1,93 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Valkey sends PING to a Valkey/KeyDB (RESP) server, authenticating first
+// when password is set. A user other than "default" selects ACL-style
+// AUTH <user> <password>.
+func Valkey(ctx context.Context, addr, user, password string) (string, error) {
+	c, err := dial(ctx, addr)
+	if err != nil {
+		return "", err
+	}
+	defer c.Close()
+
+	if password != "" {
+		args := []string{"AUTH", password}
+		if user != "" && user != "default" {
+			args = []string{"AUTH", user, password}
+		}
+		reply, err := respCall(c, args...)
+		if err != nil {
+			return "", fmt.Errorf("AUTH: %w", err)
+		}
+		if reply != "OK" {
+			return "", fmt.Errorf("AUTH: unexpected reply %q", reply)
+		}
+	}
+	reply, err := respCall(c, "PING")
+	if err != nil {
+		return "", fmt.Errorf("PING: %w", err)
+	}
+	if reply != "PONG" {
+		return "", fmt.Errorf("PING: unexpected reply %q", reply)
+	}
+	_, _ = respCall(c, "QUIT")
+	return "PING ok", nil
+}
+
+// respCall sends one command and returns a simple-string or bulk reply.
+// Error replies come back as errors.
+func respCall(c *conn, args ...string) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if _, err := c.Write([]byte(b.String())); err != nil {
+		return "", err
+	}
+	line, err := readLine(c)
+	if err != nil {
+		return "", err
+	}
+	if line == "" {
+		return "", errors.New("empty reply")
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return "", errors.New(line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return "", nil
+		}
+		data, err := c.readFull(n + 2)
+		if err != nil {
+			return "", err
+		}
+		return string(data[:n]), nil
+	default:
+		return line, nil
+	}
+}
+
+// readLine reads one CRLF-terminated line without the terminator.
+func readLine(c *conn) (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) > maxFrame {
+		return "", errors.New("line exceeds probe limit")
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
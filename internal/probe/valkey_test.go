@@ -0,0 +1,108 @@
+// Tests for: probe/valkey.go and probe/nats.go — line-oriented protocols.
+package probe
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// respStandIn answers RESP commands; with a non-empty password every
+// command except AUTH needs a prior successful AUTH.
+func respStandIn(t *testing.T, password string) string {
+	t.Helper()
+	return standIn(t, func(c net.Conn, r *bufio.Reader) {
+		authed := password == ""
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil || !strings.HasPrefix(line, "*") {
+				return
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+			args := make([]string, n)
+			for i := range args {
+				_, _ = r.ReadString('\n')
+				arg, _ := r.ReadString('\n')
+				args[i] = strings.TrimRight(arg, "\r\n")
+			}
+			switch {
+			case args[0] == "AUTH" && args[len(args)-1] == password:
+				authed = true
+				fmt.Fprint(c, "+OK\r\n")
+			case args[0] == "AUTH":
+				fmt.Fprint(c, "-WRONGPASS invalid username-password pair or user is disabled.\r\n")
+			case !authed:
+				fmt.Fprint(c, "-NOAUTH Authentication required.\r\n")
+			case args[0] == "PING":
+				fmt.Fprint(c, "+PONG\r\n")
+			case args[0] == "QUIT":
+				fmt.Fprint(c, "+OK\r\n")
+				return
+			}
+		}
+	})
+}
+
+func TestValkey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, server, client string
+		wantErr              string
+	}{
+		{"no auth", "", "", ""},
+		{"auth", "s3cret", "s3cret", ""},
+		{"wrong password", "s3cret", "nope", "AUTH: WRONGPASS"},
+		{"auth required", "s3cret", "", "PING: NOAUTH"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			detail, err := Valkey(probeCtx(t), respStandIn(t, tt.server), "", tt.client)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || detail != "PING ok" {
+				t.Fatalf("Valkey = %q, %v", detail, err)
+			}
+		})
+	}
+}
+
+// natsStandIn sends INFO, checks the CONNECT credentials and answers PING.
+func natsStandIn(t *testing.T, user, password string) string {
+	t.Helper()
+	return standIn(t, func(c net.Conn, r *bufio.Reader) {
+		fmt.Fprint(c, `INFO {"server_id":"x","version":"2.10.22","auth_required":true}`+"\r\n")
+		connect, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if !strings.Contains(connect, `"user":"`+user+`"`) || !strings.Contains(connect, `"pass":"`+password+`"`) {
+			fmt.Fprint(c, "-ERR 'Authorization Violation'\r\n")
+			return
+		}
+		if ping, _ := r.ReadString('\n'); ping == "PING\r\n" {
+			fmt.Fprint(c, "PING\r\nPONG\r\n")
+		}
+	})
+}
+
+func TestNATS(t *testing.T) {
+	t.Parallel()
+
+	addr := natsStandIn(t, "queue", "s3cret")
+	detail, err := NATS(probeCtx(t), addr, "queue", "s3cret")
+	if err != nil || detail != "CONNECT ok (nats-server 2.10.22)" {
+		t.Errorf("NATS = %q, %v", detail, err)
+	}
+	if _, err := NATS(probeCtx(t), addr, "queue", "nope"); err == nil || err.Error() != "Authorization Violation" {
+		t.Errorf("err = %v, want Authorization Violation", err)
+	}
+}
@@ -2,9 +2,13 @@ package schema
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 )
@@ -12,39 +16,112 @@ import (
 // DefaultCacheTTL is the default time-to-live for cached schemas.
 const DefaultCacheTTL = 24 * time.Hour
 
+// staleRetryInterval bounds how long a disk or embedded result is served
+// before the network is tried again. Shorter than the TTL so a flaky VPN
+// recovers within minutes, long enough that an air-gapped host does not
+// pay the fetch timeout on every call.
+const staleRetryInterval = 5 * time.Minute
+
 // fetchTimeout is the per-request timeout for schema fetches.
 const fetchTimeout = 10 * time.Second
 
 // maxResponseBytes caps schema response bodies to 5MB to prevent OOM from misbehaving servers.
 const maxResponseBytes = 5 << 20
 
+// Schema provenance sources, from most to least current.
+const (
+	SourceLive     = "live"     // fetched or revalidated against the API by this process
+	SourceDisk     = "disk"     // persisted copy from an earlier fetch; the API was unreachable
+	SourceEmbedded = "embedded" // snapshot compiled into the binary; no fetch ever succeeded
+)
+
+// Provenance records where a Schemas value came from. When one schema is
+// older than the other, the combined provenance reports the older one.
+type Provenance struct {
+	Source    string    `json:"source"`
+	FetchedAt time.Time `json:"fetchedAt,omitzero"`
+	Age       string    `json:"age,omitempty"`
+}
+
+// Stale reports whether the schemas may predate the platform — newly
+// released service types or zerops.yaml fields can be missing from them.
+// Validators use it to downgrade "unknown value" rejections. The zero
+// value (schemas parsed from an explicit file) is not stale.
+func (p Provenance) Stale() bool {
+	return p.Source == SourceDisk || p.Source == SourceEmbedded
+}
+
+// String renders the provenance for check details and error messages.
+func (p Provenance) String() string {
+	switch p.Source {
+	case SourceDisk:
+		if p.Age != "" {
+			return "disk cache fetched " + p.Age + " ago"
+		}
+		return "disk cache"
+	case SourceEmbedded:
+		return "snapshot embedded in zcp"
+	}
+	return p.Source
+}
+
+// DefaultCacheDir returns ~/.zcp/cache/schemas, falling back to the
+// system temp dir when there is no usable home directory.
+func DefaultCacheDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "/" {
+		return filepath.Join(os.TempDir(), "zcp-cache", "schemas")
+	}
+	return filepath.Join(home, ".zcp", "cache", "schemas")
+}
+
 // Cache provides TTL-cached access to live Zerops schemas.
-// Thread-safe. Coalesces concurrent fetches. On fetch error, returns stale data if available.
+// Thread-safe. Coalesces concurrent fetches. Every successful fetch is
+// persisted to dir and revalidated with ETag / Last-Modified, so a
+// restart costs a 304 rather than a full download. When the API is
+// unreachable the persisted copy is served, and when there is none the
+// schemas embedded for ValidateImportYAML / ValidateZeropsYAML are — Get
+// never returns nil. Schemas.Provenance says which one the caller got.
 type Cache struct {
 	mu        sync.Mutex
 	schemas   *Schemas
-	fetchedAt time.Time
+	expiresAt time.Time
 	ttl       time.Duration
+	docs      map[string]*cachedDoc // last good body per document, mirrors dir
 
 	// fetchCh is non-nil when a fetch is in progress. Concurrent callers
 	// wait on this channel instead of firing duplicate HTTP requests.
 	fetchCh chan struct{}
+
+	dir        string // "" disables persistence
+	httpClient *http.Client
+	zeropsURL  string
+	importURL  string
+	now        func() time.Time
 }
 
-// NewCache creates a new schema cache with the given TTL.
+// NewCache creates a new schema cache with the given TTL, persisted
+// under DefaultCacheDir.
 func NewCache(ttl time.Duration) *Cache {
-	return &Cache{ttl: ttl}
+	return &Cache{
+		ttl:        ttl,
+		docs:       make(map[string]*cachedDoc),
+		dir:        DefaultCacheDir(),
+		httpClient: http.DefaultClient,
+		zeropsURL:  ZeropsYmlURL,
+		importURL:  ImportYmlURL,
+		now:        time.Now,
+	}
 }
 
 // Get returns cached schemas, refreshing from the API when expired.
 // Coalesces concurrent requests: only one goroutine fetches while others wait.
-// Returns nil on first-fetch failure. Returns stale data on refresh failure.
 func (c *Cache) Get(ctx context.Context) *Schemas {
 	c.mu.Lock()
 
 	// Fast path: cache is fresh.
-	if !c.fetchedAt.IsZero() && time.Since(c.fetchedAt) < c.ttl {
-		result := c.schemas
+	if c.schemas != nil && c.now().Before(c.expiresAt) {
+		result := c.snapshotLocked()
 		c.mu.Unlock()
 		return result
 	}
@@ -55,7 +132,7 @@ func (c *Cache) Get(ctx context.Context) *Schemas {
 		c.mu.Unlock()
 		<-ch
 		c.mu.Lock()
-		result := c.schemas
+		result := c.snapshotLocked()
 		c.mu.Unlock()
 		return result
 	}
@@ -66,27 +143,245 @@ func (c *Cache) Get(ctx context.Context) *Schemas {
 	c.mu.Unlock()
 
 	// Fetch outside lock (no mutex held during I/O).
-	schemas, err := FetchSchemas(ctx)
+	schemas := c.refresh(ctx)
 
 	c.mu.Lock()
-	if err == nil {
-		c.schemas = schemas
-		c.fetchedAt = time.Now()
+	c.schemas = schemas
+	ttl := c.ttl
+	if schemas.Provenance.Stale() {
+		ttl = min(ttl, staleRetryInterval)
 	}
+	c.expiresAt = c.now().Add(ttl)
 	c.fetchCh = nil
+	result := c.snapshotLocked()
 	c.mu.Unlock()
 
 	// Wake all waiters.
 	close(ch)
+	return result
+}
+
+// snapshotLocked returns a shallow copy of the cached schemas with the
+// provenance age computed now. Caller holds c.mu.
+func (c *Cache) snapshotLocked() *Schemas {
+	if c.schemas == nil {
+		return nil
+	}
+	out := *c.schemas
+	if !out.Provenance.FetchedAt.IsZero() {
+		out.Provenance.Age = c.now().Sub(out.Provenance.FetchedAt).Round(time.Second).String()
+	}
+	return &out
+}
+
+// schemaDoc is one of the two schema documents the cache tracks.
+type schemaDoc struct {
+	name     string // file stem under the cache dir
+	url      string
+	embedded []byte
+}
 
+// refresh loads both documents, each from the best available source,
+// and parses them. The embedded snapshot backs up a parse failure too.
+func (c *Cache) refresh(ctx context.Context) *Schemas {
+	// Once a request fails at the transport level the second document
+	// skips the network instead of waiting out another timeout.
+	zeropsData, zeropsProv, offline := c.loadDoc(ctx, schemaDoc{name: "zerops-yml", url: c.zeropsURL, embedded: embeddedZeropsSchema}, false)
+	importData, importProv, _ := c.loadDoc(ctx, schemaDoc{name: "import-yml", url: c.importURL, embedded: embeddedImportSchema}, offline)
+
+	zeropsYml, err := ParseZeropsYmlSchema(zeropsData)
 	if err != nil {
-		// Return stale data (or nil on first-fetch failure).
-		c.mu.Lock()
-		result := c.schemas
-		c.mu.Unlock()
-		return result
+		zeropsYml, _ = ParseZeropsYmlSchema(embeddedZeropsSchema)
+		zeropsProv = Provenance{Source: SourceEmbedded}
+	}
+	importYml, err := ParseImportYmlSchema(importData)
+	if err != nil {
+		importYml, _ = ParseImportYmlSchema(embeddedImportSchema)
+		importProv = Provenance{Source: SourceEmbedded}
+	}
+
+	return &Schemas{
+		ZeropsYml:  zeropsYml,
+		ImportYml:  importYml,
+		Provenance: olderProvenance(zeropsProv, importProv),
+	}
+}
+
+// loadDoc returns the document body from the network (revalidating the
+// cached copy when there is one), else the cached copy, else the
+// embedded snapshot. offline skips the network; the returned flag
+// reports whether the API turned out to be unreachable.
+func (c *Cache) loadDoc(ctx context.Context, d schemaDoc, offline bool) ([]byte, Provenance, bool) {
+	cached := c.cachedDoc(d.name)
+	if !offline {
+		fresh, err := c.fetchConditional(ctx, d.url, cached)
+		if err == nil {
+			c.storeDoc(d.name, fresh)
+			return fresh.body, Provenance{Source: SourceLive, FetchedAt: fresh.FetchedAt}, false
+		}
+		var respErr *responseError
+		offline = !errors.As(err, &respErr)
+	}
+	if cached != nil {
+		return cached.body, Provenance{Source: SourceDisk, FetchedAt: cached.FetchedAt}, offline
+	}
+	return d.embedded, Provenance{Source: SourceEmbedded}, offline
+}
+
+// olderProvenance combines the provenance of the two documents.
+func olderProvenance(a, b Provenance) Provenance {
+	rank := map[string]int{SourceLive: 0, SourceDisk: 1, SourceEmbedded: 2}
+	out := a
+	if rank[b.Source] > rank[a.Source] {
+		out.Source = b.Source
+	}
+	if out.Source == SourceEmbedded {
+		return Provenance{Source: SourceEmbedded}
+	}
+	if !b.FetchedAt.IsZero() && (out.FetchedAt.IsZero() || b.FetchedAt.Before(out.FetchedAt)) {
+		out.FetchedAt = b.FetchedAt
+	}
+	return out
+}
+
+// cachedDoc is a persisted schema body with its revalidation headers.
+// The body lives in <name>.json, the rest in <name>.meta.json.
+type cachedDoc struct {
+	body         []byte
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	FetchedAt    time.Time `json:"fetchedAt"`
+}
+
+// cachedDoc returns the in-memory copy of a document, loading it from
+// disk on first use. A missing, unreadable or corrupt file is a miss.
+func (c *Cache) cachedDoc(name string) *cachedDoc {
+	c.mu.Lock()
+	doc := c.docs[name]
+	c.mu.Unlock()
+	if doc != nil || c.dir == "" {
+		return doc
 	}
-	return schemas
+
+	body, err := os.ReadFile(filepath.Join(c.dir, name+".json"))
+	if err != nil || !json.Valid(body) {
+		return nil
+	}
+	doc = &cachedDoc{}
+	if meta, err := os.ReadFile(filepath.Join(c.dir, name+".meta.json")); err == nil {
+		_ = json.Unmarshal(meta, doc)
+	}
+	doc.body = body
+
+	c.mu.Lock()
+	c.docs[name] = doc
+	c.mu.Unlock()
+	return doc
+}
+
+// storeDoc records a document in memory and on disk. Persistence is
+// best effort: a read-only home only costs the next process a refetch.
+func (c *Cache) storeDoc(name string, doc *cachedDoc) {
+	c.mu.Lock()
+	c.docs[name] = doc
+	c.mu.Unlock()
+	if c.dir == "" {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return
+	}
+	meta, err := json.Marshal(doc)
+	if err != nil {
+		return
+	}
+	if writeFileAtomic(filepath.Join(c.dir, name+".json"), doc.body) != nil {
+		return
+	}
+	_ = writeFileAtomic(filepath.Join(c.dir, name+".meta.json"), meta)
+}
+
+// writeFileAtomic writes data via a temp file and rename so a concurrent
+// zcp process never reads a half-written schema.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// responseError is an unusable answer: a non-200, non-304 status or a
+// body that is not JSON. Unlike a transport error it proves the API is
+// reachable.
+type responseError struct {
+	url    string
+	reason string
+}
+
+func (e *responseError) Error() string {
+	return e.reason + " from " + e.url
+}
+
+// fetchConditional GETs url, sending the cached copy's validators. A 304
+// returns the cached body with a new FetchedAt.
+func (c *Cache) fetchConditional(ctx context.Context, url string, cached *cachedDoc) (*cachedDoc, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	now := c.now()
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		return &cachedDoc{body: cached.body, ETag: cached.ETag, LastModified: cached.LastModified, FetchedAt: now}, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, &responseError{url: url, reason: fmt.Sprintf("HTTP %d", resp.StatusCode)}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(body) {
+		return nil, &responseError{url: url, reason: "invalid JSON"}
+	}
+	return &cachedDoc{
+		body:         body,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		FetchedAt:    now,
+	}, nil
 }
 
 // FetchSchemas fetches both schemas from the public API.
@@ -110,8 +405,9 @@ func FetchSchemas(ctx context.Context) (*Schemas, error) {
 	}
 
 	return &Schemas{
-		ZeropsYml: zeropsYml,
-		ImportYml: importYml,
+		ZeropsYml:  zeropsYml,
+		ImportYml:  importYml,
+		Provenance: Provenance{Source: SourceLive, FetchedAt: time.Now()},
 	}, nil
 }
 
@@ -0,0 +1,224 @@
+package schema
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// schemaServer serves the testdata schemas with an ETag, answering 304
+// to a matching If-None-Match. down makes every request fail with 503.
+type schemaServer struct {
+	*httptest.Server
+	requests    atomic.Int32
+	notModified atomic.Int32
+	down        atomic.Bool
+}
+
+func newSchemaServer(t *testing.T) *schemaServer {
+	t.Helper()
+	zeropsData, err := os.ReadFile("testdata/zerops_yml_schema.json")
+	if err != nil {
+		t.Fatalf("read test data: %v", err)
+	}
+	importData, err := os.ReadFile("testdata/import_yml_schema.json")
+	if err != nil {
+		t.Fatalf("read test data: %v", err)
+	}
+	s := &schemaServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		if s.down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, etag := zeropsData, `"zerops-v1"`
+		if r.URL.Path == "/import" {
+			body, etag = importData, `"import-v1"`
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			s.notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Mon, 05 Oct 2026 10:00:00 GMT")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func testCache(srv *schemaServer, dir string, now time.Time) *Cache {
+	c := NewCache(DefaultCacheTTL)
+	c.dir = dir
+	c.now = func() time.Time { return now }
+	c.zeropsURL = srv.URL + "/zerops"
+	c.importURL = srv.URL + "/import"
+	return c
+}
+
+func TestCache_LivePersistsToDisk(t *testing.T) {
+	t.Parallel()
+	srv := newSchemaServer(t)
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 10, 12, 0, 0, 0, time.UTC)
+
+	s := testCache(srv, dir, now).Get(context.Background())
+	if s.Provenance.Source != SourceLive || !s.Provenance.FetchedAt.Equal(now) {
+		t.Fatalf("provenance = %+v, want live at %v", s.Provenance, now)
+	}
+	if s.ImportYml == nil || len(s.ImportYml.ServiceTypes) == 0 {
+		t.Fatal("import schema not parsed")
+	}
+	for _, name := range []string{"zerops-yml.json", "zerops-yml.meta.json", "import-yml.json", "import-yml.meta.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s not persisted: %v", name, err)
+		}
+	}
+}
+
+func TestCache_RevalidatesWithETag(t *testing.T) {
+	t.Parallel()
+	srv := newSchemaServer(t)
+	dir := t.TempDir()
+	first := time.Date(2026, 10, 10, 12, 0, 0, 0, time.UTC)
+	testCache(srv, dir, first).Get(context.Background())
+
+	// A new process with the same cache dir revalidates instead of
+	// downloading: both documents answer 304 and count as live.
+	later := first.Add(48 * time.Hour)
+	s := testCache(srv, dir, later).Get(context.Background())
+	if got := srv.notModified.Load(); got != 2 {
+		t.Errorf("304 responses = %d, want 2", got)
+	}
+	if s.Provenance.Source != SourceLive || !s.Provenance.FetchedAt.Equal(later) {
+		t.Errorf("provenance = %+v, want live at %v", s.Provenance, later)
+	}
+	if s.ZeropsYml == nil || len(s.ZeropsYml.BuildBases) == 0 {
+		t.Error("zerops schema not served from the revalidated disk copy")
+	}
+}
+
+func TestCache_DiskFallbackWhenAPIFails(t *testing.T) {
+	t.Parallel()
+	srv := newSchemaServer(t)
+	dir := t.TempDir()
+	first := time.Date(2026, 10, 10, 12, 0, 0, 0, time.UTC)
+	testCache(srv, dir, first).Get(context.Background())
+
+	srv.down.Store(true)
+	s := testCache(srv, dir, first.Add(72*time.Hour)).Get(context.Background())
+	want := Provenance{Source: SourceDisk, FetchedAt: first, Age: "72h0m0s"}
+	if s.Provenance != want {
+		t.Errorf("provenance = %+v, want %+v", s.Provenance, want)
+	}
+	if !s.Provenance.Stale() {
+		t.Error("disk provenance must be stale")
+	}
+	if s.ImportYml == nil || len(s.ImportYml.ServiceTypes) == 0 {
+		t.Error("import schema not served from disk")
+	}
+}
+
+func TestCache_EmbeddedFallbackWhenOfflineWithoutDisk(t *testing.T) {
+	t.Parallel()
+	srv := newSchemaServer(t)
+	c := testCache(srv, t.TempDir(), time.Now())
+	srv.Close() // transport errors, not HTTP errors
+
+	s := c.Get(context.Background())
+	if s == nil {
+		t.Fatal("Get returned nil; embedded fallback expected")
+	}
+	if s.Provenance.Source != SourceEmbedded || !s.Provenance.FetchedAt.IsZero() {
+		t.Errorf("provenance = %+v, want embedded", s.Provenance)
+	}
+	if s.ZeropsYml == nil || len(s.ZeropsYml.RunBases) == 0 || s.ImportYml == nil || len(s.ImportYml.ServiceTypes) == 0 {
+		t.Error("embedded schemas not parsed")
+	}
+}
+
+func TestCache_StaleResultRetriedSooner(t *testing.T) {
+	t.Parallel()
+	srv := newSchemaServer(t)
+	srv.down.Store(true)
+	now := time.Date(2026, 10, 10, 12, 0, 0, 0, time.UTC)
+	c := testCache(srv, t.TempDir(), now)
+
+	if s := c.Get(context.Background()); s.Provenance.Source != SourceEmbedded {
+		t.Fatalf("source = %q, want embedded", s.Provenance.Source)
+	}
+	requests := srv.requests.Load()
+	c.Get(context.Background())
+	if srv.requests.Load() != requests {
+		t.Error("stale result refetched before the retry interval")
+	}
+
+	srv.down.Store(false)
+	c.now = func() time.Time { return now.Add(staleRetryInterval) }
+	if s := c.Get(context.Background()); s.Provenance.Source != SourceLive {
+		t.Errorf("source after retry interval = %q, want live", s.Provenance.Source)
+	}
+}
+
+func TestCache_CorruptDiskIgnored(t *testing.T) {
+	t.Parallel()
+	srv := newSchemaServer(t)
+	srv.down.Store(true)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "zerops-yml.json"), []byte("{truncated"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := testCache(srv, dir, time.Now()).Get(context.Background())
+	if s.Provenance.Source != SourceEmbedded {
+		t.Errorf("source = %q, want embedded", s.Provenance.Source)
+	}
+}
+
+func TestProvenance(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		p         Provenance
+		wantStale bool
+		wantText  string
+	}{
+		{"zero value", Provenance{}, false, ""},
+		{"live", Provenance{Source: SourceLive}, false, "live"},
+		{"disk", Provenance{Source: SourceDisk, Age: "3h0m0s"}, true, "disk cache fetched 3h0m0s ago"},
+		{"embedded", Provenance{Source: SourceEmbedded}, true, "snapshot embedded in zcp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.p.Stale(); got != tt.wantStale {
+				t.Errorf("Stale() = %v, want %v", got, tt.wantStale)
+			}
+			if got := tt.p.String(); got != tt.wantText {
+				t.Errorf("String() = %q, want %q", got, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestOlderProvenance(t *testing.T) {
+	t.Parallel()
+	older := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(24 * time.Hour)
+
+	got := olderProvenance(Provenance{Source: SourceLive, FetchedAt: newer}, Provenance{Source: SourceDisk, FetchedAt: older})
+	if got.Source != SourceDisk || !got.FetchedAt.Equal(older) {
+		t.Errorf("live+disk = %+v, want disk at %v", got, older)
+	}
+	got = olderProvenance(Provenance{Source: SourceDisk, FetchedAt: older}, Provenance{Source: SourceEmbedded})
+	if got != (Provenance{Source: SourceEmbedded}) {
+		t.Errorf("disk+embedded = %+v, want embedded", got)
+	}
+}
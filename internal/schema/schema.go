@@ -1,5 +1,6 @@
 // Package schema provides access to live Zerops YAML schemas (zerops.yaml + import.yaml).
-// Schemas are fetched from the public API and cached with a TTL.
+// Schemas are fetched from the public API and cached with a TTL, on disk
+// across restarts, with the embedded snapshot as the last resort.
 // Extracted enums are used for validation; formatted output is used for LLM knowledge injection.
 package schema
 
@@ -18,8 +19,9 @@ const (
 
 // Schemas holds parsed and extracted data from both Zerops schemas.
 type Schemas struct {
-	ZeropsYml *ZeropsYmlSchema
-	ImportYml *ImportYmlSchema
+	ZeropsYml  *ZeropsYmlSchema
+	ImportYml  *ImportYmlSchema
+	Provenance Provenance // where the schemas came from; see Provenance.Stale
 }
 
 // ZeropsYmlSchema holds extracted data from the zerops.yaml JSON schema.
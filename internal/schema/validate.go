@@ -14,6 +14,10 @@ type ValidFields struct {
 	Build  map[string]bool // build section fields
 	Deploy map[string]bool // deploy section fields
 	Run    map[string]bool // run section fields
+
+	// Provenance of the schema the fields came from. Set by callers that
+	// got it from Cache; a stale schema may lack recently added fields.
+	Provenance Provenance
 }
 
 // FieldError describes an unknown field found in zerops.yaml.
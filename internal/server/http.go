@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// httpSessionIdleTimeout closes HTTP sessions that stop sending
+	// requests. Each session owns a workflow.Engine and its caches, so
+	// abandoned IDE/CI connections must not accumulate forever.
+	httpSessionIdleTimeout = 30 * time.Minute
+
+	// httpShutdownGrace bounds how long in-flight tool calls (deploys hold
+	// the request for minutes) get to finish once the context is canceled.
+	httpShutdownGrace = 10 * time.Second
+)
+
+// HTTPHandler returns the streamable-HTTP MCP endpoint guarded by bearer
+// token auth. Every new MCP session gets a fresh server from newMCPServer,
+// so workflow sessions started by one client are invisible to the engine
+// of another. Work sessions remain keyed by process PID on disk and are
+// therefore shared by all HTTP clients of one zcp process.
+func (s *Server) HTTPHandler(token string) http.Handler {
+	mcpHandler := mcp.NewStreamableHTTPHandler(
+		func(*http.Request) *mcp.Server { return s.newMCPServer() },
+		&mcp.StreamableHTTPOptions{
+			Logger:         s.logger,
+			SessionTimeout: httpSessionIdleTimeout,
+		},
+	)
+	return requireBearer(token, mcpHandler)
+}
+
+// RunHTTP serves the MCP server over streamable HTTP on addr until ctx is
+// canceled. An empty token is refused: the endpoint exposes every mutating
+// tool with the project's API credentials, so it is never served open.
+func (s *Server) RunHTTP(ctx context.Context, addr, token string) error {
+	if token == "" {
+		return errors.New("http transport requires a bearer token (set ZCP_HTTP_TOKEN or pass --token)")
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen %s: %w", addr, err)
+	}
+
+	httpSrv := &http.Server{
+		Handler:           s.HTTPHandler(token),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), httpShutdownGrace)
+		defer cancel()
+		_ = httpSrv.Shutdown(shutdownCtx)
+	}()
+
+	s.startBackground(ctx)
+	s.logger.Info("http transport listening", "addr", ln.Addr().String())
+	err = httpSrv.Serve(ln)
+	if errors.Is(err, http.ErrServerClosed) {
+		<-shutdownDone
+		return ctx.Err()
+	}
+	return err
+}
+
+// requireBearer rejects requests whose Authorization header does not carry
+// the expected bearer token. Comparison is constant-time so the token
+// cannot be recovered by timing the 401s.
+func requireBearer(token string, next http.Handler) http.Handler {
+	want := []byte(token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="zcp"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,108 @@
+// Tests for: server/http.go — streamable HTTP transport and bearer auth.
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zcp/internal/auth"
+	"github.com/zeropsio/zcp/internal/knowledge"
+	"github.com/zeropsio/zcp/internal/platform"
+	"github.com/zeropsio/zcp/internal/runtime"
+)
+
+// bearerTransport injects the Authorization header on every request.
+type bearerTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+func (b bearerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", "Bearer "+b.token)
+	return b.base.RoundTrip(r)
+}
+
+func newHTTPTestServer(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	t.Chdir(t.TempDir())
+
+	mock := platform.NewMock().
+		WithProject(&platform.Project{ID: "p1", Name: "test"}).
+		WithServices(nil)
+	authInfo := &auth.Info{ProjectID: "p1", Token: "test", APIHost: "localhost"}
+	store, err := knowledge.GetEmbeddedStore()
+	if err != nil {
+		t.Fatalf("knowledge store: %v", err)
+	}
+	srv := New(context.Background(), mock, authInfo, store, platform.NewMockLogFetcher(), nil, nil, runtime.Info{})
+
+	ts := httptest.NewServer(srv.HTTPHandler(token))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestHTTPHandler_RejectsMissingOrWrongToken(t *testing.T) {
+	ts := newHTTPTestServer(t, "s3cret")
+
+	for _, header := range []string{"", "Bearer wrong", "s3cret", "Basic s3cret"} {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, ts.URL, strings.NewReader("{}"))
+		if err != nil {
+			t.Fatalf("new request: %v", err)
+		}
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("do: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: status = %d, want 401", header, resp.StatusCode)
+		}
+	}
+}
+
+func TestHTTPHandler_ConcurrentClientsShareToolSet(t *testing.T) {
+	ts := newHTTPTestServer(t, "s3cret")
+	ctx := context.Background()
+
+	var counts []int
+	for range 2 {
+		client := mcp.NewClient(&mcp.Implementation{Name: "http-client", Version: "0.1"}, nil)
+		session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+			Endpoint:             ts.URL,
+			HTTPClient:           &http.Client{Transport: bearerTransport{token: "s3cret", base: http.DefaultTransport}},
+			DisableStandaloneSSE: true,
+		}, nil)
+		if err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+		defer session.Close()
+
+		result, err := session.ListTools(ctx, &mcp.ListToolsParams{})
+		if err != nil {
+			t.Fatalf("list tools: %v", err)
+		}
+		counts = append(counts, len(result.Tools))
+	}
+
+	if counts[0] == 0 || counts[0] != counts[1] {
+		t.Errorf("tool counts per session = %v, want equal and non-zero", counts)
+	}
+}
+
+func TestRunHTTP_RequiresToken(t *testing.T) {
+	t.Parallel()
+
+	s := &Server{}
+	err := s.RunHTTP(context.Background(), "127.0.0.1:0", "")
+	if err == nil || !strings.Contains(err.Error(), "bearer token") {
+		t.Fatalf("RunHTTP without token: err = %v, want bearer token error", err)
+	}
+}
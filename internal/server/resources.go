@@ -9,8 +9,8 @@ import (
 
 const resourceURIPrefix = "zerops://docs/"
 
-func (s *Server) registerResources() {
-	s.server.AddResourceTemplate(
+func (s *Server) registerResources(srv *mcp.Server) {
+	srv.AddResourceTemplate(
 		&mcp.ResourceTemplate{
 			URITemplate: "zerops://docs/{+path}",
 			Name:        "zerops-docs",
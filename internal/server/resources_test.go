@@ -46,7 +46,7 @@ func testResourceServer(t *testing.T) *mcp.ClientSession {
 	authInfo := &auth.Info{ProjectID: "p1", Token: "test", APIHost: "localhost"}
 	logFetcher := platform.NewMockLogFetcher()
 
-	srv := New(context.Background(), mock, authInfo, store, logFetcher, nil, nil, runtime.Info{})
+	srv := New(context.Background(), mock, authInfo, store, logFetcher, nil, nil, runtime.Info{}, WithStateDir(t.TempDir()))
 
 	ctx := context.Background()
 	st, ct := mcp.NewInMemoryTransports()
@@ -11,11 +11,13 @@ import (
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zcp/internal/audit"
 	"github.com/zeropsio/zcp/internal/auth"
 	"github.com/zeropsio/zcp/internal/content"
 	"github.com/zeropsio/zcp/internal/knowledge"
 	"github.com/zeropsio/zcp/internal/ops"
 	"github.com/zeropsio/zcp/internal/platform"
+	"github.com/zeropsio/zcp/internal/policy"
 	"github.com/zeropsio/zcp/internal/recipe"
 	"github.com/zeropsio/zcp/internal/runtime"
 	"github.com/zeropsio/zcp/internal/schema"
@@ -42,6 +44,45 @@ type Server struct {
 	rtInfo      runtime.Info
 	logger      *slog.Logger
 	calls       atomic.Int64
+
+	// auditLog records every tool call under .zcp/state/audit/. Nil when
+	// there is no working directory to anchor state in.
+	auditLog *audit.Log
+
+	// policy is .zcp/policy.yaml as loaded at startup; nil when absent.
+	policy *policy.Policy
+	// stateDir is .zcp/state under the working directory (or WithStateDir);
+	// empty without one. The policy gate reads .zcp/scaling.yaml next to it.
+	stateDir string
+
+	// watchdog remounts stale SSHFS mounts in the background; nil outside
+	// a Zerops container. sessions receives its log notifications.
+	watchdog *ops.MountWatchdog
+	sessions sessionSet
+
+	// instructions is computed once in New and shared by every MCP server
+	// instance (one for STDIO, one per session over HTTP).
+	instructions string
+
+	// httpDoer answers the HTTP probes of verify, subdomain and env
+	// tools; nil means a real client with a 15s timeout.
+	httpDoer ops.HTTPDoer
+}
+
+// Option configures optional Server dependencies.
+type Option func(*Server)
+
+// WithHTTPDoer routes the tools' HTTP probes through d instead of the
+// network. `zcp serve --simulate` passes the simulator here.
+func WithHTTPDoer(d ops.HTTPDoer) Option {
+	return func(s *Server) { s.httpDoer = d }
+}
+
+// WithStateDir places .zcp state in dir instead of under the working
+// directory. Tests pass t.TempDir() so server construction never writes
+// into the package directory.
+func WithStateDir(dir string) Option {
+	return func(s *Server) { s.stateDir = dir }
 }
 
 // CallCount returns the number of tool calls served during this server's lifetime.
@@ -62,17 +103,34 @@ func (s *Server) CallCount() int64 { return s.calls.Load() }
 // and propagated as empty note so the server still starts; the LLM will
 // see the consequence on the first state-reading tool call. Container
 // env skips adoption entirely — container bootstrap is explicit.
-func New(ctx context.Context, client platform.Client, authInfo *auth.Info, store knowledge.Provider, logFetcher platform.LogFetcher, sshDeployer ops.SSHDeployer, mounter ops.Mounter, rtInfo runtime.Info) *Server {
+func New(ctx context.Context, client platform.Client, authInfo *auth.Info, store knowledge.Provider, logFetcher platform.LogFetcher, sshDeployer ops.SSHDeployer, mounter ops.Mounter, rtInfo runtime.Info, opts ...Option) *Server {
 	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel()}))
 
-	// stateDir resolution mirrors registerTools(); kept local here so the
-	// MCP init payload can include a state hint without depending on tool
-	// registration. Empty stateDir (no cwd) yields empty hints — same
-	// degradation path as a project that has no .zcp state yet.
-	stateDir := ""
-	if cwd, err := os.Getwd(); err == nil {
-		stateDir = filepath.Join(cwd, ".zcp", "state")
+	s := &Server{
+		client:      client,
+		authInfo:    authInfo,
+		store:       store,
+		logFetcher:  logFetcher,
+		sshDeployer: sshDeployer,
+		mounter:     mounter,
+		rtInfo:      rtInfo,
+		logger:      logger,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	// stateDir is resolved once here (WithStateDir wins over the working
+	// directory) so the MCP init payload can include a state hint and
+	// registerTools() shares the same location. Empty stateDir (no cwd)
+	// yields empty hints — same degradation path as a project that has
+	// no .zcp state yet.
+	if s.stateDir == "" {
+		if cwd, err := os.Getwd(); err == nil {
+			s.stateDir = filepath.Join(cwd, ".zcp", "state")
+		}
 	}
+	stateDir := s.stateDir
 
 	adoptionNote := ""
 	if !rtInfo.InContainer && stateDir != "" {
@@ -100,44 +158,50 @@ func New(ctx context.Context, client platform.Client, authInfo *auth.Info, store
 		StateHint:    ComposeStateHint(stateDir, os.Getpid()),
 	}
 
+	s.instructions = BuildInstructions(rc)
+	if stateDir != "" {
+		s.auditLog = audit.NewLog(audit.Dir(stateDir))
+		s.policy = loadPolicy(policy.Path(stateDir), logger)
+	}
+	if mounter != nil {
+		s.watchdog = ops.NewMountWatchdog(client, authInfo.ProjectID, mounter, s.notifyMountEvent)
+	}
+	s.server = s.newMCPServer()
+	return s
+}
+
+// newMCPServer builds one fully-registered MCP server. STDIO mode uses a
+// single instance for the process lifetime; HTTP mode calls this once per
+// client session so every connection gets its own workflow.Engine and
+// per-session caches while sharing the platform client, knowledge store
+// and runtime detection held on Server.
+func (s *Server) newMCPServer() *mcp.Server {
 	srv := mcp.NewServer(
 		&mcp.Implementation{Name: "zcp", Version: Version},
 		&mcp.ServerOptions{
-			Instructions: BuildInstructions(rc),
-			Logger:       logger,
+			Instructions: s.instructions,
+			Logger:       s.logger,
+			InitializedHandler: func(_ context.Context, req *mcp.InitializedRequest) {
+				s.sessions.track(req.Session)
+			},
 		},
 	)
-
-	s := &Server{
-		server:      srv,
-		client:      client,
-		authInfo:    authInfo,
-		store:       store,
-		logFetcher:  logFetcher,
-		sshDeployer: sshDeployer,
-		mounter:     mounter,
-		rtInfo:      rtInfo,
-		logger:      logger,
-	}
-
-	srv.AddReceivingMiddleware(s.observe())
-	s.registerTools()
-	s.registerResources()
-	return s
+	// observe wraps the policy gate so refused calls are audited too.
+	srv.AddReceivingMiddleware(s.observe(), tools.PolicyGate(s.policy, s.client, s.authInfo.ProjectID, s.stateDir))
+	s.registerTools(srv)
+	s.registerResources(srv)
+	return srv
 }
 
-func (s *Server) registerTools() {
+func (s *Server) registerTools(srv *mcp.Server) {
 	projectID := s.authInfo.ProjectID
 	stackCache := ops.NewStackTypeCache(ops.DefaultStackTypeCacheTTL)
 	schemaCache := schema.NewCache(schema.DefaultCacheTTL)
 
-	// Workflow engine: state at .zcp/state/ relative to working directory.
-	var (
-		wfEngine *workflow.Engine
-		stateDir string
-	)
-	if cwd, err := os.Getwd(); err == nil {
-		stateDir = filepath.Join(cwd, ".zcp", "state")
+	// Workflow engine: state at .zcp/state/ (see New).
+	var wfEngine *workflow.Engine
+	stateDir := s.stateDir
+	if stateDir != "" {
 		env := workflow.DetectEnvironment(s.rtInfo)
 		wfEngine = workflow.NewEngine(stateDir, env, s.store)
 	}
@@ -163,20 +227,23 @@ func (s *Server) registerTools() {
 	// request-level timeouts on top. Constructed before workflow registration
 	// so action="record-deploy" can plumb it through to maybeAutoEnableSubdomain
 	// (deploy-decomp Phase 7).
-	httpClient := &http.Client{Timeout: 15 * time.Second}
+	var httpClient ops.HTTPDoer = &http.Client{Timeout: 15 * time.Second}
+	if s.httpDoer != nil {
+		httpClient = s.httpDoer
+	}
 
 	// Read-only tools
-	tools.RegisterWorkflow(s.server, s.client, httpClient, projectID, stackCache, schemaCache, wfEngine, s.logFetcher, stateDir, s.rtInfo.ServiceName, s.mounter, s.sshDeployer, s.rtInfo)
-	tools.RegisterDiscover(s.server, s.client, projectID, stateDir)
-	tools.RegisterKnowledge(s.server, s.store, s.client, stackCache, knowledgeTracker, wfEngine)
-	tools.RegisterGuidance(s.server, wfEngine)
-	tools.RegisterRecordFact(s.server, wfEngine, recipeStore)
-	tools.RegisterWorkspaceManifest(s.server, wfEngine, recipeStore)
-	tools.RegisterLogs(s.server, s.client, s.logFetcher, projectID)
-	tools.RegisterEvents(s.server, s.client, s.logFetcher, projectID)
-	tools.RegisterProcess(s.server, s.client)
-	tools.RegisterVerify(s.server, s.client, s.logFetcher, projectID, stateDir)
-	tools.RegisterPreprocess(s.server)
+	tools.RegisterWorkflow(srv, s.client, httpClient, projectID, stackCache, schemaCache, wfEngine, s.logFetcher, stateDir, s.rtInfo.ServiceName, s.mounter, s.sshDeployer, s.rtInfo)
+	tools.RegisterDiscover(srv, s.client, projectID, stateDir)
+	tools.RegisterKnowledge(srv, s.store, s.client, stackCache, knowledgeTracker, wfEngine)
+	tools.RegisterGuidance(srv, wfEngine)
+	tools.RegisterRecordFact(srv, wfEngine, recipeStore)
+	tools.RegisterWorkspaceManifest(srv, wfEngine, recipeStore)
+	tools.RegisterLogs(srv, s.client, s.logFetcher, projectID)
+	tools.RegisterEvents(srv, s.client, s.logFetcher, projectID)
+	tools.RegisterProcess(srv, s.client)
+	tools.RegisterVerify(srv, s.client, s.logFetcher, s.sshDeployer, projectID, stateDir)
+	tools.RegisterPreprocess(srv)
 
 	// Mutating tools — deploy registration routes by environment.
 	// recipeStore wires the recipe-authoring exemption into requireAdoption:
@@ -184,48 +251,49 @@ func (s *Server) registerTools() {
 	// adoption gate so cross-deploys (e.g. `apidev → apistage`) succeed
 	// before any bootstrap workflow runs.
 	if s.sshDeployer != nil {
-		tools.RegisterDeploySSH(s.server, s.client, httpClient, projectID, s.sshDeployer, s.authInfo, s.logFetcher, s.rtInfo, stateDir, wfEngine, recipeStore)
+		tools.RegisterDeploySSH(srv, s.client, httpClient, projectID, s.sshDeployer, s.authInfo, s.logFetcher, s.rtInfo, stateDir, wfEngine, recipeStore)
 		// v8.94: batch-deploy keeps multi-target parallelism server-side
 		// so the MCP STDIO channel isn't saturated (v23 "Not connected"
 		// failure class). SSH-only — local deploys don't face the same
 		// parallelism problem.
-		tools.RegisterDeployBatch(s.server, s.client, httpClient, projectID, s.sshDeployer, s.authInfo, s.logFetcher, stateDir, wfEngine, recipeStore)
+		tools.RegisterDeployBatch(srv, s.client, httpClient, projectID, s.sshDeployer, s.authInfo, s.logFetcher, stateDir, wfEngine, recipeStore)
 		// dev_server depends on the SSH deployer — it's the lifecycle
 		// primitive for background dev servers on target containers.
 		// Skipped in local-only mode where SSH to Zerops siblings is
 		// not available.
-		tools.RegisterDevServer(s.server, s.client, projectID, s.sshDeployer)
+		tools.RegisterDevServer(srv, s.client, projectID, s.sshDeployer)
 	} else {
-		tools.RegisterDeployLocal(s.server, s.client, httpClient, projectID, s.authInfo, s.logFetcher, stateDir, wfEngine, recipeStore)
+		tools.RegisterDeployLocal(srv, s.client, httpClient, projectID, s.authInfo, s.logFetcher, stateDir, wfEngine, recipeStore)
 	}
-	tools.RegisterExport(s.server, s.client, projectID)
-	tools.RegisterManage(s.server, s.client, projectID)
-	tools.RegisterScale(s.server, s.client, projectID)
-	tools.RegisterEnv(s.server, s.client, projectID, s.rtInfo.ServiceName)
+	tools.RegisterExport(srv, s.client, projectID)
+	tools.RegisterManage(srv, s.client, projectID)
+	tools.RegisterScale(srv, s.client, projectID, stateDir)
+	tools.RegisterEnv(srv, s.client, s.logFetcher, httpClient, projectID, s.rtInfo.ServiceName, stateDir)
 
 	// zcprecipator3 (v3) recipe engine ships alongside v2's zerops_workflow.
 	// Both tools register; clients pick which to call. v2 deletion triggers
 	// on first clean showcase via v3 — see docs/zcprecipator3/plan.md §14.
 	// recipeStore was constructed above so v2-shaped tools can accept a
 	// recipe session as their workflow context.
-	recipe.Register(s.server, recipeStore)
+	recipe.Register(srv, recipeStore)
 
-	tools.RegisterImport(s.server, s.client, projectID, wfEngine, stateDir, recipeStore)
-	tools.RegisterDelete(s.server, s.client, projectID, stateDir, s.mounter, s.rtInfo)
-	tools.RegisterSubdomain(s.server, s.client, httpClient, projectID, stateDir)
-	tools.RegisterMount(s.server, s.client, projectID, s.mounter, s.rtInfo, stateDir, wfEngine, recipeStore)
+	tools.RegisterImport(srv, s.client, projectID, wfEngine, stateDir, recipeStore)
+	tools.RegisterDelete(srv, s.client, projectID, stateDir, s.mounter, s.rtInfo)
+	tools.RegisterSubdomain(srv, s.client, httpClient, projectID, stateDir)
+	tools.RegisterMount(srv, s.client, projectID, s.mounter, s.watchdog, s.rtInfo, stateDir, wfEngine, recipeStore)
 
 	// Container-only: zerops_browser wraps agent-browser with a guaranteed
 	// open→work→close lifecycle. agent-browser is pre-installed in the ZCP
 	// container but absent from local dev machines, so the tool is gated on
 	// both container detection AND binary presence on PATH.
 	if s.rtInfo.InContainer && ops.AgentBrowserAvailable() {
-		tools.RegisterBrowser(s.server)
+		tools.RegisterBrowser(srv, stateDir)
 	}
 }
 
 // Run starts the MCP server on stdio transport.
 func (s *Server) Run(ctx context.Context) error {
+	s.startBackground(ctx)
 	return s.server.Run(ctx, &mcp.StdioTransport{})
 }
 
@@ -236,7 +304,8 @@ func (s *Server) MCPServer() *mcp.Server {
 
 const methodCallTool = "tools/call"
 
-// observe returns middleware that counts tool calls and logs timing at Info level.
+// observe returns middleware that counts tool calls, logs timing at Info
+// level and appends a redacted entry to the audit log.
 func (s *Server) observe() mcp.Middleware {
 	return func(next mcp.MethodHandler) mcp.MethodHandler {
 		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
@@ -246,12 +315,82 @@ func (s *Server) observe() mcp.Middleware {
 			s.calls.Add(1)
 			start := time.Now()
 			result, err := next(ctx, method, req)
-			s.logger.Info("tool call", "ms", time.Since(start).Milliseconds())
+			elapsed := time.Since(start)
+			s.logger.Info("tool call", "ms", elapsed.Milliseconds())
+			s.recordAudit(start, elapsed, req, result, err)
 			return result, err
 		}
 	}
 }
 
+// recordAudit appends one audit entry for a finished tool call. Write
+// failures are logged, never surfaced — auditing must not break a deploy.
+func (s *Server) recordAudit(start time.Time, elapsed time.Duration, req mcp.Request, result mcp.Result, err error) {
+	if s.auditLog == nil {
+		return
+	}
+	callReq, ok := req.(*mcp.CallToolRequest)
+	if !ok || callReq.Params == nil {
+		return
+	}
+
+	entry := audit.Entry{
+		Time:       start,
+		Tool:       callReq.Params.Name,
+		DurationMs: elapsed.Milliseconds(),
+		Status:     audit.StatusOK,
+		PID:        os.Getpid(),
+	}
+	entry.Args, entry.Services = audit.Redact(entry.Tool, callReq.Params.Arguments)
+	if callReq.Session != nil {
+		entry.Session = callReq.Session.ID()
+	}
+
+	callResult, _ := result.(*mcp.CallToolResult)
+	switch {
+	case err != nil || callResult == nil:
+		entry.Status = audit.StatusFault
+	default:
+		text := firstText(callResult)
+		if callResult.IsError {
+			entry.Status = audit.StatusError
+			entry.ErrorCode = audit.ErrorCode(text)
+		} else {
+			entry.ProcessIDs = audit.ProcessIDs(text)
+		}
+	}
+
+	if werr := s.auditLog.Append(entry); werr != nil {
+		s.logger.Warn("audit log append failed", "tool", entry.Tool, "err", werr)
+	}
+}
+
+// firstText returns the text of the first TextContent block — tools
+// return a single JSON document there.
+func firstText(r *mcp.CallToolResult) string {
+	for _, c := range r.Content {
+		if tc, ok := c.(*mcp.TextContent); ok {
+			return tc.Text
+		}
+	}
+	return ""
+}
+
+// loadPolicy reads .zcp/policy.yaml. An unreadable or invalid file fails
+// closed — every mutation is refused with the parse error — rather than
+// starting unrestricted.
+func loadPolicy(path string, logger *slog.Logger) *policy.Policy {
+	pol, err := policy.Load(path)
+	if err != nil {
+		logger.Error("policy load failed; mutating tools disabled", "path", path, "err", err)
+		return policy.FailClosed(err)
+	}
+	if pol != nil {
+		logger.Info("policy loaded", "path", path, "readOnly", pol.ReadOnly, "protected", len(pol.Protected))
+	}
+	return pol
+}
+
 // runLocalAutoAdopt performs the eager local-env state bootstrap:
 // legacy-meta migration first (so existing installs get their meta
 // rewritten to the new shape), then auto-adoption if state is empty.
@@ -3,15 +3,18 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zcp/internal/audit"
 	"github.com/zeropsio/zcp/internal/auth"
 	"github.com/zeropsio/zcp/internal/knowledge"
 	"github.com/zeropsio/zcp/internal/ops"
@@ -163,7 +166,7 @@ func TestServer_BrowserToolGating(t *testing.T) {
 			}
 			logFetcher := platform.NewMockLogFetcher()
 
-			srv := New(context.Background(), mock, authInfo, store, logFetcher, nil, nil, tt.rt)
+			srv := New(context.Background(), mock, authInfo, store, logFetcher, nil, nil, tt.rt, WithStateDir(t.TempDir()))
 
 			ctx := context.Background()
 			st, ct := mcp.NewInMemoryTransports()
@@ -427,3 +430,97 @@ func TestObserve_PassesThrough(t *testing.T) {
 		t.Errorf("middleware should pass through handler error, got %v", err)
 	}
 }
+
+func TestObserve_WritesAuditEntries(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	s := &Server{
+		logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		auditLog: audit.NewLog(dir),
+	}
+
+	calls := []struct {
+		req    *mcp.CallToolRequest
+		result mcp.Result
+		err    error
+	}{
+		{
+			req: &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{
+				Name:      "zerops_env",
+				Arguments: json.RawMessage(`{"action":"set","serviceHostname":"api","variables":["DB_PASS=hunter2"]}`),
+			}},
+			result: &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{
+				Text: `{"process":{"id":"proc-env","actionName":"envUpdate"},"restartedProcesses":[{"id":"proc-restart","actionName":"stack.restart"}]}`,
+			}}},
+		},
+		{
+			req: &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{
+				Name:      "zerops_manage",
+				Arguments: json.RawMessage(`{"action":"stop","serviceHostname":"ghost"}`),
+			}},
+			result: &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{
+				Text: `{"code":"SERVICE_NOT_FOUND","error":"Service 'ghost' not found"}`,
+			}}},
+		},
+		{
+			req: &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "zerops_nope"}},
+			err: errors.New("unknown tool"),
+		},
+	}
+	for _, c := range calls {
+		handler := s.observe()(func(_ context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+			return c.result, c.err
+		})
+		_, _ = handler(context.Background(), methodCallTool, c.req)
+	}
+
+	entries, err := audit.Read(dir, audit.Filter{})
+	if err != nil {
+		t.Fatalf("audit.Read: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("entries = %d, want 3", len(entries))
+	}
+
+	env := entries[0]
+	if env.Tool != "zerops_env" || env.Status != audit.StatusOK || env.PID != os.Getpid() {
+		t.Errorf("env entry = %+v", env)
+	}
+	if len(env.Services) != 1 || env.Services[0] != "api" {
+		t.Errorf("env services = %v, want [api]", env.Services)
+	}
+	if len(env.ProcessIDs) != 2 {
+		t.Errorf("env processIds = %v, want 2", env.ProcessIDs)
+	}
+	if entries[1].Status != audit.StatusError || entries[1].ErrorCode != "SERVICE_NOT_FOUND" {
+		t.Errorf("manage entry = %+v, want error SERVICE_NOT_FOUND", entries[1])
+	}
+	if entries[2].Status != audit.StatusFault {
+		t.Errorf("unknown tool entry status = %q, want fault", entries[2].Status)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, "audit-"+env.Time.Format("2006-01-02")+".jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "hunter2") {
+		t.Error("audit log leaked an env value")
+	}
+}
+
+func TestLoadPolicy_InvalidFileFailsClosed(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte("readonly: yes\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	pol := loadPolicy(path, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := pol.CheckMutation("zerops_deploy"); err == nil {
+		t.Fatal("invalid policy must refuse mutations")
+	}
+	if pol := loadPolicy(filepath.Join(t.TempDir(), "absent.yaml"), slog.New(slog.NewTextHandler(io.Discard, nil))); pol != nil {
+		t.Errorf("missing policy file = %+v, want nil", pol)
+	}
+}
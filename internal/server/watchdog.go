@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zcp/internal/ops"
+)
+
+// mountWatchdogLogger names the watchdog in MCP log notifications.
+const mountWatchdogLogger = "mount-watchdog"
+
+// sessionSet tracks initialized MCP sessions across every server instance
+// (one for STDIO, one per HTTP session) so process-wide background work
+// can notify all connected clients.
+type sessionSet struct {
+	mu       sync.Mutex
+	sessions map[*mcp.ServerSession]struct{}
+}
+
+// track adds ss and removes it once the session closes.
+func (s *sessionSet) track(ss *mcp.ServerSession) {
+	s.mu.Lock()
+	if s.sessions == nil {
+		s.sessions = make(map[*mcp.ServerSession]struct{})
+	}
+	s.sessions[ss] = struct{}{}
+	s.mu.Unlock()
+	go func() {
+		_ = ss.Wait()
+		s.mu.Lock()
+		delete(s.sessions, ss)
+		s.mu.Unlock()
+	}()
+}
+
+func (s *sessionSet) snapshot() []*mcp.ServerSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*mcp.ServerSession, 0, len(s.sessions))
+	for ss := range s.sessions {
+		out = append(out, ss)
+	}
+	return out
+}
+
+// startBackground launches process-wide background work bound to ctx.
+// Called by Run and RunHTTP.
+func (s *Server) startBackground(ctx context.Context) {
+	if s.watchdog != nil {
+		go s.watchdog.Run(ctx)
+	}
+}
+
+// notifyMountEvent logs a watchdog event and forwards it to every client
+// as an MCP log notification. Clients that never set a log level receive
+// nothing, per the protocol.
+func (s *Server) notifyMountEvent(ev ops.MountWatchEvent) {
+	s.logger.Info("mount watchdog", "hostname", ev.Hostname, "from", ev.From, "to", ev.To, "msg", ev.Message)
+	for _, ss := range s.sessions.snapshot() {
+		_ = ss.Log(context.Background(), &mcp.LoggingMessageParams{
+			Logger: mountWatchdogLogger,
+			Level:  mcp.LoggingLevel(ev.Level),
+			Data:   ev,
+		})
+	}
+}
@@ -245,7 +245,7 @@ func listAllTools(t *testing.T) map[string]*mcp.Tool {
 	}
 	logFetcher := platform.NewMockLogFetcher()
 
-	srv := server.New(context.Background(), mock, authInfo, store, logFetcher, &nopSSH{}, &nopMounter{}, runtime.Info{})
+	srv := server.New(context.Background(), mock, authInfo, store, logFetcher, &nopSSH{}, &nopMounter{}, runtime.Info{}, server.WithStateDir(t.TempDir()))
 
 	ctx := context.Background()
 	st, ct := mcp.NewInMemoryTransports()
@@ -297,7 +297,7 @@ func TestAnnotations_BrowserTool(t *testing.T) {
 	logFetcher := platform.NewMockLogFetcher()
 
 	srv := server.New(context.Background(), mock, authInfo, store, logFetcher, &nopSSH{}, &nopMounter{},
-		runtime.Info{InContainer: true, ServiceID: "s1"})
+		runtime.Info{InContainer: true, ServiceID: "s1"}, server.WithStateDir(t.TempDir()))
 
 	ctx := context.Background()
 	st, ct := mcp.NewInMemoryTransports()
@@ -373,7 +373,7 @@ func TestAnnotations_DeleteToolRequiresExplicitApproval(t *testing.T) {
 	}
 	logFetcher := platform.NewMockLogFetcher()
 
-	srv := server.New(context.Background(), mock, authInfo, store, logFetcher, &nopSSH{}, &nopMounter{}, runtime.Info{})
+	srv := server.New(context.Background(), mock, authInfo, store, logFetcher, &nopSSH{}, &nopMounter{}, runtime.Info{}, server.WithStateDir(t.TempDir()))
 
 	ctx := context.Background()
 	st, ct := mcp.NewInMemoryTransports()
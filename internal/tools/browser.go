@@ -9,7 +9,8 @@ import (
 
 // RegisterBrowser registers the zerops_browser tool. Only called by server.go
 // when running inside the ZCP container (where agent-browser is installed).
-func RegisterBrowser(srv *mcp.Server) {
+// stateDir holds visual checkpoints under browser/; empty disables them.
+func RegisterBrowser(srv *mcp.Server, stateDir string) {
 	mcp.AddTool(srv, &mcp.Tool{
 		Name: "zerops_browser",
 		Description: "Drive Chrome via agent-browser in ONE bounded batch (ZCP-container only). " +
@@ -33,8 +34,15 @@ func RegisterBrowser(srv *mcp.Server) {
 			"[\"get\",\"count\",\"<sel>\"], [\"is\",\"visible\",\"<sel>\"], [\"wait\",\"500\"]. " +
 			"Do NOT pass [\"open\",...] or [\"close\"] in commands — both are stripped. " +
 			"Do NOT use [\"eval\",...] — dedicated commands produce structured output. " +
+			"Visual checks: screenshot=true captures a full-page PNG after your commands into " +
+			".zcp/state/browser/<host>/<checkpoint>/latest.png (checkpoint defaults to \"default\"; name one per page/state, " +
+			"e.g. \"home\", \"checkout-form\"). The first capture of a checkpoint becomes its baseline. " +
+			"compare=true also diffs against the accepted baseline and returns visual.diff.diffPercent plus " +
+			"visual.diff.diffImage (changed pixels in red) — use it after frontend deploys, since a broken " +
+			"layout still answers HTTP 200. When a difference is intended, call with action=\"accept\" and the same " +
+			"url + checkpoint to promote latest.png to the baseline (no browser is opened). " +
 			"Returns: steps[], errorsOutput (from final [errors] step), consoleOutput (from final [console] step), " +
-			"durationMs, forkRecoveryAttempted, message.",
+			"durationMs, forkRecoveryAttempted, message, visual.",
 		Annotations: &mcp.ToolAnnotations{
 			Title:           "Drive browser via agent-browser",
 			IdempotentHint:  false,
@@ -42,7 +50,7 @@ func RegisterBrowser(srv *mcp.Server) {
 			OpenWorldHint:   boolPtr(true),
 		},
 	}, func(ctx context.Context, _ *mcp.CallToolRequest, input ops.BrowserBatchInput) (*mcp.CallToolResult, any, error) {
-		result, err := ops.BrowserBatch(ctx, input)
+		result, err := ops.BrowserVisualBatch(ctx, stateDir, input)
 		if err != nil {
 			return convertError(err), nil, nil
 		}
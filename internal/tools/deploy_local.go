@@ -23,10 +23,12 @@ import (
 // own git config — no GIT_TOKEN, no .netrc, no cross-boundary
 // credential juggling.
 //
-// includeGit is not user-facing: local zcli push always runs with
-// --no-git. Recipes that need committed history go through
-// strategy=git-push, which drives the user's own git CLI.
+// includeGit is not user-facing: the native local push never ships .git.
+// Recipes that need committed history go through strategy=git-push,
+// which drives the user's own git CLI.
 type DeployLocalInput struct {
+	Action        string `json:"action,omitempty"`
+	AppVersionID  string `json:"appVersionId,omitempty"`
 	TargetService string `json:"targetService"`
 	Setup         string `json:"setup,omitempty"`
 	WorkingDir    string `json:"workingDir,omitempty"`
@@ -42,12 +44,15 @@ func deployLocalInputSchema() *jsonschema.Schema {
 		"workingDir":    {Type: "string", Description: "Local path to push from. Default: current directory."},
 		"strategy":      {Type: "string", Description: "Deploy strategy. Omit for default push (zerops build from the working directory). Set to 'git-push' to push committed code from your local git repo to the configured origin remote — ZCP invokes your own git, no GIT_TOKEN needed."},
 		"remoteUrl":     {Type: "string", Description: "Git remote URL (HTTPS). Optional for strategy=git-push — used only when origin isn't already configured in the local repo; otherwise the existing origin is reused."},
+		"action":        deployActionSchema(),
+		"appVersionId":  appVersionIDSchema(),
 		"branch":        {Type: "string", Description: "Git branch for strategy=git-push. Default: current HEAD branch."},
 	}, "targetService")
 }
 
 // RegisterDeployLocal registers the zerops_deploy tool for local mode.
-// Uses zcli push instead of SSH to deploy code from the user's machine.
+// Uses a native push (ops.DeployLocal: archive upload through the API)
+// instead of SSH to deploy code from the user's machine.
 // httpClient drives the post-success subdomain auto-enable hook — on first
 // deploy for eligible modes (dev/stage/simple/standard/local-stage) the
 // handler calls ops.Subdomain and waits for L7 readiness via
@@ -66,8 +71,9 @@ func RegisterDeployLocal(
 	mcp.AddTool(srv, &mcp.Tool{
 		Name: "zerops_deploy",
 		Description: "Push local code to Zerops — blocks until build completes. " +
-			"Requires zerops.yaml and zcli installed. " +
+			"Requires zerops.yaml in workingDir; .deployignore is honoured. " +
 			"Set targetService to the Zerops service hostname. " +
+			"action=rollback: no-rebuild revert. " +
 			"Channel-blocking: this call holds the MCP STDIO channel for the duration of the build " +
 			"(typically 60–120s). Do NOT issue other zerops_* calls in the same response — they will " +
 			"return `Not connected` (an MCP transport error, not a platform rejection). Serialize all deploys.",
@@ -77,6 +83,15 @@ func RegisterDeployLocal(
 			DestructiveHint: boolPtr(true),
 		},
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input DeployLocalInput) (*mcp.CallToolResult, any, error) {
+		if err := validateDeployAction(input.Action); err != nil {
+			return convertError(err, WithRecoveryStatus()), nil, nil
+		}
+		// Rollback reactivates an existing build — no zerops.yaml, no
+		// pre-flight, no strategy. Routed before any of those gates.
+		if input.Action == deployActionRollback {
+			return handleRollback(ctx, req, client, projectID, stateDir, recipeProbe, input.TargetService, input.AppVersionID)
+		}
+
 		// Strategy validation. "manual" is a ServiceMeta declaration only —
 		// calling zerops_deploy on a manual-strategy service is a contradiction
 		// ZCP refuses to resolve silently.
@@ -92,7 +107,7 @@ func RegisterDeployLocal(
 		}
 
 		// Local-only projects have no Zerops-side deploy target — reject
-		// push-dev (which needs a service to zcli-push into) and point the
+		// push-dev (which needs a service to push into) and point the
 		// user at either linking a stage or using git-push.
 		if err := checkLocalOnlyGate(stateDir, input.TargetService, input.Strategy); err != nil {
 			return convertError(err, WithRecoveryStatus()), nil, nil
@@ -105,7 +120,8 @@ func RegisterDeployLocal(
 		}
 
 		// Pre-flight validation (harness). v8.85 — pre-flight echoes the
-		// effective setup so zcli always invokes with --setup=<resolved>.
+		// effective setup so the build is always triggered with the
+		// resolved setup name.
 		//
 		// Local mode: yaml lives at the project root (the user's working
 		// directory), so sourceHostname is empty — there are no per-service
@@ -129,8 +145,9 @@ func RegisterDeployLocal(
 			input.Setup = resolvedSetup
 		}
 
-		// Strategy on the local deploy path is "zcli" (zcli push from the
-		// developer's machine). The local-env git-push branch lives in
+		// Strategy on the local deploy path keeps the "zcli" label (a push
+		// from the developer's machine, now native rather than via the
+		// zcli binary). The local-env git-push branch lives in
 		// deploy_local_git.go and records its own attempt with
 		// `Strategy: "git-push"`.
 		attempt := workflow.DeployAttempt{
@@ -139,12 +156,13 @@ func RegisterDeployLocal(
 			Strategy:    deployStrategyZCLILabel,
 		}
 
-		result, err := ops.DeployLocal(ctx, client, projectID, *authInfo,
-			input.TargetService, input.Setup, input.WorkingDir)
+		onProgress := buildProgressCallback(ctx, req)
+		result, err := ops.DeployLocal(ctx, client, projectID,
+			input.TargetService, input.Setup, input.WorkingDir, onProgress)
 		if err != nil {
 			attempt.Error = err.Error()
-			// Local push failed before reaching the platform — transport-
-			// layer error (e.g. zcli auth, connection).
+			// Local push failed before a build started — transport-layer
+			// error (e.g. archive upload, connection).
 			classification := classifyTransportError(err, deployStrategyZCLILabel)
 			if classification != nil {
 				attempt.FailureClass = classification.Category
@@ -155,7 +173,6 @@ func RegisterDeployLocal(
 			return convertError(err, WithRecoveryStatus(), WithFailureClassification(classification)), nil, nil
 		}
 
-		onProgress := buildProgressCallback(ctx, req)
 		pollDeployBuild(ctx, client, projectID, result, onProgress, logFetcher, nil)
 
 		if result != nil && result.Status == statusDeployed {
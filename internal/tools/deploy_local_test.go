@@ -79,7 +79,7 @@ func TestDeployLocalTool_Schema_NoSourceService(t *testing.T) {
 	}
 }
 
-func TestDeployLocalTool_Description_MentionsZcli(t *testing.T) {
+func TestDeployLocalTool_Description_NoZcliRequirement(t *testing.T) {
 	t.Parallel()
 
 	mock := platform.NewMock()
@@ -118,8 +118,13 @@ func TestDeployLocalTool_Description_MentionsZcli(t *testing.T) {
 	if desc == "" {
 		t.Fatal("zerops_deploy not found")
 	}
-	if !strings.Contains(desc, "zcli") {
-		t.Errorf("local deploy description should mention zcli, got: %q", desc)
+	// Native push: zcli is no longer a prerequisite, and telling the
+	// agent to install it would send users on a pointless detour.
+	if strings.Contains(desc, "zcli") {
+		t.Errorf("local deploy description should not require zcli, got: %q", desc)
+	}
+	if !strings.Contains(desc, ".deployignore") {
+		t.Errorf("local deploy description should mention .deployignore, got: %q", desc)
 	}
 	if strings.Contains(desc, "SSH") {
 		t.Errorf("local deploy description should NOT mention SSH, got: %q", desc)
@@ -0,0 +1,137 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zcp/internal/ops"
+	"github.com/zeropsio/zcp/internal/platform"
+	"github.com/zeropsio/zcp/internal/topology"
+	"github.com/zeropsio/zcp/internal/workflow"
+)
+
+// deployActionRollback routes zerops_deploy to app-version reactivation
+// instead of a build. Empty action (or "deploy") keeps the default push.
+const deployActionRollback = "rollback"
+
+// deployStrategyRollbackLabel is the Strategy LABEL written into
+// DeployAttempt records for rollbacks. Like deployStrategyZCLILabel it is
+// an audit-trail value, not a zerops_deploy strategy parameter.
+const deployStrategyRollbackLabel = "rollback"
+
+func deployActionSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "string",
+		Enum:        []any{"deploy", deployActionRollback},
+		Description: "deploy (default): build and deploy code. rollback: reactivate a previously built app version on targetService — no build runs. Defaults to the version that was active before the current one; pass appVersionId to choose.",
+	}
+}
+
+func appVersionIDSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "string",
+		Description: "action=rollback only: app version ID to reactivate (see zerops_events). Omit to roll back to the previous version.",
+	}
+}
+
+// validateDeployAction gates the action parameter shared by the SSH and
+// local registrations so the rejection is identical in both envs.
+func validateDeployAction(action string) error {
+	switch action {
+	case "", "deploy", deployActionRollback:
+		return nil
+	default:
+		return platform.NewPlatformError(
+			platform.ErrInvalidParameter,
+			fmt.Sprintf("Invalid action %q", action),
+			"Valid values: omit (default deploy) or 'rollback'.",
+		)
+	}
+}
+
+// deployRollbackResponse mirrors deploySSHResponse / deployLocalResponse so
+// every zerops_deploy path carries the WorkSessionState lifecycle signal.
+type deployRollbackResponse struct {
+	*ops.RollbackResult
+	WorkSessionState *WorkSessionState `json:"workSessionState,omitempty"`
+}
+
+// handleRollback reactivates a previous app version on targetService.
+// Resolution (which version, does it still have an artifact) happens
+// before anything is recorded, so a refused rollback leaves no trace in
+// the work session. Once the platform call is made the attempt is recorded
+// exactly like a deploy — a successful rollback counts as a landed deploy
+// for auto-close, since the service is now running a known build.
+func handleRollback(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	client platform.Client,
+	projectID string,
+	stateDir string,
+	recipeProbe RecipeSessionProbe,
+	targetService string,
+	appVersionID string,
+) (*mcp.CallToolResult, any, error) {
+	if targetService == "" {
+		return convertError(platform.NewPlatformError(
+			platform.ErrServiceRequired, "targetService is required for rollback",
+			"Provide targetService — the hostname whose app version to reactivate")), nil, nil
+	}
+	if blocked := requireAdoption(stateDir, recipeProbe, targetService); blocked != nil {
+		return blocked, nil, nil
+	}
+
+	plan, err := ops.PlanRollback(ctx, client, projectID, targetService, appVersionID)
+	if err != nil {
+		return convertError(err), nil, nil
+	}
+
+	attempt := workflow.DeployAttempt{
+		AttemptedAt: time.Now().UTC().Format(time.RFC3339),
+		Strategy:    deployStrategyRollbackLabel,
+	}
+
+	result, err := ops.Rollback(ctx, client, plan)
+	if err != nil {
+		attempt.Error = err.Error()
+		attempt.FailureClass = topology.FailureClassNetwork
+		_ = workflow.RecordDeployAttempt(stateDir, targetService, attempt)
+		return convertError(err, WithRecoveryStatus()), nil, nil
+	}
+
+	onProgress := buildProgressCallback(ctx, req)
+	var timedOut bool
+	result.Process, timedOut = pollManageProcess(ctx, client, result.Process, onProgress)
+
+	switch {
+	case timedOut:
+		result.TimedOut = true
+		result.Message = fmt.Sprintf("Rollback of %s to app version #%d still running. Check with zerops_process.", targetService, result.Sequence)
+		attempt.Error = "rollback process did not finish before poll timeout"
+		attempt.FailureClass = topology.FailureClassOther
+	case result.Process != nil && result.Process.Status == statusFinished:
+		result.Status = statusDeployed
+		result.Message = fmt.Sprintf("Rolled back %s to app version #%d.", targetService, result.Sequence)
+		result.NextActions = nextActionRollbackSuccess
+		attempt.SucceededAt = time.Now().UTC().Format(time.RFC3339)
+	default:
+		status := "UNKNOWN"
+		if result.Process != nil {
+			status = result.Process.Status
+		}
+		result.Status = status
+		result.Message = fmt.Sprintf("Rollback of %s to app version #%d ended with process status %s.", targetService, result.Sequence, status)
+		result.NextActions = fmt.Sprintf("Check runtime logs: zerops_logs serviceHostname=%s severity=ERROR since=5m.", targetService)
+		attempt.Error = "rollback process " + status
+		attempt.FailureClass = topology.FailureClassStart
+	}
+	_ = workflow.RecordDeployAttempt(stateDir, targetService, attempt)
+
+	return jsonResult(deployRollbackResponse{
+		RollbackResult:   result,
+		WorkSessionState: sessionAnnotations(stateDir),
+	}), nil, nil
+}
@@ -0,0 +1,143 @@
+// Tests for: tools/deploy_rollback.go — zerops_deploy action=rollback.
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zcp/internal/auth"
+	"github.com/zeropsio/zcp/internal/platform"
+	"github.com/zeropsio/zcp/internal/runtime"
+	"github.com/zeropsio/zcp/internal/workflow"
+)
+
+func rollbackToolMock() *platform.Mock {
+	return platform.NewMock().
+		WithServices([]platform.ServiceStack{{ID: "svc-1", Name: "appdev", ProjectID: "proj-1"}}).
+		WithAppVersionEvents([]platform.AppVersionEvent{
+			{ID: "av-2", ServiceStackID: "svc-1", Source: "CLI", Status: statusActive, Sequence: 2, Build: &platform.BuildInfo{}},
+			{ID: "av-1", ServiceStackID: "svc-1", Source: "CLI", Status: platform.AppVersionStatusBackup, Sequence: 1, Build: &platform.BuildInfo{}},
+		})
+}
+
+func TestDeployTool_Rollback_SSHMode_RecordsAttempt(t *testing.T) {
+	t.Parallel()
+
+	stateDir := t.TempDir()
+	ws := workflow.NewWorkSession("proj-1", string(workflow.EnvContainer), "rollback", []string{"appdev"})
+	if err := workflow.SaveWorkSession(stateDir, ws); err != nil {
+		t.Fatalf("SaveWorkSession: %v", err)
+	}
+	t.Cleanup(func() { _ = workflow.DeleteWorkSession(stateDir, os.Getpid()) })
+
+	mock := rollbackToolMock().
+		WithProcess(&platform.Process{ID: "proc-deploy-version-av-1", ActionName: "stack.deploy"}).
+		WithProcessScenario("proc-deploy-version-av-1", platform.ProcessScenario{InitialStatus: statusFinished})
+	authInfo := &auth.Info{Token: "t", APIHost: "api.app-prg1.zerops.io", Region: "prg1"}
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
+	RegisterDeploySSH(srv, mock, okHTTP, "proj-1", &stubSSH{}, authInfo, nil, runtime.Info{}, stateDir, testDeployEngine(t), nil)
+
+	result := callTool(t, srv, "zerops_deploy", map[string]any{
+		"action":        "rollback",
+		"targetService": "appdev",
+	})
+	text := getTextContent(t, result)
+	if result.IsError {
+		t.Fatalf("unexpected IsError: %s", text)
+	}
+
+	var parsed struct {
+		Status           string            `json:"status"`
+		AppVersionID     string            `json:"appVersionId"`
+		FromAppVersionID string            `json:"fromAppVersionId"`
+		WorkSessionState *WorkSessionState `json:"workSessionState"`
+	}
+	if err := json.Unmarshal([]byte(text), &parsed); err != nil {
+		t.Fatalf("parse: %v (raw: %s)", err, text)
+	}
+	if parsed.Status != statusDeployed {
+		t.Errorf("status = %q, want DEPLOYED", parsed.Status)
+	}
+	if parsed.AppVersionID != "av-1" || parsed.FromAppVersionID != "av-2" {
+		t.Errorf("versions = %s from %s, want av-1 from av-2", parsed.AppVersionID, parsed.FromAppVersionID)
+	}
+	if mock.CallCounts["DeployAppVersion"] != 1 {
+		t.Errorf("DeployAppVersion calls = %d, want 1", mock.CallCounts["DeployAppVersion"])
+	}
+
+	got, err := workflow.CurrentWorkSession(stateDir)
+	if err != nil || got == nil {
+		t.Fatalf("CurrentWorkSession: %v", err)
+	}
+	attempts := got.Deploys["appdev"]
+	if len(attempts) != 1 {
+		t.Fatalf("deploy attempts = %d, want 1", len(attempts))
+	}
+	if attempts[0].Strategy != deployStrategyRollbackLabel || attempts[0].SucceededAt == "" {
+		t.Errorf("attempt = %+v, want succeeded rollback", attempts[0])
+	}
+}
+
+func TestDeployTool_Rollback_LocalMode_ProcessFailed(t *testing.T) {
+	t.Parallel()
+
+	mock := rollbackToolMock().
+		WithProcess(&platform.Process{ID: "proc-deploy-version-av-1", ActionName: "stack.deploy"}).
+		WithProcessScenario("proc-deploy-version-av-1", platform.ProcessScenario{InitialStatus: statusFailed})
+	authInfo := &auth.Info{Token: "t", APIHost: "api.app-prg1.zerops.io", Region: "prg1"}
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
+	RegisterDeployLocal(srv, mock, okHTTP, "proj-1", authInfo, nil, "", nil, nil)
+
+	result := callTool(t, srv, "zerops_deploy", map[string]any{
+		"action":        "rollback",
+		"targetService": "appdev",
+		"appVersionId":  "av-1",
+	})
+	text := getTextContent(t, result)
+	if result.IsError {
+		t.Fatalf("unexpected IsError: %s", text)
+	}
+	if !strings.Contains(text, `"status":"FAILED"`) {
+		t.Errorf("expected FAILED status in response, got: %s", text)
+	}
+}
+
+func TestDeployTool_Rollback_NoCandidate(t *testing.T) {
+	t.Parallel()
+
+	mock := platform.NewMock().
+		WithServices([]platform.ServiceStack{{ID: "svc-1", Name: "appdev", ProjectID: "proj-1"}})
+	authInfo := &auth.Info{Token: "t", APIHost: "api.app-prg1.zerops.io", Region: "prg1"}
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
+	RegisterDeployLocal(srv, mock, okHTTP, "proj-1", authInfo, nil, "", nil, nil)
+
+	result := callTool(t, srv, "zerops_deploy", map[string]any{
+		"action":        "rollback",
+		"targetService": "appdev",
+	})
+	if !result.IsError {
+		t.Fatalf("expected IsError, got: %s", getTextContent(t, result))
+	}
+	if mock.CallCounts["DeployAppVersion"] != 0 {
+		t.Error("DeployAppVersion must not be called when no candidate exists")
+	}
+}
+
+func TestValidateDeployAction(t *testing.T) {
+	t.Parallel()
+
+	for _, action := range []string{"", "deploy", "rollback"} {
+		if err := validateDeployAction(action); err != nil {
+			t.Errorf("validateDeployAction(%q) = %v, want nil", action, err)
+		}
+	}
+	if err := validateDeployAction("revert"); err == nil {
+		t.Error("validateDeployAction(revert) = nil, want error")
+	}
+}
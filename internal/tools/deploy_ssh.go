@@ -75,6 +75,8 @@ const deployStrategyZCLILabel = "zcli"
 // depend on) and leaves it off on cross-deploys (dev→stage would otherwise
 // carry the dev container's .git across).
 type DeploySSHInput struct {
+	Action        string `json:"action,omitempty"`
+	AppVersionID  string `json:"appVersionId,omitempty"`
 	SourceService string `json:"sourceService,omitempty"`
 	TargetService string `json:"targetService"`
 	Setup         string `json:"setup,omitempty"`
@@ -92,6 +94,8 @@ func deploySSHInputSchema() *jsonschema.Schema {
 		"workingDir":    {Type: "string", Description: "Container path for deploy. Default: /var/www. In container mode: omit entirely (always correct)."},
 		"strategy":      {Type: "string", Description: "Deploy strategy. Omit for default push (direct deploy to the Zerops service). Set to 'git-push' to push committed code to an external git remote (requires GIT_TOKEN project env var). BEFORE using git-push: ask the user if they want push-only or full CI/CD. LLM should commit changes via SSH BEFORE calling git-push."},
 		"remoteUrl":     {Type: "string", Description: "Git remote URL (HTTPS). Required for strategy=git-push on first push. Omit on subsequent pushes if remote already configured."},
+		"action":        deployActionSchema(),
+		"appVersionId":  appVersionIDSchema(),
 		"branch":        {Type: "string", Description: "Git branch name for git-push. Default: main."},
 	}, "targetService")
 }
@@ -128,6 +132,7 @@ func RegisterDeploySSH(
 	desc += "Requires zerops.yaml. Self-deploy: set targetService only. Cross-deploy: set sourceService + targetService. " +
 		"Self-deploying services MUST use deployFiles: [.] — otherwise source files are destroyed. " +
 		"strategy=git-push: pushes committed code to an external git remote. " +
+		"action=rollback: no-rebuild revert. " +
 		"Channel-blocking 60–120s — serialize deploys, no parallel zerops_* calls (returns Not connected)."
 
 	mcp.AddTool(srv, &mcp.Tool{
@@ -139,6 +144,15 @@ func RegisterDeploySSH(
 			DestructiveHint: boolPtr(true),
 		},
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input DeploySSHInput) (*mcp.CallToolResult, any, error) {
+		if err := validateDeployAction(input.Action); err != nil {
+			return convertError(err, WithRecoveryStatus()), nil, nil
+		}
+		// Rollback reactivates an existing build — no zerops.yaml, no
+		// pre-flight, no strategy. Routed before any of those gates.
+		if input.Action == deployActionRollback {
+			return handleRollback(ctx, req, client, projectID, stateDir, recipeProbe, input.TargetService, input.AppVersionID)
+		}
+
 		// Strategy validation. "manual" is a ServiceMeta declaration only —
 		// calling zerops_deploy on a manual-strategy service is a contradiction
 		// ZCP refuses to resolve silently.
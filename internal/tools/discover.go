@@ -25,6 +25,9 @@ type DiscoverInput struct {
 	Service          string   `json:"service,omitempty"`
 	IncludeEnvs      FlexBool `json:"includeEnvs,omitempty"`
 	IncludeEnvValues FlexBool `json:"includeEnvValues,omitempty"`
+	Drift            FlexBool `json:"drift,omitempty"`
+	Content          string   `json:"content,omitempty"`
+	FilePath         string   `json:"filePath,omitempty"`
 }
 
 // discoverInputSchema is the explicit InputSchema for zerops_discover.
@@ -41,6 +44,15 @@ func discoverInputSchema() *jsonschema.Schema {
 		},
 		"includeEnvs":      flexBoolSchema("Include env var keys (service-level and project-level). Returns keys and annotations only — no values. Sufficient for bootstrap, deploy, recipe validation."),
 		"includeEnvValues": flexBoolSchema("Also include actual env var values. Use only for troubleshooting when keys-only is insufficient (e.g. empty values, wrong formats, unresolved refs). For .env generation use zerops_env generate-dotenv instead."),
+		"drift":            flexBoolSchema("Compare a declared import YAML against the live project instead of listing services. Returns typed entries (added / removed / changed) for services, type, mode, autoscaling, ports, subdomain access, build config and env keys, each with the call that fixes it. Env values are never included."),
+		"content": {
+			Type:        "string",
+			Description: "drift=true only: inline declared import YAML. Provide either content or filePath.",
+		},
+		"filePath": {
+			Type:        "string",
+			Description: "drift=true only: path to the declared import YAML. Default: " + ops.DefaultDriftFile + " in the working directory.",
+		},
 	})
 }
 
@@ -48,7 +60,7 @@ func discoverInputSchema() *jsonschema.Schema {
 func RegisterDiscover(srv *mcp.Server, client platform.Client, projectID, stateDir string) {
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "zerops_discover",
-		Description: "Discover project and service information. Filter by service hostname or list all. Use includeEnvs=true to read env var keys. Add includeEnvValues=true only when you need actual secret values (troubleshooting).",
+		Description: "Discover project and service information. Filter by service hostname or list all. Use includeEnvs=true to read env var keys. Add includeEnvValues=true only when you need actual secret values (troubleshooting). drift=true compares a declared import YAML against the live project.",
 		InputSchema: discoverInputSchema(),
 		Annotations: &mcp.ToolAnnotations{
 			Title:          "Discover project and services",
@@ -56,6 +68,13 @@ func RegisterDiscover(srv *mcp.Server, client platform.Client, projectID, stateD
 			IdempotentHint: true,
 		},
 	}, func(ctx context.Context, _ *mcp.CallToolRequest, input DiscoverInput) (*mcp.CallToolResult, any, error) {
+		if input.Drift.Bool() {
+			report, err := ops.DetectDrift(ctx, client, projectID, input.Content, input.FilePath)
+			if err != nil {
+				return convertError(err), nil, nil
+			}
+			return jsonResult(report), nil, nil
+		}
 		result, err := ops.Discover(ctx, client, projectID, input.Service, input.IncludeEnvs.Bool(), input.IncludeEnvValues.Bool())
 		if err != nil {
 			return convertError(err), nil, nil
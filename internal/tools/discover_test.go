@@ -248,3 +248,30 @@ func TestDiscoverTool_Error(t *testing.T) {
 		t.Error("expected IsError for API error")
 	}
 }
+
+func TestDiscoverTool_Drift(t *testing.T) {
+	t.Parallel()
+	mock := platform.NewMock().
+		WithServices([]platform.ServiceStack{
+			{ID: "svc-1", Name: "api", Status: statusActive, ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "nodejs@20"}},
+		})
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
+	RegisterDiscover(srv, mock, "proj-1", "")
+
+	result := callTool(t, srv, "zerops_discover", map[string]any{
+		"drift":   "true",
+		"content": "services:\n  - hostname: api\n    type: nodejs@22\n",
+	})
+	if result.IsError {
+		t.Fatalf("unexpected IsError: %s", getTextContent(t, result))
+	}
+
+	var report ops.DriftReport
+	if err := json.Unmarshal([]byte(getTextContent(t, result)), &report); err != nil {
+		t.Fatalf("failed to parse result: %v", err)
+	}
+	if report.InSync || len(report.Entries) != 1 || report.Entries[0].Category != ops.DriftCategoryType {
+		t.Errorf("report = %+v, want a single type drift", report)
+	}
+}
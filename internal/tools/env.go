@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"github.com/zeropsio/zcp/internal/ops"
 	"github.com/zeropsio/zcp/internal/platform"
 	"github.com/zeropsio/zcp/internal/topology"
+	"github.com/zeropsio/zcp/internal/workflow"
 )
 
 // EnvInput is the input type for zerops_env.
@@ -26,6 +28,14 @@ type EnvInput struct {
 	Project         FlexBool `json:"project,omitempty"`
 	Variables       []string `json:"variables,omitempty"`
 	SkipRestart     FlexBool `json:"skipRestart,omitempty"`
+	Source          string   `json:"source,omitempty"`
+	Target          string   `json:"target,omitempty"`
+	Exclude         []string `json:"exclude,omitempty"`
+	Format          string   `json:"format,omitempty"`
+	FilePath        string   `json:"filePath,omitempty"`
+	DryRun          FlexBool `json:"dryRun,omitempty"`
+	Key             string   `json:"key,omitempty"`
+	Generator       string   `json:"generator,omitempty"`
 }
 
 // envInputSchema is the explicit InputSchema for zerops_env. It
@@ -41,18 +51,49 @@ func envInputSchema() *jsonschema.Schema {
 	return objectSchema(map[string]*jsonschema.Schema{
 		"action": {
 			Type:        "string",
-			Enum:        []any{"get", "set", "delete", "generate-dotenv"},
-			Description: "get: return env var keys and values for a service (serviceHostname) or the project (project=true). set: upsert KEY=VALUE pairs. delete: remove keys. generate-dotenv: reads a local zerops.yaml and writes a resolved .env (requires zerops.yaml in the working directory).",
+			Enum:        []any{"get", "set", "delete", "generate-dotenv", "diff", "promote", "graph", "import", "rotate"},
+			Description: "get: return env var keys and values for a service (serviceHostname) or the project (project=true). set: upsert KEY=VALUE pairs. delete: remove keys. generate-dotenv: reads a local zerops.yaml and writes a resolved .env (requires zerops.yaml in the working directory). diff: added/removed/changed service env vars between source and target (sensitive values masked). promote: copy added and changed keys from source to target in one write, then restart target once. graph: project-wide ${host_var} reference graph (service, project and zerops.yaml vars) with dangling refs, cycles, self-shadows, project shadowing and refs to stopped or deleted services. import: load a dotenv file (filePath) into a service or the project; values with <@...> are generated locally and never echoed. rotate: replace secret key with a freshly generated value, then restart and verify every consumer one at a time, halting on failure; history (hashes only) goes to .zcp/state.",
 		},
 		"serviceHostname": {
 			Type:        "string",
 			Description: "Hostname of the service to operate on. Required for get/set/delete unless project=true. Ignored by generate-dotenv (which reads zerops.yaml instead).",
 		},
-		"project": flexBoolSchema("Set to true to operate on project-level env vars instead of service-level. Valid for get/set/delete."),
+		"project": flexBoolSchema("Set to true to operate on project-level env vars instead of service-level. Valid for get/set/delete/import."),
 		"variables": {
 			Type:        "array",
 			Items:       &jsonschema.Schema{Type: "string"},
-			Description: "List of env vars. set: KEY=VALUE strings (literal values). delete: KEY names only. Ignored by get and generate-dotenv.",
+			Description: "List of env vars. set: KEY=VALUE strings (literal values). delete: KEY names only. promote: KEY names to promote (default: every added and changed key). Ignored by get, diff and generate-dotenv.",
+		},
+		"source": {
+			Type:        "string",
+			Description: "diff/promote: service to copy from, usually the dev half (e.g. appdev). Defaults to serviceHostname.",
+		},
+		"target": {
+			Type:        "string",
+			Description: "diff/promote: service to copy to. Defaults to the stage half of source's standard pair.",
+		},
+		"exclude": {
+			Type:        "array",
+			Items:       &jsonschema.Schema{Type: "string"},
+			Description: "diff/promote: keys that must differ per half, never diffed or promoted. Exact names or globs like *_URL.",
+		},
+		"filePath": {
+			Type:        "string",
+			Description: "import: path to a dotenv file (quotes, multiline values, export prefixes and comments allowed). Single-quoted values are stored literally.",
+		},
+		"dryRun": flexBoolSchema("import: return the diff against the current env vars without writing anything. rotate: list the consumers and restart order without rotating."),
+		"key": {
+			Type:        "string",
+			Description: "rotate: name of the existing env var to rotate, on serviceHostname or the project (project=true).",
+		},
+		"generator": {
+			Type:        "string",
+			Description: "rotate: preprocessor expression minting the new value. Default <@generateRandomString(<32>)>.",
+		},
+		"format": {
+			Type:        "string",
+			Enum:        []any{"mermaid", "dot"},
+			Description: "graph: rendering of the service-level graph returned next to the JSON. Default mermaid.",
 		},
 		"skipRestart": flexBoolSchema("set/delete: skip the automatic service restart after the env change. Default false (auto-restart affected services so the new value takes effect). Pass true only if you will redeploy immediately afterwards and the restart would be wasted."),
 	}, "action")
@@ -70,13 +111,44 @@ type envChangeResult struct {
 	NextActions        string              `json:"nextActions,omitempty"`
 }
 
+// envPromoteResult is the promote response: what was copied plus the
+// single target restart.
+type envPromoteResult struct {
+	Diff     *ops.EnvDiff `json:"diff"`
+	Promoted []string     `json:"promoted"`
+	Warnings []string     `json:"warnings,omitempty"`
+	envChangeResult
+}
+
+// envImportResult is the import response: the per-key diff plus the
+// write process and restarts.
+type envImportResult struct {
+	File    string               `json:"file"`
+	Target  string               `json:"target"`
+	Summary string               `json:"summary"`
+	DryRun  bool                 `json:"dryRun,omitempty"`
+	Entries []ops.EnvImportEntry `json:"entries"`
+	envChangeResult
+}
+
+// envGraphResult is the graph response: the JSON graph plus a compact
+// service-level rendering.
+type envGraphResult struct {
+	*ops.EnvGraph
+	Format string `json:"format"`
+	Render string `json:"render"`
+}
+
 // RegisterEnv registers the zerops_env tool.
 // selfHostname is the hostname of the service running ZCP — it is excluded
 // from auto-restart so the tool does not kill its own MCP connection.
-func RegisterEnv(srv *mcp.Server, client platform.Client, projectID, selfHostname string) {
+// fetcher and httpClient back the verify run after each rotate restart.
+// stateDir resolves the stage half of a standard pair for diff/promote and
+// the zerops.yaml env vars and tracked services for graph.
+func RegisterEnv(srv *mcp.Server, client platform.Client, fetcher platform.LogFetcher, httpClient ops.HTTPDoer, projectID, selfHostname, stateDir string) {
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "zerops_env",
-		Description: "Manage env vars. Actions: get (read), set (upsert), delete, generate-dotenv (write local .env from local zerops.yaml). Scope: service via serviceHostname, or project=true. set values expand <@...> via zParser; encoding prefixes (base64:, hex:) are rejected. Response 'stored' verifies what landed. set/delete auto-restart affected services unless skipRestart=true. For bulk env reads across many services, prefer zerops_discover includeEnvs=true.",
+		Description: "Manage env vars. Actions: get, set (upsert), delete, generate-dotenv (local .env from zerops.yaml), diff/promote (dev → stage half), graph (${ref} problems), import (dotenv file), rotate (secret rollout). Scope: serviceHostname or project=true. set values expand <@...> via zParser; encoding prefixes (base64:, hex:) are rejected. Response 'stored' verifies what landed. Writes auto-restart affected services unless skipRestart=true. For bulk reads prefer zerops_discover includeEnvs=true.",
 		InputSchema: envInputSchema(),
 		Annotations: &mcp.ToolAnnotations{
 			Title:           "Manage environment variables",
@@ -133,10 +205,55 @@ func RegisterEnv(srv *mcp.Server, client platform.Client, projectID, selfHostnam
 				return convertError(err), nil, nil
 			}
 			return jsonResult(result), nil, nil
+		case "diff":
+			source := cmp.Or(input.Source, input.ServiceHostname)
+			diff, err := ops.EnvDiffServices(ctx, client, projectID, source, envPairTarget(stateDir, source, input.Target), input.Exclude)
+			if err != nil {
+				return convertError(err), nil, nil
+			}
+			return jsonResult(diff), nil, nil
+		case "promote":
+			source := cmp.Or(input.Source, input.ServiceHostname)
+			promoted, err := ops.EnvPromote(ctx, client, projectID, source, envPairTarget(stateDir, source, input.Target), input.Variables, input.Exclude)
+			if err != nil {
+				return convertError(err), nil, nil
+			}
+			resp := envPromoteResult{Diff: promoted.Diff, Promoted: promoted.Promoted, Warnings: promoted.Warnings}
+			if len(promoted.Promoted) == 0 {
+				resp.NextActions = "Nothing to promote — " + promoted.Diff.Target + " already has every non-excluded key of " + promoted.Diff.Source + "."
+				return jsonResult(resp), nil, nil
+			}
+			resp.Process = promoted.Process
+			if resp.Process != nil {
+				resp.Process, _ = pollManageProcess(ctx, client, resp.Process, onProgress)
+			}
+			restart := EnvInput{ServiceHostname: promoted.Diff.Target, SkipRestart: input.SkipRestart}
+			applyAutoRestart(ctx, client, projectID, restart, selfHostname, &resp.envChangeResult, onProgress)
+			return jsonResult(resp), nil, nil
+		case "import":
+			imported, err := ops.EnvImport(ctx, client, projectID, input.ServiceHostname, input.Project.Bool(), input.FilePath, input.DryRun.Bool())
+			if err != nil {
+				return convertError(err), nil, nil
+			}
+			resp := envImportResult{File: imported.File, Target: imported.Target, Summary: imported.Summary, DryRun: imported.DryRun, Entries: imported.Entries}
+			if imported.DryRun {
+				return jsonResult(resp), nil, nil
+			}
+			if imported.Process == nil {
+				resp.NextActions = "Nothing to write — every key already matches or is kept."
+				return jsonResult(resp), nil, nil
+			}
+			resp.Process, _ = pollManageProcess(ctx, client, imported.Process, onProgress)
+			applyAutoRestart(ctx, client, projectID, input, selfHostname, &resp.envChangeResult, onProgress)
+			return jsonResult(resp), nil, nil
+		case "rotate":
+			return handleEnvRotate(ctx, client, fetcher, httpClient, projectID, selfHostname, stateDir, input, onProgress)
+		case "graph":
+			return handleEnvGraph(ctx, client, projectID, stateDir, input.Format)
 		case "":
 			return convertError(platform.NewPlatformError(
 				platform.ErrInvalidParameter, "Action is required",
-				"Use get, set, delete, or generate-dotenv")), nil, nil
+				"Use get, set, delete, generate-dotenv, diff, promote, graph, import, or rotate")), nil, nil
 		default:
 			// Invalid-action errors guided agents toward generate-dotenv in the
 			// past, which fails from arbitrary working directories (see LOG.txt
@@ -144,7 +261,7 @@ func RegisterEnv(srv *mcp.Server, client platform.Client, projectID, selfHostnam
 			// meant (get) and at zerops_discover for bulk reads.
 			return convertError(platform.NewPlatformError(
 				platform.ErrInvalidParameter, "Invalid action '"+input.Action+"'",
-				"Valid actions: get, set, delete, generate-dotenv. To read env vars for a service use get (or zerops_discover includeEnvs=true for all services at once). generate-dotenv is only for writing a local .env file from a local zerops.yaml.")), nil, nil
+				"Valid actions: get, set, delete, generate-dotenv, diff, promote, graph, import, rotate. To read env vars for a service use get (or zerops_discover includeEnvs=true for all services at once). generate-dotenv is only for writing a local .env file from a local zerops.yaml.")), nil, nil
 		}
 	})
 }
@@ -203,6 +320,87 @@ func applyAutoRestart(
 	}
 }
 
+// envPairTarget defaults target to the stage half of source's standard
+// pair. Returns target unchanged when set, and "" when source is not the
+// dev half of a tracked pair.
+func envPairTarget(stateDir, source, target string) string {
+	if target != "" || stateDir == "" || source == "" {
+		return target
+	}
+	meta, err := workflow.FindServiceMeta(stateDir, source)
+	if err != nil || meta == nil || meta.Hostname != source {
+		return ""
+	}
+	return meta.StageHostname
+}
+
+// handleEnvRotate rotates a secret and rolls it out. Each consumer is
+// verified with the same checks as zerops_verify, including the declared
+// checks from .zcp/verify.yaml.
+func handleEnvRotate(
+	ctx context.Context,
+	client platform.Client,
+	fetcher platform.LogFetcher,
+	httpClient ops.HTTPDoer,
+	projectID, selfHostname, stateDir string,
+	input EnvInput,
+	onProgress ops.ProgressCallback,
+) (*mcp.CallToolResult, any, error) {
+	declared, err := loadDeclaredChecks(stateDir)
+	if err != nil {
+		return convertError(err), nil, nil
+	}
+	opts := ops.EnvRotateOptions{
+		Key:          input.Key,
+		Hostname:     input.ServiceHostname,
+		Project:      input.Project.Bool(),
+		Generator:    input.Generator,
+		DeclaredEnv:  connectivityDeclaredEnv(stateDir),
+		SelfHostname: selfHostname,
+		DryRun:       input.DryRun.Bool(),
+	}
+	poll := func(ctx context.Context, proc *platform.Process) *platform.Process {
+		polled, _ := pollManageProcess(ctx, client, proc, onProgress)
+		return polled
+	}
+	verify := func(ctx context.Context, hostname string) (*ops.VerifyResult, error) {
+		return ops.Verify(ctx, client, fetcher, httpClient, projectID, hostname, declared)
+	}
+	result, err := ops.EnvRotate(ctx, client, projectID, stateDir, opts, poll, verify)
+	if err != nil {
+		return convertError(err), nil, nil
+	}
+	return jsonResult(result), nil, nil
+}
+
+// handleEnvGraph builds the project env reference graph. zerops.yaml
+// run.envVariables of adopted runtimes are overlaid on the API env, and
+// hostnames tracked in service metas tell deleted services from typos.
+func handleEnvGraph(ctx context.Context, client platform.Client, projectID, stateDir, format string) (*mcp.CallToolResult, any, error) {
+	format = cmp.Or(format, "mermaid")
+	if format != "mermaid" && format != "dot" {
+		return convertError(platform.NewPlatformError(platform.ErrInvalidParameter,
+			fmt.Sprintf("invalid format %q", format), "Use format=mermaid or format=dot")), nil, nil
+	}
+	var known []string
+	if stateDir != "" {
+		if metas, err := workflow.ListServiceMetas(stateDir); err == nil {
+			for _, meta := range metas {
+				known = append(known, meta.Hostnames()...)
+			}
+		}
+	}
+	graph, err := ops.BuildEnvGraph(ctx, client, projectID, connectivityDeclaredEnv(stateDir), known)
+	if err != nil {
+		return convertError(err), nil, nil
+	}
+	resp := envGraphResult{EnvGraph: graph, Format: format, Render: graph.Mermaid()}
+	if format == "dot" {
+		resp.Render = graph.DOT()
+	}
+	return jsonResult(resp), nil, nil
+}
+
 type restartTarget struct {
 	id       string
 	hostname string
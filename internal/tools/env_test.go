@@ -4,11 +4,15 @@ package tools
 
 import (
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zcp/internal/ops"
 	"github.com/zeropsio/zcp/internal/platform"
+	"github.com/zeropsio/zcp/internal/workflow"
 )
 
 // TestEnvTool_GetAction_Success is the new happy path for `get` — the
@@ -30,7 +34,7 @@ func TestEnvTool_GetAction_Success(t *testing.T) {
 		})
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterEnv(srv, mock, "proj-1", "")
+	RegisterEnv(srv, mock, nil, nil, "proj-1", "", "")
 
 	result := callTool(t, srv, "zerops_env", map[string]any{
 		"action": "get", "serviceHostname": "db",
@@ -68,7 +72,7 @@ func TestEnvTool_GetAction_RequiresTarget(t *testing.T) {
 	mock := platform.NewMock()
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterEnv(srv, mock, "proj-1", "")
+	RegisterEnv(srv, mock, nil, nil, "proj-1", "", "")
 
 	result := callTool(t, srv, "zerops_env", map[string]any{"action": "get"})
 
@@ -99,7 +103,7 @@ func TestEnvTool_GetAction_StringifiedBoolProject(t *testing.T) {
 		})
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterEnv(srv, mock, "proj-1", "")
+	RegisterEnv(srv, mock, nil, nil, "proj-1", "", "")
 
 	result := callTool(t, srv, "zerops_env", map[string]any{
 		"action":  "get",
@@ -121,7 +125,7 @@ func TestEnvTool_Set_PollsToFinished(t *testing.T) {
 		})
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterEnv(srv, mock, "proj-1", "")
+	RegisterEnv(srv, mock, nil, nil, "proj-1", "", "")
 
 	result := callTool(t, srv, "zerops_env", map[string]any{
 		"action":          "set",
@@ -157,7 +161,7 @@ func TestEnvTool_Delete_PollsToFinished(t *testing.T) {
 		})
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterEnv(srv, mock, "proj-1", "")
+	RegisterEnv(srv, mock, nil, nil, "proj-1", "", "")
 
 	result := callTool(t, srv, "zerops_env", map[string]any{
 		"action":          "delete",
@@ -192,7 +196,7 @@ func TestEnvTool_EmptyAction(t *testing.T) {
 	mock := platform.NewMock()
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterEnv(srv, mock, "proj-1", "")
+	RegisterEnv(srv, mock, nil, nil, "proj-1", "", "")
 
 	err := callToolMayError(t, srv, "zerops_env", map[string]any{
 		"action": "", "serviceHostname": "api",
@@ -215,7 +219,7 @@ func TestEnvTool_InvalidAction(t *testing.T) {
 	mock := platform.NewMock()
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterEnv(srv, mock, "proj-1", "")
+	RegisterEnv(srv, mock, nil, nil, "proj-1", "", "")
 
 	err := callToolMayError(t, srv, "zerops_env", map[string]any{
 		"action": "wipe", "serviceHostname": "api",
@@ -235,3 +239,166 @@ func TestEnvTool_InvalidAction(t *testing.T) {
 		}
 	}
 }
+
+func TestEnvTool_DiffAndPromote(t *testing.T) {
+	t.Parallel()
+	stateDir := t.TempDir()
+	if err := workflow.WriteServiceMeta(stateDir, &workflow.ServiceMeta{
+		Hostname: "appdev", StageHostname: "appstage", BootstrapSession: "s1", BootstrappedAt: "2026-03-04T12:00:00Z",
+	}); err != nil {
+		t.Fatalf("WriteServiceMeta: %v", err)
+	}
+	mock := platform.NewMock().
+		WithServices([]platform.ServiceStack{
+			{ID: "svc-dev", Name: "appdev", Status: statusActive},
+			{ID: "svc-stage", Name: "appstage", Status: statusActive},
+		}).
+		WithServiceEnv("svc-dev", []platform.EnvVar{{Key: "API_TOKEN", Content: "t1"}, {Key: "MODE", Content: "dev"}}).
+		WithServiceEnv("svc-stage", []platform.EnvVar{{Key: "API_TOKEN", Content: "t0"}, {Key: "MODE", Content: "stage"}}).
+		WithProcess(&platform.Process{ID: "proc-envset-svc-stage", ActionName: "envSet", Status: statusFinished}).
+		WithProcess(&platform.Process{ID: "proc-restart-svc-stage", ActionName: "restart", Status: statusFinished})
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
+	RegisterEnv(srv, mock, nil, nil, "proj-1", "", stateDir)
+
+	// target defaults to the stage half of appdev's pair.
+	result := callTool(t, srv, "zerops_env", map[string]any{"action": "diff", "source": "appdev"})
+	text := getTextContent(t, result)
+	if result.IsError || !strings.Contains(text, `"target":"appstage"`) || strings.Contains(text, "t1") {
+		t.Fatalf("diff = %s; want appstage target with API_TOKEN masked", text)
+	}
+
+	result = callTool(t, srv, "zerops_env", map[string]any{"action": "promote", "source": "appdev", "exclude": []string{"MODE"}})
+	if result.IsError {
+		t.Fatalf("unexpected IsError: %s", getTextContent(t, result))
+	}
+	var parsed struct {
+		Promoted          []string `json:"promoted"`
+		RestartedServices []string `json:"restartedServices"`
+	}
+	if err := json.Unmarshal([]byte(getTextContent(t, result)), &parsed); err != nil {
+		t.Fatalf("parse result: %v", err)
+	}
+	if strings.Join(parsed.Promoted, ",") != "API_TOKEN" || strings.Join(parsed.RestartedServices, ",") != "appstage" {
+		t.Errorf("promote = %+v, want API_TOKEN promoted and one appstage restart", parsed)
+	}
+}
+
+func TestEnvTool_PromoteWithoutTarget(t *testing.T) {
+	t.Parallel()
+	mock := platform.NewMock().WithServices([]platform.ServiceStack{{ID: "svc-1", Name: "api"}})
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
+	RegisterEnv(srv, mock, nil, nil, "proj-1", "", t.TempDir())
+
+	result := callTool(t, srv, "zerops_env", map[string]any{"action": "promote", "serviceHostname": "api"})
+	if !result.IsError || !strings.Contains(getTextContent(t, result), "source and a target") {
+		t.Errorf("expected missing-target error, got %s", getTextContent(t, result))
+	}
+}
+
+func TestEnvTool_Graph(t *testing.T) {
+	t.Parallel()
+	mock := platform.NewMock().
+		WithServices([]platform.ServiceStack{
+			{ID: "svc-api", Name: "api", Status: statusActive},
+			{ID: "svc-db", Name: "db", Status: statusActive},
+		}).
+		WithServiceEnv("svc-api", []platform.EnvVar{{Key: "DB_URL", Content: "${db_hostname}:${db_port}"}}).
+		WithServiceEnv("svc-db", []platform.EnvVar{{Key: "hostname", Content: "db"}})
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
+	RegisterEnv(srv, mock, nil, nil, "proj-1", "", t.TempDir())
+
+	result := callTool(t, srv, "zerops_env", map[string]any{"action": "graph", "format": "dot"})
+	if result.IsError {
+		t.Fatalf("unexpected IsError: %s", getTextContent(t, result))
+	}
+	var parsed struct {
+		Findings []ops.EnvGraphFinding `json:"findings"`
+		Edges    []ops.EnvGraphEdge    `json:"edges"`
+		Format   string                `json:"format"`
+		Render   string                `json:"render"`
+	}
+	if err := json.Unmarshal([]byte(getTextContent(t, result)), &parsed); err != nil {
+		t.Fatalf("parse result: %v", err)
+	}
+	if len(parsed.Edges) != 1 || len(parsed.Findings) != 1 || parsed.Findings[0].Reference != "${db_port}" {
+		t.Errorf("graph = %+v, want one edge and a dangling ${db_port}", parsed)
+	}
+	if parsed.Format != "dot" || !strings.HasPrefix(parsed.Render, "digraph env {") {
+		t.Errorf("render = %q", parsed.Render)
+	}
+
+	result = callTool(t, srv, "zerops_env", map[string]any{"action": "graph", "format": "svg"})
+	if !result.IsError {
+		t.Error("unknown format should be rejected")
+	}
+}
+
+func TestEnvTool_Import(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("export MODE=prod\nTOKEN=<@generateRandomString(<24>)>\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	mock := platform.NewMock().
+		WithServices([]platform.ServiceStack{{ID: "svc-api", Name: "api", Status: statusActive}}).
+		WithServiceEnv("svc-api", []platform.EnvVar{{Key: "MODE", Content: "dev"}}).
+		WithProcess(&platform.Process{ID: "proc-envset-svc-api", ActionName: "envSet", Status: statusFinished}).
+		WithProcess(&platform.Process{ID: "proc-restart-svc-api", ActionName: "restart", Status: statusFinished})
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
+	RegisterEnv(srv, mock, nil, nil, "proj-1", "", "")
+
+	result := callTool(t, srv, "zerops_env", map[string]any{"action": "import", "serviceHostname": "api", "filePath": path, "dryRun": true})
+	text := getTextContent(t, result)
+	if result.IsError || !strings.Contains(text, `"summary":"1 added, 1 changed, 0 unchanged, 0 kept"`) || strings.Contains(text, "restartedServices") {
+		t.Fatalf("dry run = %s", text)
+	}
+
+	result = callTool(t, srv, "zerops_env", map[string]any{"action": "import", "serviceHostname": "api", "filePath": path})
+	if result.IsError {
+		t.Fatalf("unexpected IsError: %s", getTextContent(t, result))
+	}
+	var parsed struct {
+		Entries           []ops.EnvImportEntry `json:"entries"`
+		RestartedServices []string             `json:"restartedServices"`
+	}
+	if err := json.Unmarshal([]byte(getTextContent(t, result)), &parsed); err != nil {
+		t.Fatalf("parse result: %v", err)
+	}
+	if len(parsed.Entries) != 2 || parsed.Entries[1].Value != "[generated]" || strings.Join(parsed.RestartedServices, ",") != "api" {
+		t.Errorf("import = %+v", parsed)
+	}
+}
+
+func TestEnvTool_Rotate(t *testing.T) {
+	t.Parallel()
+	stateDir := t.TempDir()
+	mock := platform.NewMock().
+		WithServices([]platform.ServiceStack{
+			{ID: "svc-app", Name: "app", Status: statusActive, ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "nodejs@22", ServiceStackTypeCategoryName: "USER"}},
+		}).
+		WithProjectEnv([]platform.EnvVar{{ID: "env-1", Key: "APP_KEY", Content: "old"}}).
+		WithProcess(&platform.Process{ID: "proc-projenvset", ActionName: "envSet", Status: statusFinished}).
+		WithProcess(&platform.Process{ID: "proc-restart-svc-app", ActionName: "restart", Status: statusFinished})
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
+	RegisterEnv(srv, mock, platform.NewMockLogFetcher(), nil, "proj-1", "", stateDir)
+
+	result := callTool(t, srv, "zerops_env", map[string]any{"action": "rotate", "key": "APP_KEY", "project": true})
+	if result.IsError {
+		t.Fatalf("unexpected IsError: %s", getTextContent(t, result))
+	}
+	var parsed ops.EnvRotateResult
+	if err := json.Unmarshal([]byte(getTextContent(t, result)), &parsed); err != nil {
+		t.Fatalf("parse result: %v", err)
+	}
+	if len(parsed.Steps) != 1 || parsed.Steps[0].Status != ops.RotateStepRestarted || parsed.Steps[0].Verify != ops.StatusHealthy || parsed.Halted != "" {
+		t.Errorf("rotate = %+v", parsed)
+	}
+	if _, err := os.Stat(filepath.Join(stateDir, ops.RotationHistoryFile)); err != nil {
+		t.Errorf("history not written: %v", err)
+	}
+}
@@ -22,6 +22,7 @@ type ImportInput struct {
 	Content  string   `json:"content,omitempty"`
 	FilePath string   `json:"filePath,omitempty"`
 	Override FlexBool `json:"override,omitempty"`
+	DryRun   FlexBool `json:"dryRun,omitempty"`
 }
 
 // importInputSchema is the explicit InputSchema for zerops_import. Lives
@@ -38,6 +39,7 @@ func importInputSchema() *jsonschema.Schema {
 			Description: "Path to a YAML file containing the import definition. Provide either filePath or content.",
 		},
 		"override": flexBoolSchema("Set override: true on every imported service so the API replaces existing service stacks with matching hostnames. DESTRUCTIVE: replacement tears down the previous container, deployed code, env vars, and the SSHFS mount on those services — back up any uncommitted work first. The response Warnings name the replaced hostnames so the destruction is never silent. Required when re-importing a service that already exists (e.g. to transition READY_TO_DEPLOY to ACTIVE by adding startWithoutCode: true)."),
+		"dryRun":   flexBoolSchema("Return a plan instead of importing: services to create, hostname collisions with existing services (with type/mode/scaling differences against the live service), env secrets that would shadow existing project or service vars, schema errors, and preprocessor-expanded values. Submits nothing and needs no active workflow."),
 	})
 }
 
@@ -50,13 +52,22 @@ func importInputSchema() *jsonschema.Schema {
 func RegisterImport(srv *mcp.Server, client platform.Client, projectID string, engine *workflow.Engine, stateDir string, recipeProbe RecipeSessionProbe) {
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "zerops_import",
-		Description: "REQUIRES active workflow (zerops_recipe for recipe authoring, or zerops_workflow bootstrap/develop). Import services from YAML into the project. The Zerops API validates fields, modes, types, and hostnames server-side and returns structured apiMeta on the error response when anything is wrong. Blocks until all processes complete; returns final statuses (FINISHED/FAILED).",
+		Description: "REQUIRES active workflow unless dryRun=true (zerops_recipe for recipe authoring, or zerops_workflow bootstrap/develop). Import services from YAML into the project. dryRun=true returns a plan without importing. The Zerops API validates fields, modes, types, and hostnames server-side and returns structured apiMeta on the error response when anything is wrong. Blocks until all processes complete; returns final statuses (FINISHED/FAILED).",
 		InputSchema: importInputSchema(),
 		Annotations: &mcp.ToolAnnotations{
 			Title:           "Import services from YAML",
 			DestructiveHint: boolPtr(true),
 		},
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input ImportInput) (*mcp.CallToolResult, any, error) {
+		// A dry run changes nothing, so it is available outside a
+		// workflow — the plan is useful input for deciding to start one.
+		if input.DryRun.Bool() {
+			plan, err := ops.PlanImport(ctx, client, projectID, input.Content, input.FilePath, input.Override.Bool())
+			if err != nil {
+				return convertError(err), nil, nil
+			}
+			return jsonResult(plan), nil, nil
+		}
 		if blocked := requireWorkflowContext(engine, stateDir, recipeProbe); blocked != nil {
 			return blocked, nil, nil
 		}
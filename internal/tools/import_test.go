@@ -244,3 +244,31 @@ func TestImportTool_WithWorkSession_Succeeds(t *testing.T) {
 		t.Errorf("unexpected IsError with develop marker: %s", getTextContent(t, result))
 	}
 }
+
+func TestImportTool_DryRun_NoWorkflowNoSubmit(t *testing.T) {
+	t.Parallel()
+	mock := platform.NewMock().
+		WithServices([]platform.ServiceStack{{ID: "svc-api", Name: "api", Status: "ACTIVE"}})
+	stateDir := t.TempDir()
+	engine := workflow.NewEngine(stateDir, workflow.EnvLocal, nil)
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
+	RegisterImport(srv, mock, "proj-1", engine, stateDir, nil)
+
+	yaml := "services:\n  - hostname: api\n    type: nodejs@22\n  - hostname: db\n    type: postgresql@16\n"
+	result := callTool(t, srv, "zerops_import", map[string]any{"content": yaml, "dryRun": "true"})
+	if result.IsError {
+		t.Fatalf("unexpected IsError: %s", getTextContent(t, result))
+	}
+	if mock.CallCounts["ImportServices"] != 0 {
+		t.Error("dry run must not call ImportServices")
+	}
+
+	var plan ops.ImportPlan
+	if err := json.Unmarshal([]byte(getTextContent(t, result)), &plan); err != nil {
+		t.Fatalf("parse plan: %v", err)
+	}
+	if !plan.DryRun || len(plan.Services) != 2 || len(plan.Collisions) != 1 || plan.Collisions[0].Hostname != "api" {
+		t.Errorf("plan = %+v, want 2 services with an api collision", plan)
+	}
+}
@@ -2,32 +2,146 @@ package tools
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/zeropsio/zcp/internal/ops"
 	"github.com/zeropsio/zcp/internal/platform"
 )
 
 // LogsInput is the input type for zerops_logs.
+//
+// Follow is FlexBool with an explicit InputSchema below — same rationale
+// as every other MCP-boundary boolean (stringified-primitive agents).
 type LogsInput struct {
-	ServiceHostname string `json:"serviceHostname"    jsonschema:"Hostname of the service to fetch logs from."`
-	Severity        string `json:"severity,omitempty" jsonschema:"Filter by log severity: WARNING or ERROR. Omit for all severities."`
-	Since           string `json:"since,omitempty"    jsonschema:"Fetch logs since this time. RFC3339 format (e.g. 2024-01-15T10:00:00Z) or relative duration (e.g. 30s, 5m, 1h, 7d)."`
-	Limit           int    `json:"limit,omitempty"    jsonschema:"Maximum number of log entries to return. Default: 100."`
-	Search          string `json:"search,omitempty"   jsonschema:"Full-text search filter applied to log messages."`
+	ServiceHostname string   `json:"serviceHostname,omitempty"`
+	Severity        string   `json:"severity,omitempty"`
+	Since           string   `json:"since,omitempty"`
+	Limit           int      `json:"limit,omitempty"`
+	Search          string   `json:"search,omitempty"`
+	Follow          FlexBool `json:"follow,omitempty"`
+	Services        []string `json:"services,omitempty"`
+	Duration        string   `json:"duration,omitempty"`
+	StopOn          string   `json:"stopOn,omitempty"`
+	MaxLines        int      `json:"maxLines,omitempty"`
+	Mode            string   `json:"mode,omitempty"`
+	Top             int      `json:"top,omitempty"`
+}
+
+// Log output modes.
+const (
+	logsModeEntries = "entries"
+	logsModeSummary = "summary"
+)
+
+// logsInputSchema is the explicit InputSchema for zerops_logs.
+func logsInputSchema() *jsonschema.Schema {
+	return objectSchema(map[string]*jsonschema.Schema{
+		"serviceHostname": {
+			Type:        "string",
+			Description: "Hostname of the service to fetch logs from.",
+		},
+		"severity": {
+			Type:        "string",
+			Description: "Filter by log severity: WARNING or ERROR. Omit for all severities.",
+		},
+		"since": {
+			Type:        "string",
+			Description: "Fetch logs since this time. RFC3339 format (e.g. 2024-01-15T10:00:00Z) or relative duration (e.g. 30s, 5m, 1h, 7d). With follow=true: initial backlog; omit to stream only new lines.",
+		},
+		"limit": {
+			Type:        "integer",
+			Description: "Maximum number of log entries to return (mode=summary: to scan). Default: 100; 1000 for mode=summary.",
+		},
+		"search": {
+			Type:        "string",
+			Description: "Full-text search filter applied to log messages.",
+		},
+		"follow": flexBoolSchema("Tail logs live instead of one fetch: polls every 2s, merges services by timestamp with a hostname column, streams new lines as progress notifications, and returns the full interleaved list when a stop condition hits (duration, stopOn, maxLines)."),
+		"services": {
+			Type:        "array",
+			Items:       &jsonschema.Schema{Type: "string"},
+			Description: "follow=true: hostnames to tail together, e.g. [\"app\",\"worker\"]. serviceHostname is used when omitted.",
+		},
+		"duration": {
+			Type:        "string",
+			Description: "follow=true: how long to tail, Go duration (e.g. 60s, 5m). Default 60s, max 10m.",
+		},
+		"stopOn": {
+			Type:        "string",
+			Description: "follow=true: regular expression; the first matching line ends the follow (e.g. `listening on|panic`).",
+		},
+		"maxLines": {
+			Type:        "integer",
+			Description: "follow=true: stop after this many lines. Default 500, max 5000.",
+		},
+		"mode": {
+			Type:        "string",
+			Enum:        []any{logsModeEntries, logsModeSummary},
+			Description: "entries (default): raw log lines. summary: cluster up to limit lines (default 1000) into templates with numbers, UUIDs, IPs and timestamps masked; returns the top patterns with counts, first/last seen, severity mix, one exemplar and a failure-signal tag when a known pattern matches.",
+		},
+		"top": {
+			Type:        "integer",
+			Description: "mode=summary: number of patterns to return, ranked by count. Default 10, max 50. Patterns tagged with a failure signal are always included.",
+		},
+	})
 }
 
 // RegisterLogs registers the zerops_logs tool.
 func RegisterLogs(srv *mcp.Server, client platform.Client, fetcher platform.LogFetcher, projectID string) {
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "zerops_logs",
-		Description: "Fetch runtime logs from a service. Filter by severity, time range, and search text.",
+		Description: "Fetch runtime logs from a service. Filter by severity, time range, and search text. mode=summary clusters lines into masked templates with counts and failure-signal tags. follow=true tails one or more services live until duration, stopOn match, or maxLines.",
+		InputSchema: logsInputSchema(),
 		Annotations: &mcp.ToolAnnotations{
 			Title:          "Fetch service logs",
 			ReadOnlyHint:   true,
 			IdempotentHint: true,
 		},
-	}, func(ctx context.Context, _ *mcp.CallToolRequest, input LogsInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input LogsInput) (*mcp.CallToolResult, any, error) {
+		switch input.Mode {
+		case "", logsModeEntries, logsModeSummary:
+		default:
+			return convertError(platform.NewPlatformError(platform.ErrInvalidParameter,
+				fmt.Sprintf("Invalid mode %q", input.Mode),
+				"Use mode=entries (default) or mode=summary")), nil, nil
+		}
+
+		if input.Follow.Bool() {
+			if input.Mode == logsModeSummary {
+				return convertError(platform.NewPlatformError(platform.ErrInvalidParameter,
+					"mode=summary cannot be combined with follow=true",
+					"Follow first, then summarize the same window with since")), nil, nil
+			}
+			services := input.Services
+			if len(services) == 0 && input.ServiceHostname != "" {
+				services = []string{input.ServiceHostname}
+			}
+			result, err := ops.FollowLogs(ctx, client, fetcher, projectID, ops.FollowParams{
+				Services: services,
+				Severity: input.Severity,
+				Search:   input.Search,
+				Since:    input.Since,
+				Duration: input.Duration,
+				StopOn:   input.StopOn,
+				MaxLines: input.MaxLines,
+			}, buildProgressCallback(ctx, req))
+			if err != nil {
+				return convertError(err), nil, nil
+			}
+			return jsonResult(result), nil, nil
+		}
+
+		if input.Mode == logsModeSummary {
+			summary, err := ops.SummarizeLogs(ctx, client, fetcher, projectID,
+				input.ServiceHostname, input.Severity, input.Since, input.Limit, input.Search, input.Top)
+			if err != nil {
+				return convertError(err), nil, nil
+			}
+			return jsonResult(summary), nil, nil
+		}
+
 		result, err := ops.FetchLogs(ctx, client, fetcher, projectID,
 			input.ServiceHostname, input.Severity, input.Since, input.Limit, input.Search)
 		if err != nil {
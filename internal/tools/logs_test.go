@@ -164,3 +164,82 @@ func TestLogsTool_EmptyHostname(t *testing.T) {
 		t.Error("expected IsError for empty hostname")
 	}
 }
+
+func TestLogsTool_FollowStopsOnPattern(t *testing.T) {
+	t.Parallel()
+	mock := platform.NewMock().
+		WithServices([]platform.ServiceStack{
+			{ID: "svc-1", Name: "api", ProjectID: "proj-1"},
+		}).
+		WithLogAccess(&platform.LogAccess{URL: "http://logs.test"})
+	fetcher := platform.NewMockLogFetcher().WithEntries([]platform.LogEntry{
+		{ID: "1", Timestamp: recentTS(-30), Severity: "info", Facility: "local0", Message: "booting"},
+		{ID: "2", Timestamp: recentTS(-20), Severity: "info", Facility: "local0", Message: "listening on :3000"},
+		{ID: "3", Timestamp: recentTS(-10), Severity: "info", Facility: "local0", Message: "GET /"},
+	})
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
+	RegisterLogs(srv, mock, fetcher, "proj-1")
+
+	result := callTool(t, srv, "zerops_logs", map[string]any{
+		"serviceHostname": "api",
+		"follow":          "true",
+		"since":           "5m",
+		"stopOn":          "listening on",
+	})
+	if result.IsError {
+		t.Fatalf("unexpected IsError: %s", getTextContent(t, result))
+	}
+
+	var fr ops.FollowResult
+	if err := json.Unmarshal([]byte(getTextContent(t, result)), &fr); err != nil {
+		t.Fatalf("failed to parse result: %v", err)
+	}
+	if fr.StopReason != ops.FollowStopPattern || len(fr.Entries) != 2 {
+		t.Errorf("stop = %s with %d entries, want pattern with 2", fr.StopReason, len(fr.Entries))
+	}
+	if fr.Entries[1].Hostname != "api" {
+		t.Errorf("hostname = %q, want api", fr.Entries[1].Hostname)
+	}
+}
+
+func TestLogsTool_SummaryMode(t *testing.T) {
+	t.Parallel()
+	mock := platform.NewMock().
+		WithServices([]platform.ServiceStack{
+			{ID: "svc-1", Name: "api", ProjectID: "proj-1"},
+		}).
+		WithLogAccess(&platform.LogAccess{URL: "http://logs.test"})
+	fetcher := platform.NewMockLogFetcher().WithEntries([]platform.LogEntry{
+		{Timestamp: recentTS(-30), Severity: "info", Facility: "local0", Message: "job 1 done"},
+		{Timestamp: recentTS(-20), Severity: "info", Facility: "local0", Message: "job 2 done"},
+		{Timestamp: recentTS(-10), Severity: "error", Facility: "local0", Message: "JavaScript heap out of memory"},
+	})
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
+	RegisterLogs(srv, mock, fetcher, "proj-1")
+
+	result := callTool(t, srv, "zerops_logs", map[string]any{"serviceHostname": "api", "mode": "summary"})
+	if result.IsError {
+		t.Fatalf("unexpected IsError: %s", getTextContent(t, result))
+	}
+	var summary ops.LogSummary
+	if err := json.Unmarshal([]byte(getTextContent(t, result)), &summary); err != nil {
+		t.Fatalf("failed to parse result: %v", err)
+	}
+	if summary.Clusters != 2 || summary.Patterns[0].Template != "job <num> done" || summary.Patterns[0].Count != 2 {
+		t.Errorf("summary = %+v", summary)
+	}
+	if sig := summary.Patterns[1].Signal; sig == nil || sig.ID != "init:oom-killed" {
+		t.Errorf("oom pattern signal = %+v, want init:oom-killed", sig)
+	}
+
+	for _, args := range []map[string]any{
+		{"serviceHostname": "api", "mode": "tree"},
+		{"serviceHostname": "api", "mode": "summary", "follow": true},
+	} {
+		if result := callTool(t, srv, "zerops_logs", args); !result.IsError {
+			t.Errorf("args %v: expected IsError", args)
+		}
+	}
+}
@@ -18,11 +18,19 @@ type MountInput struct {
 	ServiceHostname string `json:"serviceHostname,omitempty" jsonschema:"Hostname of the service to mount/unmount. Required for mount and unmount actions."`
 }
 
-// RegisterMount registers the zerops_mount tool.
-func RegisterMount(srv *mcp.Server, client platform.Client, projectID string, mounter ops.Mounter, rtInfo runtime.Info, stateDir string, engine *workflow.Engine, recipeProbe RecipeSessionProbe) {
+// mountStatusResult is the status response: live mount state plus the
+// background watchdog's last checks and remount history.
+type mountStatusResult struct {
+	*ops.MountStatusResult
+	Watchdog *ops.MountWatchdogStatus `json:"watchdog,omitempty"`
+}
+
+// RegisterMount registers the zerops_mount tool. watchdog may be nil, in
+// which case status reports live state only.
+func RegisterMount(srv *mcp.Server, client platform.Client, projectID string, mounter ops.Mounter, watchdog *ops.MountWatchdog, rtInfo runtime.Info, stateDir string, engine *workflow.Engine, recipeProbe RecipeSessionProbe) {
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "zerops_mount",
-		Description: "Mount/unmount service filesystems via SSHFS. Actions: mount (requires active workflow — bootstrap or develop), unmount, status.",
+		Description: "Mount/unmount service filesystems via SSHFS. Actions: mount (requires active workflow — bootstrap or develop), unmount, status. A background watchdog remounts stale mounts (e.g. after a redeploy); status includes its last checks and remount history.",
 		Annotations: &mcp.ToolAnnotations{
 			Title:           "Mount/unmount service filesystems",
 			IdempotentHint:  true,
@@ -66,13 +74,20 @@ func RegisterMount(srv *mcp.Server, client platform.Client, projectID string, mo
 			if err != nil {
 				return convertError(err, WithRecoveryStatus()), nil, nil
 			}
+			if watchdog != nil {
+				watchdog.Forget(input.ServiceHostname)
+			}
 			return jsonResult(result), nil, nil
 		case actionStatus:
 			result, err := ops.MountStatus(ctx, client, projectID, mounter, input.ServiceHostname)
 			if err != nil {
 				return convertError(err, WithRecoveryStatus()), nil, nil
 			}
-			return jsonResult(result), nil, nil
+			resp := mountStatusResult{MountStatusResult: result}
+			if watchdog != nil {
+				resp.Watchdog = watchdog.Status(input.ServiceHostname)
+			}
+			return jsonResult(resp), nil, nil
 		default:
 			return convertError(platform.NewPlatformError(
 				platform.ErrInvalidParameter, "Invalid action '"+input.Action+"'",
@@ -20,6 +20,7 @@ type stubMounter struct {
 	writable map[string]bool
 	mountErr error
 	units    map[string]bool
+	dirs     []string
 }
 
 func newStubMounter() *stubMounter {
@@ -61,7 +62,7 @@ func (s *stubMounter) IsWritable(_ context.Context, path string) (bool, error) {
 }
 
 func (s *stubMounter) ListMountDirs(_ context.Context, _ string) ([]string, error) {
-	return nil, nil
+	return s.dirs, nil
 }
 
 func (s *stubMounter) HasUnit(_ context.Context, hostname string) (bool, error) {
@@ -78,7 +79,7 @@ func mountServer(mock platform.Client, mounter ops.Mounter) *mcp.Server {
 
 func mountServerWithRT(mock platform.Client, mounter ops.Mounter, rtInfo runtime.Info, engine *workflow.Engine) *mcp.Server {
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterMount(srv, mock, "proj-1", mounter, rtInfo, "", engine, nil)
+	RegisterMount(srv, mock, "proj-1", mounter, nil, rtInfo, "", engine, nil)
 	return srv
 }
 
@@ -91,7 +92,7 @@ func mountServerWithDevelop(t *testing.T, mock platform.Client, mounter ops.Moun
 		t.Fatalf("save work session: %v", err)
 	}
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterMount(srv, mock, "proj-1", mounter, runtime.Info{}, stateDir, nil, nil)
+	RegisterMount(srv, mock, "proj-1", mounter, nil, runtime.Info{}, stateDir, nil, nil)
 	return srv
 }
 
@@ -104,7 +105,7 @@ func mountServerWithSession(t *testing.T, mock platform.Client, mounter ops.Moun
 		t.Fatalf("bootstrap start: %v", err)
 	}
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterMount(srv, mock, "proj-1", mounter, runtime.Info{}, stateDir, engine, nil)
+	RegisterMount(srv, mock, "proj-1", mounter, nil, runtime.Info{}, stateDir, engine, nil)
 	return srv
 }
 
@@ -358,7 +359,7 @@ func TestMountTool_MountBlockedWithoutWorkflow(t *testing.T) {
 	mounter := newStubMounter()
 	// stateDir with no develop marker and nil engine = no workflow context.
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterMount(srv, mock, "proj-1", mounter, runtime.Info{}, t.TempDir(), nil, nil)
+	RegisterMount(srv, mock, "proj-1", mounter, nil, runtime.Info{}, t.TempDir(), nil, nil)
 
 	result := callTool(t, srv, "zerops_mount", map[string]any{
 		"action":          "mount",
@@ -523,3 +524,38 @@ func TestMountTool_SelfStatus_Allowed(t *testing.T) {
 		t.Errorf("self-status should be allowed, got: %s", getTextContent(t, result))
 	}
 }
+
+func TestMountTool_StatusIncludesWatchdog(t *testing.T) {
+	t.Parallel()
+
+	mock := platform.NewMock().WithServices([]platform.ServiceStack{
+		{ID: "svc-1", Name: "app"},
+	})
+	mounter := newStubMounter()
+	mounter.dirs = []string{"app"}
+	mounter.states["/var/www/app"] = platform.MountStateStale
+	watchdog := ops.NewMountWatchdog(mock, "proj-1", mounter, nil)
+	watchdog.Check(context.Background())
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
+	RegisterMount(srv, mock, "proj-1", mounter, watchdog, runtime.Info{}, t.TempDir(), nil, nil)
+
+	result := callTool(t, srv, "zerops_mount", map[string]any{"action": "status", "serviceHostname": "app"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", getTextContent(t, result))
+	}
+	var parsed struct {
+		Mounts   []ops.MountInfo          `json:"mounts"`
+		Watchdog *ops.MountWatchdogStatus `json:"watchdog"`
+	}
+	if err := json.Unmarshal([]byte(getTextContent(t, result)), &parsed); err != nil {
+		t.Fatalf("parse result: %v", err)
+	}
+	if len(parsed.Mounts) != 1 || !parsed.Mounts[0].Mounted {
+		t.Errorf("mounts = %+v; the watchdog should have remounted app", parsed.Mounts)
+	}
+	if parsed.Watchdog == nil || parsed.Watchdog.LastCheck == nil || len(parsed.Watchdog.Mounts) != 1 ||
+		len(parsed.Watchdog.Mounts[0].History) != 2 {
+		t.Errorf("watchdog = %+v, want stale and remount events for app", parsed.Watchdog)
+	}
+}
@@ -23,6 +23,7 @@ const (
 	nextActionManageDisconnect = "Storage disconnected. Verify: zerops_discover."
 	nextActionScaleSuccess     = "Verify scaling: zerops_discover."
 	nextActionSubdomainEnable  = "Subdomain active. Verify: zerops_verify."
+	nextActionRollbackSuccess  = "Rollback landed — confirm runtime state: zerops_verify. Fix forward and redeploy when ready."
 )
 
 // deploySuccessNextActions returns the unified post-deploy next-action
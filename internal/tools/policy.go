@@ -0,0 +1,251 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zcp/internal/ops"
+	"github.com/zeropsio/zcp/internal/platform"
+	"github.com/zeropsio/zcp/internal/policy"
+)
+
+// mutatingTools maps each tool that can change platform state to the
+// actions that do. A nil slice means every call mutates. Tools absent from
+// the map are read-only or only touch local state (.zcp/, mounts, recipe
+// output) and stay available in read-only mode.
+var mutatingTools = map[string][]string{
+	"zerops_delete":       nil,
+	"zerops_deploy":       nil,
+	"zerops_deploy_batch": nil,
+	"zerops_import":       nil,
+	"zerops_manage":       nil,
+	"zerops_scale":        nil,
+	"zerops_subdomain":    nil,
+	"zerops_env":          {"set", "delete", "promote", "import", "rotate"},
+	"zerops_process":      {"cancel"},
+	"zerops_dev_server":   {"start", "stop", "restart"},
+	"zerops_workflow":     {"record-deploy"},
+}
+
+// policyArgs is the subset of tool arguments the policy gate inspects.
+// Every tool names its target service serviceHostname except the deploy
+// family (targetService) and dev_server (hostname); the gate only needs
+// the hostname for tools whose protected operations it knows about.
+type policyArgs struct {
+	Action          string   `json:"action"`
+	ServiceHostname string   `json:"serviceHostname"`
+	Project         FlexBool `json:"project"`
+	DryRun          FlexBool `json:"dryRun"`
+	Profile         string   `json:"profile"`
+	Apply           FlexBool `json:"apply"`
+}
+
+// readOnly reports calls of a mutating tool that only compute a plan:
+// zerops_import dryRun=true, zerops_env action=import dryRun=true and
+// zerops_scale profile= without apply=true. The exemption is per tool and
+// action on purpose — tools with an explicit objectSchema accept unknown
+// properties, so a stray dryRun on any other call must not slip past a
+// read-only policy.
+func (a policyArgs) readOnly(tool string) bool {
+	switch tool {
+	case "zerops_import":
+		return a.DryRun.Bool()
+	case "zerops_env":
+		return a.Action == "import" && a.DryRun.Bool()
+	case "zerops_scale":
+		return a.Profile != "" && !a.Apply.Bool()
+	}
+	return false
+}
+
+// PolicyGate returns receiving middleware that enforces the project
+// policy on every tools/call before the handler runs — and therefore
+// before anything reaches internal/ops. Denials are returned as ordinary
+// IsError tool results carrying POLICY_DENIED, the same wire shape as any
+// other typed error, so the agent reads the reason and the suggested
+// alternative instead of seeing a protocol failure.
+//
+// Scale-down detection needs the service's current limits, so the gate
+// looks the service up for zerops_scale calls on protected hostnames. A
+// failed lookup refuses the call: a protected service is exactly where
+// guessing wrong is not acceptable. Profile applies are planned with the
+// same .zcp/scaling.yaml (under stateDir) the handler reads, and every
+// protected service in the plan is checked.
+func PolicyGate(pol *policy.Policy, client platform.Client, projectID, stateDir string) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" || pol == nil {
+				return next(ctx, method, req)
+			}
+			callReq, ok := req.(*mcp.CallToolRequest)
+			if !ok || callReq.Params == nil {
+				return next(ctx, method, req)
+			}
+			if err := checkPolicy(ctx, pol, client, projectID, stateDir, callReq.Params.Name, callReq.Params.Arguments); err != nil {
+				return convertError(err), nil
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// checkPolicy applies the tool lists, read-only mode and protected
+// hostnames, in that order.
+func checkPolicy(ctx context.Context, pol *policy.Policy, client platform.Client, projectID, stateDir, tool string, raw json.RawMessage) error {
+	if err := pol.CheckTool(tool); err != nil {
+		return err
+	}
+
+	var args policyArgs
+	if len(raw) > 0 {
+		// Malformed arguments are the handler's problem; the gate
+		// only refuses what it can positively identify.
+		_ = json.Unmarshal(raw, &args)
+	}
+
+	// Plan-only calls submit nothing.
+	if isMutatingCall(tool, args.Action) && !args.readOnly(tool) {
+		what := tool
+		if args.Action != "" {
+			what += " action=" + args.Action
+		}
+		if err := pol.CheckMutation(what); err != nil {
+			return err
+		}
+	}
+
+	host := args.ServiceHostname
+	switch tool {
+	case "zerops_delete":
+		return pol.CheckProtected(host, policy.OpDelete, "")
+	case "zerops_manage":
+		if args.Action == "stop" {
+			return pol.CheckProtected(host, policy.OpStop, "")
+		}
+	case "zerops_env":
+		if args.Action == "delete" && !args.Project.Bool() {
+			return pol.CheckProtected(host, policy.OpEnvDelete, "")
+		}
+	case "zerops_scale":
+		if args.Profile != "" {
+			if args.readOnly(tool) {
+				return nil
+			}
+			return checkScaleProfile(ctx, pol, client, projectID, stateDir, raw)
+		}
+		if !pol.IsProtected(host) {
+			return nil
+		}
+		var input ScaleInput
+		if err := json.Unmarshal(raw, &input); err != nil {
+			return nil //nolint:nilerr // handler rejects malformed input with its own error
+		}
+		return checkScaleDown(ctx, pol, client, projectID, host, input.scaleParams())
+	}
+	return nil
+}
+
+// checkScaleDown refuses params that would lower a protected service.
+func checkScaleDown(ctx context.Context, pol *policy.Policy, client platform.Client, projectID, host string, params ops.ScaleParams) error {
+	lowered, err := scaleDownFields(ctx, client, projectID, host, params)
+	if err != nil {
+		return pol.CheckProtected(host, policy.OpScaleDown, "current scaling could not be read to rule out a scale-down: "+err.Error())
+	}
+	if len(lowered) > 0 {
+		return pol.CheckProtected(host, policy.OpScaleDown, "would lower "+strings.Join(lowered, ", "))
+	}
+	return nil
+}
+
+// checkScaleProfile plans a profile apply and checks each protected
+// service in it. An unknown profile, bad scaling.yaml or unknown hostname
+// is left to the handler, which fails the same way before scaling
+// anything; any other planning failure refuses the call when a protected
+// service is in scope.
+func checkScaleProfile(ctx context.Context, pol *policy.Policy, client platform.Client, projectID, stateDir string, raw json.RawMessage) error {
+	var input ScaleInput
+	if err := json.Unmarshal(raw, &input); err != nil {
+		return nil //nolint:nilerr // handler rejects malformed input with its own error
+	}
+	plan, err := planScaleProfile(ctx, client, projectID, stateDir, input)
+	if err != nil {
+		var pe *platform.PlatformError
+		if errors.As(err, &pe) && (pe.Code == platform.ErrInvalidParameter || pe.Code == platform.ErrServiceNotFound) {
+			return nil
+		}
+		scope := input.profileServices()
+		if len(scope) == 0 {
+			scope = pol.Protected
+		}
+		for _, host := range scope {
+			if pol.IsProtected(host) {
+				return pol.CheckProtected(host, policy.OpScaleDown, "current scaling could not be read to rule out a scale-down: "+err.Error())
+			}
+		}
+		return nil
+	}
+	for _, e := range plan.Services {
+		if len(e.Changes) == 0 || !pol.IsProtected(e.Hostname) {
+			continue
+		}
+		if err := checkScaleDown(ctx, pol, client, projectID, e.Hostname, e.Params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isMutatingCall(tool, action string) bool {
+	actions, ok := mutatingTools[tool]
+	if !ok {
+		return false
+	}
+	return actions == nil || slices.Contains(actions, action)
+}
+
+// scaleDownFields returns the scaling parameters in input that are lower
+// than the service's current configuration. Switching DEDICATED → SHARED
+// CPU counts as a scale-down.
+func scaleDownFields(ctx context.Context, client platform.Client, projectID, hostname string, input ops.ScaleParams) ([]string, error) {
+	svc, err := ops.LookupService(ctx, client, projectID, hostname)
+	if err != nil {
+		return nil, err
+	}
+	cur := svc.CustomAutoscaling
+	if cur == nil {
+		cur = svc.CurrentAutoscaling
+	}
+	if cur == nil {
+		return nil, fmt.Errorf("service %s reports no autoscaling configuration", hostname)
+	}
+
+	var lowered []string
+	lowerInt := func(name string, want *int, have int32) {
+		if want != nil && int32(*want) < have { //nolint:gosec // scaling values are small
+			lowered = append(lowered, fmt.Sprintf("%s %d→%d", name, have, *want))
+		}
+	}
+	lowerFloat := func(name string, want *float64, have float64) {
+		if want != nil && *want < have {
+			lowered = append(lowered, fmt.Sprintf("%s %g→%g", name, have, *want))
+		}
+	}
+	if input.CPUMode != nil && *input.CPUMode == "SHARED" && cur.CPUMode == "DEDICATED" {
+		lowered = append(lowered, "cpuMode DEDICATED→SHARED")
+	}
+	lowerInt("minCpu", input.MinCPU, cur.MinCPU)
+	lowerInt("maxCpu", input.MaxCPU, cur.MaxCPU)
+	lowerInt("startCpu", input.StartCPU, cur.StartCPUCoreCount)
+	lowerFloat("minRam", input.MinRAM, cur.MinRAM)
+	lowerFloat("maxRam", input.MaxRAM, cur.MaxRAM)
+	lowerFloat("minDisk", input.MinDisk, cur.MinDisk)
+	lowerFloat("maxDisk", input.MaxDisk, cur.MaxDisk)
+	lowerInt("minContainers", input.MinContainers, cur.HorizontalMinCount)
+	lowerInt("maxContainers", input.MaxContainers, cur.HorizontalMaxCount)
+	return lowered, nil
+}
@@ -0,0 +1,190 @@
+// Tests for: tools/policy.go — policy gate middleware.
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zcp/internal/platform"
+	"github.com/zeropsio/zcp/internal/policy"
+	"github.com/zeropsio/zcp/internal/runtime"
+)
+
+func policyTestServer(pol *policy.Policy, mock *platform.Mock) *mcp.Server {
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
+	srv.AddReceivingMiddleware(PolicyGate(pol, mock, "proj-1", ""))
+	RegisterDelete(srv, mock, "proj-1", "", nil, runtime.Info{})
+	RegisterManage(srv, mock, "proj-1")
+	RegisterScale(srv, mock, "proj-1", "")
+	RegisterEnv(srv, mock, nil, nil, "proj-1", "", "")
+	RegisterDiscover(srv, mock, "proj-1", "")
+	RegisterImport(srv, mock, "proj-1", nil, "", nil)
+	return srv
+}
+
+func policyMock() *platform.Mock {
+	return platform.NewMock().
+		WithProject(&platform.Project{ID: "proj-1", Name: "test"}).
+		WithServices([]platform.ServiceStack{
+			{ID: "svc-db", Name: "db", ProjectID: "proj-1", Status: "ACTIVE", CustomAutoscaling: &platform.CustomAutoscaling{
+				CPUMode: "DEDICATED", MinCPU: 1, MaxCPU: 4, MinRAM: 1, MaxRAM: 8, HorizontalMinCount: 1, HorizontalMaxCount: 1,
+			}},
+			{ID: "svc-app", Name: "app", ProjectID: "proj-1", Status: "ACTIVE"},
+		})
+}
+
+func TestPolicyGate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		pol        *policy.Policy
+		tool       string
+		args       map[string]any
+		wantDenied string // substring of the denial message; empty = must pass the gate
+	}{
+		{name: "no policy", tool: "zerops_delete", args: map[string]any{"serviceHostname": "db", "confirm": true}},
+		{
+			name: "read-only blocks mutation", pol: &policy.Policy{ReadOnly: true},
+			tool: "zerops_manage", args: map[string]any{"action": "restart", "serviceHostname": "app"},
+			wantDenied: "zerops_manage action=restart refused: project is read-only",
+		},
+		{
+			name: "read-only allows env get", pol: &policy.Policy{ReadOnly: true},
+			tool: "zerops_env", args: map[string]any{"action": "get", "serviceHostname": "app"},
+		},
+		{
+			name: "read-only allows env diff", pol: &policy.Policy{ReadOnly: true},
+			tool: "zerops_env", args: map[string]any{"action": "diff", "source": "app", "target": "db"},
+		},
+		{
+			name: "read-only blocks env promote", pol: &policy.Policy{ReadOnly: true},
+			tool: "zerops_env", args: map[string]any{"action": "promote", "source": "app", "target": "db"},
+			wantDenied: "zerops_env action=promote refused",
+		},
+		{
+			name: "read-only blocks env import", pol: &policy.Policy{ReadOnly: true},
+			tool: "zerops_env", args: map[string]any{"action": "import", "serviceHostname": "app", "filePath": ".env"},
+			wantDenied: "zerops_env action=import refused",
+		},
+		{
+			name: "read-only allows env import dry run", pol: &policy.Policy{ReadOnly: true},
+			tool: "zerops_env", args: map[string]any{"action": "import", "serviceHostname": "app", "filePath": ".env", "dryRun": true},
+		},
+		{
+			name: "read-only blocks env set with stray dryRun", pol: &policy.Policy{ReadOnly: true},
+			tool: "zerops_env", args: map[string]any{"action": "set", "serviceHostname": "app", "variables": []string{"A=1"}, "dryRun": true},
+			wantDenied: "zerops_env action=set refused",
+		},
+		{
+			name: "read-only blocks env delete with stray dryRun", pol: &policy.Policy{ReadOnly: true},
+			tool: "zerops_env", args: map[string]any{"action": "delete", "serviceHostname": "app", "variables": []string{"A"}, "dryRun": true},
+			wantDenied: "zerops_env action=delete refused",
+		},
+		{
+			name: "read-only blocks manage with stray dryRun", pol: &policy.Policy{ReadOnly: true},
+			tool: "zerops_manage", args: map[string]any{"action": "restart", "serviceHostname": "app", "dryRun": true},
+			wantDenied: "zerops_manage action=restart refused",
+		},
+		{
+			name: "read-only allows discover", pol: &policy.Policy{ReadOnly: true},
+			tool: "zerops_discover", args: map[string]any{},
+		},
+		{
+			name: "read-only blocks import", pol: &policy.Policy{ReadOnly: true},
+			tool: "zerops_import", args: map[string]any{"content": "services: []\n"},
+			wantDenied: "zerops_import refused: project is read-only",
+		},
+		{
+			name: "read-only allows import dry run", pol: &policy.Policy{ReadOnly: true},
+			tool: "zerops_import", args: map[string]any{"content": "services: []\n", "dryRun": true},
+		},
+		{
+			name: "deny list", pol: &policy.Policy{Tools: policy.ToolRules{Deny: []string{"zerops_discover"}}},
+			tool: "zerops_discover", args: map[string]any{},
+			wantDenied: "denies tool zerops_discover",
+		},
+		{
+			name: "protected delete", pol: &policy.Policy{Protected: []string{"db"}},
+			tool: "zerops_delete", args: map[string]any{"serviceHostname": "db", "confirm": true},
+			wantDenied: "Service db is protected",
+		},
+		{
+			name: "protected stop", pol: &policy.Policy{Protected: []string{"db"}},
+			tool: "zerops_manage", args: map[string]any{"action": "stop", "serviceHostname": "db"},
+			wantDenied: "stop is not allowed",
+		},
+		{
+			name: "protected restart allowed", pol: &policy.Policy{Protected: []string{"db"}},
+			tool: "zerops_manage", args: map[string]any{"action": "restart", "serviceHostname": "db"},
+		},
+		{
+			name: "protected env delete", pol: &policy.Policy{Protected: []string{"db"}},
+			tool: "zerops_env", args: map[string]any{"action": "delete", "serviceHostname": "db", "variables": []string{"X"}},
+			wantDenied: "env-delete is not allowed",
+		},
+		{
+			name: "protected scale down", pol: &policy.Policy{Protected: []string{"db"}},
+			tool: "zerops_scale", args: map[string]any{"serviceHostname": "db", "maxRam": 4, "cpuMode": "SHARED"},
+			wantDenied: "would lower cpuMode DEDICATED→SHARED, maxRam 8→4",
+		},
+		{
+			name: "protected scale up allowed", pol: &policy.Policy{Protected: []string{"db"}},
+			tool: "zerops_scale", args: map[string]any{"serviceHostname": "db", "maxRam": 16},
+		},
+		{
+			name: "unprotected scale down allowed", pol: &policy.Policy{Protected: []string{"db"}},
+			tool: "zerops_scale", args: map[string]any{"serviceHostname": "app", "maxRam": 1},
+		},
+		{
+			name: "read-only allows profile diff", pol: &policy.Policy{ReadOnly: true},
+			tool: "zerops_scale", args: map[string]any{"profile": "stage"},
+		},
+		{
+			name: "read-only blocks profile apply", pol: &policy.Policy{ReadOnly: true},
+			tool: "zerops_scale", args: map[string]any{"profile": "stage", "apply": true},
+			wantDenied: "zerops_scale refused: project is read-only",
+		},
+		{
+			name: "protected profile scale down", pol: &policy.Policy{Protected: []string{"db"}},
+			tool: "zerops_scale", args: map[string]any{"profile": "small-prod", "services": []string{"app", "db"}, "apply": true},
+			wantDenied: "would lower cpuMode DEDICATED→SHARED, minRam 1→0.5",
+		},
+		{
+			name: "unprotected profile apply allowed", pol: &policy.Policy{Protected: []string{"db"}},
+			tool: "zerops_scale", args: map[string]any{"profile": "small-prod", "services": []string{"app"}, "apply": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := callTool(t, policyTestServer(tt.pol, policyMock()), tt.tool, tt.args)
+			text := getTextContent(t, result)
+			if tt.wantDenied == "" {
+				if strings.Contains(text, platform.ErrPolicyDenied) {
+					t.Fatalf("unexpected policy denial: %s", text)
+				}
+				return
+			}
+			if !result.IsError || !strings.Contains(text, `"code":"`+platform.ErrPolicyDenied+`"`) {
+				t.Fatalf("expected POLICY_DENIED error, got: %s", text)
+			}
+			if !strings.Contains(text, tt.wantDenied) {
+				t.Errorf("denial %s missing %q", text, tt.wantDenied)
+			}
+		})
+	}
+}
+
+func TestPolicyGate_DenialNeverReachesPlatform(t *testing.T) {
+	t.Parallel()
+
+	mock := policyMock()
+	srv := policyTestServer(&policy.Policy{Protected: []string{"db"}}, mock)
+	callTool(t, srv, "zerops_delete", map[string]any{"serviceHostname": "db", "confirm": true})
+	if mock.CallCounts["DeleteService"] != 0 {
+		t.Error("DeleteService called despite policy denial")
+	}
+}
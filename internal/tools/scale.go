@@ -6,11 +6,12 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/zeropsio/zcp/internal/ops"
 	"github.com/zeropsio/zcp/internal/platform"
+	"github.com/zeropsio/zcp/internal/recipe"
 )
 
 // ScaleInput is the input type for zerops_scale.
 type ScaleInput struct {
-	ServiceHostname   string   `json:"serviceHostname"             jsonschema:"Hostname of the service to scale."`
+	ServiceHostname   string   `json:"serviceHostname,omitempty"   jsonschema:"Hostname of the service to scale. Required unless profile is set."`
 	CPUMode           *string  `json:"cpuMode,omitempty"           jsonschema:"CPU scaling mode: SHARED or DEDICATED."`
 	MinCPU            *int     `json:"minCpu,omitempty"            jsonschema:"Minimum CPU cores (autoscaling lower bound)."`
 	MaxCPU            *int     `json:"maxCpu,omitempty"            jsonschema:"Maximum CPU cores (autoscaling upper bound)."`
@@ -25,41 +26,62 @@ type ScaleInput struct {
 	MinFreeRAMPercent *float64 `json:"minFreeRamPercent,omitempty" jsonschema:"Free RAM threshold as percentage of granted RAM (0-100). Scales proportionally — e.g. 5%% of 12 GB = 600 MB buffer. Whichever of minFreeRamGB or minFreeRamPercent provides MORE free memory is used. Default: 0 (disabled)."`
 	MinFreeCPUCores   *float64 `json:"minFreeCpuCores,omitempty"   jsonschema:"Free CPU threshold as fraction of one core (0.0-1.0). Value 0.2 means scale-up when less than 20%% of one core is free. DEDICATED CPU mode only — ignored in SHARED mode. Default: 0.1 (10%%)."`
 	MinFreeCPUPercent *float64 `json:"minFreeCpuPercent,omitempty" jsonschema:"Free CPU threshold as percentage of total capacity across ALL cores (0-100). DEDICATED CPU mode only — ignored in SHARED mode. Default: 0 (disabled)."`
+	Profile           string   `json:"profile,omitempty"           jsonschema:"Named scaling profile to apply instead of explicit values: a built-in tier (agent, remote, local, stage, small-prod, ha-prod) or a profile from .zcp/scaling.yaml. Returns a diff against current scaling; nothing changes until apply=true."`
+	Services          []string `json:"services,omitempty"          jsonschema:"Hostnames the profile applies to. Default: serviceHostname when set, otherwise every service in the project."`
+	Apply             FlexBool `json:"apply,omitempty"             jsonschema:"With profile: apply the diff to all listed services in parallel. All-or-nothing: nothing is sent if any entry is invalid, and services already scaled are reverted when another fails. Default false (dry-run diff only)."`
 }
 
-// RegisterScale registers the zerops_scale tool.
-func RegisterScale(srv *mcp.Server, client platform.Client, projectID string) {
+// scaleParams returns the explicit scaling values of the input.
+func (in ScaleInput) scaleParams() ops.ScaleParams {
+	return ops.ScaleParams{
+		CPUMode:           in.CPUMode,
+		MinCPU:            in.MinCPU,
+		MaxCPU:            in.MaxCPU,
+		StartCPU:          in.StartCPU,
+		MinRAM:            in.MinRAM,
+		MaxRAM:            in.MaxRAM,
+		MinDisk:           in.MinDisk,
+		MaxDisk:           in.MaxDisk,
+		MinContainers:     in.MinContainers,
+		MaxContainers:     in.MaxContainers,
+		MinFreeRAMGB:      in.MinFreeRAMGB,
+		MinFreeRAMPercent: in.MinFreeRAMPercent,
+		MinFreeCPUCores:   in.MinFreeCPUCores,
+		MinFreeCPUPercent: in.MinFreeCPUPercent,
+	}
+}
+
+// profileServices is the hostname list a profile call targets; empty
+// means every service in the project.
+func (in ScaleInput) profileServices() []string {
+	if len(in.Services) == 0 && in.ServiceHostname != "" {
+		return []string{in.ServiceHostname}
+	}
+	return in.Services
+}
+
+// RegisterScale registers the zerops_scale tool. stateDir locates the
+// project's .zcp/scaling.yaml profiles.
+func RegisterScale(srv *mcp.Server, client platform.Client, projectID, stateDir string) {
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "zerops_scale",
-		Description: "Scale a service: adjust CPU, RAM, disk, and container autoscaling parameters. Blocks until completion (FINISHED/FAILED). Constraints: HA mode immutable after creation; Docker has no autoscaling; CPU mode changeable once/hour; managed services (DB/cache) support vertical only, container count fixed by mode (NON_HA=1, HA=3). Use zerops_knowledge query=\"scaling\" for detailed scaling mechanics and strategy presets.",
+		Description: "Scale a service: adjust CPU, RAM, disk, and container autoscaling parameters. Blocks until completion (FINISHED/FAILED). Constraints: HA mode immutable after creation; Docker has no autoscaling; CPU mode changeable once/hour; managed services (DB/cache) support vertical only, container count fixed by mode (NON_HA=1, HA=3). profile= applies a named sizing to many services: dry-run diff first, apply=true scales all, reverting on failure.",
 		Annotations: &mcp.ToolAnnotations{
 			Title:           "Scale a service",
 			IdempotentHint:  true,
 			DestructiveHint: boolPtr(false),
 		},
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input ScaleInput) (*mcp.CallToolResult, any, error) {
+		if input.Profile != "" {
+			return handleScaleProfile(ctx, req, client, projectID, stateDir, input), nil, nil
+		}
 		if input.ServiceHostname == "" {
 			return convertError(platform.NewPlatformError(
 				platform.ErrServiceRequired, "Service hostname is required",
 				"Provide serviceHostname parameter")), nil, nil
 		}
 
-		result, err := ops.Scale(ctx, client, projectID, input.ServiceHostname, ops.ScaleParams{
-			CPUMode:           input.CPUMode,
-			MinCPU:            input.MinCPU,
-			MaxCPU:            input.MaxCPU,
-			StartCPU:          input.StartCPU,
-			MinRAM:            input.MinRAM,
-			MaxRAM:            input.MaxRAM,
-			MinDisk:           input.MinDisk,
-			MaxDisk:           input.MaxDisk,
-			MinContainers:     input.MinContainers,
-			MaxContainers:     input.MaxContainers,
-			MinFreeRAMGB:      input.MinFreeRAMGB,
-			MinFreeRAMPercent: input.MinFreeRAMPercent,
-			MinFreeCPUCores:   input.MinFreeCPUCores,
-			MinFreeCPUPercent: input.MinFreeCPUPercent,
-		})
+		result, err := ops.Scale(ctx, client, projectID, input.ServiceHostname, input.scaleParams())
 		if err != nil {
 			return convertError(err), nil, nil
 		}
@@ -72,3 +94,78 @@ func RegisterScale(srv *mcp.Server, client platform.Client, projectID string) {
 		return jsonResult(result), nil, nil
 	})
 }
+
+// handleScaleProfile plans a named profile and, with apply=true, scales
+// every changed service in parallel, polling each process and rolling
+// back on a partial failure.
+func handleScaleProfile(ctx context.Context, req *mcp.CallToolRequest, client platform.Client, projectID, stateDir string, input ScaleInput) *mcp.CallToolResult {
+	if input.scaleParams() != (ops.ScaleParams{}) {
+		return convertError(platform.NewPlatformError(platform.ErrInvalidParameter,
+			"profile cannot be combined with explicit scaling values",
+			"Pass either profile (with services) or cpuMode/minRam/... for a single service"))
+	}
+	plan, err := planScaleProfile(ctx, client, projectID, stateDir, input)
+	if err != nil {
+		return convertError(err)
+	}
+	if !input.Apply.Bool() {
+		return jsonResult(plan)
+	}
+
+	onProgress := buildProgressCallback(ctx, req)
+	result, err := ops.ApplyScaleProfile(ctx, client, plan, func(ctx context.Context, proc *platform.Process) *platform.Process {
+		proc, _ = pollManageProcess(ctx, client, proc, onProgress)
+		return proc
+	})
+	if err != nil {
+		return convertError(err)
+	}
+	for i := range result.Services {
+		if result.Services[i].Error == "" && !result.Services[i].RolledBack {
+			result.Services[i].NextActions = nextActionScaleSuccess
+		}
+	}
+	return jsonResult(result)
+}
+
+// planScaleProfile resolves input.Profile against .zcp/scaling.yaml and
+// the built-in tiers and diffs it against the target services. Shared
+// with the policy gate so both see the same plan.
+func planScaleProfile(ctx context.Context, client platform.Client, projectID, stateDir string, input ScaleInput) (*ops.ScaleProfilePlan, error) {
+	var cfg *ops.ScalingConfig
+	if stateDir != "" {
+		var err error
+		if cfg, err = ops.LoadScalingConfig(ops.ScalingConfigPath(stateDir)); err != nil {
+			return nil, platform.NewPlatformError(platform.ErrInvalidParameter, err.Error(),
+				"Fix .zcp/scaling.yaml: profiles.<name>.{mode, runtime, managed, services}")
+		}
+	}
+	profile, source, err := ops.ResolveScalingProfile(cfg, builtinScalingProfiles(), input.Profile)
+	if err != nil {
+		return nil, err
+	}
+	return ops.PlanScaleProfile(ctx, client, projectID, input.Profile, source, profile, input.profileServices())
+}
+
+// builtinScalingProfiles derives one profile per recipe tier, named by
+// the tier suffix. Runtimes get the tier's container floor, minimum RAM
+// and CPU mode; managed services its managed minimum RAM. Tiers without
+// a free-RAM reserve leave the service's current one alone.
+func builtinScalingProfiles() map[string]ops.ScalingProfile {
+	tiers := recipe.Tiers()
+	out := make(map[string]ops.ScalingProfile, len(tiers))
+	for _, t := range tiers {
+		cpuMode := t.CPUMode
+		if cpuMode == "" {
+			cpuMode = "SHARED"
+		}
+		runtime := ops.ProfileScale{CPUMode: &cpuMode, MinRAM: &t.RuntimeMinRAM, MinContainers: &t.RuntimeMinContainers}
+		managed := ops.ProfileScale{MinRAM: &t.ManagedMinRAM}
+		if t.MinFreeRAMGB > 0 {
+			runtime.MinFreeRAMGB = &t.MinFreeRAMGB
+			managed.MinFreeRAMGB = &t.MinFreeRAMGB
+		}
+		out[t.Suffix] = ops.ScalingProfile{Mode: t.ServiceMode, Runtime: &runtime, Managed: &managed}
+	}
+	return out
+}
@@ -4,9 +4,12 @@ package tools
 
 import (
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zcp/internal/ops"
 	"github.com/zeropsio/zcp/internal/platform"
 )
 
@@ -16,7 +19,7 @@ func TestScaleTool_Success(t *testing.T) {
 		WithServices([]platform.ServiceStack{{ID: "svc-1", Name: "api"}})
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterScale(srv, mock, "proj-1")
+	RegisterScale(srv, mock, "proj-1", "")
 
 	result := callTool(t, srv, "zerops_scale", map[string]any{
 		"serviceHostname": "api",
@@ -54,7 +57,7 @@ func TestScaleTool_PollsToFinished(t *testing.T) {
 		})
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterScale(srv, mock, "proj-1")
+	RegisterScale(srv, mock, "proj-1", "")
 
 	result := callTool(t, srv, "zerops_scale", map[string]any{
 		"serviceHostname": "api",
@@ -85,7 +88,7 @@ func TestScaleTool_WithDisk(t *testing.T) {
 		WithServices([]platform.ServiceStack{{ID: "svc-1", Name: "db"}})
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterScale(srv, mock, "proj-1")
+	RegisterScale(srv, mock, "proj-1", "")
 
 	result := callTool(t, srv, "zerops_scale", map[string]any{
 		"serviceHostname": "db",
@@ -104,7 +107,7 @@ func TestScaleTool_WithThresholds(t *testing.T) {
 		WithServices([]platform.ServiceStack{{ID: "svc-1", Name: "api"}})
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterScale(srv, mock, "proj-1")
+	RegisterScale(srv, mock, "proj-1", "")
 
 	result := callTool(t, srv, "zerops_scale", map[string]any{
 		"serviceHostname":   "api",
@@ -135,7 +138,7 @@ func TestScaleTool_ThresholdOnly(t *testing.T) {
 		WithServices([]platform.ServiceStack{{ID: "svc-1", Name: "api"}})
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterScale(srv, mock, "proj-1")
+	RegisterScale(srv, mock, "proj-1", "")
 
 	result := callTool(t, srv, "zerops_scale", map[string]any{
 		"serviceHostname": "api",
@@ -160,9 +163,10 @@ func TestScaleTool_MissingService(t *testing.T) {
 	mock := platform.NewMock()
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterScale(srv, mock, "proj-1")
+	RegisterScale(srv, mock, "proj-1", "")
 
-	// SDK schema validation rejects missing required "serviceHostname" field.
+	// serviceHostname is optional in the schema (profile calls span
+	// services); the handler rejects a raw-values call without it.
 	err := callToolMayError(t, srv, "zerops_scale", map[string]any{
 		"minCpu": 1,
 	})
@@ -176,7 +180,7 @@ func TestScaleTool_EmptyServiceHostname(t *testing.T) {
 	mock := platform.NewMock()
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterScale(srv, mock, "proj-1")
+	RegisterScale(srv, mock, "proj-1", "")
 
 	result := callTool(t, srv, "zerops_scale", map[string]any{
 		"serviceHostname": "",
@@ -187,3 +191,99 @@ func TestScaleTool_EmptyServiceHostname(t *testing.T) {
 		t.Error("expected IsError for empty serviceHostname")
 	}
 }
+
+func scaleProfileToolMock() *platform.Mock {
+	return platform.NewMock().
+		WithServices([]platform.ServiceStack{
+			{ID: "svc-api", Name: "api", Mode: "NON_HA",
+				ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "nodejs@22", ServiceStackTypeCategoryName: "USER"},
+				CurrentAutoscaling:   &platform.CustomAutoscaling{CPUMode: "SHARED", MinRAM: 0.25, MaxRAM: 4, HorizontalMinCount: 1, HorizontalMaxCount: 4}},
+			{ID: "svc-db", Name: "db", Mode: "NON_HA",
+				ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "postgresql@16", ServiceStackTypeCategoryName: "STANDARD"},
+				CurrentAutoscaling:   &platform.CustomAutoscaling{CPUMode: "SHARED", MinRAM: 0.25, MaxRAM: 4}},
+		}).
+		WithAutoscalingProcess(&platform.Process{ID: "proc-scale-1", ActionName: "scale", Status: "PENDING"}).
+		WithProcess(&platform.Process{ID: "proc-scale-1", ActionName: "scale", Status: statusFinished})
+}
+
+func TestScaleTool_ProfileDryRun(t *testing.T) {
+	t.Parallel()
+	mock := scaleProfileToolMock()
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
+	RegisterScale(srv, mock, "proj-1", "")
+
+	result := callTool(t, srv, "zerops_scale", map[string]any{"profile": "small-prod"})
+	if result.IsError {
+		t.Fatalf("unexpected IsError: %s", getTextContent(t, result))
+	}
+	var plan ops.ScaleProfilePlan
+	if err := json.Unmarshal([]byte(getTextContent(t, result)), &plan); err != nil {
+		t.Fatalf("failed to parse result: %v", err)
+	}
+	if plan.Source != "builtin" || len(plan.Services) != 2 {
+		t.Fatalf("plan = %+v", plan)
+	}
+	// small-prod: runtime minRam 0.5, two containers, 0.25 GB free RAM.
+	if n := len(plan.Services[0].Changes); n != 3 {
+		t.Errorf("api changes = %+v", plan.Services[0].Changes)
+	}
+	if mock.CallCounts["SetAutoscaling"] != 0 {
+		t.Error("dry run must not scale anything")
+	}
+}
+
+func TestScaleTool_ProfileApply(t *testing.T) {
+	t.Parallel()
+	mock := scaleProfileToolMock()
+
+	dir := t.TempDir()
+	stateDir := filepath.Join(dir, ".zcp", "state")
+	if err := os.MkdirAll(stateDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".zcp", "scaling.yaml"),
+		[]byte("profiles:\n  prod:\n    runtime: {minRam: 1}\n    managed: {minRam: 1}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
+	RegisterScale(srv, mock, "proj-1", stateDir)
+
+	result := callTool(t, srv, "zerops_scale", map[string]any{"profile": "prod", "services": []string{"api", "db"}, "apply": true})
+	if result.IsError {
+		t.Fatalf("unexpected IsError: %s", getTextContent(t, result))
+	}
+	var applied ops.ScaleProfileResult
+	if err := json.Unmarshal([]byte(getTextContent(t, result)), &applied); err != nil {
+		t.Fatalf("failed to parse result: %v", err)
+	}
+	if applied.Source != ops.ScalingFileName || applied.Summary != "2/2 scaled" {
+		t.Errorf("result = %+v", applied)
+	}
+	for _, s := range applied.Services {
+		if s.Process == nil || s.Process.Status != statusFinished || s.NextActions != nextActionScaleSuccess {
+			t.Errorf("%s = %+v, want a polled FINISHED process", s.Hostname, s)
+		}
+	}
+	if mock.CallCounts["SetAutoscaling"] != 2 {
+		t.Errorf("SetAutoscaling calls = %d, want 2", mock.CallCounts["SetAutoscaling"])
+	}
+}
+
+func TestScaleTool_ProfileErrors(t *testing.T) {
+	t.Parallel()
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
+	RegisterScale(srv, scaleProfileToolMock(), "proj-1", "")
+
+	for name, args := range map[string]map[string]any{
+		"unknown profile": {"profile": "huge"},
+		"mixed values":    {"profile": "stage", "minRam": 2},
+		"unknown service": {"profile": "stage", "services": []string{"nope"}},
+	} {
+		if result := callTool(t, srv, "zerops_scale", args); !result.IsError {
+			t.Errorf("%s: expected IsError, got %s", name, getTextContent(t, result))
+		}
+	}
+}
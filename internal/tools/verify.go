@@ -14,13 +14,20 @@ import (
 	"github.com/zeropsio/zcp/internal/workflow"
 )
 
+const (
+	verifyModeHealth       = "health"
+	verifyModeConnectivity = "connectivity"
+)
+
 // VerifyInput is the input type for zerops_verify.
 type VerifyInput struct {
 	ServiceHostname string `json:"serviceHostname,omitempty" jsonschema:"Hostname of the service to verify. Omit to verify all services."`
+	Mode            string `json:"mode,omitempty" jsonschema:"health (default) runs per-service checks. connectivity dials every managed service a runtime's env vars reference from inside that runtime and returns a source x target matrix."`
 }
 
-// RegisterVerify registers the zerops_verify tool.
-func RegisterVerify(srv *mcp.Server, client platform.Client, fetcher platform.LogFetcher, projectID, stateDir string) {
+// RegisterVerify registers the zerops_verify tool. sshDeployer may be nil
+// (local mode without SSH), in which case mode=connectivity is unavailable.
+func RegisterVerify(srv *mcp.Server, client platform.Client, fetcher platform.LogFetcher, sshDeployer ops.SSHDeployer, projectID, stateDir string) {
 	httpClient := &http.Client{
 		Timeout: 15 * time.Second,
 		Transport: &http.Transport{
@@ -30,15 +37,38 @@ func RegisterVerify(srv *mcp.Server, client platform.Client, fetcher platform.Lo
 
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "zerops_verify",
-		Description: "Run health checks on a service. Returns structured results: service status, error logs, startup detection, HTTP connectivity. Check statuses: pass, fail, skip, info (advisory, not failure). Omit serviceHostname to verify all services.",
+		Description: "Run health checks on a service: status, error logs, HTTP connectivity, managed-service protocol login, plus user-declared checks from .zcp/verify.yaml (declared=true). Check statuses: pass, fail, skip, info (advisory). Omit serviceHostname to verify all services. mode=connectivity returns a runtime x managed-service reachability matrix probed over SSH.",
 		Annotations: &mcp.ToolAnnotations{
 			Title:          "Verify service health",
 			ReadOnlyHint:   true,
 			IdempotentHint: true,
 		},
 	}, func(ctx context.Context, _ *mcp.CallToolRequest, input VerifyInput) (*mcp.CallToolResult, any, error) {
+		switch input.Mode {
+		case "", verifyModeHealth:
+		case verifyModeConnectivity:
+			if sshDeployer == nil {
+				return convertError(platform.NewPlatformError(platform.ErrPrerequisiteMissing,
+					"mode=connectivity needs SSH access to runtime containers",
+					"Run ZCP inside the project (zcp container) or use the default health mode")), nil, nil
+			}
+			result, err := ops.VerifyConnectivity(ctx, client, sshDeployer, projectID, input.ServiceHostname, connectivityDeclaredEnv(stateDir))
+			if err != nil {
+				return convertError(err, WithRecoveryStatus()), nil, nil
+			}
+			return jsonResult(result), nil, nil
+		default:
+			return convertError(platform.NewPlatformError(platform.ErrInvalidParameter,
+				fmt.Sprintf("unknown mode %q", input.Mode),
+				"Use mode=health (default) or mode=connectivity")), nil, nil
+		}
+
+		declared, err := loadDeclaredChecks(stateDir)
+		if err != nil {
+			return convertError(err), nil, nil
+		}
 		if input.ServiceHostname == "" {
-			result, err := ops.VerifyAll(ctx, client, fetcher, httpClient, projectID)
+			result, err := ops.VerifyAll(ctx, client, fetcher, httpClient, projectID, declared)
 			if err != nil {
 				return convertError(err, WithRecoveryStatus()), nil, nil
 			}
@@ -48,7 +78,7 @@ func RegisterVerify(srv *mcp.Server, client platform.Client, fetcher platform.Lo
 				WorkSessionState: sessionAnnotations(stateDir),
 			}), nil, nil
 		}
-		result, err := ops.Verify(ctx, client, fetcher, httpClient, projectID, input.ServiceHostname)
+		result, err := ops.Verify(ctx, client, fetcher, httpClient, projectID, input.ServiceHostname, declared)
 		if err != nil {
 			return convertError(err, WithRecoveryStatus()), nil, nil
 		}
@@ -60,6 +90,52 @@ func RegisterVerify(srv *mcp.Server, client platform.Client, fetcher platform.Lo
 	})
 }
 
+// loadDeclaredChecks reads .zcp/verify.yaml on every call so edits apply
+// without a restart. No state dir means no project-level config.
+func loadDeclaredChecks(stateDir string) (*ops.VerifyConfig, error) {
+	if stateDir == "" {
+		return nil, nil
+	}
+	cfg, err := ops.LoadVerifyConfig(ops.VerifyConfigPath(stateDir))
+	if err != nil {
+		return nil, platform.NewPlatformError(platform.ErrInvalidParameter, err.Error(),
+			"Fix .zcp/verify.yaml: services.<hostname> is a list of {name, path, method, expectStatus, bodyMatches, json: {path, equals}, maxLatency, retries}")
+	}
+	return cfg, nil
+}
+
+// connectivityDeclaredEnv collects zerops.yaml run.envVariables for every
+// adopted runtime, read from the dev mount and resolved to a setup the same
+// way deploy pre-flight does. Best-effort: a missing or invalid yaml leaves
+// the API env vars alone to decide the edges.
+func connectivityDeclaredEnv(stateDir string) map[string]map[string]string {
+	if stateDir == "" {
+		return nil
+	}
+	metas, err := workflow.ListServiceMetas(stateDir)
+	if err != nil {
+		return nil
+	}
+	projectRoot := projectRootFromState(stateDir)
+	out := make(map[string]map[string]string)
+	for _, meta := range metas {
+		doc, _, err := findAndParseZeropsYml(projectRoot, meta.Hostname, "")
+		if err != nil {
+			continue
+		}
+		for _, host := range meta.Hostnames() {
+			role := meta.RoleFor(host)
+			if role == "" {
+				role = meta.PrimaryRole()
+			}
+			if entry := resolveSetupEntry(doc, "", role, host); entry != nil && len(entry.EnvVariables) > 0 {
+				out[host] = entry.EnvVariables
+			}
+		}
+	}
+	return out
+}
+
 // verifyResponse wraps ops.VerifyResult with the structured
 // WorkSessionState lifecycle signal (F5 closure). Surfacing the
 // session state turns verify from a pure HTTP probe into an observable
@@ -79,7 +155,9 @@ type verifyAllResponse struct {
 }
 
 // recordVerifyToWorkSession records one service verify result as a WorkSession attempt.
-// Pass = summary "healthy" status; fail = first failing check detail.
+// Pass = summary "healthy" status with every declared check passed (a
+// skipped declared check blocks auto-close too); fail = first failing
+// check detail.
 //
 // On failure the FailureClass is populated from the failing check's name —
 // `service_running` → FailureClassStart (container not up); HTTP-shape
@@ -92,7 +170,7 @@ func recordVerifyToWorkSession(stateDir string, r *ops.VerifyResult) {
 	attempt := workflow.VerifyAttempt{
 		AttemptedAt: time.Now().UTC().Format(time.RFC3339),
 	}
-	passed := r.Status == statusHealthy
+	passed := r.Status == statusHealthy && ops.DeclaredChecksPassed(r)
 	if passed {
 		attempt.Passed = true
 		attempt.PassedAt = attempt.AttemptedAt
@@ -145,5 +223,10 @@ func verifyFailureSummary(r *ops.VerifyResult) string {
 			return c.Name
 		}
 	}
+	for _, c := range r.Checks {
+		if c.Declared && c.Status != ops.CheckPass {
+			return fmt.Sprintf("declared check %s not passed: %s", c.Name, c.Detail)
+		}
+	}
 	return r.Status
 }
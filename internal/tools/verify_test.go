@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -27,7 +28,7 @@ func TestVerifyTool_RuntimeHealthy(t *testing.T) {
 	fetcher := platform.NewMockLogFetcher()
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterVerify(srv, mock, fetcher, "proj-1", "")
+	RegisterVerify(srv, mock, fetcher, nil, "proj-1", "")
 
 	result := callTool(t, srv, "zerops_verify", map[string]any{"serviceHostname": "app"})
 
@@ -58,7 +59,7 @@ func TestVerifyTool_ManagedHealthy(t *testing.T) {
 	fetcher := platform.NewMockLogFetcher()
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterVerify(srv, mock, fetcher, "proj-1", "")
+	RegisterVerify(srv, mock, fetcher, nil, "proj-1", "")
 
 	result := callTool(t, srv, "zerops_verify", map[string]any{"serviceHostname": "db"})
 
@@ -76,8 +77,9 @@ func TestVerifyTool_ManagedHealthy(t *testing.T) {
 	if vr.Status != "healthy" {
 		t.Errorf("Status = %q, want healthy", vr.Status)
 	}
-	if len(vr.Checks) != 1 {
-		t.Errorf("Checks count = %d, want 1", len(vr.Checks))
+	// service_running + the protocol probe, skipped without env vars.
+	if len(vr.Checks) != 2 {
+		t.Errorf("Checks count = %d, want 2", len(vr.Checks))
 	}
 }
 
@@ -92,7 +94,7 @@ func TestVerifyTool_RuntimeActive(t *testing.T) {
 	fetcher := platform.NewMockLogFetcher()
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterVerify(srv, mock, fetcher, "proj-1", "")
+	RegisterVerify(srv, mock, fetcher, nil, "proj-1", "")
 
 	result := callTool(t, srv, "zerops_verify", map[string]any{"serviceHostname": "app"})
 
@@ -128,7 +130,7 @@ func TestVerifyTool_NotFound(t *testing.T) {
 	fetcher := platform.NewMockLogFetcher()
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterVerify(srv, mock, fetcher, "proj-1", "")
+	RegisterVerify(srv, mock, fetcher, nil, "proj-1", "")
 
 	result := callTool(t, srv, "zerops_verify", map[string]any{"serviceHostname": "nonexistent"})
 
@@ -148,7 +150,7 @@ func TestVerifyTool_GracefulLogError(t *testing.T) {
 	fetcher := platform.NewMockLogFetcher()
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterVerify(srv, mock, fetcher, "proj-1", "")
+	RegisterVerify(srv, mock, fetcher, nil, "proj-1", "")
 
 	result := callTool(t, srv, "zerops_verify", map[string]any{"serviceHostname": "app"})
 
@@ -181,7 +183,7 @@ func TestVerifyTool_BatchMode(t *testing.T) {
 	fetcher := platform.NewMockLogFetcher()
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterVerify(srv, mock, fetcher, "proj-1", "")
+	RegisterVerify(srv, mock, fetcher, nil, "proj-1", "")
 
 	// Call with empty serviceHostname → batch mode.
 	result := callTool(t, srv, "zerops_verify", map[string]any{})
@@ -213,7 +215,7 @@ func TestVerifyTool_SingleMode(t *testing.T) {
 	fetcher := platform.NewMockLogFetcher()
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterVerify(srv, mock, fetcher, "proj-1", "")
+	RegisterVerify(srv, mock, fetcher, nil, "proj-1", "")
 
 	// Call with serviceHostname → single mode, returns VerifyResult.
 	result := callTool(t, srv, "zerops_verify", map[string]any{"serviceHostname": "app"})
@@ -268,7 +270,7 @@ func TestVerifyTool_ReportsLifecycleState(t *testing.T) {
 	fetcher := platform.NewMockLogFetcher()
 
 	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
-	RegisterVerify(srv, mock, fetcher, "proj-1", dir)
+	RegisterVerify(srv, mock, fetcher, nil, "proj-1", dir)
 
 	result := callTool(t, srv, "zerops_verify", map[string]any{"serviceHostname": "worker"})
 	if result.IsError {
@@ -287,3 +289,97 @@ func TestVerifyTool_ReportsLifecycleState(t *testing.T) {
 		}
 	}
 }
+
+// A declared check that cannot pass keeps the work session open even when
+// the service itself aggregates to healthy: the worker has no subdomain,
+// so its declared /healthz check is skipped, not proven.
+func TestVerifyTool_DeclaredCheckBlocksAutoClose(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	dir := filepath.Join(root, ".zcp", "state")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".zcp", ops.VerifyFileName),
+		[]byte("services:\n  worker:\n    - path: /healthz\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	ws := workflow.NewWorkSession("proj-1", string(workflow.EnvContainer), "scope demo", []string{"worker"})
+	ws.Deploys = map[string][]workflow.DeployAttempt{
+		"worker": {{AttemptedAt: now, SucceededAt: now}},
+	}
+	if err := workflow.SaveWorkSession(dir, ws); err != nil {
+		t.Fatalf("SaveWorkSession: %v", err)
+	}
+	t.Cleanup(func() { _ = workflow.DeleteWorkSession(dir, os.Getpid()) })
+
+	mock := platform.NewMock().
+		WithServices([]platform.ServiceStack{
+			{ID: "svc-worker", Name: "worker", ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "nodejs@22", ServiceStackTypeCategoryName: "USER"}, Status: serviceStatusRunning},
+		})
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
+	RegisterVerify(srv, mock, platform.NewMockLogFetcher(), nil, "proj-1", dir)
+
+	result := callTool(t, srv, "zerops_verify", map[string]any{"serviceHostname": "worker"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", getTextContent(t, result))
+	}
+	text := getTextContent(t, result)
+	for _, needle := range []string{`"name":"/healthz"`, `"declared":true`, `"status":"healthy"`} {
+		if !contains(text, needle) {
+			t.Errorf("response missing %q:\n%s", needle, text)
+		}
+	}
+	if contains(text, `"auto-closed"`) {
+		t.Errorf("session must stay open while a declared check is unproven:\n%s", text)
+	}
+
+	// An invalid file is reported instead of silently verifying without it.
+	if err := os.WriteFile(filepath.Join(root, ".zcp", ops.VerifyFileName), []byte("services: {worker: [{path: healthz}]}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if result := callTool(t, srv, "zerops_verify", map[string]any{"serviceHostname": "worker"}); !result.IsError {
+		t.Error("expected IsError for invalid verify.yaml")
+	}
+}
+
+func TestVerifyTool_ConnectivityMode(t *testing.T) {
+	t.Parallel()
+
+	mock := platform.NewMock().
+		WithServices([]platform.ServiceStack{
+			{ID: "svc-1", Name: "api", ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "nodejs@22", ServiceStackTypeCategoryName: "USER"}, Status: serviceStatusActive},
+			{ID: "svc-2", Name: "db", ServiceStackTypeInfo: platform.ServiceTypeInfo{ServiceStackTypeVersionName: "postgresql@16", ServiceStackTypeCategoryName: "STANDARD"}, Status: serviceStatusRunning},
+		}).
+		WithServiceEnv("svc-1", []platform.EnvVar{{Key: "DB_HOST", Content: "${db_hostname}"}}).
+		WithServiceEnv("svc-2", []platform.EnvVar{{Key: "hostname", Content: "db"}, {Key: "port", Content: "5432"}})
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
+	RegisterVerify(srv, mock, platform.NewMockLogFetcher(), &stubSSH{output: []byte("db:5432 0 0.001 0.003\n")}, "proj-1", "")
+
+	result := callTool(t, srv, "zerops_verify", map[string]any{"mode": "connectivity"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", getTextContent(t, result))
+	}
+	var cr ops.ConnectivityResult
+	if err := json.Unmarshal([]byte(getTextContent(t, result)), &cr); err != nil {
+		t.Fatalf("failed to parse result: %v", err)
+	}
+	if cr.Matrix["api"]["db"] != ops.EdgeReachable || cr.Status != statusHealthy {
+		t.Errorf("matrix = %v, status = %s", cr.Matrix, cr.Status)
+	}
+
+	if result := callTool(t, srv, "zerops_verify", map[string]any{"mode": "matrix"}); !result.IsError {
+		t.Error("unknown mode: expected IsError")
+	}
+
+	noSSH := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1"}, nil)
+	RegisterVerify(noSSH, mock, platform.NewMockLogFetcher(), nil, "proj-1", "")
+	result = callTool(t, noSSH, "zerops_verify", map[string]any{"mode": "connectivity"})
+	if !result.IsError || !contains(getTextContent(t, result), platform.ErrPrerequisiteMissing) {
+		t.Errorf("want PREREQUISITE_MISSING without SSH, got %s", getTextContent(t, result))
+	}
+}
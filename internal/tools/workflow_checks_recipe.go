@@ -43,6 +43,9 @@ func buildRecipeStepChecker(ctx context.Context, step, _, stateDir string, schem
 		if schemaCache != nil {
 			if schemas := schemaCache.Get(ctx); schemas != nil && schemas.ZeropsYml != nil {
 				validFields = schema.ExtractValidFields(schemas.ZeropsYml)
+				if validFields != nil {
+					validFields.Provenance = schemas.Provenance
+				}
 			}
 		}
 		return checkRecipeGenerate(stateDir, validFields, kp)
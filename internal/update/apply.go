@@ -1,7 +1,11 @@
 package update
 
 import (
+	"bytes"
 	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -44,13 +48,34 @@ func CanWrite(dir string) bool {
 	return true
 }
 
-// Apply downloads the new binary and atomically replaces the current one.
-// If info.Available is false, this is a no-op.
+// PrevSuffix is appended to the binary path for the copy of the binary an
+// update replaced (zcp → zcp.prev), restored by Rollback.
+const PrevSuffix = ".prev"
+
+// PreviousPath returns where Apply keeps the replaced binary.
+func PreviousPath(binaryPath string) string {
+	return binaryPath + PrevSuffix
+}
+
+// Apply downloads the new binary, verifies it against the signed release
+// checksum manifest and atomically replaces the current one, keeping the
+// old binary at PreviousPath. If info.Available is false, this is a no-op.
+//
+// Nothing on disk changes unless the manifest signature verifies against
+// ReleasePublicKey and the download matches its SHA-256 — a tampered or
+// truncated download fails here, not at the next restart.
 func Apply(ctx context.Context, info *Info, binaryPath string, client *http.Client) error {
 	if !info.Available {
 		return nil
 	}
+	key, err := releaseKey()
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+	return apply(ctx, info, binaryPath, client, key)
+}
 
+func apply(ctx context.Context, info *Info, binaryPath string, client *http.Client, key ed25519.PublicKey) error {
 	if client == nil {
 		client = &http.Client{Timeout: downloadTimeout}
 	}
@@ -58,6 +83,11 @@ func Apply(ctx context.Context, info *Info, binaryPath string, client *http.Clie
 	ctx, cancel := context.WithTimeout(ctx, downloadTimeout)
 	defer cancel()
 
+	wantSum, err := fetchExpectedSum(ctx, client, info.DownloadURL, key)
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, info.DownloadURL, nil)
 	if err != nil {
 		return fmt.Errorf("download: create request: %w", err)
@@ -94,19 +124,28 @@ func Apply(ctx context.Context, info *Info, binaryPath string, client *http.Clie
 		}
 	}()
 
-	if _, err := io.Copy(tmp, resp.Body); err != nil {
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), resp.Body); err != nil {
 		tmp.Close()
 		return fmt.Errorf("write binary: %w", err)
 	}
 	if err := tmp.Close(); err != nil {
 		return fmt.Errorf("close temp file: %w", err)
 	}
+	if gotSum := hash.Sum(nil); !bytes.Equal(gotSum, wantSum) {
+		return fmt.Errorf("verify: %s checksum mismatch (got %x, want %x)", releaseAssetName(info.DownloadURL), gotSum, wantSum)
+	}
 
 	if err := os.Chmod(tmpPath, 0o755); err != nil {
 		return fmt.Errorf("chmod: %w", err)
 	}
 
 	if sameFS {
+		// The previous binary can only be kept where the new one lands —
+		// a read-only directory takes the copy fallback below without it.
+		if err := keepPrevious(binaryPath); err != nil {
+			return fmt.Errorf("keep previous binary: %w", err)
+		}
 		// Atomic rename (same filesystem).
 		if err := os.Rename(tmpPath, binaryPath); err != nil {
 			return fmt.Errorf("replace binary: %w", err)
@@ -121,3 +160,72 @@ func Apply(ctx context.Context, info *Info, binaryPath string, client *http.Clie
 	}
 	return nil
 }
+
+// keepPrevious copies binaryPath to PreviousPath via a temp file, so an
+// interrupted copy never leaves a truncated zcp.prev behind.
+func keepPrevious(binaryPath string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(binaryPath), "zcp-prev-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+	if err := os.Chmod(tmpPath, 0o755); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := copyFile(binaryPath, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, PreviousPath(binaryPath)); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// ErrNoPrevious is returned by Rollback when no previous binary was kept.
+var ErrNoPrevious = errors.New("no previous binary to roll back to")
+
+// Rollback restores the binary kept at PreviousPath by the last Apply.
+// The binaries swap places, so a second Rollback returns to the updated
+// version. binaryPath always exists while the swap runs: the previous
+// binary is renamed over it atomically after the current one was copied
+// aside.
+func Rollback(binaryPath string) error {
+	prev := PreviousPath(binaryPath)
+	if _, err := os.Stat(prev); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w (%s)", ErrNoPrevious, prev)
+		}
+		return fmt.Errorf("stat previous binary: %w", err)
+	}
+
+	aside, err := os.CreateTemp(filepath.Dir(binaryPath), "zcp-rollback-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	asidePath := aside.Name()
+	aside.Close()
+	defer func() {
+		if asidePath != "" {
+			os.Remove(asidePath)
+		}
+	}()
+	if err := os.Chmod(asidePath, 0o755); err != nil {
+		return fmt.Errorf("chmod: %w", err)
+	}
+	if err := copyFile(binaryPath, asidePath); err != nil {
+		return fmt.Errorf("save current binary: %w", err)
+	}
+
+	if err := os.Rename(prev, binaryPath); err != nil {
+		return fmt.Errorf("restore previous binary: %w", err)
+	}
+	if err := os.Rename(asidePath, prev); err != nil {
+		return fmt.Errorf("keep current binary as %s: %w", filepath.Base(prev), err)
+	}
+	asidePath = ""
+	return nil
+}
@@ -18,9 +18,8 @@ func TestApply_Success(t *testing.T) {
 	t.Parallel()
 
 	binaryContent := []byte("#!/bin/sh\necho new-version\n")
-	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
-		_, _ = w.Write(binaryContent)
-	}))
+	release := newSignedRelease(t, "zcp-darwin-arm64", binaryContent)
+	srv := httptest.NewServer(release)
 	defer srv.Close()
 
 	// Create a fake current binary.
@@ -35,9 +34,9 @@ func TestApply_Success(t *testing.T) {
 		DownloadURL: srv.URL + "/zcp-darwin-arm64",
 	}
 
-	err := Apply(t.Context(), info, binaryPath, srv.Client())
+	err := apply(t.Context(), info, binaryPath, srv.Client(), release.key)
 	if err != nil {
-		t.Fatalf("Apply() error: %v", err)
+		t.Fatalf("apply() error: %v", err)
 	}
 
 	// Verify the binary was replaced.
@@ -57,6 +56,15 @@ func TestApply_Success(t *testing.T) {
 	if fi.Mode()&0o111 == 0 {
 		t.Error("binary should be executable")
 	}
+
+	// The replaced binary is kept for rollback.
+	prev, err := os.ReadFile(PreviousPath(binaryPath))
+	if err != nil {
+		t.Fatalf("previous binary not kept: %v", err)
+	}
+	if string(prev) != "old" {
+		t.Errorf("previous binary = %q, want %q", prev, "old")
+	}
 }
 
 func TestApply_DownloadError(t *testing.T) {
@@ -78,7 +86,8 @@ func TestApply_DownloadError(t *testing.T) {
 		DownloadURL: srv.URL + "/missing",
 	}
 
-	err := Apply(t.Context(), info, binaryPath, srv.Client())
+	release := newSignedRelease(t, "missing", nil)
+	err := apply(t.Context(), info, binaryPath, srv.Client(), release.key)
 	if err == nil {
 		t.Fatal("expected error on 404")
 	}
@@ -115,7 +124,8 @@ func TestApply_NetworkError(t *testing.T) {
 	}
 
 	client := &http.Client{Timeout: 1}
-	err := Apply(t.Context(), info, binaryPath, client)
+	release := newSignedRelease(t, "zcp", nil)
+	err := apply(t.Context(), info, binaryPath, client, release.key)
 	if err == nil {
 		t.Fatal("expected error on network failure")
 	}
@@ -137,9 +147,8 @@ func TestApply_ReadOnlyDir_FallbackCopy(t *testing.T) {
 	t.Parallel()
 
 	binaryContent := []byte("#!/bin/sh\necho new-version\n")
-	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
-		_, _ = w.Write(binaryContent)
-	}))
+	release := newSignedRelease(t, "zcp-darwin-arm64", binaryContent)
+	srv := httptest.NewServer(release)
 	defer srv.Close()
 
 	// Create binary in a read-only directory (simulates /usr/local/bin/ without write).
@@ -166,9 +175,9 @@ func TestApply_ReadOnlyDir_FallbackCopy(t *testing.T) {
 		DownloadURL: srv.URL + "/zcp-darwin-arm64",
 	}
 
-	err := Apply(t.Context(), info, binaryPath, srv.Client())
+	err := apply(t.Context(), info, binaryPath, srv.Client(), release.key)
 	if err != nil {
-		t.Fatalf("Apply() with read-only dir should fallback to copy, got error: %v", err)
+		t.Fatalf("apply() with read-only dir should fallback to copy, got error: %v", err)
 	}
 
 	// Verify the binary was replaced.
@@ -1,6 +1,7 @@
 // Package update provides self-update functionality for the ZCP binary.
-// It checks GitHub releases for newer versions and can download + replace
-// the running binary before the MCP server starts.
+// It checks GitHub releases for newer versions and can download, verify
+// (signed SHA-256 manifest) and replace the running binary before the MCP
+// server starts, keeping the replaced binary for rollback.
 package update
 
 import (
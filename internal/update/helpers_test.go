@@ -2,7 +2,14 @@ package update
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"path"
 	"sync"
+	"testing"
 )
 
 // syncBuffer is a thread-safe bytes.Buffer for tests where a background
@@ -23,3 +30,40 @@ func (b *syncBuffer) String() string {
 	defer b.mu.Unlock()
 	return b.buf.String()
 }
+
+// signedRelease is a fake release directory: a binary asset plus the
+// SHA256SUMS manifest and its ed25519 signature.
+type signedRelease struct {
+	key    ed25519.PublicKey
+	assets map[string][]byte
+}
+
+// newSignedRelease signs a manifest covering binary under asset. The
+// returned handler serves any "…/<name>" path from the release assets.
+func newSignedRelease(t *testing.T, asset string, binary []byte) *signedRelease {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(binary)
+	manifest := []byte(fmt.Sprintf("%x  %s\n%x  zcp-other-arch\n", sum, asset, sha256.Sum256([]byte("other"))))
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, manifest))
+	return &signedRelease{
+		key: pub,
+		assets: map[string][]byte{
+			asset:          binary,
+			ChecksumsAsset: manifest,
+			SignatureAsset: []byte(sig + "\n"),
+		},
+	}
+}
+
+func (r *signedRelease) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	data, ok := r.assets[path.Base(req.URL.Path)]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	_, _ = w.Write(data)
+}
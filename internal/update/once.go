@@ -2,6 +2,7 @@ package update
 
 import (
 	"context"
+	"crypto/ed25519"
 	"fmt"
 	"io"
 	"os"
@@ -13,8 +14,9 @@ import (
 type OnceOpts struct {
 	CurrentVersion string
 	LogOutput      io.Writer
-	BinaryPath     string // empty = use os.Executable()
-	CacheDir       string // empty = use default cache dir
+	BinaryPath     string            // empty = use os.Executable()
+	CacheDir       string            // empty = use default cache dir
+	PublicKey      ed25519.PublicKey // empty = ReleasePublicKey
 }
 
 // Once checks for an update and applies it (replaces the binary on disk).
@@ -64,7 +66,16 @@ func OnceWithOpts(ctx context.Context, opts OnceOpts) {
 		return
 	}
 
-	if err := Apply(ctx, info, binary, nil); err != nil {
+	key := opts.PublicKey
+	if key == nil {
+		var err error
+		if key, err = releaseKey(); err != nil {
+			fmt.Fprintf(opts.LogOutput, "zcp: auto-update: %v\n", err)
+			return
+		}
+	}
+
+	if err := apply(ctx, info, binary, nil, key); err != nil {
 		fmt.Fprintf(opts.LogOutput, "zcp: auto-update: %v\n", err)
 		return
 	}
@@ -60,14 +60,13 @@ func TestOnce_UpdateAvailable_AppliesWithoutShutdown(t *testing.T) {
 	// Cannot use t.Parallel() — t.Setenv modifies process environment.
 
 	newBinary := []byte("#!/bin/sh\necho v2\n")
+	release := newSignedRelease(t, assetName(runtime.GOOS, runtime.GOARCH), newBinary)
 	mux := http.NewServeMux()
 	mux.HandleFunc("/repos/zeropsio/zcp/releases/latest", func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(githubRelease{TagName: "v99.0.0"})
 	})
-	mux.HandleFunc("/download/", func(w http.ResponseWriter, _ *http.Request) {
-		_, _ = w.Write(newBinary)
-	})
+	mux.Handle("/download/", release)
 	srv := httptest.NewServer(mux)
 	defer srv.Close()
 	t.Setenv("ZCP_UPDATE_URL", srv.URL)
@@ -85,6 +84,7 @@ func TestOnce_UpdateAvailable_AppliesWithoutShutdown(t *testing.T) {
 		LogOutput:      &logBuf,
 		BinaryPath:     binaryPath,
 		CacheDir:       t.TempDir(),
+		PublicKey:      release.key,
 	})
 
 	// Binary should be replaced on disk.
@@ -0,0 +1,134 @@
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// ChecksumsAsset is the release asset listing "sha256  filename" for
+	// every binary, in sha256sum(1) format.
+	ChecksumsAsset = "SHA256SUMS"
+	// SignatureAsset is the base64 ed25519 signature over ChecksumsAsset.
+	SignatureAsset = "SHA256SUMS.sig"
+
+	maxManifestSize = 64 << 10
+)
+
+// ReleasePublicKey is the base64 ed25519 public key release manifests are
+// signed with. Injected at release build time via
+// -ldflags "-X github.com/zeropsio/zcp/internal/update.ReleasePublicKey=...".
+// Builds without it refuse to self-update rather than install an
+// unverified binary.
+var ReleasePublicKey string
+
+// ErrNoPublicKey is returned when the binary carries no release key.
+var ErrNoPublicKey = errors.New("this build has no release public key; download the release manually")
+
+// releaseKey decodes ReleasePublicKey.
+func releaseKey() (ed25519.PublicKey, error) {
+	if ReleasePublicKey == "" {
+		return nil, ErrNoPublicKey
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(ReleasePublicKey))
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid release public key (want base64 of %d bytes)", ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// releaseAssetURL returns the URL of a sibling asset of downloadURL in the
+// same release (…/download/<tag>/<asset>).
+func releaseAssetURL(downloadURL, asset string) string {
+	return downloadURL[:strings.LastIndex(downloadURL, "/")+1] + asset
+}
+
+// releaseAssetName returns the asset filename downloadURL points at.
+func releaseAssetName(downloadURL string) string {
+	return downloadURL[strings.LastIndex(downloadURL, "/")+1:]
+}
+
+// fetchExpectedSum downloads the release checksum manifest and its
+// signature, verifies the signature against key and returns the
+// SHA-256 listed for asset.
+func fetchExpectedSum(ctx context.Context, client *http.Client, downloadURL string, key ed25519.PublicKey) ([]byte, error) {
+	manifest, err := fetchSmall(ctx, client, releaseAssetURL(downloadURL, ChecksumsAsset))
+	if err != nil {
+		return nil, fmt.Errorf("checksums: %w", err)
+	}
+	sigText, err := fetchSmall(ctx, client, releaseAssetURL(downloadURL, SignatureAsset))
+	if err != nil {
+		return nil, fmt.Errorf("signature: %w", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigText)))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return nil, errors.New("signature: malformed")
+	}
+	if !ed25519.Verify(key, manifest, sig) {
+		return nil, errors.New("signature: checksums not signed by the release key")
+	}
+
+	sums, err := parseChecksums(manifest)
+	if err != nil {
+		return nil, err
+	}
+	asset := releaseAssetName(downloadURL)
+	sum, ok := sums[asset]
+	if !ok {
+		return nil, fmt.Errorf("checksums: no entry for %s", asset)
+	}
+	return sum, nil
+}
+
+// parseChecksums parses sha256sum(1) output. Both text ("  name") and
+// binary ("*name") markers are accepted.
+func parseChecksums(data []byte) (map[string][]byte, error) {
+	sums := make(map[string][]byte)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		hexSum, name, ok := strings.Cut(text, " ")
+		name = strings.TrimPrefix(strings.TrimSpace(name), "*")
+		sum, err := hex.DecodeString(hexSum)
+		if !ok || err != nil || len(sum) != 32 || name == "" {
+			return nil, fmt.Errorf("checksums: malformed line %d", line)
+		}
+		sums[name] = sum
+	}
+	return sums, scanner.Err()
+}
+
+func fetchSmall(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxManifestSize {
+		return nil, fmt.Errorf("larger than %d bytes", maxManifestSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,182 @@
+// Tests for: internal/update/verify.go — signed checksum manifest and rollback.
+
+package update
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeOldBinary(t *testing.T) string {
+	t.Helper()
+	binaryPath := filepath.Join(t.TempDir(), "zcp")
+	if err := os.WriteFile(binaryPath, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return binaryPath
+}
+
+func TestApply_RejectsUnverifiedDownloads(t *testing.T) {
+	t.Parallel()
+
+	binary := []byte("#!/bin/sh\necho new\n")
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+
+	tests := []struct {
+		name    string
+		asset   string // default zcp-linux-amd64
+		tamper  func(r *signedRelease)
+		key     func(r *signedRelease) ed25519.PublicKey
+		wantErr string
+	}{
+		{
+			name:    "truncated download",
+			tamper:  func(r *signedRelease) { r.assets["zcp-linux-amd64"] = binary[:5] },
+			wantErr: "checksum mismatch",
+		},
+		{
+			name:    "tampered manifest",
+			tamper:  func(r *signedRelease) { r.assets[ChecksumsAsset] = append(r.assets[ChecksumsAsset], '\n') },
+			wantErr: "not signed by the release key",
+		},
+		{
+			name:    "foreign key",
+			key:     func(*signedRelease) ed25519.PublicKey { return otherPub },
+			wantErr: "not signed by the release key",
+		},
+		{
+			name:    "missing signature",
+			tamper:  func(r *signedRelease) { delete(r.assets, SignatureAsset) },
+			wantErr: "signature: HTTP 404",
+		},
+		{
+			name:    "asset not in manifest",
+			asset:   "zcp-linux-riscv",
+			tamper:  func(r *signedRelease) { r.assets["zcp-linux-riscv"] = binary },
+			wantErr: "no entry for zcp-linux-riscv",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			release := newSignedRelease(t, "zcp-linux-amd64", binary)
+			asset := "zcp-linux-amd64"
+			if tt.asset != "" {
+				asset = tt.asset
+			}
+			if tt.tamper != nil {
+				tt.tamper(release)
+			}
+			key := release.key
+			if tt.key != nil {
+				key = tt.key(release)
+			}
+			srv := httptest.NewServer(release)
+			defer srv.Close()
+
+			binaryPath := writeOldBinary(t)
+			info := &Info{Available: true, DownloadURL: srv.URL + "/download/v1.2.3/" + asset}
+			err := apply(t.Context(), info, binaryPath, srv.Client(), key)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("apply() error = %v, want %q", err, tt.wantErr)
+			}
+
+			got, _ := os.ReadFile(binaryPath)
+			if string(got) != "old" {
+				t.Error("binary must stay untouched when verification fails")
+			}
+			if _, err := os.Stat(PreviousPath(binaryPath)); !os.IsNotExist(err) {
+				t.Error("no previous binary should be written when verification fails")
+			}
+		})
+	}
+}
+
+func TestApply_NoPublicKey(t *testing.T) {
+	t.Parallel()
+
+	// Package tests never set ReleasePublicKey — the same state as a
+	// local `make build`.
+	err := Apply(t.Context(), &Info{Available: true, DownloadURL: "http://127.0.0.1:1/zcp"}, "/nonexistent", nil)
+	if !errors.Is(err, ErrNoPublicKey) {
+		t.Errorf("Apply() error = %v, want ErrNoPublicKey", err)
+	}
+}
+
+func TestRollback_SwapsBinaries(t *testing.T) {
+	t.Parallel()
+
+	binaryPath := writeOldBinary(t)
+	if err := os.WriteFile(PreviousPath(binaryPath), []byte("older"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Rollback(binaryPath); err != nil {
+		t.Fatalf("Rollback() error: %v", err)
+	}
+	cur, _ := os.ReadFile(binaryPath)
+	prev, _ := os.ReadFile(PreviousPath(binaryPath))
+	if string(cur) != "older" || string(prev) != "old" {
+		t.Errorf("after rollback current=%q prev=%q, want older/old", cur, prev)
+	}
+	if fi, err := os.Stat(PreviousPath(binaryPath)); err != nil || fi.Mode()&0o111 == 0 {
+		t.Error("kept binary should stay executable")
+	}
+
+	// A second rollback returns to the updated binary.
+	if err := Rollback(binaryPath); err != nil {
+		t.Fatalf("second Rollback() error: %v", err)
+	}
+	if cur, _ := os.ReadFile(binaryPath); string(cur) != "old" {
+		t.Errorf("after second rollback current=%q, want old", cur)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(binaryPath))
+	if len(entries) != 2 {
+		t.Errorf("temp files left behind: %v", entries)
+	}
+}
+
+func TestRollback_NoPrevious(t *testing.T) {
+	t.Parallel()
+
+	if err := Rollback(writeOldBinary(t)); !errors.Is(err, ErrNoPrevious) {
+		t.Errorf("Rollback() error = %v, want ErrNoPrevious", err)
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	t.Parallel()
+
+	sum := strings.Repeat("ab", 32)
+	sums, err := parseChecksums([]byte(sum + "  zcp-linux-amd64\n" + sum + " *zcp-win-x64.exe\n\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sums) != 2 || sums["zcp-win-x64.exe"] == nil {
+		t.Errorf("sums = %v", sums)
+	}
+
+	for _, bad := range []string{"nothex  zcp\n", sum + "\n", "abcd  zcp\n"} {
+		if _, err := parseChecksums([]byte(bad)); err == nil {
+			t.Errorf("parseChecksums(%q) should fail", bad)
+		}
+	}
+}
+
+func TestReleaseAssetURL(t *testing.T) {
+	t.Parallel()
+
+	url := "https://github.com/zeropsio/zcp/releases/download/v1.2.3/zcp-linux-amd64"
+	if got := releaseAssetURL(url, SignatureAsset); got != "https://github.com/zeropsio/zcp/releases/download/v1.2.3/SHA256SUMS.sig" {
+		t.Errorf("releaseAssetURL = %q", got)
+	}
+	if got := releaseAssetName(url); got != "zcp-linux-amd64" {
+		t.Errorf("releaseAssetName = %q", got)
+	}
+}
@@ -313,7 +313,12 @@ func (e *Engine) RecipeCompletePlan(plan RecipePlan, attestation string, liveTyp
 		return nil, fmt.Errorf("recipe complete plan save: %w", err)
 	}
 
-	return state.Recipe.BuildResponse(state.SessionID, state.Intent, state.Iteration, e.environment, e.knowledge), nil
+	resp := state.Recipe.BuildResponse(state.SessionID, state.Intent, state.Iteration, e.environment, e.knowledge)
+	if schemas != nil && schemas.Provenance.Stale() {
+		prov := schemas.Provenance
+		resp.SchemaProvenance = &prov
+	}
+	return resp, nil
 }
 
 // RecipeSkip skips the current recipe step (only close is skippable).
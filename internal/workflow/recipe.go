@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/zeropsio/zcp/internal/knowledge"
+	"github.com/zeropsio/zcp/internal/schema"
 )
 
 // WorkflowRecipe is the workflow name for recipe sessions.
@@ -265,6 +266,10 @@ type RecipeResponse struct {
 	// ≤ 5 TodoWrite calls per run (starter + periodic updates). Omitted
 	// on non-start responses.
 	StartingTodos []string `json:"startingTodos,omitempty"`
+	// SchemaProvenance reports where the schemas that validated the plan
+	// came from, set on research completion only when they were stale
+	// (disk cache or embedded snapshot) — type checks were softened then.
+	SchemaProvenance *schema.Provenance `json:"schemaProvenance,omitempty"`
 }
 
 // RecipeProgress summarizes overall recipe progress.
@@ -71,13 +71,17 @@ func ValidateRecipePlan(plan RecipePlan, liveTypes []platform.ServiceStackType,
 }
 
 // validateRuntimeType checks the runtime type against schema enums or liveTypes.
+// A miss against a stale schema (disk cache or embedded snapshot) is not
+// trusted: the type may be newer than the schema, so liveTypes decide.
 func validateRuntimeType(rt string, schemas *schema.Schemas, liveTypes []platform.ServiceStackType) []string {
 	// Prefer schema: check import.yaml service types (authoritative for what can be created).
 	if schemas != nil && schemas.ImportYml != nil {
-		if !schemas.ImportYml.ServiceTypeSet()[rt] {
+		if schemas.ImportYml.ServiceTypeSet()[rt] {
+			return nil
+		}
+		if !schemas.Provenance.Stale() {
 			return []string{fmt.Sprintf("runtimeType %q not found in available service types (schema)", rt)}
 		}
-		return nil
 	}
 	// Fallback: liveTypes from API.
 	if liveTypes != nil && !typeExists(rt, liveTypes) {
@@ -96,16 +100,21 @@ func validateBuildBases(bases []string, schemas *schema.Schemas, liveTypes []pla
 	}
 
 	// Prefer schema: zerops.yaml build.base enum is the authoritative list.
+	// Bases missing from a stale schema fall through to liveTypes.
 	if schemas != nil && schemas.ZeropsYml != nil {
 		baseSet := schemas.ZeropsYml.BuildBaseSet()
-		var errs []string
+		var errs, unknown []string
 		for _, bb := range bases {
 			base, _, _ := strings.Cut(bb, "@")
 			if !baseSet[base] {
 				errs = append(errs, fmt.Sprintf("buildBase %q: base name %q not found in zerops.yaml schema", bb, base))
+				unknown = append(unknown, bb)
 			}
 		}
-		return errs
+		if !schemas.Provenance.Stale() {
+			return errs
+		}
+		bases = unknown
 	}
 
 	// Fallback: check version name bases across all API types.
@@ -136,6 +145,9 @@ func validateBuildBases(bases []string, schemas *schema.Schemas, liveTypes []pla
 }
 
 // validateTargets checks target fields and optionally types against schema.
+// A stale schema (disk cache or embedded snapshot) is not used for type
+// checks: it can lack newly released types and versions, which would turn
+// both the existence check and the latest-version rule into false rejections.
 func validateTargets(targets []RecipeTarget, schemas *schema.Schemas) []string {
 	if len(targets) == 0 {
 		return []string{"at least one target is required"}
@@ -143,7 +155,7 @@ func validateTargets(targets []RecipeTarget, schemas *schema.Schemas) []string {
 
 	var svcTypeSet map[string]bool
 	var svcTypes []string
-	if schemas != nil && schemas.ImportYml != nil {
+	if schemas != nil && schemas.ImportYml != nil && !schemas.Provenance.Stale() {
 		svcTypeSet = schemas.ImportYml.ServiceTypeSet()
 		svcTypes = schemas.ImportYml.ServiceTypes
 	}
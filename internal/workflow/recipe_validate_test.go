@@ -751,6 +751,69 @@ func TestValidateRecipePlan_SchemaTargetTypeValidation(t *testing.T) {
 	}
 }
 
+// TestValidateRecipePlan_StaleSchema pins the softening for schemas
+// served from the disk cache or the embedded snapshot: a type or base the
+// stale schema does not know may be newer than it, so misses defer to
+// liveTypes and are accepted when there are none.
+func TestValidateRecipePlan_StaleSchema(t *testing.T) {
+	t.Parallel()
+
+	liveTypes := []platform.ServiceStackType{
+		{Name: "bun", Category: "USER", Versions: []platform.ServiceStackTypeVersion{{Name: "bun@9", Status: "ACTIVE"}}},
+		{Name: "php-nginx", Category: "USER", Versions: []platform.ServiceStackTypeVersion{{Name: "php-nginx@8.4", Status: "ACTIVE"}}},
+	}
+
+	tests := []struct {
+		name      string
+		modify    func(*RecipePlan)
+		liveTypes []platform.ServiceStackType
+		wantErr   string // empty = plan must validate
+	}{
+		{"unknown runtimeType without liveTypes", func(p *RecipePlan) {
+			p.RuntimeType = "bun@9"
+		}, nil, ""},
+		{"unknown runtimeType present in liveTypes", func(p *RecipePlan) {
+			p.RuntimeType = "bun@9"
+		}, liveTypes, ""},
+		{"unknown runtimeType absent from liveTypes", func(p *RecipePlan) {
+			p.RuntimeType = "foobar@1.0"
+		}, liveTypes, "runtimeType \"foobar@1.0\" not found in available service types"},
+		{"unknown buildBase without liveTypes", func(p *RecipePlan) {
+			p.BuildBases = []string{"php@8.4", "zig@1"}
+		}, nil, ""},
+		{"unknown buildBase absent from liveTypes", func(p *RecipePlan) {
+			p.BuildBases = []string{"php@8.4", "zig@1"}
+		}, liveTypes, "buildBase \"zig@1\": base name \"zig\" not found in available service types"},
+		{"unknown target type", func(p *RecipePlan) {
+			p.Targets = append(p.Targets, RecipeTarget{Hostname: "cache", Type: "valkey@9"})
+		}, nil, ""},
+		{"older managed version without pin reason", func(p *RecipePlan) {
+			p.Targets = append(p.Targets, RecipeTarget{Hostname: "db", Type: "postgresql@16"})
+		}, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			schemas := loadTestSchemas(t)
+			schemas.Provenance = schema.Provenance{Source: schema.SourceEmbedded}
+			plan := validMinimalPlan()
+			tt.modify(&plan)
+
+			errs := ValidateRecipePlan(plan, tt.liveTypes, schemas)
+			if tt.wantErr == "" {
+				if len(errs) > 0 {
+					t.Errorf("expected stale schema to accept the plan, got: %v", errs)
+				}
+				return
+			}
+			if !strings.Contains(strings.Join(errs, "\n"), tt.wantErr) {
+				t.Errorf("expected error containing %q, got: %v", tt.wantErr, errs)
+			}
+		})
+	}
+}
+
 // TestValidateRecipePlan_LatestManagedVersion locks the rule that landed
 // after the v14 nestjs-showcase run shipped postgresql@17 in all six
 // generated environment imports while @18 was available in the catalog.